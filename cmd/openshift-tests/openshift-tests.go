@@ -19,6 +19,7 @@ import (
 	run_monitor "github.com/openshift/origin/pkg/cmd/openshift-tests/monitor/run"
 	"github.com/openshift/origin/pkg/cmd/openshift-tests/monitor/timeline"
 	"github.com/openshift/origin/pkg/cmd/openshift-tests/render"
+	"github.com/openshift/origin/pkg/cmd/openshift-tests/resourcewatch"
 	risk_analysis "github.com/openshift/origin/pkg/cmd/openshift-tests/risk-analysis"
 	"github.com/openshift/origin/pkg/cmd/openshift-tests/run"
 	run_disruption "github.com/openshift/origin/pkg/cmd/openshift-tests/run-disruption"
@@ -89,6 +90,7 @@ func main() {
 		disruption.NewDisruptionCommand(ioStreams),
 		risk_analysis.NewTestFailureRiskAnalysisCommand(),
 		run_resource_watch.NewRunResourceWatchCommand(),
+		resourcewatch.NewResourceWatchCommand(ioStreams),
 		timeline.NewTimelineCommand(ioStreams),
 		run_disruption.NewRunInClusterDisruptionMonitorCommand(ioStreams),
 		collectdiskcertificates.NewRunCollectDiskCertificatesCommand(ioStreams),
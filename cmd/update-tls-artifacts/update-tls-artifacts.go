@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"github.com/openshift/origin/pkg/cmd/update-tls-artifacts/generate-owners"
+	"github.com/openshift/origin/pkg/cmd/update-tls-artifacts/graph"
 
 	"github.com/openshift/library-go/pkg/serviceability"
 	exutil "github.com/openshift/origin/test/extended/util"
@@ -35,6 +36,7 @@ func main() {
 
 	root.AddCommand(
 		generate_owners.NewGenerateOwnershipCommand(streams),
+		graph.NewGraphCommand(streams),
 	)
 
 	f := flag.CommandLine.Lookup("v")
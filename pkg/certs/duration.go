@@ -0,0 +1,41 @@
+package certs
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+var validityDurationPartRegex = regexp.MustCompile(`^(\d+)([ydhms])`)
+
+// ParseValidityDuration reads a CertKeyMetadata.ValidityDuration, which is recorded by duration.HumanDuration, like 2y60d
+// or 23h.  Years are 365 days.  HumanDuration rounds down, so the result can be short by up to one of its smallest unit.
+func ParseValidityDuration(human string) (time.Duration, error) {
+	units := map[string]time.Duration{
+		"y": 365 * 24 * time.Hour,
+		"d": 24 * time.Hour,
+		"h": time.Hour,
+		"m": time.Minute,
+		"s": time.Second,
+	}
+
+	if len(human) == 0 {
+		return 0, fmt.Errorf("missing validity duration")
+	}
+
+	var ret time.Duration
+	for remaining := human; len(remaining) > 0; {
+		match := validityDurationPartRegex.FindStringSubmatch(remaining)
+		if match == nil {
+			return 0, fmt.Errorf("unable to read validity duration %q", human)
+		}
+		value, err := strconv.Atoi(match[1])
+		if err != nil {
+			return 0, fmt.Errorf("unable to read validity duration %q: %w", human, err)
+		}
+		ret += time.Duration(value) * units[match[2]]
+		remaining = remaining[len(match[0]):]
+	}
+	return ret, nil
+}
@@ -0,0 +1,31 @@
+package certs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseValidityDuration(t *testing.T) {
+	tests := map[string]time.Duration{
+		"23h":   23 * time.Hour,
+		"365d":  365 * 24 * time.Hour,
+		"2y60d": (2*365 + 60) * 24 * time.Hour,
+		"5m30s": 5*time.Minute + 30*time.Second,
+		"10y":   10 * 365 * 24 * time.Hour,
+	}
+	for human, expected := range tests {
+		actual, err := ParseValidityDuration(human)
+		if err != nil {
+			t.Errorf("%v: %v", human, err)
+			continue
+		}
+		if actual != expected {
+			t.Errorf("%v: expected %v, got %v", human, expected, actual)
+		}
+	}
+	for _, invalid := range []string{"", "<invalid>", "2y60", "3w"} {
+		if _, err := ParseValidityDuration(invalid); err == nil {
+			t.Errorf("%q: expected an error", invalid)
+		}
+	}
+}
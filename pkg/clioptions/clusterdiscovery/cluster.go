@@ -7,11 +7,11 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
-	"strings"
 
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	clientset "k8s.io/client-go/kubernetes"
@@ -23,6 +23,7 @@ import (
 	configclient "github.com/openshift/client-go/config/clientset/versioned"
 	operatorclient "github.com/openshift/client-go/operator/clientset/versioned"
 	"github.com/openshift/origin/test/extended/util/azure"
+	"github.com/sirupsen/logrus"
 )
 
 type ClusterConfiguration struct {
@@ -70,6 +71,14 @@ type ClusterConfiguration struct {
 
 	// IsNoOptionalCapabilities indicates the cluster has no optional capabilities enabled
 	HasNoOptionalCapabilities bool
+
+	// EnabledFeatureGates and APIGroups are available to skip expressions
+	EnabledFeatureGates []string `json:",omitempty"`
+	APIGroups           []string `json:",omitempty"`
+
+	// UnavailableFacts are the skip expression facts that could not be discovered.  Tests whose skip expressions
+	// refer to them are skipped rather than evaluated against an empty fact.
+	UnavailableFacts []string `json:",omitempty"`
 }
 
 func (c *ClusterConfiguration) ToJSONString() string {
@@ -90,6 +99,9 @@ type ClusterState struct {
 	NetworkSpec          *operatorv1.NetworkSpec
 	ControlPlaneTopology *configv1.TopologyMode
 	OptionalCapabilities []configv1.ClusterVersionCapability
+	EnabledFeatureGates  []string
+	APIGroups            []string
+	UnavailableFacts     []string
 }
 
 // DiscoverClusterState creates a ClusterState based on a live cluster
@@ -154,6 +166,38 @@ func DiscoverClusterState(clientConfig *rest.Config) (*ClusterState, error) {
 	}
 	state.OptionalCapabilities = clusterVersion.Status.Capabilities.EnabledCapabilities
 
+	featureGate, err := configClient.ConfigV1().FeatureGates().Get(context.Background(), "cluster", metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		// no featuregates, MicroShift for instance
+	case err != nil:
+		// only skip expressions use feature gates, so the tests referring to them are skipped rather than failing discovery
+		logrus.WithError(err).Warning("unable to discover enabled feature gates")
+		state.UnavailableFacts = append(state.UnavailableFacts, "featureGates")
+	default:
+		for _, featureGateValues := range featureGate.Status.FeatureGates {
+			if featureGateValues.Version != clusterVersion.Status.Desired.Version {
+				continue
+			}
+			for _, enabled := range featureGateValues.Enabled {
+				state.EnabledFeatureGates = append(state.EnabledFeatureGates, string(enabled.Name))
+			}
+		}
+	}
+
+	groups, err := coreClient.Discovery().ServerGroups()
+	if err != nil {
+		logrus.WithError(err).Warning("unable to discover served API groups")
+		state.UnavailableFacts = append(state.UnavailableFacts, "apiGroups")
+	}
+	if groups != nil {
+		for _, group := range groups.Groups {
+			if len(group.Name) > 0 {
+				state.APIGroups = append(state.APIGroups, group.Name)
+			}
+		}
+	}
+
 	return state, nil
 }
 
@@ -170,6 +214,9 @@ func LoadConfig(state *ClusterState) (*ClusterConfiguration, error) {
 		MultiZone:             zones.Len() > 1,
 		Zones:                 zones.List(),
 		SingleReplicaTopology: *state.ControlPlaneTopology == configv1.SingleReplicaTopologyMode,
+		EnabledFeatureGates:   sets.List(sets.New[string](state.EnabledFeatureGates...)),
+		APIGroups:             sets.List(sets.New[string](state.APIGroups...)),
+		UnavailableFacts:      state.UnavailableFacts,
 	}
 
 	config.HasNoOptionalCapabilities = len(state.OptionalCapabilities) == 0
@@ -275,57 +322,8 @@ func LoadConfig(state *ClusterState) (*ClusterConfiguration, error) {
 // MatchFn returns a function that tests if a named function should be run based on
 // the cluster configuration
 func (c *ClusterConfiguration) MatchFn() func(string) bool {
-	var skips []string
-	skips = append(skips, fmt.Sprintf("[Skipped:%s]", c.ProviderName))
-
-	if c.IsIBMROKS {
-		skips = append(skips, "[Skipped:ibmroks]")
-	}
-	if c.NetworkPlugin != "" {
-		skips = append(skips, fmt.Sprintf("[Skipped:Network/%s]", c.NetworkPlugin))
-		if c.NetworkPluginMode != "" {
-			skips = append(skips, fmt.Sprintf("[Skipped:Network/%s/%s]", c.NetworkPlugin, c.NetworkPluginMode))
-		}
-	}
-
-	if c.Disconnected {
-		skips = append(skips, "[Skipped:Disconnected]")
-	}
-
-	if c.IsProxied {
-		skips = append(skips, "[Skipped:Proxy]")
-	}
-
-	if c.SingleReplicaTopology {
-		skips = append(skips, "[Skipped:SingleReplicaTopology]")
-	}
-
-	if !c.HasIPv4 {
-		skips = append(skips, "[Feature:Networking-IPv4]")
-	}
-	if !c.HasIPv6 {
-		skips = append(skips, "[Feature:Networking-IPv6]")
-	}
-	if !c.HasIPv4 || !c.HasIPv6 {
-		// lack of "]" is intentional; this matches multiple tags
-		skips = append(skips, "[Feature:IPv6DualStack")
-	}
-
-	if !c.HasSCTP {
-		skips = append(skips, "[Feature:SCTPConnectivity]")
-	}
-
-	if c.HasNoOptionalCapabilities {
-		skips = append(skips, "[Skipped:NoOptionalCapabilities]")
-	}
-
-	matchFn := func(name string) bool {
-		for _, skip := range skips {
-			if strings.Contains(name, skip) {
-				return false
-			}
-		}
-		return true
+	rejectionReasonFn := c.RejectionReasonFn(nil)
+	return func(name string) bool {
+		return len(rejectionReasonFn(name)) == 0
 	}
-	return matchFn
 }
@@ -0,0 +1,386 @@
+package clusterdiscovery
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// ClusterFacts are the properties of a cluster that skip expressions can refer to.
+type ClusterFacts struct {
+	Platform          string
+	NetworkPlugin     string
+	NetworkPluginMode string
+	// Topology is SingleReplica or HighlyAvailable
+	Topology string
+
+	Disconnected         bool
+	Proxied              bool
+	IBMROKS              bool
+	SCTP                 bool
+	IPv4                 bool
+	IPv6                 bool
+	OptionalCapabilities bool
+
+	FeatureGates sets.Set[string]
+	APIGroups    sets.Set[string]
+}
+
+// Facts describes the cluster for skip expressions.
+func (c *ClusterConfiguration) Facts() *ClusterFacts {
+	topology := "HighlyAvailable"
+	if c.SingleReplicaTopology {
+		topology = "SingleReplica"
+	}
+	return &ClusterFacts{
+		Platform:             c.ProviderName,
+		NetworkPlugin:        c.NetworkPlugin,
+		NetworkPluginMode:    c.NetworkPluginMode,
+		Topology:             topology,
+		Disconnected:         c.Disconnected,
+		Proxied:              c.IsProxied,
+		IBMROKS:              c.IsIBMROKS,
+		SCTP:                 c.HasSCTP,
+		IPv4:                 c.HasIPv4,
+		IPv6:                 c.HasIPv6,
+		OptionalCapabilities: !c.HasNoOptionalCapabilities,
+		FeatureGates:         sets.New[string](c.EnabledFeatureGates...),
+		APIGroups:            sets.New[string](c.APIGroups...),
+	}
+}
+
+type valueType string
+
+const (
+	boolType      valueType = "bool"
+	stringType    valueType = "string"
+	stringSetType valueType = "set of strings"
+)
+
+type value struct {
+	b   bool
+	s   string
+	set sets.Set[string]
+}
+
+type clusterFact struct {
+	valueType valueType
+	value     func(*ClusterFacts) value
+}
+
+// clusterFactIdentifiers are the names expressions use for each ClusterFacts field.
+var clusterFactIdentifiers = map[string]clusterFact{
+	"platform":             {stringType, func(f *ClusterFacts) value { return value{s: f.Platform} }},
+	"network":              {stringType, func(f *ClusterFacts) value { return value{s: f.NetworkPlugin} }},
+	"networkMode":          {stringType, func(f *ClusterFacts) value { return value{s: f.NetworkPluginMode} }},
+	"topology":             {stringType, func(f *ClusterFacts) value { return value{s: f.Topology} }},
+	"disconnected":         {boolType, func(f *ClusterFacts) value { return value{b: f.Disconnected} }},
+	"proxied":              {boolType, func(f *ClusterFacts) value { return value{b: f.Proxied} }},
+	"ibmroks":              {boolType, func(f *ClusterFacts) value { return value{b: f.IBMROKS} }},
+	"sctp":                 {boolType, func(f *ClusterFacts) value { return value{b: f.SCTP} }},
+	"ipv4":                 {boolType, func(f *ClusterFacts) value { return value{b: f.IPv4} }},
+	"ipv6":                 {boolType, func(f *ClusterFacts) value { return value{b: f.IPv6} }},
+	"dualstack":            {boolType, func(f *ClusterFacts) value { return value{b: f.IPv4 && f.IPv6} }},
+	"optionalCapabilities": {boolType, func(f *ClusterFacts) value { return value{b: f.OptionalCapabilities} }},
+	"featureGates":         {stringSetType, func(f *ClusterFacts) value { return value{set: f.FeatureGates} }},
+	"apiGroups":            {stringSetType, func(f *ClusterFacts) value { return value{set: f.APIGroups} }},
+}
+
+// ClusterExpression is a compiled, type checked expression over ClusterFacts, for instance
+//
+//	platform == "gce" && !ipv6 || "openshift.io" in apiGroups
+//
+// Operands are fact identifiers, "double quoted" strings, true and false.  Strings compare with == and !=, a string
+// is tested for membership in a set with in, and booleans combine with !, && and ||, in decreasing precedence.
+type ClusterExpression struct {
+	source string
+	root   expressionNode
+	// facts are the identifiers of the facts the expression refers to
+	facts sets.Set[string]
+}
+
+func (e *ClusterExpression) String() string {
+	return e.source
+}
+
+// RefersTo reports whether the expression uses any of the named facts.
+func (e *ClusterExpression) RefersTo(facts ...string) bool {
+	return e.facts.HasAny(facts...)
+}
+
+// Evaluate reports whether the expression is true for the cluster.
+func (e *ClusterExpression) Evaluate(facts *ClusterFacts) bool {
+	return e.root.eval(facts).b
+}
+
+// CompileClusterExpression parses and type checks an expression.  The expression must be boolean.
+func CompileClusterExpression(source string) (*ClusterExpression, error) {
+	tokens, err := tokenizeExpression(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", source, err)
+	}
+	p := &expressionParser{tokens: tokens, facts: sets.New[string]()}
+	root, err := p.parseOr()
+	if err == nil && p.peek().kind != endToken {
+		err = fmt.Errorf("unexpected %s", p.peek())
+	}
+	if err == nil && root.valueType() != boolType {
+		err = fmt.Errorf("expression is a %s, not a bool", root.valueType())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", source, err)
+	}
+	return &ClusterExpression{source: source, root: root, facts: p.facts}, nil
+}
+
+type tokenKind string
+
+const (
+	identifierToken tokenKind = "identifier"
+	stringToken     tokenKind = "string"
+	operatorToken   tokenKind = "operator"
+	endToken        tokenKind = "end of expression"
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+func (t token) String() string {
+	if t.kind == endToken {
+		return string(t.kind)
+	}
+	return fmt.Sprintf("%s %q", t.kind, t.text)
+}
+
+var expressionOperators = []string{"==", "!=", "&&", "||", "!", "(", ")"}
+
+func tokenizeExpression(source string) ([]token, error) {
+	tokens := []token{}
+	for i := 0; i < len(source); {
+		c := rune(source[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"':
+			end := strings.IndexByte(source[i+1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string at offset %d", i)
+			}
+			tokens = append(tokens, token{kind: stringToken, text: source[i+1 : i+1+end]})
+			i += end + 2
+		case unicode.IsLetter(c):
+			start := i
+			for i < len(source) && (unicode.IsLetter(rune(source[i])) || unicode.IsDigit(rune(source[i]))) {
+				i++
+			}
+			tokens = append(tokens, token{kind: identifierToken, text: source[start:i]})
+		default:
+			matched := false
+			for _, operator := range expressionOperators {
+				if strings.HasPrefix(source[i:], operator) {
+					tokens = append(tokens, token{kind: operatorToken, text: operator})
+					i += len(operator)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected character %q at offset %d", c, i)
+			}
+		}
+	}
+	return append(tokens, token{kind: endToken}), nil
+}
+
+type expressionParser struct {
+	tokens []token
+	next   int
+	facts  sets.Set[string]
+}
+
+func (p *expressionParser) peek() token {
+	return p.tokens[p.next]
+}
+
+func (p *expressionParser) take() token {
+	t := p.tokens[p.next]
+	if t.kind != endToken {
+		p.next++
+	}
+	return t
+}
+
+func (p *expressionParser) takeOperator(operator string) bool {
+	if t := p.peek(); t.kind == operatorToken && t.text == operator {
+		p.next++
+		return true
+	}
+	return false
+}
+
+func (p *expressionParser) parseOr() (expressionNode, error) {
+	return p.parseBinary("||", p.parseAnd)
+}
+
+func (p *expressionParser) parseAnd() (expressionNode, error) {
+	return p.parseBinary("&&", p.parseUnary)
+}
+
+func (p *expressionParser) parseBinary(operator string, parseOperand func() (expressionNode, error)) (expressionNode, error) {
+	left, err := parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	for p.takeOperator(operator) {
+		right, err := parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		for _, operand := range []expressionNode{left, right} {
+			if operand.valueType() != boolType {
+				return nil, fmt.Errorf("%s needs bool operands, not a %s", operator, operand.valueType())
+			}
+		}
+		left = &logicalNode{and: operator == "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *expressionParser) parseUnary() (expressionNode, error) {
+	if !p.takeOperator("!") {
+		return p.parseComparison()
+	}
+	operand, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	if operand.valueType() != boolType {
+		return nil, fmt.Errorf("! needs a bool operand, not a %s", operand.valueType())
+	}
+	return &notNode{operand: operand}, nil
+}
+
+func (p *expressionParser) parseComparison() (expressionNode, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	switch t := p.peek(); {
+	case t.kind == operatorToken && (t.text == "==" || t.text == "!="):
+		p.take()
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		if left.valueType() != right.valueType() || left.valueType() == stringSetType {
+			return nil, fmt.Errorf("cannot compare a %s with a %s", left.valueType(), right.valueType())
+		}
+		return &equalityNode{negate: t.text == "!=", left: left, right: right}, nil
+	case t.kind == identifierToken && t.text == "in":
+		p.take()
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		if left.valueType() != stringType || right.valueType() != stringSetType {
+			return nil, fmt.Errorf("in needs a string and a set of strings, not a %s and a %s", left.valueType(), right.valueType())
+		}
+		return &membershipNode{element: left, set: right}, nil
+	}
+	return left, nil
+}
+
+func (p *expressionParser) parseOperand() (expressionNode, error) {
+	t := p.take()
+	switch {
+	case t.kind == operatorToken && t.text == "(":
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.takeOperator(")") {
+			return nil, fmt.Errorf("expected \")\", found %s", p.peek())
+		}
+		return inner, nil
+	case t.kind == stringToken:
+		return &literalNode{literalType: stringType, literal: value{s: t.text}}, nil
+	case t.kind == identifierToken && (t.text == "true" || t.text == "false"):
+		return &literalNode{literalType: boolType, literal: value{b: t.text == "true"}}, nil
+	case t.kind == identifierToken:
+		fact, ok := clusterFactIdentifiers[t.text]
+		if !ok {
+			return nil, fmt.Errorf("unknown identifier %q", t.text)
+		}
+		p.facts.Insert(t.text)
+		return &factNode{fact: fact}, nil
+	}
+	return nil, fmt.Errorf("unexpected %s", t)
+}
+
+type expressionNode interface {
+	valueType() valueType
+	eval(facts *ClusterFacts) value
+}
+
+type literalNode struct {
+	literalType valueType
+	literal     value
+}
+
+func (n *literalNode) valueType() valueType           { return n.literalType }
+func (n *literalNode) eval(facts *ClusterFacts) value { return n.literal }
+
+type factNode struct {
+	fact clusterFact
+}
+
+func (n *factNode) valueType() valueType           { return n.fact.valueType }
+func (n *factNode) eval(facts *ClusterFacts) value { return n.fact.value(facts) }
+
+type notNode struct {
+	operand expressionNode
+}
+
+func (n *notNode) valueType() valueType { return boolType }
+func (n *notNode) eval(facts *ClusterFacts) value {
+	return value{b: !n.operand.eval(facts).b}
+}
+
+type logicalNode struct {
+	and         bool
+	left, right expressionNode
+}
+
+func (n *logicalNode) valueType() valueType { return boolType }
+func (n *logicalNode) eval(facts *ClusterFacts) value {
+	left := n.left.eval(facts).b
+	if n.and != left {
+		// false && x and true || x are decided by the left side
+		return value{b: left}
+	}
+	return n.right.eval(facts)
+}
+
+type equalityNode struct {
+	negate      bool
+	left, right expressionNode
+}
+
+func (n *equalityNode) valueType() valueType { return boolType }
+func (n *equalityNode) eval(facts *ClusterFacts) value {
+	left, right := n.left.eval(facts), n.right.eval(facts)
+	return value{b: (left.b == right.b && left.s == right.s) != n.negate}
+}
+
+type membershipNode struct {
+	element, set expressionNode
+}
+
+func (n *membershipNode) valueType() valueType { return boolType }
+func (n *membershipNode) eval(facts *ClusterFacts) value {
+	return value{b: n.set.eval(facts).set.Has(n.element.eval(facts).s)}
+}
@@ -0,0 +1,102 @@
+package clusterdiscovery
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestCompileClusterExpression(t *testing.T) {
+	facts := &ClusterFacts{
+		Platform:      "gce",
+		NetworkPlugin: "OVNKubernetes",
+		Topology:      "HighlyAvailable",
+		IPv4:          true,
+		FeatureGates:  sets.New[string]("GatewayAPI"),
+		APIGroups:     sets.New[string]("route.openshift.io"),
+	}
+
+	tests := []struct {
+		expression string
+		expected   bool
+		err        string
+	}{
+		{expression: `platform == "gce"`, expected: true},
+		{expression: `platform != "gce"`, expected: false},
+		{expression: `"gce" == platform`, expected: true},
+		{expression: `ipv4`, expected: true},
+		{expression: `!ipv4`, expected: false},
+		{expression: `!!ipv4`, expected: true},
+		{expression: `dualstack`, expected: false},
+		{expression: `ipv4 == true`, expected: true},
+		{expression: `ipv6 != false`, expected: false},
+		{expression: `true`, expected: true},
+		{expression: `platform == "aws" || platform == "gce"`, expected: true},
+		{expression: `platform == "gce" && disconnected`, expected: false},
+		// && binds tighter than ||
+		{expression: `ipv4 || ipv6 && disconnected`, expected: true},
+		{expression: `(ipv4 || ipv6) && disconnected`, expected: false},
+		{expression: `"GatewayAPI" in featureGates`, expected: true},
+		{expression: `!("Other" in featureGates)`, expected: true},
+		{expression: `"route.openshift.io" in apiGroups && network == "OVNKubernetes"`, expected: true},
+		{expression: `"" in apiGroups`, expected: false},
+		{expression: `platform in featureGates`, expected: false},
+		{expression: `networkMode == ""`, expected: true},
+		{expression: `topology == "SingleReplica"`, expected: false},
+		{expression: "platform==\"gce\"&&\tipv4", expected: true},
+
+		{expression: ``, err: "unexpected end of expression"},
+		{expression: `platform`, err: "expression is a string, not a bool"},
+		{expression: `featureGates`, err: "expression is a set of strings, not a bool"},
+		{expression: `platform == ipv4`, err: "cannot compare a string with a bool"},
+		{expression: `featureGates == apiGroups`, err: "cannot compare a set of strings with a set of strings"},
+		{expression: `!platform`, err: "! needs a bool operand, not a string"},
+		{expression: `ipv4 && platform`, err: "&& needs bool operands, not a string"},
+		{expression: `platform || ipv4`, err: "|| needs bool operands, not a string"},
+		{expression: `ipv4 in featureGates`, err: "in needs a string and a set of strings, not a bool and a set of strings"},
+		{expression: `"a" in platform`, err: "in needs a string and a set of strings, not a string and a string"},
+		{expression: `region == "us-east-1"`, err: `unknown identifier "region"`},
+		{expression: `platform == "gce`, err: "unterminated string"},
+		{expression: `platform = "gce"`, err: `unexpected character '='`},
+		{expression: `(ipv4`, err: `expected ")", found end of expression`},
+		{expression: `ipv4)`, err: `unexpected operator ")"`},
+		{expression: `ipv4 ipv6`, err: `unexpected identifier "ipv6"`},
+	}
+	for _, test := range tests {
+		t.Run(test.expression, func(t *testing.T) {
+			expression, err := CompileClusterExpression(test.expression)
+			switch {
+			case len(test.err) > 0 && err == nil:
+				t.Fatalf("expected error containing %q", test.err)
+			case len(test.err) > 0 && !strings.Contains(err.Error(), test.err):
+				t.Fatalf("expected error containing %q, got %v", test.err, err)
+			case len(test.err) > 0:
+				return
+			case err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual := expression.Evaluate(facts); actual != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestClusterConfigurationFacts(t *testing.T) {
+	facts := (&ClusterConfiguration{
+		ProviderName:              "aws",
+		SingleReplicaTopology:     true,
+		HasIPv6:                   true,
+		HasNoOptionalCapabilities: true,
+		EnabledFeatureGates:       []string{"GatewayAPI"},
+	}).Facts()
+
+	expression, err := CompileClusterExpression(`platform == "aws" && topology == "SingleReplica" && !ipv4 && ipv6 && !optionalCapabilities && "GatewayAPI" in featureGates && !("route.openshift.io" in apiGroups)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !expression.Evaluate(facts) {
+		t.Errorf("expected the facts to describe the configuration: %#v", facts)
+	}
+}
@@ -0,0 +1,219 @@
+package clusterdiscovery
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/yaml"
+)
+
+// SkipRule skips the tests matching any of the Tests regular expressions on clusters where When is true.
+type SkipRule struct {
+	When   string   `json:"when"`
+	Tests  []string `json:"tests"`
+	Reason string   `json:"reason,omitempty"`
+
+	expression *ClusterExpression
+	tests      []*regexp.Regexp
+}
+
+// LoadSkipRules reads and compiles a YAML or JSON list of skip rules, like
+//
+//	# services on single stack IPv6 GCE clusters
+//	- when: platform == "gce" && !ipv4
+//	  tests:
+//	  - '\[sig-network\] Services should serve endpoints on same port'
+//	  reason: https://issues.redhat.com/browse/OCPBUGS-1
+func LoadSkipRules(path string) ([]SkipRule, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	rules := []SkipRule{}
+	if err := yaml.Unmarshal(content, &rules); err != nil {
+		return nil, fmt.Errorf("failed to read skip rules from %s: %w", path, err)
+	}
+	for i := range rules {
+		if err := rules[i].compile(); err != nil {
+			return nil, fmt.Errorf("skip rule %d in %s: %w", i, path, err)
+		}
+	}
+	return rules, nil
+}
+
+func (r *SkipRule) compile() error {
+	expression, err := CompileClusterExpression(r.When)
+	if err != nil {
+		return err
+	}
+	if len(r.Tests) == 0 {
+		return fmt.Errorf("no tests listed")
+	}
+	r.expression = expression
+	r.tests = nil
+	for _, test := range r.Tests {
+		re, err := regexp.Compile(test)
+		if err != nil {
+			return err
+		}
+		r.tests = append(r.tests, re)
+	}
+	return nil
+}
+
+func (r *SkipRule) matchesTest(name string) bool {
+	for _, re := range r.tests {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	// skippedLabelRegex finds [Skipped:<value>] labels.
+	skippedLabelRegex = regexp.MustCompile(`\[Skipped:([^\]]*)\]`)
+	// skipWhenLabelRegex finds [SkipWhen:<expression>] labels.
+	skipWhenLabelRegex = regexp.MustCompile(`\[SkipWhen:([^\]]*)\]`)
+
+	// featureLabelExpressions skip tests for features the cluster lacks.  The dual stack label has no closing bracket
+	// because it matches several labels, like [Feature:IPv6DualStack] and [Feature:IPv6DualStackAlpha].
+	featureLabelExpressions = [][2]string{
+		{"[Feature:Networking-IPv4]", "!ipv4"},
+		{"[Feature:Networking-IPv6]", "!ipv6"},
+		{"[Feature:IPv6DualStack", "!dualstack"},
+		{"[Feature:SCTPConnectivity]", "!sctp"},
+	}
+
+	// skippedLabelExpressions are the [Skipped:<value>] labels that are about something other than the platform.
+	skippedLabelExpressions = map[string]string{
+		"ibmroks":                "ibmroks",
+		"Disconnected":           "disconnected",
+		"Proxy":                  "proxied",
+		"SingleReplicaTopology":  `topology == "SingleReplica"`,
+		"NoOptionalCapabilities": "!optionalCapabilities",
+	}
+)
+
+// skippedLabelExpression compiles the value of a [Skipped:<value>] label.  Every value names a platform, and some
+// values also name other facts of the cluster.
+func skippedLabelExpression(skipped string) string {
+	expression := fmt.Sprintf("platform == %q", skipped)
+	if other, ok := skippedLabelExpressions[skipped]; ok {
+		return expression + " || " + other
+	}
+	if network, ok := strings.CutPrefix(skipped, "Network/"); ok {
+		plugin, mode, hasMode := strings.Cut(network, "/")
+		switch {
+		case len(plugin) == 0:
+		case !hasMode:
+			expression += fmt.Sprintf(" || network == %q", plugin)
+		case len(mode) > 0:
+			expression += fmt.Sprintf(" || network == %q && networkMode == %q", plugin, mode)
+		}
+	}
+	return expression
+}
+
+// labelExpressions returns the label and skip expression source of every skip label in the test name.
+func labelExpressions(name string) [][2]string {
+	ret := [][2]string{}
+	for _, match := range skippedLabelRegex.FindAllStringSubmatch(name, -1) {
+		ret = append(ret, [2]string{match[0], skippedLabelExpression(match[1])})
+	}
+	for _, labelExpression := range featureLabelExpressions {
+		if strings.Contains(name, labelExpression[0]) {
+			ret = append(ret, labelExpression)
+		}
+	}
+	for _, match := range skipWhenLabelRegex.FindAllStringSubmatch(name, -1) {
+		ret = append(ret, [2]string{match[0], match[1]})
+	}
+	return ret
+}
+
+// ValidateSkipLabels returns an error for every distinct skip label expression in the test names that does not
+// compile.  RejectionReasonFn does not skip tests for labels it cannot compile, so a typo in a label must fail the run
+// instead of quietly dropping the test from it.
+func ValidateSkipLabels(names []string) error {
+	var errs []error
+	seen := sets.NewString()
+	for _, name := range names {
+		for _, labelExpression := range labelExpressions(name) {
+			if seen.Has(labelExpression[1]) {
+				continue
+			}
+			seen.Insert(labelExpression[1])
+			if _, err := CompileClusterExpression(labelExpression[1]); err != nil {
+				errs = append(errs, fmt.Errorf("%s on %q: %w", labelExpression[0], name, err))
+			}
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// RejectionReasonFn returns a function that explains why a test is skipped on this cluster, or returns an empty
+// string if the test may run.  Tests are skipped when an expression from one of their labels, or from a skip rule
+// matching their name, is true for the cluster.  Labels that do not compile are left to ValidateSkipLabels.
+//
+// An expression that refers to a fact discovery could not determine, like featureGates when the FeatureGate could not
+// be read, cannot be evaluated.  Rather than deciding against an empty fact, the test is skipped with a reason that
+// says so.
+func (c *ClusterConfiguration) RejectionReasonFn(skipRules []SkipRule) func(string) string {
+	facts := c.Facts()
+	unavailable := func(expression *ClusterExpression) string {
+		if !expression.RefersTo(c.UnavailableFacts...) {
+			return ""
+		}
+		return fmt.Sprintf("%s cannot be evaluated because %s could not be discovered", expression, strings.Join(c.UnavailableFacts, ", "))
+	}
+
+	// the same labels appear on many tests, so only compile and evaluate each once
+	lock := sync.Mutex{}
+	skipsByExpression := map[string]string{}
+	skips := func(source string) string {
+		lock.Lock()
+		defer lock.Unlock()
+		if reason, ok := skipsByExpression[source]; ok {
+			return reason
+		}
+		reason := ""
+		if expression, err := CompileClusterExpression(source); err == nil {
+			reason = unavailable(expression)
+			if len(reason) == 0 && expression.Evaluate(facts) {
+				reason = fmt.Sprintf("%s is true", expression)
+			}
+		}
+		skipsByExpression[source] = reason
+		return reason
+	}
+
+	return func(name string) string {
+		for _, labelExpression := range labelExpressions(name) {
+			if reason := skips(labelExpression[1]); len(reason) > 0 {
+				return fmt.Sprintf("skipped by %s: %s", labelExpression[0], reason)
+			}
+		}
+		for _, rule := range skipRules {
+			if !rule.matchesTest(name) {
+				continue
+			}
+			if reason := unavailable(rule.expression); len(reason) > 0 {
+				return fmt.Sprintf("skipped by rule: %s", reason)
+			}
+			if !rule.expression.Evaluate(facts) {
+				continue
+			}
+			if len(rule.Reason) > 0 {
+				return fmt.Sprintf("skipped by rule when %s: %s", rule.When, rule.Reason)
+			}
+			return fmt.Sprintf("skipped by rule when %s", rule.When)
+		}
+		return ""
+	}
+}
@@ -0,0 +1,268 @@
+package clusterdiscovery
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// legacySkips is the substring matching that compiled skip labels replaced, kept to prove they skip the same tests.
+func legacySkips(c *ClusterConfiguration) []string {
+	skips := []string{fmt.Sprintf("[Skipped:%s]", c.ProviderName)}
+	if c.IsIBMROKS {
+		skips = append(skips, "[Skipped:ibmroks]")
+	}
+	if c.NetworkPlugin != "" {
+		skips = append(skips, fmt.Sprintf("[Skipped:Network/%s]", c.NetworkPlugin))
+		if c.NetworkPluginMode != "" {
+			skips = append(skips, fmt.Sprintf("[Skipped:Network/%s/%s]", c.NetworkPlugin, c.NetworkPluginMode))
+		}
+	}
+	if c.Disconnected {
+		skips = append(skips, "[Skipped:Disconnected]")
+	}
+	if c.IsProxied {
+		skips = append(skips, "[Skipped:Proxy]")
+	}
+	if c.SingleReplicaTopology {
+		skips = append(skips, "[Skipped:SingleReplicaTopology]")
+	}
+	if !c.HasIPv4 {
+		skips = append(skips, "[Feature:Networking-IPv4]")
+	}
+	if !c.HasIPv6 {
+		skips = append(skips, "[Feature:Networking-IPv6]")
+	}
+	if !c.HasIPv4 || !c.HasIPv6 {
+		skips = append(skips, "[Feature:IPv6DualStack")
+	}
+	if !c.HasSCTP {
+		skips = append(skips, "[Feature:SCTPConnectivity]")
+	}
+	if c.HasNoOptionalCapabilities {
+		skips = append(skips, "[Skipped:NoOptionalCapabilities]")
+	}
+	return skips
+}
+
+func TestSkipLabelsMatchLegacySkips(t *testing.T) {
+	labels := []string{
+		"[Skipped:gce]",
+		"[Skipped:aws]",
+		"[Skipped:ibmroks]",
+		"[Skipped:ibmcloud]",
+		"[Skipped:Disconnected]",
+		"[Skipped:Proxy]",
+		"[Skipped:SingleReplicaTopology]",
+		"[Skipped:NoOptionalCapabilities]",
+		"[Skipped:Network/OVNKubernetes]",
+		"[Skipped:Network/OpenShiftSDN]",
+		"[Skipped:Network/OVNKubernetes/Multitenant]",
+		"[Skipped:Network/OpenShiftSDN/Multitenant]",
+		"[Skipped:Network/OpenShiftSDN/]",
+		"[Skipped:Network/]",
+		"[Feature:Networking-IPv4]",
+		"[Feature:Networking-IPv6]",
+		"[Feature:IPv6DualStack]",
+		"[Feature:IPv6DualStackAlpha]",
+		"[Feature:SCTPConnectivity]",
+		"[Feature:Other]",
+	}
+	// every test name with up to two labels
+	names := []string{"[sig-test] no labels"}
+	for i, first := range labels {
+		names = append(names, "[sig-test] one label "+first)
+		for _, second := range labels[i+1:] {
+			names = append(names, "[sig-test] two labels "+first+" "+second)
+		}
+	}
+
+	configurations := []*ClusterConfiguration{}
+	for _, provider := range []string{"gce", "aws", "ibmcloud", "skeleton", ""} {
+		for _, network := range [][2]string{{"", ""}, {"OVNKubernetes", ""}, {"OpenShiftSDN", "Multitenant"}, {"OpenShiftSDN", ""}} {
+			// every combination of the boolean settings
+			for bits := 0; bits < 1<<8; bits++ {
+				configurations = append(configurations, &ClusterConfiguration{
+					ProviderName:              provider,
+					NetworkPlugin:             network[0],
+					NetworkPluginMode:         network[1],
+					IsIBMROKS:                 bits&(1<<0) != 0,
+					Disconnected:              bits&(1<<1) != 0,
+					IsProxied:                 bits&(1<<2) != 0,
+					SingleReplicaTopology:     bits&(1<<3) != 0,
+					HasIPv4:                   bits&(1<<4) != 0,
+					HasIPv6:                   bits&(1<<5) != 0,
+					HasSCTP:                   bits&(1<<6) != 0,
+					HasNoOptionalCapabilities: bits&(1<<7) != 0,
+				})
+			}
+		}
+	}
+
+	for _, c := range configurations {
+		skips := legacySkips(c)
+		rejectionReason := c.RejectionReasonFn(nil)
+		for _, name := range names {
+			legacySkipped := false
+			for _, skip := range skips {
+				if strings.Contains(name, skip) {
+					legacySkipped = true
+					break
+				}
+			}
+			if reason := rejectionReason(name); legacySkipped != (len(reason) > 0) {
+				t.Fatalf("%q on %#v: legacy skipped=%v, reason=%q", name, c, legacySkipped, reason)
+			}
+		}
+	}
+}
+
+func TestRejectionReasonFn(t *testing.T) {
+	dir := t.TempDir()
+	rulesFile := filepath.Join(dir, "rules.yaml")
+	rules := `
+- when: platform == "gce" && !ipv4
+  tests:
+  - '^\[sig-network\] Services '
+  reason: broken on single stack IPv6
+- when: '"GatewayAPI" in featureGates'
+  tests:
+  - Gateway
+`
+	if err := os.WriteFile(rulesFile, []byte(rules), 0644); err != nil {
+		t.Fatal(err)
+	}
+	skipRules, err := LoadSkipRules(rulesFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &ClusterConfiguration{
+		ProviderName:        "gce",
+		HasIPv6:             true,
+		EnabledFeatureGates: []string{"GatewayAPI"},
+	}
+	rejectionReason := c.RejectionReasonFn(skipRules)
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{name: "[sig-node] runs", expected: ""},
+		{name: "[sig-node] platform [Skipped:gce]", expected: `skipped by [Skipped:gce]: platform == "gce" is true`},
+		{name: "[sig-node] feature [Feature:Networking-IPv4]", expected: "skipped by [Feature:Networking-IPv4]: !ipv4 is true"},
+		{name: `[sig-node] label [SkipWhen:"GatewayAPI" in featureGates && topology == "HighlyAvailable"]`, expected: `skipped by [SkipWhen:"GatewayAPI" in featureGates && topology == "HighlyAvailable"]: "GatewayAPI" in featureGates && topology == "HighlyAvailable" is true`},
+		{name: `[sig-node] label [SkipWhen:platform == "aws"]`, expected: ""},
+		// left to ValidateSkipLabels
+		{name: `[sig-node] invalid label [SkipWhen:region == "east"]`, expected: ""},
+		{name: "[sig-network] Services should work", expected: `skipped by rule when platform == "gce" && !ipv4: broken on single stack IPv6`},
+		{name: "[sig-network] Gateway should work", expected: `skipped by rule when "GatewayAPI" in featureGates`},
+	}
+	for _, test := range tests {
+		if actual := rejectionReason(test.name); actual != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.name, test.expected, actual)
+		}
+	}
+}
+
+func TestRejectionReasonFnWithUnavailableFacts(t *testing.T) {
+	dir := t.TempDir()
+	rulesFile := filepath.Join(dir, "rules.yaml")
+	rules := `
+- when: '"GatewayAPI" in featureGates'
+  tests:
+  - Gateway
+`
+	if err := os.WriteFile(rulesFile, []byte(rules), 0644); err != nil {
+		t.Fatal(err)
+	}
+	skipRules, err := LoadSkipRules(rulesFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &ClusterConfiguration{
+		ProviderName:     "gce",
+		HasIPv4:          true,
+		APIGroups:        []string{"config.openshift.io"},
+		UnavailableFacts: []string{"featureGates"},
+	}
+	rejectionReason := c.RejectionReasonFn(skipRules)
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		// facts that were discovered are still evaluated
+		{name: "[sig-node] platform [Skipped:aws]", expected: ""},
+		{name: `[sig-node] api group [SkipWhen:!("config.openshift.io" in apiGroups)]`, expected: ""},
+		// an empty set of feature gates would run this test
+		{name: `[sig-node] feature gate [SkipWhen:!("GatewayAPI" in featureGates)]`, expected: `skipped by [SkipWhen:!("GatewayAPI" in featureGates)]: !("GatewayAPI" in featureGates) cannot be evaluated because featureGates could not be discovered`},
+		// and would not skip this one
+		{name: "[sig-network] Gateway should work", expected: `skipped by rule: "GatewayAPI" in featureGates cannot be evaluated because featureGates could not be discovered`},
+	}
+	for _, test := range tests {
+		if actual := rejectionReason(test.name); actual != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.name, test.expected, actual)
+		}
+	}
+}
+
+func TestValidateSkipLabels(t *testing.T) {
+	tests := []struct {
+		name  string
+		names []string
+		err   string
+	}{
+		{name: "no labels", names: []string{"[sig-node] runs"}},
+		{
+			name: "valid labels",
+			names: []string{
+				"[sig-node] platform [Skipped:gce]",
+				"[sig-node] network [Skipped:Network/OVNKubernetes/Multitenant]",
+				`[sig-node] label [SkipWhen:"GatewayAPI" in featureGates && topology == "HighlyAvailable"]`,
+			},
+		},
+		{
+			name:  "invalid label",
+			names: []string{`[sig-node] invalid label [SkipWhen:region == "east"]`, `[sig-node] also invalid [SkipWhen:region == "east"]`},
+			err:   `[SkipWhen:region == "east"] on "[sig-node] invalid label [SkipWhen:region == \"east\"]": invalid expression "region == \"east\"": unknown identifier "region"`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateSkipLabels(test.names)
+			switch {
+			case len(test.err) == 0 && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case len(test.err) > 0 && (err == nil || err.Error() != test.err):
+				t.Fatalf("expected error %q, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestLoadSkipRulesErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules string
+		err   string
+	}{
+		{name: "invalid yaml", rules: "- when: [", err: "failed to read skip rules"},
+		{name: "invalid expression", rules: "- when: platform\n  tests: [a]", err: "skip rule 0 in"},
+		{name: "no tests", rules: "- when: ipv4", err: "no tests listed"},
+		{name: "invalid test", rules: "- when: ipv4\n  tests: ['(']", err: "missing closing )"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rulesFile := filepath.Join(t.TempDir(), "rules.yaml")
+			if err := os.WriteFile(rulesFile, []byte(test.rules), 0644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadSkipRules(rulesFile)
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("expected error containing %q, got %v", test.err, err)
+			}
+		})
+	}
+}
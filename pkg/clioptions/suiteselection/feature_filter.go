@@ -55,7 +55,7 @@ func newFeatureGateFilter(ctx context.Context, configClient clientconfigv1.Inter
 	return ret, nil
 }
 
-func (f *featureGateFilter) includeTest(name string) bool {
+func (f *featureGateFilter) rejectionReason(name string) string {
 	featureGates := []string{}
 	matches := featureGateRegex.FindAllStringSubmatch(name, -1)
 	for _, match := range matches {
@@ -66,15 +66,24 @@ func (f *featureGateFilter) includeTest(name string) bool {
 		featureGates = append(featureGates, featureGate)
 	}
 
-	if f.disabled.HasAny(featureGates...) {
-		return false
+	for _, featureGate := range featureGates {
+		if f.disabled.Has(featureGate) {
+			return fmt.Sprintf("FeatureGate %s is disabled", featureGate)
+		}
 	}
-
-	return f.enabled.HasAll(featureGates...)
+	for _, featureGate := range featureGates {
+		if !f.enabled.Has(featureGate) {
+			return fmt.Sprintf("FeatureGate %s is not enabled", featureGate)
+		}
+	}
+	return ""
 }
 
-func includeNonFeatureGateTest(name string) bool {
-	return featureGateRegex.FindAllStringSubmatch(name, -1) == nil
+func nonFeatureGateTestRejectionReason(name string) string {
+	if featureGateRegex.FindAllStringSubmatch(name, -1) == nil {
+		return ""
+	}
+	return "the cluster has no FeatureGates, so featuregated tests are excluded"
 }
 
 var (
@@ -36,7 +36,7 @@ var (
 	apiGroupRegex = regexp.MustCompile(`\[apigroup:([^]]*)\]`)
 )
 
-func (agf *apiGroupFilter) includeTest(name string) bool {
+func (agf *apiGroupFilter) rejectionReason(name string) string {
 	apiGroups := []string{}
 	matches := apiGroupRegex.FindAllStringSubmatch(name, -1)
 	for _, match := range matches {
@@ -47,5 +47,10 @@ func (agf *apiGroupFilter) includeTest(name string) bool {
 		apiGroups = append(apiGroups, apigroup)
 	}
 
-	return agf.apiGroups.HasAll(apiGroups...)
+	for _, apiGroup := range apiGroups {
+		if !agf.apiGroups.Has(apiGroup) {
+			return fmt.Sprintf("apigroup %s is not served by the cluster", apiGroup)
+		}
+	}
+	return ""
 }
@@ -69,7 +69,7 @@ func (f *TestSuiteSelectionFlags) SelectSuite(
 	discoveryClientGetter DiscoveryClientGetter,
 	configClientGetter ConfigClientGetter,
 	dryRun bool,
-	additionalMatchFn testginkgo.TestMatchFunc,
+	clusterRejectionReasonFn testginkgo.TestRejectionReasonFunc,
 ) (*testginkgo.TestSuite, error) {
 	var suite *testginkgo.TestSuite
 
@@ -100,18 +100,18 @@ func (f *TestSuiteSelectionFlags) SelectSuite(
 	if err != nil {
 		return nil, err
 	}
-	suite.AddRequiredMatchFunc(testFileMatchFn)
+	suite.AddRequiredNamedMatchFunc("--file", testFileMatchFn)
 
 	if len(f.Regex) > 0 {
 		re, err := regexp.Compile(f.Regex)
 		if err != nil {
 			return nil, err
 		}
-		suite.AddRequiredMatchFunc(re.MatchString)
+		suite.AddRequiredNamedMatchFunc("--run", re.MatchString)
 	}
 
-	suite.AddRequiredMatchFunc(f.MatchFn)
-	suite.AddRequiredMatchFunc(additionalMatchFn)
+	suite.AddRequiredNamedMatchFunc("selection match function", f.MatchFn)
+	suite.AddRequiredFilter("cluster configuration", clusterRejectionReasonFn)
 
 	// Skip tests with [apigroup:GROUP] labels for apigroups which are not
 	// served by a cluster. E.g. MicroShift is not serving most of the openshift.io
@@ -135,7 +135,7 @@ func (f *TestSuiteSelectionFlags) SelectSuite(
 			if err != nil {
 				return nil, fmt.Errorf("unable to build api group filter: %w", err)
 			}
-			suite.AddRequiredMatchFunc(apiGroupFilter.includeTest)
+			suite.AddRequiredFilter("apigroup filter", apiGroupFilter.rejectionReason)
 		}
 	}
 
@@ -151,11 +151,11 @@ func (f *TestSuiteSelectionFlags) SelectSuite(
 		case apierrors.IsNotFound(err):
 			// In case we are unable to determine if there is support for feature gates, exclude all featuregated tests
 			// as the test target doesnt comply with preconditions.
-			suite.AddRequiredMatchFunc(includeNonFeatureGateTest)
+			suite.AddRequiredFilter("FeatureGate filter", nonFeatureGateTestRejectionReason)
 		case err != nil:
 			return nil, fmt.Errorf("unable to build FeatureGate filter: %w", err)
 		default:
-			suite.AddRequiredMatchFunc(featureGateFilter.includeTest)
+			suite.AddRequiredFilter("FeatureGate filter", featureGateFilter.rejectionReason)
 		}
 	}
 
@@ -10,8 +10,12 @@ import (
 )
 
 type UpgradeOptions struct {
-	Suite       string
-	ToImage     string
+	Suite string
+	// ToImage is the image to upgrade to, or a comma delimited list of images for sequential upgrades. It is ignored
+	// when Hops is set.
+	ToImage string
+	// Hops is the ordered list of upgrades to perform within a single upgrade test.
+	Hops        []upgrade.Hop `json:",omitempty"`
 	TestOptions []string
 }
 
@@ -66,7 +70,7 @@ func (o *UpgradeOptions) SetUpgradeGlobals() error {
 		}
 	}
 
-	upgrade.SetToImage(o.ToImage)
+	upgrade.SetUpgradePath(o.UpgradePath())
 	switch o.Suite {
 	case "none":
 		return filterUpgrade(upgrade.NoTests(), func(string) bool { return true })
@@ -79,6 +83,21 @@ func (o *UpgradeOptions) SetUpgradeGlobals() error {
 	}
 }
 
+// UpgradePath returns the ordered upgrades to perform.
+func (o *UpgradeOptions) UpgradePath() []upgrade.Hop {
+	if len(o.Hops) > 0 {
+		return o.Hops
+	}
+	if len(o.ToImage) == 0 {
+		return nil
+	}
+	var path []upgrade.Hop
+	for _, image := range strings.Split(o.ToImage, ",") {
+		path = append(path, upgrade.Hop{ToImage: image})
+	}
+	return path
+}
+
 func filterUpgrade(tests []upgrades.Test, match func(string) bool) error {
 	var scope []upgrades.Test
 	for _, test := range tests {
@@ -8,6 +8,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/openshift/library-go/pkg/certs/cert-inspection/certgraphanalysis"
 	"github.com/openshift/library-go/pkg/certs/cert-inspection/certgraphapi"
@@ -33,14 +34,19 @@ type RunCollectDiskCertificatesFlags struct {
 	RootFSMountpoint string
 	CollectDirs      []string
 
+	Report          bool
+	PreviousReport  string
+	ExpiryThreshold time.Duration
+
 	genericclioptions.IOStreams
 }
 
 func NewRunCollectDiskCertificatesFlags(ioStreams genericclioptions.IOStreams) *RunCollectDiskCertificatesFlags {
 	return &RunCollectDiskCertificatesFlags{
-		ConfigFlags: genericclioptions.NewConfigFlags(false),
-		OutputFlags: iooptions.NewOutputOptions(),
-		IOStreams:   ioStreams,
+		ConfigFlags:     genericclioptions.NewConfigFlags(false),
+		OutputFlags:     iooptions.NewOutputOptions(),
+		ExpiryThreshold: 30 * 24 * time.Hour,
+		IOStreams:       ioStreams,
 	}
 }
 
@@ -51,6 +57,10 @@ func NewRunCollectDiskCertificatesCommand(ioStreams genericclioptions.IOStreams)
 		Short: "Run disk certificate collector",
 		Long: templates.LongDesc(`
 		Run a colelctor which fetches information about certificates stored on disk
+
+		With --report, no cluster access is needed and the output file is a report of the remaining validity of every
+		certificate instead.  Certificates expiring within --expiry-threshold or already expired are summarized on
+		stdout, along with the certificates added, removed and rotated since --previous-report, if given.
 		`),
 
 		SilenceUsage:  true,
@@ -96,6 +106,9 @@ func NewRunCollectDiskCertificatesCommand(ioStreams genericclioptions.IOStreams)
 func (f *RunCollectDiskCertificatesFlags) AddFlags(flags *pflag.FlagSet) {
 	flags.StringArrayVar(&f.CollectDirs, "collect-dir", f.CollectDirs, "directories to collect certs in")
 	flags.StringVar(&f.RootFSMountpoint, "root-fs-mountpoint", f.RootFSMountpoint, "rootfs mountpoint (will be stripped from paths)")
+	flags.BoolVar(&f.Report, "report", f.Report, "write a certificate expiry report instead of the PKI list")
+	flags.StringVar(&f.PreviousReport, "previous-report", f.PreviousReport, "report of a previous collection to diff against, requires --report")
+	flags.DurationVar(&f.ExpiryThreshold, "expiry-threshold", f.ExpiryThreshold, "report certificates expiring within this duration, requires --report")
 	f.ConfigFlags.AddFlags(flags)
 	f.OutputFlags.BindFlags(flags)
 }
@@ -111,6 +124,12 @@ func (f *RunCollectDiskCertificatesFlags) Validate() error {
 	if len(f.CollectDirs) == 0 {
 		return fmt.Errorf("dirs must be specified")
 	}
+	if !f.Report && len(f.PreviousReport) > 0 {
+		return fmt.Errorf("--previous-report requires --report")
+	}
+	if f.ExpiryThreshold < 0 {
+		return fmt.Errorf("--expiry-threshold must not be negative")
+	}
 
 	return nil
 }
@@ -122,6 +141,20 @@ func (f *RunCollectDiskCertificatesFlags) ToOptions() (*RunCollectDiskCertificat
 		return nil, err
 	}
 
+	if f.Report {
+		return &RunCollectDiskCertificatesOptions{
+			OutputFile:       f.OutputFlags.OutFile,
+			CloseFn:          closeFn,
+			OriginalOutFile:  originalOutStream,
+			IOStreams:        f.IOStreams,
+			CollectDirs:      f.CollectDirs,
+			RootFSMountpoint: f.RootFSMountpoint,
+			Report:           true,
+			PreviousReport:   f.PreviousReport,
+			ExpiryThreshold:  f.ExpiryThreshold,
+		}, nil
+	}
+
 	restConfig, err := f.ConfigFlags.ToRESTConfig()
 	if err != nil {
 		return nil, err
@@ -150,6 +183,11 @@ type RunCollectDiskCertificatesOptions struct {
 	CollectDirs      []string
 	RootFSMountpoint string
 
+	// Report writes a CertificateReport instead of the PKI list and needs no KubeClient.
+	Report          bool
+	PreviousReport  string
+	ExpiryThreshold time.Duration
+
 	OriginalOutFile io.Writer
 	CloseFn         iooptions.CloseFunc
 	genericclioptions.IOStreams
@@ -159,6 +197,10 @@ func (o *RunCollectDiskCertificatesOptions) Run(ctx context.Context) error {
 	ctx, cancelFn := context.WithCancel(ctx)
 	defer cancelFn()
 
+	if o.Report {
+		return o.runReport(ctx, time.Now())
+	}
+
 	pkiList := &certgraphapi.PKIList{}
 	errs := []error{}
 
@@ -201,3 +243,31 @@ func (o *RunCollectDiskCertificatesOptions) Run(ctx context.Context) error {
 
 	return nil
 }
+
+func (o *RunCollectDiskCertificatesOptions) runReport(ctx context.Context, evaluationTime time.Time) error {
+	var previous *CertificateReport
+	if len(o.PreviousReport) > 0 {
+		previousBytes, err := os.ReadFile(o.PreviousReport)
+		if err != nil {
+			return fmt.Errorf("failed to read previous report: %v", err)
+		}
+		previous = &CertificateReport{}
+		if err := json.Unmarshal(previousBytes, previous); err != nil {
+			return fmt.Errorf("failed to parse previous report %s: %v", o.PreviousReport, err)
+		}
+	}
+
+	certificates, fileErrors := GatherDiskCertificates(ctx, o.CollectDirs, o.RootFSMountpoint)
+	report := NewCertificateReport(certificates, previous, evaluationTime, o.ExpiryThreshold)
+	report.Errors = fileErrors
+
+	bytes, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal certificate report: %v", err)
+	}
+	if err := os.WriteFile(o.OutputFile, bytes, 0644); err != nil {
+		return fmt.Errorf("failed to write to output file: %v", err)
+	}
+
+	return WriteCertificateReportSummary(o.OriginalOutFile, report)
+}
@@ -0,0 +1,381 @@
+package collectdiskcertificates
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/openshift/library-go/pkg/certs/cert-inspection/certgraphanalysis"
+	"github.com/openshift/library-go/pkg/certs/cert-inspection/certgraphapi"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/cert"
+)
+
+type CertificateState string
+
+const (
+	CertificateValid    CertificateState = "Valid"
+	CertificateExpiring CertificateState = "Expiring"
+	CertificateExpired  CertificateState = "Expired"
+)
+
+// DiskCertificate is a single certificate found on disk.  Files holding several certificates, like CA bundles and
+// kubeconfigs, produce one DiskCertificate per certificate.
+type DiskCertificate struct {
+	// Path is the location of the file with the root filesystem mountpoint stripped.
+	Path         string      `json:"path"`
+	CommonName   string      `json:"commonName"`
+	Issuer       string      `json:"issuer"`
+	SerialNumber string      `json:"serialNumber"`
+	Fingerprint  string      `json:"fingerprint"`
+	NotBefore    metav1.Time `json:"notBefore"`
+	NotAfter     metav1.Time `json:"notAfter"`
+}
+
+type CertificateStatus struct {
+	DiskCertificate
+
+	State CertificateState `json:"state"`
+	// RemainingValidity is negative once the certificate has expired.
+	RemainingValidity metav1.Duration `json:"remainingValidity"`
+}
+
+// CertificateRotation is a certificate replaced by another with the same common name in the same file.
+type CertificateRotation struct {
+	Previous DiskCertificate `json:"previous"`
+	Current  DiskCertificate `json:"current"`
+}
+
+type CertificateDiff struct {
+	PreviousEvaluationTime metav1.Time           `json:"previousEvaluationTime"`
+	Added                  []DiskCertificate     `json:"added,omitempty"`
+	Removed                []DiskCertificate     `json:"removed,omitempty"`
+	Rotated                []CertificateRotation `json:"rotated,omitempty"`
+}
+
+// CertificateReport is the output of collect-disk-certificates --report.  It is also read back as the previous
+// collection to diff against.
+type CertificateReport struct {
+	EvaluationTime  metav1.Time         `json:"evaluationTime"`
+	ExpiryThreshold metav1.Duration     `json:"expiryThreshold"`
+	Certificates    []CertificateStatus `json:"certificates"`
+	Diff            *CertificateDiff    `json:"diff,omitempty"`
+	// Errors are the files which could not be read or parsed.  Their certificates are missing from the report.
+	Errors []CertificateFileError `json:"errors,omitempty"`
+}
+
+// CertificateFileError is a file or directory which could not be read or parsed.  It is reported instead of failing
+// the whole report.
+type CertificateFileError struct {
+	// Path is the location of the file with the root filesystem mountpoint stripped.
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// GatherDiskCertificates walks the directories and returns every certificate in PEM files and kubeconfigs, sorted by
+// path, along with the files which could not be read or parsed.  Files which are not certificates are skipped, as are
+// the files the PKI list collection skips: revisioned copies of static pod resources are dropped the same way.
+func GatherDiskCertificates(ctx context.Context, dirs []string, rootFSMountpoint string) ([]DiskCertificate, []CertificateFileError) {
+	ret := []DiskCertificate{}
+	fileErrors := []CertificateFileError{}
+	addFileError := func(path string, err error) {
+		fileErrors = append(fileErrors, CertificateFileError{Path: stripRootFSMountpoint(path, rootFSMountpoint), Error: err.Error()})
+	}
+	for _, dir := range dirs {
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			continue
+		}
+
+		skipped, err := skippedDiskPaths(ctx, dir)
+		if err != nil {
+			addFileError(dir, err)
+		}
+		err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				addFileError(path, err)
+				if d != nil && d.IsDir() && path != dir {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() || skipped.Has(path) {
+				return nil
+			}
+			certificates, err := parseCertificateFile(path)
+			if err != nil {
+				addFileError(path, err)
+				return nil
+			}
+			for _, certificate := range certificates {
+				ret = append(ret, toDiskCertificate(stripRootFSMountpoint(path, rootFSMountpoint), certificate))
+			}
+			return nil
+		})
+		if err != nil {
+			addFileError(dir, err)
+		}
+	}
+
+	sort.SliceStable(ret, func(i, j int) bool {
+		return ret[i].Path < ret[j].Path
+	})
+	sort.SliceStable(fileErrors, func(i, j int) bool {
+		return fileErrors[i].Path < fileErrors[j].Path
+	})
+	return ret, fileErrors
+}
+
+// skippedDiskPaths returns the files under dir which the PKI list collection drops with the same filters as the
+// non-report mode: the files it collects without the filters but not with them.  Files it cannot parse are not
+// skipped, so their errors are still reported.
+func skippedDiskPaths(ctx context.Context, dir string) (sets.Set[string], error) {
+	all, err := certgraphanalysis.GatherCertsFromDisk(ctx, nil, dir)
+	if err != nil {
+		return sets.New[string](), err
+	}
+	filtered, err := certgraphanalysis.GatherCertsFromDisk(ctx, nil, dir,
+		certgraphanalysis.SkipRevisioned,
+		certgraphanalysis.SkipHashed,
+		certgraphanalysis.SkipRevisionedLocations)
+	if err != nil {
+		return sets.New[string](), err
+	}
+	return onDiskPaths(all).Difference(onDiskPaths(filtered)), nil
+}
+
+func onDiskPaths(pkiList *certgraphapi.PKIList) sets.Set[string] {
+	ret := sets.New[string]()
+	for _, metadata := range pkiList.OnDiskResourceData.TLSArtifact {
+		ret.Insert(metadata.Path)
+	}
+	return ret
+}
+
+func stripRootFSMountpoint(path, rootFSMountpoint string) string {
+	if len(rootFSMountpoint) == 0 {
+		return path
+	}
+	return "/" + strings.TrimPrefix(strings.TrimPrefix(path, filepath.Clean(rootFSMountpoint)), "/")
+}
+
+// parseCertificateFile returns the certificates in a PEM file or embedded in a kubeconfig, and nothing for other files.
+func parseCertificateFile(path string) ([]*x509.Certificate, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case bytes.Contains(content, []byte("-----BEGIN CERTIFICATE-----")):
+		return cert.ParseCertsPEM(content)
+
+	case bytes.Contains(content, []byte("certificate-authority-data")) || bytes.Contains(content, []byte("client-certificate-data")):
+		kubeconfig, err := clientcmd.Load(content)
+		if err != nil {
+			// not every file mentioning certificate data is a kubeconfig
+			return nil, nil
+		}
+		ret := []*x509.Certificate{}
+		for _, name := range sortedKeys(kubeconfig.Clusters) {
+			if data := kubeconfig.Clusters[name].CertificateAuthorityData; len(data) > 0 {
+				certificates, err := cert.ParseCertsPEM(data)
+				if err != nil {
+					return nil, fmt.Errorf("cluster %q: %w", name, err)
+				}
+				ret = append(ret, certificates...)
+			}
+		}
+		for _, name := range sortedKeys(kubeconfig.AuthInfos) {
+			if data := kubeconfig.AuthInfos[name].ClientCertificateData; len(data) > 0 {
+				certificates, err := cert.ParseCertsPEM(data)
+				if err != nil {
+					return nil, fmt.Errorf("user %q: %w", name, err)
+				}
+				ret = append(ret, certificates...)
+			}
+		}
+		return ret, nil
+	}
+
+	return nil, nil
+}
+
+func sortedKeys[V any](in map[string]V) []string {
+	ret := make([]string, 0, len(in))
+	for key := range in {
+		ret = append(ret, key)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func toDiskCertificate(path string, certificate *x509.Certificate) DiskCertificate {
+	fingerprint := sha256.Sum256(certificate.Raw)
+	return DiskCertificate{
+		Path:         path,
+		CommonName:   certificate.Subject.CommonName,
+		Issuer:       certificate.Issuer.CommonName,
+		SerialNumber: certificate.SerialNumber.String(),
+		Fingerprint:  hex.EncodeToString(fingerprint[:]),
+		NotBefore:    metav1.NewTime(certificate.NotBefore.UTC()),
+		NotAfter:     metav1.NewTime(certificate.NotAfter.UTC()),
+	}
+}
+
+// NewCertificateReport computes the remaining validity of every certificate at evaluationTime.  Certificates expiring
+// within expiryThreshold are Expiring.  If previous is not nil, the report includes the diff against it.
+func NewCertificateReport(certificates []DiskCertificate, previous *CertificateReport, evaluationTime time.Time, expiryThreshold time.Duration) *CertificateReport {
+	ret := &CertificateReport{
+		EvaluationTime:  metav1.NewTime(evaluationTime.UTC()),
+		ExpiryThreshold: metav1.Duration{Duration: expiryThreshold},
+		Certificates:    []CertificateStatus{},
+	}
+	for _, certificate := range certificates {
+		remaining := certificate.NotAfter.Sub(evaluationTime)
+		state := CertificateValid
+		switch {
+		case remaining <= 0:
+			state = CertificateExpired
+		case remaining <= expiryThreshold:
+			state = CertificateExpiring
+		}
+		ret.Certificates = append(ret.Certificates, CertificateStatus{
+			DiskCertificate:   certificate,
+			State:             state,
+			RemainingValidity: metav1.Duration{Duration: remaining},
+		})
+	}
+
+	if previous != nil {
+		previousCertificates := []DiskCertificate{}
+		for _, certificate := range previous.Certificates {
+			previousCertificates = append(previousCertificates, certificate.DiskCertificate)
+		}
+		ret.Diff = diffCertificates(previousCertificates, certificates)
+		ret.Diff.PreviousEvaluationTime = previous.EvaluationTime
+	}
+	return ret
+}
+
+// diffCertificates matches certificates by path and fingerprint.  Of the remaining certificates, those with the same
+// path and common name were rotated, the rest were added or removed.
+func diffCertificates(previous, current []DiskCertificate) *CertificateDiff {
+	type key struct {
+		path, fingerprint string
+	}
+	previousKeys := map[key]bool{}
+	for _, certificate := range previous {
+		previousKeys[key{certificate.Path, certificate.Fingerprint}] = true
+	}
+	currentKeys := map[key]bool{}
+	for _, certificate := range current {
+		currentKeys[key{certificate.Path, certificate.Fingerprint}] = true
+	}
+
+	type name struct {
+		path, commonName string
+	}
+	removedByName := map[name][]DiskCertificate{}
+	for _, certificate := range previous {
+		if !currentKeys[key{certificate.Path, certificate.Fingerprint}] {
+			n := name{certificate.Path, certificate.CommonName}
+			removedByName[n] = append(removedByName[n], certificate)
+		}
+	}
+
+	ret := &CertificateDiff{}
+	rotated := map[key]bool{}
+	for _, certificate := range current {
+		if previousKeys[key{certificate.Path, certificate.Fingerprint}] {
+			continue
+		}
+		n := name{certificate.Path, certificate.CommonName}
+		if replaced := removedByName[n]; len(replaced) > 0 {
+			ret.Rotated = append(ret.Rotated, CertificateRotation{Previous: replaced[0], Current: certificate})
+			rotated[key{replaced[0].Path, replaced[0].Fingerprint}] = true
+			removedByName[n] = replaced[1:]
+			continue
+		}
+		ret.Added = append(ret.Added, certificate)
+	}
+	for _, certificate := range previous {
+		k := key{certificate.Path, certificate.Fingerprint}
+		if !currentKeys[k] && !rotated[k] {
+			ret.Removed = append(ret.Removed, certificate)
+		}
+	}
+	return ret
+}
+
+// WriteCertificateReportSummary writes the expired and expiring certificates, the unreadable files and the diff in
+// human readable form.
+func WriteCertificateReportSummary(out io.Writer, report *CertificateReport) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+
+	counts := map[CertificateState]int{}
+	for _, certificate := range report.Certificates {
+		counts[certificate.State]++
+	}
+	fmt.Fprintf(w, "%d certificates at %s: %d expired, %d expiring within %s\n",
+		len(report.Certificates), report.EvaluationTime.UTC().Format(time.RFC3339),
+		counts[CertificateExpired], counts[CertificateExpiring], duration.HumanDuration(report.ExpiryThreshold.Duration))
+
+	if counts[CertificateExpired]+counts[CertificateExpiring] > 0 {
+		fmt.Fprintf(w, "\nSTATE\tPATH\tCOMMON NAME\tNOT AFTER\tREMAINING\n")
+		for _, certificate := range report.Certificates {
+			remaining := ""
+			switch certificate.State {
+			case CertificateExpired:
+				remaining = "expired " + duration.HumanDuration(-certificate.RemainingValidity.Duration) + " ago"
+			case CertificateExpiring:
+				remaining = duration.HumanDuration(certificate.RemainingValidity.Duration)
+			default:
+				continue
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", certificate.State, certificate.Path, certificate.CommonName,
+				certificate.NotAfter.UTC().Format(time.RFC3339), remaining)
+		}
+	}
+
+	if len(report.Errors) > 0 {
+		fmt.Fprintf(w, "\n%d files could not be read\n", len(report.Errors))
+		fmt.Fprintf(w, "\nPATH\tERROR\n")
+		for _, fileError := range report.Errors {
+			fmt.Fprintf(w, "%s\t%s\n", fileError.Path, fileError.Error)
+		}
+	}
+
+	if diff := report.Diff; diff != nil {
+		fmt.Fprintf(w, "\nSince %s: %d added, %d removed, %d rotated\n",
+			diff.PreviousEvaluationTime.UTC().Format(time.RFC3339), len(diff.Added), len(diff.Removed), len(diff.Rotated))
+		if len(diff.Added)+len(diff.Removed)+len(diff.Rotated) > 0 {
+			fmt.Fprintf(w, "\nCHANGE\tPATH\tCOMMON NAME\tNOT AFTER\n")
+		}
+		for _, certificate := range diff.Added {
+			fmt.Fprintf(w, "Added\t%s\t%s\t%s\n", certificate.Path, certificate.CommonName, certificate.NotAfter.UTC().Format(time.RFC3339))
+		}
+		for _, certificate := range diff.Removed {
+			fmt.Fprintf(w, "Removed\t%s\t%s\t%s\n", certificate.Path, certificate.CommonName, certificate.NotAfter.UTC().Format(time.RFC3339))
+		}
+		for _, rotation := range diff.Rotated {
+			fmt.Fprintf(w, "Rotated\t%s\t%s\t%s -> %s\n", rotation.Current.Path, rotation.Current.CommonName,
+				rotation.Previous.NotAfter.UTC().Format(time.RFC3339), rotation.Current.NotAfter.UTC().Format(time.RFC3339))
+		}
+	}
+
+	return w.Flush()
+}
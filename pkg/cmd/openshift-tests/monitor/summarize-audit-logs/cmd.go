@@ -2,9 +2,14 @@ package summarize_audit_logs
 
 import (
 	"context"
+	"encoding/xml"
+	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
 	auditloganalyzer2 "github.com/openshift/origin/pkg/monitortests/kubeapiserver/auditloganalyzer"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
 
 	"k8s.io/client-go/kubernetes"
 
@@ -15,6 +20,8 @@ import (
 
 type auditLogSummaryOptions struct {
 	ArtifactDir string
+	AuditLogDir string
+	APIServers  []string
 
 	ConfigFlags *genericclioptions.ConfigFlags
 	IOStreams   genericclioptions.IOStreams
@@ -22,6 +29,7 @@ type auditLogSummaryOptions struct {
 
 func AuditLogSummaryCommand() *cobra.Command {
 	o := &auditLogSummaryOptions{
+		APIServers:  []string{"kube-apiserver"},
 		ConfigFlags: genericclioptions.NewConfigFlags(true),
 		IOStreams: genericclioptions.IOStreams{
 			In:     os.Stdin,
@@ -32,15 +40,26 @@ func AuditLogSummaryCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "summarize-audit-logs",
 		Short: "Download and inspect audit logs for interesting things.",
+		Long: `Download and inspect audit logs for interesting things.
+
+By default audit logs are streamed from the control plane nodes of the cluster.  With --audit-log-dir, audit logs
+are read from disk instead, for instance the audit_logs directory of a must-gather.  Gzipped logs are supported.
+Offline, every check the audit log monitor test runs is evaluated and the results are written as junit.
+`,
 
 		SilenceUsage:  true,
 		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(o.AuditLogDir) > 0 {
+				return o.RunOffline()
+			}
 			return o.Run(context.Background())
 		},
 	}
 
 	cmd.Flags().StringVar(&o.ArtifactDir, "artifact-dir", o.ArtifactDir, "The directory where monitor events will be stored.")
+	cmd.Flags().StringVar(&o.AuditLogDir, "audit-log-dir", o.AuditLogDir, "Read audit logs from this directory instead of the cluster.")
+	cmd.Flags().StringSliceVar(&o.APIServers, "apiserver", o.APIServers, fmt.Sprintf("The apiservers to read audit logs for, any of %v.", auditloganalyzer2.AllAPIServers))
 	o.ConfigFlags.AddFlags(cmd.Flags())
 	return cmd
 }
@@ -56,7 +75,7 @@ func (o auditLogSummaryOptions) Run(ctx context.Context) error {
 	}
 
 	summarizer := auditloganalyzer2.NewAuditLogSummarizer()
-	err = auditloganalyzer2.GetKubeAuditLogSummary(ctx, kubeClient, nil, nil, []auditloganalyzer2.AuditEventHandler{summarizer})
+	err = auditloganalyzer2.GetAuditLogSummary(ctx, kubeClient, o.APIServers, nil, nil, []auditloganalyzer2.AuditEventHandler{summarizer})
 	if err != nil {
 		return err
 	}
@@ -67,3 +86,46 @@ func (o auditLogSummaryOptions) Run(ctx context.Context) error {
 
 	return nil
 }
+
+func (o auditLogSummaryOptions) RunOffline() error {
+	intervals, junits, err := auditloganalyzer2.AnalyzeAuditLogDirectory(o.AuditLogDir, o.APIServers, nil, nil, o.ArtifactDir, "")
+	if err != nil {
+		return err
+	}
+	for _, interval := range intervals {
+		fmt.Fprintf(o.IOStreams.Out, "%s\n", interval.String())
+	}
+	if len(intervals) > 0 {
+		fmt.Fprintln(o.IOStreams.Out)
+	}
+
+	junitSuite := junitapi.JUnitTestSuite{
+		Name: "Audit log analysis",
+	}
+	for _, junit := range junits {
+		junitSuite.NumTests++
+		if junit.FailureOutput != nil {
+			junitSuite.NumFailed++
+			fmt.Fprintf(o.IOStreams.Out, "FAIL: %s\n%s\n\n", junit.Name, failureText(junit.FailureOutput))
+		}
+		junitSuite.TestCases = append(junitSuite.TestCases, junit)
+	}
+	out, err := xml.MarshalIndent(junitSuite, "", "    ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(o.ArtifactDir, "junit_audit-log-analysis.xml")
+	fmt.Fprintf(o.IOStreams.ErrOut, "Writing JUnit report to %s\n", path)
+	return os.WriteFile(path, out, 0640)
+}
+
+// failureText is the summary and the details of a failure, either of which may be empty.
+func failureText(failure *junitapi.FailureOutput) string {
+	text := []string{}
+	for _, part := range []string{failure.Message, failure.Output} {
+		if len(part) > 0 {
+			text = append(text, part)
+		}
+	}
+	return strings.Join(text, "\n")
+}
@@ -0,0 +1,51 @@
+package summarize_audit_logs
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+// slowGet took two seconds, which misses the get request latency SLO.
+const slowGet = `{"kind":"Event","apiVersion":"audit.k8s.io/v1","level":"Metadata","auditID":"slow","stage":"ResponseComplete","requestURI":"/api/v1/namespaces/openshift-etcd/configmaps/config","verb":"get","user":{"username":"system:admin"},"objectRef":{"resource":"configmaps","namespace":"openshift-etcd","name":"config","apiVersion":"v1"},"responseStatus":{"code":200},"requestReceivedTimestamp":"2024-05-01T12:00:00.000000Z","stageTimestamp":"2024-05-01T12:00:02.000000Z"}
+`
+
+func TestRunOffline(t *testing.T) {
+	auditLogDir := t.TempDir()
+	logDir := filepath.Join(auditLogDir, "audit_logs", "kube-apiserver")
+	if err := os.MkdirAll(logDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(logDir, "master-0-audit.log"), []byte(slowGet), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := &bytes.Buffer{}
+	o := auditLogSummaryOptions{
+		ArtifactDir: t.TempDir(),
+		AuditLogDir: auditLogDir,
+		APIServers:  []string{"kube-apiserver"},
+		IOStreams:   genericclioptions.IOStreams{Out: out, ErrOut: &bytes.Buffer{}},
+	}
+	if err := o.RunOffline(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		`FAIL: [Jira:"kube-apiserver"] API SLO: 99% of get requests should complete within 1s`,
+		"0.00% of 1 get requests completed within 1s",
+		"slowest get requests over 1s:\n2.0s get /api/v1/namespaces/openshift-etcd/configmaps/config user=system:admin auditID=slow",
+	}
+	for _, text := range expected {
+		if !strings.Contains(out.String(), text) {
+			t.Errorf("expected output to contain %q, got:\n%s", text, out.String())
+		}
+	}
+	if _, err := os.Stat(filepath.Join(o.ArtifactDir, "junit_audit-log-analysis.xml")); err != nil {
+		t.Errorf("expected a junit report: %v", err)
+	}
+}
@@ -0,0 +1,357 @@
+package query
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
+	resourcewatchquery "github.com/openshift/origin/pkg/resourcewatch/query"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+// QueryFlags are shared by every query subcommand.
+type QueryFlags struct {
+	RepositoryPath string
+	Resource       string
+	Namespace      string
+	Name           string
+
+	genericclioptions.IOStreams
+}
+
+func NewQueryFlags(streams genericclioptions.IOStreams) *QueryFlags {
+	repositoryPath := "/repository"
+	if repositoryPathEnv := os.Getenv("REPOSITORY_PATH"); len(repositoryPathEnv) > 0 {
+		repositoryPath = repositoryPathEnv
+	}
+	return &QueryFlags{
+		RepositoryPath: repositoryPath,
+		IOStreams:      streams,
+	}
+}
+
+func (f *QueryFlags) BindFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&f.RepositoryPath, "repository-path", f.RepositoryPath, "The repository written by run-resourcewatch.  Defaults to REPOSITORY_PATH.")
+	flags.StringVar(&f.Resource, "resource", f.Resource, "Only objects of this resource, as resource or resource.group, for instance kubeapiservers.operator.openshift.io.")
+	flags.StringVarP(&f.Namespace, "namespace", "n", f.Namespace, "Only objects in this namespace.")
+	flags.StringVar(&f.Name, "name", f.Name, "Only objects with this name.")
+}
+
+func (f *QueryFlags) selector() resourcewatchquery.Selector {
+	return resourcewatchquery.Selector{
+		Resource:  f.Resource,
+		Namespace: f.Namespace,
+		Name:      f.Name,
+	}
+}
+
+func NewQueryCommand(streams genericclioptions.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "query",
+		Short: "Reconstruct and compare cluster state recorded by run-resourcewatch",
+		Long: templates.LongDesc(`
+			Answer questions about a repository written by run-resourcewatch without git archaeology.
+
+			Times are RFC3339, for instance 2024-05-01T14:03:12Z.  Changes are placed at the time they
+			were observed, which may be earlier than the time they were committed.
+		`),
+		SilenceErrors: true,
+	}
+	cmd.AddCommand(
+		newSnapshotCommand(streams),
+		newDiffCommand(streams),
+		newHistoryCommand(streams),
+		newIntervalsCommand(streams),
+	)
+	return cmd
+}
+
+func newSnapshotCommand(streams genericclioptions.IOStreams) *cobra.Command {
+	f := NewQueryFlags(streams)
+	var at, outputDir string
+	cmd := &cobra.Command{
+		Use:   "snapshot --at TIME",
+		Short: "Reconstruct the state of every matching object at an instant",
+		Long: templates.LongDesc(`
+			Reconstruct the state of every matching object at an instant.
+
+			Objects are printed as a yaml stream, or written to --output-dir using the same layout
+			as the repository, which matches must-gather.
+		`),
+		Example: templates.Examples(`
+			# What did the kube-apiserver operator look like at 14:03:12
+			openshift-tests resourcewatch query snapshot --repository-path ./resourcewatch --at 2024-05-01T14:03:12Z \
+			  --resource kubeapiservers.operator.openshift.io --name cluster
+		`),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			atTime, err := parseTime("at", at, true)
+			if err != nil {
+				return err
+			}
+			repo, err := resourcewatchquery.OpenRepository(f.RepositoryPath)
+			if err != nil {
+				return err
+			}
+			objects, err := repo.StateAt(f.selector(), atTime)
+			if err != nil {
+				return err
+			}
+
+			if len(outputDir) > 0 {
+				for _, obj := range objects {
+					path := filepath.Join(outputDir, filepath.FromSlash(obj.Change.Path))
+					if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+						return err
+					}
+					if err := os.WriteFile(path, obj.Content, 0644); err != nil {
+						return err
+					}
+				}
+				fmt.Fprintf(f.Out, "Wrote %d objects to %s\n", len(objects), outputDir)
+				return nil
+			}
+			for i, obj := range objects {
+				if i > 0 {
+					fmt.Fprintln(f.Out, "---")
+				}
+				fmt.Fprintf(f.Out, "# %s as of %s\n", obj.Change.Object, obj.Change.Observed.UTC().Format(time.RFC3339))
+				if _, err := f.Out.Write(obj.Content); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	f.BindFlags(cmd.Flags())
+	cmd.Flags().StringVar(&at, "at", at, "The instant to reconstruct.")
+	cmd.Flags().StringVar(&outputDir, "output-dir", outputDir, "Write the objects to this directory instead of printing them.")
+	return cmd
+}
+
+func newDiffCommand(streams genericclioptions.IOStreams) *cobra.Command {
+	f := NewQueryFlags(streams)
+	var from, to string
+	cmd := &cobra.Command{
+		Use:   "diff --resource RESOURCE --name NAME --from TIME --to TIME",
+		Short: "Compare an object between two instants",
+		Example: templates.Examples(`
+			openshift-tests resourcewatch query diff --repository-path ./resourcewatch \
+			  --resource clusteroperators.config.openshift.io --name kube-apiserver \
+			  --from 2024-05-01T14:00:00Z --to 2024-05-01T14:30:00Z
+		`),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(f.Resource) == 0 || len(f.Name) == 0 {
+				return fmt.Errorf("--resource and --name are required")
+			}
+			fromTime, err := parseTime("from", from, true)
+			if err != nil {
+				return err
+			}
+			toTime, err := parseTime("to", to, true)
+			if err != nil {
+				return err
+			}
+			repo, err := resourcewatchquery.OpenRepository(f.RepositoryPath)
+			if err != nil {
+				return err
+			}
+			ref, err := singleObject(repo, f.selector())
+			if err != nil {
+				return err
+			}
+			oldObj, err := repo.ObjectAt(ref, fromTime)
+			if err != nil {
+				return err
+			}
+			newObj, err := repo.ObjectAt(ref, toTime)
+			if err != nil {
+				return err
+			}
+			diff, err := resourcewatchquery.Diff(oldObj, newObj)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(f.Out, "%s from %s to %s\n", ref, describeVersion(oldObj), describeVersion(newObj))
+			printFieldChanges(f.Out, "", diff)
+			if len(diff.Content) > 0 {
+				fmt.Fprintf(f.Out, "\n%s", diff.Content)
+			}
+			return nil
+		},
+	}
+	f.BindFlags(cmd.Flags())
+	cmd.Flags().StringVar(&from, "from", from, "The instant of the old version.")
+	cmd.Flags().StringVar(&to, "to", to, "The instant of the new version.")
+	return cmd
+}
+
+func newHistoryCommand(streams genericclioptions.IOStreams) *cobra.Command {
+	f := NewQueryFlags(streams)
+	var from, to string
+	var showContent bool
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "List every change to matching objects with the fields each change touched",
+		Example: templates.Examples(`
+			openshift-tests resourcewatch query history --repository-path ./resourcewatch \
+			  --resource kubeapiservers.operator.openshift.io --name cluster
+		`),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fromTime, err := parseTime("from", from, false)
+			if err != nil {
+				return err
+			}
+			toTime, err := parseTime("to", to, false)
+			if err != nil {
+				return err
+			}
+			repo, err := resourcewatchquery.OpenRepository(f.RepositoryPath)
+			if err != nil {
+				return err
+			}
+
+			for _, change := range repo.Changes(f.selector(), fromTime, toTime) {
+				fmt.Fprintf(f.Out, "%s %s %s by %s (%s)\n",
+					change.Observed.UTC().Format(time.RFC3339Nano), change.Operation, change.Object, change.Author, change.Commit.String()[:12])
+				if change.Operation != "modified" {
+					continue
+				}
+				previous, err := repo.Previous(change)
+				if err != nil {
+					return err
+				}
+				content, err := repo.Content(change)
+				if err != nil {
+					return err
+				}
+				diff, err := resourcewatchquery.Diff(previous, &resourcewatchquery.Object{Change: change, Content: content})
+				if err != nil {
+					return err
+				}
+				printFieldChanges(f.Out, "    ", diff)
+				if showContent && len(diff.Content) > 0 {
+					fmt.Fprintf(f.Out, "%s\n", diff.Content)
+				}
+			}
+			return nil
+		},
+	}
+	f.BindFlags(cmd.Flags())
+	cmd.Flags().StringVar(&from, "from", from, "Only changes observed at or after this instant.")
+	cmd.Flags().StringVar(&to, "to", to, "Only changes observed at or before this instant.")
+	cmd.Flags().BoolVar(&showContent, "show-content", showContent, "Include a content diff for every modification.")
+	return cmd
+}
+
+func newIntervalsCommand(streams genericclioptions.IOStreams) *cobra.Command {
+	f := NewQueryFlags(streams)
+	var from, to, output string
+	cmd := &cobra.Command{
+		Use:   "intervals",
+		Short: "Export changes as monitor intervals to overlay on an e2e timeline",
+		Long: templates.LongDesc(`
+			Export changes as monitor intervals.  The output can be merged with e2e-events json files
+			and rendered with openshift-tests timeline.
+		`),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fromTime, err := parseTime("from", from, false)
+			if err != nil {
+				return err
+			}
+			toTime, err := parseTime("to", to, false)
+			if err != nil {
+				return err
+			}
+			repo, err := resourcewatchquery.OpenRepository(f.RepositoryPath)
+			if err != nil {
+				return err
+			}
+
+			intervals := resourcewatchquery.ChangesToIntervals(repo.Changes(f.selector(), fromTime, toTime))
+			if len(output) > 0 {
+				return monitorserialization.IntervalsToFile(output, intervals)
+			}
+			intervalsJSON, err := monitorserialization.IntervalsToJSON(intervals)
+			if err != nil {
+				return err
+			}
+			_, err = f.Out.Write(intervalsJSON)
+			return err
+		},
+	}
+	f.BindFlags(cmd.Flags())
+	cmd.Flags().StringVar(&from, "from", from, "Only changes observed at or after this instant.")
+	cmd.Flags().StringVar(&to, "to", to, "Only changes observed at or before this instant.")
+	cmd.Flags().StringVarP(&output, "output", "o", output, "Write the intervals to this file instead of stdout.")
+	return cmd
+}
+
+func parseTime(flagName, value string, required bool) (time.Time, error) {
+	if len(value) == 0 {
+		if required {
+			return time.Time{}, fmt.Errorf("--%s is required", flagName)
+		}
+		return time.Time{}, nil
+	}
+	ret, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("--%s must be in RFC3339 format, for instance %s: %w", flagName, time.RFC3339, err)
+	}
+	return ret, nil
+}
+
+// singleObject finds the one object the selector refers to over the whole history.
+func singleObject(repo *resourcewatchquery.Repository, selector resourcewatchquery.Selector) (resourcewatchquery.ObjectReference, error) {
+	refs := map[resourcewatchquery.ObjectReference]bool{}
+	for _, change := range repo.Changes(selector, time.Time{}, time.Time{}) {
+		refs[change.Object] = true
+	}
+	switch len(refs) {
+	case 0:
+		return resourcewatchquery.ObjectReference{}, fmt.Errorf("no object matches")
+	case 1:
+		for ref := range refs {
+			return ref, nil
+		}
+	}
+	matches := []string{}
+	for ref := range refs {
+		matches = append(matches, ref.String())
+	}
+	return resourcewatchquery.ObjectReference{}, fmt.Errorf("%d objects match, narrow the selection: %s", len(matches), strings.Join(matches, ", "))
+}
+
+func describeVersion(obj *resourcewatchquery.Object) string {
+	if obj == nil {
+		return "<absent>"
+	}
+	return fmt.Sprintf("the version observed at %s", obj.Change.Observed.UTC().Format(time.RFC3339Nano))
+}
+
+func printFieldChanges(out io.Writer, indent string, diff resourcewatchquery.ObjectDiff) {
+	for _, path := range diff.Fields.Added {
+		fmt.Fprintf(out, "%s+ %s\n", indent, path)
+	}
+	for _, path := range diff.Fields.Modified {
+		fmt.Fprintf(out, "%s~ %s\n", indent, path)
+	}
+	for _, path := range diff.Fields.Removed {
+		fmt.Fprintf(out, "%s- %s\n", indent, path)
+	}
+}
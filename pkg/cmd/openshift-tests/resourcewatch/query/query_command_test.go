@@ -0,0 +1,187 @@
+package query
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/openshift/origin/pkg/resourcewatch/storage"
+)
+
+var (
+	t1 = time.Date(2024, 5, 1, 14, 0, 0, 0, time.UTC)
+	t2 = t1.Add(10 * time.Minute)
+	t3 = t1.Add(20 * time.Minute)
+	t4 = t1.Add(30 * time.Minute)
+)
+
+const (
+	configMapA = "namespaces/ns/core/configmaps/a.yaml"
+	configMapB = "namespaces/ns/core/configmaps/b.yaml"
+)
+
+func configMapYAML(name, value string) string {
+	return "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: " + name + "\n  namespace: ns\ndata:\n  key: " + value + "\n"
+}
+
+// newTestRepository records a being added and modified, and b being added and removed, the way run-resourcewatch
+// commits them.
+func newTestRepository(t *testing.T) string {
+	path := t.TempDir()
+	repo, err := git.PlainInit(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit := func(change storage.ResourceChange, content string) {
+		t.Helper()
+		if change.Operation == "removed" {
+			if _, err := worktree.Remove(change.Path); err != nil {
+				t.Fatal(err)
+			}
+		} else {
+			fullPath := filepath.Join(path, change.Path)
+			if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := worktree.Add(change.Path); err != nil {
+				t.Fatal(err)
+			}
+		}
+		message := change.Operation + "\n\n" + change.String() + "\n"
+		if _, err := worktree.Commit(message, &git.CommitOptions{
+			Author: &object.Signature{Name: change.Author, Email: "ci-monitor@openshift.io", When: change.Observed},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	commit(storage.ResourceChange{Operation: "added", Observed: t1, Path: configMapA, Author: "creator"}, configMapYAML("a", "one"))
+	commit(storage.ResourceChange{Operation: "modified", Observed: t2, Path: configMapA, Author: "editor"}, configMapYAML("a", "two"))
+	commit(storage.ResourceChange{Operation: "added", Observed: t2.Add(time.Minute), Path: configMapB, Author: "creator"}, configMapYAML("b", "one"))
+	commit(storage.ResourceChange{Operation: "removed", Observed: t3, Path: configMapB, Author: "unknown"}, "")
+	return path
+}
+
+func TestQueryCommand(t *testing.T) {
+	repositoryPath := newTestRepository(t)
+
+	tests := []struct {
+		name         string
+		args         []string
+		wantContains []string
+		wantMissing  []string
+		wantErr      string
+	}{
+		{
+			name:         "snapshot",
+			args:         []string{"snapshot", "--at", t2.Add(2 * time.Minute).Format(time.RFC3339)},
+			wantContains: []string{"# configmaps/a -n ns as of 2024-05-01T14:10:00Z\n", "key: two", "---\n# configmaps/b -n ns as of 2024-05-01T14:11:00Z\n"},
+		},
+		{
+			name:         "snapshot after removal",
+			args:         []string{"snapshot", "--at", t4.Format(time.RFC3339)},
+			wantContains: []string{"# configmaps/a -n ns"},
+			wantMissing:  []string{"configmaps/b"},
+		},
+		{
+			name:    "snapshot without time",
+			args:    []string{"snapshot"},
+			wantErr: "--at is required",
+		},
+		{
+			name:         "diff from absent",
+			args:         []string{"diff", "--resource", "configmaps", "--name", "b", "--from", t1.Format(time.RFC3339), "--to", t2.Add(2 * time.Minute).Format(time.RFC3339)},
+			wantContains: []string{"configmaps/b -n ns from <absent> to the version observed at 2024-05-01T14:11:00Z\n", "+ .data.key\n"},
+		},
+		{
+			name:         "diff to absent",
+			args:         []string{"diff", "--resource", "configmaps", "--name", "b", "--from", t2.Add(2 * time.Minute).Format(time.RFC3339), "--to", t4.Format(time.RFC3339)},
+			wantContains: []string{"configmaps/b -n ns from the version observed at 2024-05-01T14:11:00Z to <absent>\n", "- .data.key\n"},
+		},
+		{
+			name:         "diff modification",
+			args:         []string{"diff", "--resource", "configmaps", "--name", "a", "--from", t1.Format(time.RFC3339), "--to", t4.Format(time.RFC3339)},
+			wantContains: []string{"~ .data.key\n"},
+			// only the field changes, the content diff below them varies its whitespace on purpose
+			wantMissing: []string{"+ .", "- ."},
+		},
+		{
+			name:    "diff without name",
+			args:    []string{"diff", "--resource", "configmaps", "--from", t1.Format(time.RFC3339), "--to", t4.Format(time.RFC3339)},
+			wantErr: "--resource and --name are required",
+		},
+		{
+			name:    "diff without match",
+			args:    []string{"diff", "--resource", "secrets", "--name", "a", "--from", t1.Format(time.RFC3339), "--to", t4.Format(time.RFC3339)},
+			wantErr: "no object matches",
+		},
+		{
+			name:    "diff with bad time",
+			args:    []string{"diff", "--resource", "configmaps", "--name", "a", "--from", "yesterday", "--to", t4.Format(time.RFC3339)},
+			wantErr: "--from must be in RFC3339 format",
+		},
+		{
+			name: "history",
+			args: []string{"history", "--resource", "configmaps", "--name", "a"},
+			wantContains: []string{
+				"2024-05-01T14:00:00Z added configmaps/a -n ns by creator",
+				"2024-05-01T14:10:00Z modified configmaps/a -n ns by editor",
+				"    ~ .data.key\n",
+			},
+			wantMissing: []string{"configmaps/b"},
+		},
+		{
+			name:         "history in a window",
+			args:         []string{"history", "--from", t2.Add(time.Second).Format(time.RFC3339), "--to", t4.Format(time.RFC3339)},
+			wantContains: []string{"added configmaps/b -n ns by creator", "removed configmaps/b -n ns by unknown"},
+			wantMissing:  []string{"configmaps/a"},
+		},
+		{
+			name:         "intervals",
+			args:         []string{"intervals", "--name", "b"},
+			wantContains: []string{`"name": "b"`},
+			wantMissing:  []string{`"name": "a"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			cmd := NewQueryCommand(genericclioptions.IOStreams{Out: out, ErrOut: &bytes.Buffer{}})
+			cmd.SetArgs(append(tt.args, "--repository-path", repositoryPath))
+			cmd.SetOut(&bytes.Buffer{})
+			cmd.SetErr(&bytes.Buffer{})
+
+			err := cmd.Execute()
+			switch {
+			case len(tt.wantErr) > 0 && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			case len(tt.wantErr) == 0 && err != nil:
+				t.Fatal(err)
+			}
+			for _, want := range tt.wantContains {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("expected output to contain %q, got:\n%s", want, out.String())
+				}
+			}
+			for _, unwanted := range tt.wantMissing {
+				if strings.Contains(out.String(), unwanted) {
+					t.Errorf("expected output not to contain %q, got:\n%s", unwanted, out.String())
+				}
+			}
+		})
+	}
+}
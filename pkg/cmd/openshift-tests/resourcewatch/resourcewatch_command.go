@@ -0,0 +1,19 @@
+package resourcewatch
+
+import (
+	"github.com/openshift/origin/pkg/cmd/openshift-tests/resourcewatch/query"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func NewResourceWatchCommand(streams genericclioptions.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "resourcewatch",
+		Short:         "Inspect repositories written by run-resourcewatch",
+		SilenceErrors: true,
+	}
+	cmd.AddCommand(
+		query.NewQueryCommand(streams),
+	)
+	return cmd
+}
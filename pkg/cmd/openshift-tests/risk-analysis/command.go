@@ -21,6 +21,12 @@ files after an invocation of openshift-tests. If multiple files are present
 Results are then submitted to sippy which will return an analysis of per-test
 and overall risk level given historical pass rates on the failed tests.
 The resulting analysis is then also written to the junit artifacts directory.
+
+When --historical-data is set, sippy is not contacted. The failures are instead
+scored against the pass rates in that file, a JSON list or CSV file with the
+columns TestName, Release, FromRelease, Platform, Architecture, Network,
+Topology, Runs and Passes. Rows with empty job variant columns apply to any
+variant without a more specific row.
 `),
 
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -34,5 +40,8 @@ The resulting analysis is then also written to the junit artifacts directory.
 	cmd.Flags().StringVar(&riskAnalysisOpts.SippyURL,
 		"sippy-url", sippyDefaultURL,
 		"Sippy URL API endpoint")
+	cmd.Flags().StringVar(&riskAnalysisOpts.HistoricalDataFile,
+		"historical-data", riskAnalysisOpts.HistoricalDataFile,
+		"A local file of historical test pass rates to analyze risk against instead of sippy.")
 	return cmd
 }
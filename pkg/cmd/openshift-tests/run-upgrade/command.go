@@ -26,6 +26,12 @@ func NewRunUpgradeCommand(streams genericclioptions.IOStreams) *cobra.Command {
 		If you specify the --dry-run argument, the actions the suite will take will be printed to the
 		output.
 
+		Specify --to-image more than once, or as a comma delimited list, to upgrade through each release in
+		order. All hops run under one monitor session, and the upgrade tests report results for each hop.
+		To keep machine config pools from updating during a hop, as the worker pool during an EUS-to-EUS
+		upgrade, pass --paused-pools=HOP=POOL[,POOL...]. A paused pool is unpaused before the first later
+		hop that does not list it, or after the last hop.
+
 		Supported options:
 
 		* abort-at=NUMBER - Set to a number between 0 and 100 to control the percent of operators
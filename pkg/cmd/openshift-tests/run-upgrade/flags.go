@@ -2,11 +2,15 @@ package run_upgrade
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
+
 	"github.com/openshift/origin/pkg/clioptions/clusterdiscovery"
 	"github.com/openshift/origin/pkg/clioptions/iooptions"
 	"github.com/openshift/origin/pkg/clioptions/kubeconfig"
 	"github.com/openshift/origin/pkg/clioptions/suiteselection"
 	testginkgo "github.com/openshift/origin/pkg/test/ginkgo"
+	"github.com/openshift/origin/test/e2e/upgrade"
 	"github.com/spf13/pflag"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 )
@@ -23,8 +27,10 @@ type RunUpgradeSuiteFlags struct {
 
 	// Passed to the test process if set
 	UpgradeSuite string
-	ToImage      string
-	TestOptions  []string
+	ToImages     []string
+	// PausedPools holds HOP=POOL[,POOL...] values naming the machine config pools to keep paused during a hop
+	PausedPools []string
+	TestOptions []string
 
 	// Shared by initialization code
 	config *clusterdiscovery.ClusterConfiguration
@@ -47,7 +53,8 @@ func NewRunUpgradeSuiteFlags(streams genericclioptions.IOStreams, fromRepository
 func (f *RunUpgradeSuiteFlags) BindFlags(flags *pflag.FlagSet) {
 	flags.StringVar(&f.FromRepository, "from-repository", f.FromRepository, "A container image repository to retrieve test images from.")
 	flags.StringVar(&f.ProviderTypeOrJSON, "provider", f.ProviderTypeOrJSON, "The cluster infrastructure provider. Will automatically default to the correct value.")
-	flags.StringVar(&f.ToImage, "to-image", f.ToImage, "Specify the image to test an upgrade to.")
+	flags.StringSliceVar(&f.ToImages, "to-image", f.ToImages, "Specify the image to test an upgrade to. Repeat the flag or pass a comma delimited list to upgrade through each image in order.")
+	flags.StringArrayVar(&f.PausedPools, "paused-pools", f.PausedPools, "A HOP=POOL[,POOL...] list of machine config pools to keep paused during an upgrade hop, numbered from 1. Repeat for each hop.")
 	flags.StringSliceVar(&f.TestOptions, "options", f.TestOptions, "A set of KEY=VALUE options to control the test. See the help text.")
 	f.GinkgoRunSuiteOptions.BindFlags(flags)
 	f.TestSuiteSelectionFlags.BindFlags(flags)
@@ -76,9 +83,13 @@ func (f *RunUpgradeSuiteFlags) ToOptions(args []string) (*RunUpgradeSuiteOptions
 	// and when the CVO hangs.
 	ginkgoOptions.IncludeSuccessOutput = true
 
-	if len(f.ToImage) == 0 {
+	if len(f.ToImages) == 0 {
 		return nil, fmt.Errorf("--to-image must be specified to run an upgrade test")
 	}
+	upgradePath, err := f.upgradePath()
+	if err != nil {
+		return nil, err
+	}
 
 	suite, err := f.TestSuiteSelectionFlags.SelectSuite(
 		f.AvailableSuites,
@@ -95,7 +106,7 @@ func (f *RunUpgradeSuiteFlags) ToOptions(args []string) (*RunUpgradeSuiteOptions
 	o := &RunUpgradeSuiteOptions{
 		GinkgoRunSuiteOptions: ginkgoOptions,
 		Suite:                 suite,
-		ToImage:               f.ToImage,
+		UpgradePath:           upgradePath,
 		FromRepository:        f.FromRepository,
 		TestOptions:           f.TestOptions,
 		CloseFn:               closeFn,
@@ -104,3 +115,32 @@ func (f *RunUpgradeSuiteFlags) ToOptions(args []string) (*RunUpgradeSuiteOptions
 
 	return o, nil
 }
+
+// upgradePath combines the upgrade targets with the machine config pools each hop keeps paused.
+func (f *RunUpgradeSuiteFlags) upgradePath() ([]upgrade.Hop, error) {
+	path := []upgrade.Hop{}
+	for _, image := range f.ToImages {
+		if len(image) == 0 {
+			return nil, fmt.Errorf("--to-image must not contain an empty image")
+		}
+		path = append(path, upgrade.Hop{ToImage: image})
+	}
+
+	for _, value := range f.PausedPools {
+		parts := strings.SplitN(value, "=", 2)
+		if len(parts) != 2 || len(parts[1]) == 0 {
+			return nil, fmt.Errorf("expected --paused-pools of the form HOP=POOL[,POOL...] instead of %q", value)
+		}
+		hop, err := strconv.Atoi(parts[0])
+		if err != nil || hop < 1 || hop > len(path) {
+			return nil, fmt.Errorf("--paused-pools %q must name a hop between 1 and %d", value, len(path))
+		}
+		for _, pool := range strings.Split(parts[1], ",") {
+			if len(pool) == 0 {
+				return nil, fmt.Errorf("--paused-pools %q must not contain an empty pool", value)
+			}
+			path[hop-1].PausedPools = append(path[hop-1].PausedPools, pool)
+		}
+	}
+	return path, nil
+}
@@ -0,0 +1,70 @@
+package run_upgrade
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/openshift/origin/test/e2e/upgrade"
+)
+
+func TestUpgradePath(t *testing.T) {
+	testCases := []struct {
+		name        string
+		toImages    []string
+		pausedPools []string
+		expected    []upgrade.Hop
+		expectedErr string
+	}{
+		{
+			name:     "single hop",
+			toImages: []string{"quay.io/openshift/release:4.14.0"},
+			expected: []upgrade.Hop{{ToImage: "quay.io/openshift/release:4.14.0"}},
+		},
+		{
+			name:        "eus upgrade",
+			toImages:    []string{"quay.io/openshift/release:4.13.0", "quay.io/openshift/release:4.14.0"},
+			pausedPools: []string{"1=worker,infra", "2=worker"},
+			expected: []upgrade.Hop{
+				{ToImage: "quay.io/openshift/release:4.13.0", PausedPools: []string{"worker", "infra"}},
+				{ToImage: "quay.io/openshift/release:4.14.0", PausedPools: []string{"worker"}},
+			},
+		},
+		{
+			name:        "hop out of range",
+			toImages:    []string{"quay.io/openshift/release:4.14.0"},
+			pausedPools: []string{"2=worker"},
+			expectedErr: `--paused-pools "2=worker" must name a hop between 1 and 1`,
+		},
+		{
+			name:        "missing pools",
+			toImages:    []string{"quay.io/openshift/release:4.14.0"},
+			pausedPools: []string{"1"},
+			expectedErr: `expected --paused-pools of the form HOP=POOL[,POOL...] instead of "1"`,
+		},
+		{
+			name:        "empty pool",
+			toImages:    []string{"quay.io/openshift/release:4.14.0"},
+			pausedPools: []string{"1=worker,"},
+			expectedErr: `--paused-pools "1=worker," must not contain an empty pool`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			f := &RunUpgradeSuiteFlags{ToImages: testCase.toImages, PausedPools: testCase.pausedPools}
+			path, err := f.upgradePath()
+			if len(testCase.expectedErr) > 0 {
+				if err == nil || err.Error() != testCase.expectedErr {
+					t.Fatalf("expected error %q, got %v", testCase.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(path, testCase.expected) {
+				t.Errorf("expected %#v, got %#v", testCase.expected, path)
+			}
+		})
+	}
+}
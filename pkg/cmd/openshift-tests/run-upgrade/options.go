@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/openshift/origin/pkg/monitortestframework"
 
@@ -14,6 +15,7 @@ import (
 	"github.com/openshift/origin/pkg/clioptions/upgradeoptions"
 	testginkgo "github.com/openshift/origin/pkg/test/ginkgo"
 	"github.com/openshift/origin/pkg/version"
+	"github.com/openshift/origin/test/e2e/upgrade"
 	exutil "github.com/openshift/origin/test/extended/util"
 	"github.com/openshift/origin/test/extended/util/image"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
@@ -26,7 +28,8 @@ type RunUpgradeSuiteOptions struct {
 	GinkgoRunSuiteOptions *testginkgo.GinkgoRunSuiteOptions
 	Suite                 *testginkgo.TestSuite
 
-	ToImage        string
+	// UpgradePath is the ordered list of upgrades to perform
+	UpgradePath    []upgrade.Hop
 	FromRepository string
 	// I don't see where this is initialized in this flow
 	// CloudProviderJSON string
@@ -51,9 +54,14 @@ func (o *RunUpgradeSuiteOptions) TestCommandEnvironment() []string {
 		}
 	}
 
+	toImages := []string{}
+	for _, hop := range o.UpgradePath {
+		toImages = append(toImages, hop.ToImage)
+	}
 	upgradeOptions := upgradeoptions.UpgradeOptions{
 		Suite:       o.Suite.Name,
-		ToImage:     o.ToImage,
+		ToImage:     strings.Join(toImages, ","),
+		Hops:        o.UpgradePath,
 		TestOptions: o.TestOptions,
 	}
 	args = append(args, fmt.Sprintf("TEST_UPGRADE_OPTIONS=%s", upgradeOptions.ToEnv()))
@@ -108,7 +116,7 @@ func (o *RunUpgradeSuiteOptions) Run(ctx context.Context) error {
 	// TODO the gingkoRunSuiteOptions needs to have flags then calculated options to express specified versus computed values
 	monitorTestInfo := monitortestframework.MonitorTestInitializationInfo{
 		ClusterStabilityDuringTest:        monitortestframework.Stable,
-		UpgradeTargetPayloadImagePullSpec: o.ToImage,
+		UpgradeTargetPayloadImagePullSpec: o.UpgradePath[len(o.UpgradePath)-1].ToImage,
 		ExactMonitorTests:                 o.GinkgoRunSuiteOptions.ExactMonitorTests,
 		DisableMonitorTests:               o.GinkgoRunSuiteOptions.DisableMonitorTests,
 	}
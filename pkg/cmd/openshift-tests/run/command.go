@@ -28,6 +28,20 @@ func NewRunCommand(streams genericclioptions.IOStreams) *cobra.Command {
 		command with the --file argument. You may also pipe a list of test names, one per line, on
 		standard input by passing "-f -".
 
+		If you specify the --explain argument, each test is listed along with whether it was selected
+		and, if not, the first filter that excluded it: the suite itself, --file, --run, the cluster
+		configuration, served API groups, or enabled FeatureGates. Pass a regular expression, as in
+		--explain=sig-network, to only explain matching tests. Nothing is run, but the cluster is
+		discovered as for a run; if it cannot be reached the decisions are made for the skeleton
+		cluster used by --dry-run and the output says so.
+
+		Tests are skipped on clusters where an expression in one of their labels is true, for instance
+		[SkipWhen:platform == "gce" && !ipv4]. Expressions compare the string facts platform, network,
+		networkMode and topology with ==, !=; test the boolean facts disconnected, proxied, ibmroks,
+		sctp, ipv4, ipv6, dualstack and optionalCapabilities; check membership with "name" in featureGates
+		or "group" in apiGroups; and combine with !, && and ||. Labels like [Skipped:gce] are shorthand
+		for such expressions. The same expressions can be used in a --skip-rules file.
+
 		`) + testsuites.SuitesString(testsuites.StandardTestSuites(), "\n\nAvailable test suites:\n\n"),
 
 		SilenceUsage:  true,
@@ -22,6 +22,8 @@ type RunSuiteFlags struct {
 
 	FromRepository     string
 	ProviderTypeOrJSON string
+	// SkipRulesFile holds extra rules for which tests to skip on which clusters
+	SkipRulesFile string
 
 	// Passed to the test process if set
 	UpgradeSuite string
@@ -51,12 +53,16 @@ func NewRunSuiteFlags(streams genericclioptions.IOStreams, fromRepository string
 //  2. ensures that the suite filters out tests from providers that aren't relevant (see exutilcluster.ClusterConfig.MatchFn) by
 //     loading the provider info from the cluster or flags.
 func (f *RunSuiteFlags) SuiteWithKubeTestInitializationPreSuite() (*clusterdiscovery.ClusterConfiguration, error) {
-	providerConfig, err := clusterdiscovery.DecodeProvider(f.ProviderTypeOrJSON, f.GinkgoRunSuiteOptions.DryRun, true, nil)
+	return f.suiteWithKubeTestInitializationPreSuite(f.GinkgoRunSuiteOptions.DryRun)
+}
+
+func (f *RunSuiteFlags) suiteWithKubeTestInitializationPreSuite(dryRun bool) (*clusterdiscovery.ClusterConfiguration, error) {
+	providerConfig, err := clusterdiscovery.DecodeProvider(f.ProviderTypeOrJSON, dryRun, true, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := clusterdiscovery.InitializeTestFramework(exutil.TestContext, providerConfig, f.GinkgoRunSuiteOptions.DryRun); err != nil {
+	if err := clusterdiscovery.InitializeTestFramework(exutil.TestContext, providerConfig, dryRun); err != nil {
 		return nil, err
 	}
 	return providerConfig, nil
@@ -65,6 +71,7 @@ func (f *RunSuiteFlags) SuiteWithKubeTestInitializationPreSuite() (*clusterdisco
 func (f *RunSuiteFlags) BindFlags(flags *pflag.FlagSet) {
 	flags.StringVar(&f.FromRepository, "from-repository", f.FromRepository, "A container image repository to retrieve test images from.")
 	flags.StringVar(&f.ProviderTypeOrJSON, "provider", f.ProviderTypeOrJSON, "The cluster infrastructure provider. Will automatically default to the correct value.")
+	flags.StringVar(&f.SkipRulesFile, "skip-rules", f.SkipRulesFile, "A YAML file of rules that skip tests matching a regular expression when a cluster expression, like 'platform == \"aws\" && !ipv4', is true.")
 	f.GinkgoRunSuiteOptions.BindFlags(flags)
 	f.TestSuiteSelectionFlags.BindFlags(flags)
 	f.OutputFlags.BindFlags(flags)
@@ -76,12 +83,22 @@ func (f *RunSuiteFlags) SetIOStreams(streams genericclioptions.IOStreams) {
 }
 
 func (f *RunSuiteFlags) ToOptions(args []string) (*RunSuiteOptions, error) {
+	// explaining the selection never runs anything, but it discovers the cluster like a run does so that it explains
+	// the decisions a run would make.  Only when the cluster cannot be reached does it fall back to the skeleton
+	// cluster of a dry-run, and the explanation says so.
+	explain := len(f.GinkgoRunSuiteOptions.Explain) > 0
+	dryRun := f.GinkgoRunSuiteOptions.DryRun
+
 	adminRESTConfig, err := kubeconfig.GetStaticRESTConfig()
 	switch {
-	case err != nil && f.GinkgoRunSuiteOptions.DryRun:
+	case err != nil && dryRun:
 		fmt.Fprintf(f.ErrOut, "Unable to get admin rest config, skipping apigroup check in the dry-run mode: %v\n", err)
 		adminRESTConfig = &rest.Config{}
-	case err != nil && !f.GinkgoRunSuiteOptions.DryRun:
+	case err != nil && explain:
+		f.GinkgoRunSuiteOptions.ExplainNote = explainWithoutClusterNote(err)
+		dryRun = true
+		adminRESTConfig = &rest.Config{}
+	case err != nil && !dryRun:
 		return nil, fmt.Errorf("unable to get admin rest config, %w", err)
 	}
 
@@ -93,21 +110,34 @@ func (f *RunSuiteFlags) ToOptions(args []string) (*RunSuiteOptions, error) {
 	// shallow copy to mutate
 	ginkgoOptions := f.GinkgoRunSuiteOptions
 
-	providerConfig, err := f.SuiteWithKubeTestInitializationPreSuite()
+	providerConfig, err := f.suiteWithKubeTestInitializationPreSuite(dryRun)
+	if err != nil && explain && !dryRun {
+		f.GinkgoRunSuiteOptions.ExplainNote = explainWithoutClusterNote(err)
+		dryRun = true
+		providerConfig, err = f.suiteWithKubeTestInitializationPreSuite(dryRun)
+	}
 	if err != nil {
 		return nil, err
 	}
+	var skipRules []clusterdiscovery.SkipRule
+	if len(f.SkipRulesFile) > 0 {
+		skipRules, err = clusterdiscovery.LoadSkipRules(f.SkipRulesFile)
+		if err != nil {
+			return nil, err
+		}
+	}
 	suite, err := f.TestSuiteSelectionFlags.SelectSuite(
 		f.AvailableSuites,
 		args,
 		kubeconfig.NewDiscoveryGetter(adminRESTConfig),
 		kubeconfig.NewConfigClientGetter(adminRESTConfig),
-		f.GinkgoRunSuiteOptions.DryRun,
-		providerConfig.MatchFn(),
+		dryRun,
+		providerConfig.RejectionReasonFn(skipRules),
 	)
 	if err != nil {
 		return nil, err
 	}
+	suite.AddValidation(clusterdiscovery.ValidateSkipLabels)
 
 	o := &RunSuiteOptions{
 		GinkgoRunSuiteOptions: ginkgoOptions,
@@ -120,3 +150,8 @@ func (f *RunSuiteFlags) ToOptions(args []string) (*RunSuiteOptions, error) {
 
 	return o, nil
 }
+
+func explainWithoutClusterNote(err error) string {
+	return fmt.Sprintf("the cluster could not be discovered, so cluster configuration, API group, and FeatureGate "+
+		"decisions are for a skeleton cluster and may differ from a run: %v", err)
+}
@@ -1,12 +1,45 @@
 package run_resource_watch
 
 import (
+	"time"
+
 	"github.com/openshift/origin/pkg/resourcewatch/operator"
+	"github.com/openshift/origin/pkg/resourcewatch/storage"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/kubectl/pkg/util/templates"
 )
 
+type RunResourceWatchFlags struct {
+	ConfigFile              string
+	RepositoryPath          string
+	DisableDefaultResources bool
+	IncludeResources        []string
+	ExcludeResources        []string
+	DiscoverGroups          []string
+	Namespaces              []string
+	LabelSelector           string
+	Redactions              []string
+
+	MaxBatchSize       int
+	MaxBatchDelay      time.Duration
+	QueueSize          int
+	MetricsBindAddress string
+}
+
+func NewRunResourceWatchFlags() *RunResourceWatchFlags {
+	batchOptions := storage.DefaultBatchOptions()
+	return &RunResourceWatchFlags{
+		MaxBatchSize:  batchOptions.MaxBatchSize,
+		MaxBatchDelay: batchOptions.MaxBatchDelay,
+		QueueSize:     batchOptions.QueueSize,
+	}
+}
+
 func NewRunResourceWatchCommand() *cobra.Command {
+	f := NewRunResourceWatchFlags()
+
 	cmd := &cobra.Command{
 		Use:   "run-resourcewatch",
 		Short: "Run watch for resource changes and commit each to a git repository",
@@ -14,20 +47,101 @@ func NewRunResourceWatchCommand() *cobra.Command {
 			Watches specific resources using the given kubeconfig for create/update/delete,
 			and commits the latest state of the resource to a git repo. This allows you to
 			see precisely how a resource changed over time.
-			By default /repository will be used, specify REPOSITORY_PATH env var to
-			override.
+			By default /repository will be used, specify REPOSITORY_PATH env var or
+			--repository-path to override.
+
+			The set of watched resources starts from a built-in list of platform resources.
+			Use --include-resource and --exclude-resource to adjust it, and --discover-group
+			to watch every resource of an API group, for instance one serving your own CRDs.
+			Use --redact to remove noisy or sensitive fields before they are committed.
+			All of these may also be provided in a yaml file with --config, flags are added
+			to the values from the file.
+
+			Changes are committed in batches of up to --max-batch-size changes, at least every
+			--max-batch-delay.  Every change in a commit is listed in a Resource-Change trailer
+			with the user that made it.
+
 			Sample invocation against an external cluster:
 			  $ REPOSITORY_PATH="/tmp/resource-watch-repo" openshift-tests run-resourcewatch --kubeconfig /path/to/kubeconfig --namespace default
+			Watching extra resources while dropping heartbeats and secret contents:
+			  $ openshift-tests run-resourcewatch --repository-path /tmp/resource-watch-repo \
+			      --discover-group example.openshift.io --include-resource v1/configmaps \
+			      --redact 'status.conditions[*].lastHeartbeatTime' --redact secrets:data
 		`),
 
 		SilenceUsage:  true,
 		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return operator.RunResourceWatch()
+			config, err := f.ToConfig(cmd.Flags())
+			if err != nil {
+				return err
+			}
+			return operator.RunResourceWatch(config)
 		},
 	}
+	f.BindFlags(cmd.Flags())
+
 	var dummy string
 	cmd.Flags().StringVar(&dummy, "kubeconfig", "", "This option is not used any more. It will be removed in later releases")
 	cmd.Flags().StringVar(&dummy, "namespace", "", "This option is not used any more. It will be removed in later releases")
 	return cmd
 }
+
+func (f *RunResourceWatchFlags) BindFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&f.ConfigFile, "config", f.ConfigFile, "A yaml file describing the resources to watch, see ResourceWatchConfig for the fields.")
+	flags.StringVar(&f.RepositoryPath, "repository-path", f.RepositoryPath, "The git repository to commit changes to.  Overrides REPOSITORY_PATH.")
+	flags.BoolVar(&f.DisableDefaultResources, "disable-default-resources", f.DisableDefaultResources, "Do not watch the built-in list of platform resources.")
+	flags.StringSliceVar(&f.IncludeResources, "include-resource", f.IncludeResources, "Additional resources to watch as group/version/resource, or version/resource for the core group.")
+	flags.StringSliceVar(&f.ExcludeResources, "exclude-resource", f.ExcludeResources, "Resources not to watch as group/version/resource, or version/resource for the core group.")
+	flags.StringSliceVar(&f.DiscoverGroups, "discover-group", f.DiscoverGroups, "API groups for which every watchable resource found in discovery at startup is watched.")
+	flags.StringSliceVar(&f.Namespaces, "watch-namespace", f.Namespaces, "Only record namespaced resources in these namespaces.  Cluster scoped resources are always recorded.")
+	flags.StringVar(&f.LabelSelector, "label-selector", f.LabelSelector, "Only record resources matching this label selector.")
+	flags.IntVar(&f.MaxBatchSize, "max-batch-size", f.MaxBatchSize, "Commit after this many changes.  1 commits every change on its own.")
+	flags.DurationVar(&f.MaxBatchDelay, "max-batch-delay", f.MaxBatchDelay, "Commit pending changes at least this often.")
+	flags.IntVar(&f.QueueSize, "queue-size", f.QueueSize, "The number of observed changes that may wait to be committed before informers are slowed down.")
+	flags.StringVar(&f.MetricsBindAddress, "metrics-bind-address", f.MetricsBindAddress, "Serve prometheus metrics on /metrics at this address, for instance :9090.")
+	flags.StringArrayVar(&f.Redactions, "redact", f.Redactions, "Fields to remove before committing, as [resource[.group]:]path, for instance 'status.conditions[*].lastHeartbeatTime' or 'secrets:data'.")
+}
+
+func (f *RunResourceWatchFlags) ToConfig(flags *pflag.FlagSet) (*operator.ResourceWatchConfig, error) {
+	config := operator.NewResourceWatchConfig()
+	if len(f.ConfigFile) > 0 {
+		var err error
+		config, err = operator.ReadResourceWatchConfig(f.ConfigFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if flags.Changed("repository-path") {
+		config.RepositoryPath = f.RepositoryPath
+	}
+	if flags.Changed("disable-default-resources") {
+		config.DisableDefaultResources = f.DisableDefaultResources
+	}
+	if flags.Changed("label-selector") {
+		config.LabelSelector = f.LabelSelector
+	}
+	if flags.Changed("max-batch-size") {
+		config.MaxBatchSize = f.MaxBatchSize
+	}
+	if flags.Changed("max-batch-delay") {
+		config.MaxBatchDelay = metav1.Duration{Duration: f.MaxBatchDelay}
+	}
+	if flags.Changed("queue-size") {
+		config.QueueSize = f.QueueSize
+	}
+	if flags.Changed("metrics-bind-address") {
+		config.MetricsBindAddress = f.MetricsBindAddress
+	}
+	config.IncludeResources = append(config.IncludeResources, f.IncludeResources...)
+	config.ExcludeResources = append(config.ExcludeResources, f.ExcludeResources...)
+	config.DiscoverGroups = append(config.DiscoverGroups, f.DiscoverGroups...)
+	config.Namespaces = append(config.Namespaces, f.Namespaces...)
+	config.Redactions = append(config.Redactions, f.Redactions...)
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
@@ -0,0 +1,135 @@
+package crypto_policy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openshift/library-go/pkg/certs/cert-inspection/certgraphapi"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/origin/pkg/certs"
+)
+
+// CertificateCategory is the role of a certificate that decides which validity bounds apply to it.
+type CertificateCategory string
+
+const (
+	SignerCategory  CertificateCategory = "signer"
+	ServingCategory CertificateCategory = "serving"
+	ClientCategory  CertificateCategory = "client"
+)
+
+const day = 24 * time.Hour
+
+// ValidityBounds is the allowed range for the validity period of a certificate.  A zero bound is not checked.
+type ValidityBounds struct {
+	Minimum metav1.Duration `json:"minimum"`
+	Maximum metav1.Duration `json:"maximum"`
+}
+
+// CryptoPolicy describes the keys, signatures and lifetimes TLS artifacts are expected to have.
+type CryptoPolicy struct {
+	// MinimumRSAKeyBits is the smallest allowed RSA modulus.
+	MinimumRSAKeyBits int `json:"minimumRSAKeyBits"`
+	// DisallowedSignatureAlgorithms are x509.SignatureAlgorithm names, like SHA1-RSA.
+	DisallowedSignatureAlgorithms []string `json:"disallowedSignatureAlgorithms"`
+	// ValidityBounds are the allowed validity periods for each certificate category.  CA bundle entries are signers.
+	ValidityBounds map[CertificateCategory]ValidityBounds `json:"validityBounds"`
+}
+
+// DefaultCryptoPolicy is the policy the checked in violations are generated with.
+func DefaultCryptoPolicy() CryptoPolicy {
+	return CryptoPolicy{
+		MinimumRSAKeyBits: 2048,
+		DisallowedSignatureAlgorithms: []string{
+			"MD2-RSA",
+			"MD5-RSA",
+			"SHA1-RSA",
+			"DSA-SHA1",
+			"DSA-SHA256",
+			"ECDSA-SHA1",
+		},
+		ValidityBounds: map[CertificateCategory]ValidityBounds{
+			SignerCategory:  {Minimum: metav1.Duration{Duration: 12 * time.Hour}, Maximum: metav1.Duration{Duration: 10 * 365 * day}},
+			ServingCategory: {Minimum: metav1.Duration{Duration: time.Hour}, Maximum: metav1.Duration{Duration: 2 * 365 * day}},
+			ClientCategory:  {Minimum: metav1.Duration{Duration: time.Hour}, Maximum: metav1.Duration{Duration: 2 * 365 * day}},
+		},
+	}
+}
+
+// certificateCategories returns the categories of a certificate.  Certificates with more than one usage have the
+// details of each one set.
+func certificateCategories(details certgraphapi.CertKeyPairDetails) []CertificateCategory {
+	ret := []CertificateCategory{}
+	if details.SignerDetails != nil {
+		ret = append(ret, SignerCategory)
+	}
+	if details.ServingCertDetails != nil {
+		ret = append(ret, ServingCategory)
+	}
+	if details.ClientCertDetails != nil {
+		ret = append(ret, ClientCategory)
+	}
+	return ret
+}
+
+// Violations lists how the certificate breaks the policy.  Empty metadata, like for a key without a certificate, has
+// nothing to check.
+func (p CryptoPolicy) Violations(metadata certgraphapi.CertKeyMetadata, categories []CertificateCategory) []string {
+	ret := []string{}
+
+	if metadata.PublicKeyAlgorithm == "RSA" && len(metadata.PublicKeyBitSize) > 0 {
+		bits, err := parsePublicKeyBitSize(metadata.PublicKeyBitSize)
+		switch {
+		case err != nil:
+			ret = append(ret, err.Error())
+		case bits < p.MinimumRSAKeyBits:
+			ret = append(ret, fmt.Sprintf("RSA key is %d bits, the minimum is %d", bits, p.MinimumRSAKeyBits))
+		}
+	}
+
+	if sets.New[string](p.DisallowedSignatureAlgorithms...).Has(metadata.SignatureAlgorithm) {
+		ret = append(ret, fmt.Sprintf("signature algorithm %v is not allowed", metadata.SignatureAlgorithm))
+	}
+
+	if len(metadata.ValidityDuration) > 0 {
+		validity, err := certs.ParseValidityDuration(metadata.ValidityDuration)
+		if err != nil {
+			return append(ret, err.Error())
+		}
+		ret = append(ret, p.validityViolations(metadata.ValidityDuration, validity, categories)...)
+	}
+
+	return ret
+}
+
+func (p CryptoPolicy) validityViolations(humanValidity string, validity time.Duration, categories []CertificateCategory) []string {
+	ret := []string{}
+	for _, category := range categories {
+		bounds, ok := p.ValidityBounds[category]
+		if !ok {
+			continue
+		}
+		if minimum := bounds.Minimum.Duration; minimum > 0 && validity < minimum {
+			ret = append(ret, fmt.Sprintf("%v certificate is valid for %v, the minimum is %v", category, humanValidity, duration.HumanDuration(minimum)))
+		}
+		if maximum := bounds.Maximum.Duration; maximum > 0 && validity > maximum {
+			ret = append(ret, fmt.Sprintf("%v certificate is valid for %v, the maximum is %v", category, humanValidity, duration.HumanDuration(maximum)))
+		}
+	}
+	return ret
+}
+
+// parsePublicKeyBitSize reads sizes like "2048 bit" or "256 bit, P-256 curve".
+func parsePublicKeyBitSize(bitSize string) (int, error) {
+	bits, _, _ := strings.Cut(bitSize, " ")
+	ret, err := strconv.Atoi(bits)
+	if err != nil {
+		return 0, fmt.Errorf("unable to read key size %q", bitSize)
+	}
+	return ret, nil
+}
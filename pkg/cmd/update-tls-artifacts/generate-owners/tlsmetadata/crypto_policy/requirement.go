@@ -0,0 +1,264 @@
+package crypto_policy
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/openshift/library-go/pkg/certs/cert-inspection/certgraphapi"
+	"github.com/openshift/library-go/pkg/markdown"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/origin/pkg/certs"
+	"github.com/openshift/origin/pkg/cmd/update-tls-artifacts/generate-owners/tlsmetadatainterfaces"
+)
+
+type CryptoPolicyRequirement struct {
+	name   string
+	policy CryptoPolicy
+}
+
+func NewCryptoPolicyRequirement() tlsmetadatainterfaces.Requirement {
+	return NewCryptoPolicyRequirementForPolicy(DefaultCryptoPolicy())
+}
+
+func NewCryptoPolicyRequirementForPolicy(policy CryptoPolicy) tlsmetadatainterfaces.Requirement {
+	return CryptoPolicyRequirement{
+		name:   "crypto-policy",
+		policy: policy,
+	}
+}
+
+// CryptoPolicyReport is the content of crypto-policy.json.
+type CryptoPolicyReport struct {
+	Policy                      CryptoPolicy           `json:"policy"`
+	CertKeyPairs                []CryptoPolicyArtifact `json:"certKeyPairs"`
+	CertificateAuthorityBundles []CryptoPolicyArtifact `json:"certificateAuthorityBundles"`
+}
+
+// CryptoPolicyArtifact is the policy check of one TLS artifact location.
+type CryptoPolicyArtifact struct {
+	Location            string   `json:"location"`
+	OwningJiraComponent string   `json:"owningJiraComponent"`
+	Violations          []string `json:"violations,omitempty"`
+}
+
+// certKeyPairMetadata is what the raw data records about the certificate at a location.
+type certKeyPairMetadata struct {
+	metadata   certgraphapi.CertKeyMetadata
+	categories []CertificateCategory
+}
+
+// rawMetadataByLocation indexes the certificate metadata of the raw data by the locations used for violations.  The same
+// location can be collected from several clusters, so every location may have more than one certificate.
+type rawMetadataByLocation struct {
+	secrets         map[certgraphapi.InClusterSecretLocation][]certKeyPairMetadata
+	onDiskCertKeys  map[certgraphapi.OnDiskLocation][]certKeyPairMetadata
+	configMaps      map[certgraphapi.InClusterConfigMapLocation][]certgraphapi.CertKeyMetadata
+	onDiskCABundles map[certgraphapi.OnDiskLocation][]certgraphapi.CertKeyMetadata
+}
+
+func indexRawMetadata(rawData []*certgraphapi.PKIList) *rawMetadataByLocation {
+	ret := &rawMetadataByLocation{
+		secrets:         map[certgraphapi.InClusterSecretLocation][]certKeyPairMetadata{},
+		onDiskCertKeys:  map[certgraphapi.OnDiskLocation][]certKeyPairMetadata{},
+		configMaps:      map[certgraphapi.InClusterConfigMapLocation][]certgraphapi.CertKeyMetadata{},
+		onDiskCABundles: map[certgraphapi.OnDiskLocation][]certgraphapi.CertKeyMetadata{},
+	}
+	for _, currPKI := range rawData {
+		for _, certKeyPair := range currPKI.CertKeyPairs.Items {
+			curr := certKeyPairMetadata{
+				metadata:   certKeyPair.Spec.CertMetadata,
+				categories: certificateCategories(certKeyPair.Spec.Details),
+			}
+			for _, location := range certKeyPair.Spec.SecretLocations {
+				ret.secrets[location] = append(ret.secrets[location], curr)
+			}
+			for _, location := range certKeyPair.Spec.OnDiskLocations {
+				for _, path := range []certgraphapi.OnDiskLocation{location.Cert, location.Key} {
+					if len(path.Path) > 0 {
+						ret.onDiskCertKeys[path] = append(ret.onDiskCertKeys[path], curr)
+					}
+				}
+			}
+		}
+		for _, caBundle := range currPKI.CertificateAuthorityBundles.Items {
+			for _, location := range caBundle.Spec.ConfigMapLocations {
+				ret.configMaps[location] = append(ret.configMaps[location], caBundle.Spec.CertificateMetadata...)
+			}
+			for _, location := range caBundle.Spec.OnDiskLocations {
+				ret.onDiskCABundles[location] = append(ret.onDiskCABundles[location], caBundle.Spec.CertificateMetadata...)
+			}
+		}
+	}
+	return ret
+}
+
+func (o CryptoPolicyRequirement) certKeyPairViolations(curr certgraphapi.PKIRegistryCertKeyPair, rawMetadata *rawMetadataByLocation) []string {
+	var found []certKeyPairMetadata
+	if curr.InClusterLocation != nil {
+		found = rawMetadata.secrets[curr.InClusterLocation.SecretLocation]
+	}
+	if curr.OnDiskLocation != nil {
+		found = rawMetadata.onDiskCertKeys[curr.OnDiskLocation.OnDiskLocation]
+	}
+
+	violations := sets.New[string]()
+	for _, certificate := range found {
+		violations.Insert(o.policy.Violations(certificate.metadata, certificate.categories)...)
+	}
+	return sets.List(violations)
+}
+
+func (o CryptoPolicyRequirement) caBundleViolations(curr certgraphapi.PKIRegistryCABundle, rawMetadata *rawMetadataByLocation) []string {
+	var found []certgraphapi.CertKeyMetadata
+	if curr.InClusterLocation != nil {
+		found = rawMetadata.configMaps[curr.InClusterLocation.ConfigMapLocation]
+	}
+	if curr.OnDiskLocation != nil {
+		found = rawMetadata.onDiskCABundles[curr.OnDiskLocation.OnDiskLocation]
+	}
+
+	violations := sets.New[string]()
+	for _, certificate := range found {
+		for _, violation := range o.policy.Violations(certificate, []CertificateCategory{SignerCategory}) {
+			violations.Insert(fmt.Sprintf("%v: %v", certificate.CertIdentifier.CommonName, violation))
+		}
+	}
+	return sets.List(violations)
+}
+
+func (o CryptoPolicyRequirement) InspectRequirement(rawData []*certgraphapi.PKIList) (tlsmetadatainterfaces.RequirementResult, error) {
+	pkiInfo, err := tlsmetadatainterfaces.ProcessByLocation(rawData)
+	if err != nil {
+		return nil, fmt.Errorf("transforming raw data %v: %w", o.GetName(), err)
+	}
+	rawMetadata := indexRawMetadata(rawData)
+
+	report := &CryptoPolicyReport{Policy: o.policy}
+	violations := &certs.PKIRegistryInfo{}
+	for _, curr := range pkiInfo.CertKeyPairs {
+		certKeyInfo := tlsmetadatainterfaces.GetCertKeyPairInfo(curr)
+		if certKeyInfo == nil {
+			continue
+		}
+		artifact := CryptoPolicyArtifact{
+			Location:            certs.BuildCertKeyPath(curr),
+			OwningJiraComponent: certKeyInfo.OwningJiraComponent,
+			Violations:          o.certKeyPairViolations(curr, rawMetadata),
+		}
+		report.CertKeyPairs = append(report.CertKeyPairs, artifact)
+		if len(artifact.Violations) > 0 {
+			violations.CertKeyPairs = append(violations.CertKeyPairs, curr)
+		}
+	}
+	for _, curr := range pkiInfo.CertificateAuthorityBundles {
+		caBundleInfo := tlsmetadatainterfaces.GetCABundleInfo(curr)
+		if caBundleInfo == nil {
+			continue
+		}
+		artifact := CryptoPolicyArtifact{
+			Location:            certs.BuildCABundlePath(curr),
+			OwningJiraComponent: caBundleInfo.OwningJiraComponent,
+			Violations:          o.caBundleViolations(curr, rawMetadata),
+		}
+		report.CertificateAuthorityBundles = append(report.CertificateAuthorityBundles, artifact)
+		if len(artifact.Violations) > 0 {
+			violations.CertificateAuthorityBundles = append(violations.CertificateAuthorityBundles, curr)
+		}
+	}
+
+	reportJSONBytes, err := json.MarshalIndent(report, "", "    ")
+	if err != nil {
+		return nil, fmt.Errorf("failure marshalling %v.json: %w", o.GetName(), err)
+	}
+	markdown, err := o.generateCryptoPolicyMarkdown(report)
+	if err != nil {
+		return nil, fmt.Errorf("failure marshalling %v.md: %w", o.GetName(), err)
+	}
+	violationJSONBytes, err := tlsmetadatainterfaces.MarshalViolationsToJSON(violations)
+	if err != nil {
+		return nil, fmt.Errorf("failure marshalling %v-violations.json: %w", o.GetName(), err)
+	}
+
+	return tlsmetadatainterfaces.NewRequirementResult(
+		o.GetName(),
+		reportJSONBytes,
+		markdown,
+		violationJSONBytes)
+}
+
+func (o CryptoPolicyRequirement) generateCryptoPolicyMarkdown(report *CryptoPolicyReport) ([]byte, error) {
+	md := markdown.NewMarkdown("Cryptographic Policy")
+	md.Title(2, "How to meet the requirement")
+	md.Text("Certificates must use keys, signatures and validity periods that match the policy below.")
+	md.Text("CA bundle entries are checked as signer certificates.")
+	md.Text("")
+	md.Textf("* RSA keys must be at least %d bits.", o.policy.MinimumRSAKeyBits)
+	if len(o.policy.DisallowedSignatureAlgorithms) > 0 {
+		md.Textf("* These signature algorithms are not allowed: %v.", o.policy.DisallowedSignatureAlgorithms)
+	}
+	for _, category := range []CertificateCategory{SignerCategory, ServingCategory, ClientCategory} {
+		bounds, ok := o.policy.ValidityBounds[category]
+		if !ok {
+			continue
+		}
+		md.Textf("* The validity period of %v certificates must be between %v and %v.", category, duration.HumanDuration(bounds.Minimum.Duration), duration.HumanDuration(bounds.Maximum.Duration))
+	}
+	md.Text("")
+
+	violatingByOwner := map[string][]CryptoPolicyArtifact{}
+	compliantByOwner := map[string][]CryptoPolicyArtifact{}
+	numViolators, numCompliant := 0, 0
+	for _, artifact := range append(append([]CryptoPolicyArtifact{}, report.CertKeyPairs...), report.CertificateAuthorityBundles...) {
+		owner := artifact.OwningJiraComponent
+		if len(owner) == 0 {
+			owner = tlsmetadatainterfaces.UnknownOwner
+		}
+		if len(artifact.Violations) > 0 {
+			violatingByOwner[owner] = append(violatingByOwner[owner], artifact)
+			numViolators++
+			continue
+		}
+		compliantByOwner[owner] = append(compliantByOwner[owner], artifact)
+		numCompliant++
+	}
+
+	if numViolators > 0 {
+		md.Title(2, fmt.Sprintf("Items Do NOT Meet the Requirement (%d)", numViolators))
+		for _, owner := range sets.StringKeySet(violatingByOwner).List() {
+			artifacts := violatingByOwner[owner]
+			md.Title(3, fmt.Sprintf("%s (%d)", owner, len(artifacts)))
+			md.OrderedListStart()
+			for _, artifact := range artifacts {
+				md.NewOrderedListItem()
+				md.Textf("%v\n", artifact.Location)
+				for _, violation := range artifact.Violations {
+					md.Textf("* %v", violation)
+				}
+				md.Text("\n")
+			}
+			md.OrderedListEnd()
+			md.Text("\n")
+		}
+	}
+
+	md.Title(2, fmt.Sprintf("Items That DO Meet the Requirement (%d)", numCompliant))
+	for _, owner := range sets.StringKeySet(compliantByOwner).List() {
+		artifacts := compliantByOwner[owner]
+		md.Title(3, fmt.Sprintf("%s (%d)", owner, len(artifacts)))
+		md.OrderedListStart()
+		for _, artifact := range artifacts {
+			md.NewOrderedListItem()
+			md.Textf("%v\n", artifact.Location)
+		}
+		md.OrderedListEnd()
+		md.Text("\n")
+	}
+
+	return md.Bytes(), nil
+}
+
+func (o CryptoPolicyRequirement) GetName() string {
+	return o.name
+}
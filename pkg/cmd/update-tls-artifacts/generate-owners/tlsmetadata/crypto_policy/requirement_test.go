@@ -0,0 +1,177 @@
+package crypto_policy
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/openshift/library-go/pkg/certs/cert-inspection/certgraphapi"
+
+	"github.com/openshift/origin/pkg/certs"
+)
+
+func TestCryptoPolicyViolations(t *testing.T) {
+	policy := DefaultCryptoPolicy()
+	tests := []struct {
+		name       string
+		metadata   certgraphapi.CertKeyMetadata
+		categories []CertificateCategory
+		expected   []string
+	}{
+		{
+			name:       "compliant",
+			metadata:   certgraphapi.CertKeyMetadata{SignatureAlgorithm: "SHA256-RSA", PublicKeyAlgorithm: "RSA", PublicKeyBitSize: "2048 bit", ValidityDuration: "2y"},
+			categories: []CertificateCategory{ServingCategory},
+			expected:   []string{},
+		},
+		{
+			name:       "ecdsa keys have no minimum",
+			metadata:   certgraphapi.CertKeyMetadata{SignatureAlgorithm: "ECDSA-SHA256", PublicKeyAlgorithm: "ECDSA", PublicKeyBitSize: "256 bit, P-256 curve", ValidityDuration: "23h"},
+			categories: []CertificateCategory{ClientCategory},
+			expected:   []string{},
+		},
+		{
+			name:       "small key and weak signature",
+			metadata:   certgraphapi.CertKeyMetadata{SignatureAlgorithm: "SHA1-RSA", PublicKeyAlgorithm: "RSA", PublicKeyBitSize: "1024 bit", ValidityDuration: "365d"},
+			categories: []CertificateCategory{SignerCategory},
+			expected: []string{
+				"RSA key is 1024 bits, the minimum is 2048",
+				"signature algorithm SHA1-RSA is not allowed",
+			},
+		},
+		{
+			name:       "every category of a certificate with several usages is checked",
+			metadata:   certgraphapi.CertKeyMetadata{SignatureAlgorithm: "SHA256-RSA", PublicKeyAlgorithm: "RSA", PublicKeyBitSize: "2048 bit", ValidityDuration: "3y"},
+			categories: []CertificateCategory{ServingCategory, ClientCategory},
+			expected: []string{
+				"serving certificate is valid for 3y, the maximum is 2y",
+				"client certificate is valid for 3y, the maximum is 2y",
+			},
+		},
+		{
+			name:       "short lived signer",
+			metadata:   certgraphapi.CertKeyMetadata{SignatureAlgorithm: "SHA256-RSA", PublicKeyAlgorithm: "RSA", PublicKeyBitSize: "2048 bit", ValidityDuration: "6h"},
+			categories: []CertificateCategory{SignerCategory},
+			expected:   []string{"signer certificate is valid for 6h, the minimum is 12h"},
+		},
+		{
+			name:     "empty metadata",
+			expected: []string{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := policy.Violations(test.metadata, test.categories); !reflect.DeepEqual(test.expected, actual) {
+				t.Errorf("expected %q, got %q", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestInspectRequirement(t *testing.T) {
+	weakSigner := certgraphapi.CertKeyMetadata{
+		CertIdentifier:     certgraphapi.CertIdentifier{CommonName: "weak-signer"},
+		SignatureAlgorithm: "SHA1-RSA",
+		PublicKeyAlgorithm: "RSA",
+		PublicKeyBitSize:   "2048 bit",
+		ValidityDuration:   "5y",
+	}
+	goodSigner := weakSigner
+	goodSigner.CertIdentifier.CommonName = "good-signer"
+	goodSigner.SignatureAlgorithm = "SHA256-RSA"
+
+	goodSecret := certgraphapi.InClusterSecretLocation{Namespace: "ns", Name: "good"}
+	longLivedSecret := certgraphapi.InClusterSecretLocation{Namespace: "ns", Name: "long-lived"}
+	weakBundle := certgraphapi.InClusterConfigMapLocation{Namespace: "ns", Name: "weak-bundle"}
+	goodBundle := certgraphapi.InClusterConfigMapLocation{Namespace: "ns", Name: "good-bundle"}
+
+	rawData := []*certgraphapi.PKIList{{
+		InClusterResourceData: certgraphapi.PerInClusterResourceData{
+			CertKeyPairs: []certgraphapi.PKIRegistryInClusterCertKeyPair{
+				{SecretLocation: goodSecret},
+				{SecretLocation: longLivedSecret},
+			},
+			CertificateAuthorityBundles: []certgraphapi.PKIRegistryInClusterCABundle{
+				{ConfigMapLocation: weakBundle},
+				{ConfigMapLocation: goodBundle},
+			},
+		},
+		CertKeyPairs: certgraphapi.CertKeyPairList{Items: []certgraphapi.CertKeyPair{
+			{Spec: certgraphapi.CertKeyPairSpec{
+				SecretLocations: []certgraphapi.InClusterSecretLocation{goodSecret},
+				CertMetadata:    certgraphapi.CertKeyMetadata{SignatureAlgorithm: "SHA256-RSA", PublicKeyAlgorithm: "RSA", PublicKeyBitSize: "2048 bit", ValidityDuration: "30d"},
+				Details:         certgraphapi.CertKeyPairDetails{ServingCertDetails: &certgraphapi.ServingCertDetails{}},
+			}},
+			{Spec: certgraphapi.CertKeyPairSpec{
+				SecretLocations: []certgraphapi.InClusterSecretLocation{longLivedSecret},
+				CertMetadata:    certgraphapi.CertKeyMetadata{SignatureAlgorithm: "SHA256-RSA", PublicKeyAlgorithm: "RSA", PublicKeyBitSize: "2048 bit", ValidityDuration: "10y"},
+				Details:         certgraphapi.CertKeyPairDetails{ClientCertDetails: &certgraphapi.ClientCertDetails{}},
+			}},
+		}},
+		CertificateAuthorityBundles: certgraphapi.CertificateAuthorityBundleList{Items: []certgraphapi.CertificateAuthorityBundle{
+			{Spec: certgraphapi.CertificateAuthorityBundleSpec{
+				ConfigMapLocations:  []certgraphapi.InClusterConfigMapLocation{weakBundle},
+				CertificateMetadata: []certgraphapi.CertKeyMetadata{goodSigner, weakSigner},
+			}},
+			{Spec: certgraphapi.CertificateAuthorityBundleSpec{
+				ConfigMapLocations:  []certgraphapi.InClusterConfigMapLocation{goodBundle},
+				CertificateMetadata: []certgraphapi.CertKeyMetadata{goodSigner},
+			}},
+		}},
+	}}
+
+	result, err := NewCryptoPolicyRequirement().InspectRequirement(rawData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tlsDir := t.TempDir()
+	for _, dir := range []string{filepath.Join(tlsDir, "crypto-policy"), filepath.Join(tlsDir, "violations", "crypto-policy")} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := result.WriteResultToTLSDir(tlsDir); err != nil {
+		t.Fatal(err)
+	}
+
+	reportBytes, err := os.ReadFile(filepath.Join(tlsDir, "crypto-policy", "crypto-policy.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := &CryptoPolicyReport{}
+	if err := json.Unmarshal(reportBytes, report); err != nil {
+		t.Fatal(err)
+	}
+	// the well known on disk locations are always reported, in cluster locations sort first
+	expectedCertKeyPairs := []CryptoPolicyArtifact{
+		{Location: "ns/ns secret/good"},
+		{Location: "ns/ns secret/long-lived", Violations: []string{"client certificate is valid for 10y, the maximum is 2y"}},
+	}
+	if !reflect.DeepEqual(expectedCertKeyPairs, report.CertKeyPairs[:2]) {
+		t.Errorf("unexpected cert key pairs: %#v", report.CertKeyPairs)
+	}
+	expectedCABundles := []CryptoPolicyArtifact{
+		{Location: "ns/ns configmap/good-bundle"},
+		{Location: "ns/ns configmap/weak-bundle", Violations: []string{"weak-signer: signature algorithm SHA1-RSA is not allowed"}},
+	}
+	if !reflect.DeepEqual(expectedCABundles, report.CertificateAuthorityBundles[:2]) {
+		t.Errorf("unexpected CA bundles: %#v", report.CertificateAuthorityBundles)
+	}
+
+	violationBytes, err := os.ReadFile(filepath.Join(tlsDir, "violations", "crypto-policy", "crypto-policy-violations.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	violations := &certs.PKIRegistryInfo{}
+	if err := json.Unmarshal(violationBytes, violations); err != nil {
+		t.Fatal(err)
+	}
+	if len(violations.CertKeyPairs) != 1 || violations.CertKeyPairs[0].InClusterLocation.SecretLocation != longLivedSecret {
+		t.Errorf("unexpected cert key pair violations: %s", violationBytes)
+	}
+	if len(violations.CertificateAuthorityBundles) != 1 || violations.CertificateAuthorityBundles[0].InClusterLocation.ConfigMapLocation != weakBundle {
+		t.Errorf("unexpected CA bundle violations: %s", violationBytes)
+	}
+}
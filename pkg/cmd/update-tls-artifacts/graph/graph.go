@@ -0,0 +1,279 @@
+package graph
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/openshift/library-go/pkg/certs/cert-inspection/certgraphapi"
+
+	"github.com/openshift/origin/pkg/certs"
+)
+
+type NodeKind string
+
+const (
+	SignerNode   NodeKind = "signer"
+	CABundleNode NodeKind = "ca-bundle"
+	LeafNode     NodeKind = "leaf"
+)
+
+type EdgeKind string
+
+const (
+	// TrustedByEdge goes from a signer to a CA bundle containing it.
+	TrustedByEdge EdgeKind = "trusted by"
+	// SignsEdge goes from a signer to a certificate it issued.
+	SignsEdge EdgeKind = "signs"
+)
+
+// Node is a signer, a CA bundle or a leaf certificate.
+type Node struct {
+	ID        string
+	Kind      NodeKind
+	Name      string
+	Locations []string
+	// ValidityDuration is only set for certificates.
+	ValidityDuration string
+	// Problems are the reasons the node is highlighted.
+	Problems []string
+}
+
+type Edge struct {
+	From string
+	To   string
+	Kind EdgeKind
+}
+
+// TrustGraph is the signer -> CA bundle -> leaf topology of one raw TLS data file.
+type TrustGraph struct {
+	Name  string
+	Nodes []*Node
+	Edges []Edge
+
+	nodesByID map[string]*Node
+}
+
+func (g *TrustGraph) Node(id string) *Node {
+	return g.nodesByID[id]
+}
+
+// NumProblems counts the highlighted nodes.
+func (g *TrustGraph) NumProblems() int {
+	ret := 0
+	for _, node := range g.Nodes {
+		if len(node.Problems) > 0 {
+			ret++
+		}
+	}
+	return ret
+}
+
+// EdgesFrom returns the edges leaving a node, in order.
+func (g *TrustGraph) EdgesFrom(id string) []Edge {
+	ret := []Edge{}
+	for _, edge := range g.Edges {
+		if edge.From == id {
+			ret = append(ret, edge)
+		}
+	}
+	return ret
+}
+
+// EdgesTo returns the edges entering a node, in order.
+func (g *TrustGraph) EdgesTo(id string) []Edge {
+	ret := []Edge{}
+	for _, edge := range g.Edges {
+		if edge.To == id {
+			ret = append(ret, edge)
+		}
+	}
+	return ret
+}
+
+func signerID(identifier certgraphapi.CertIdentifier) string {
+	return fmt.Sprintf("signer:%s::%s", identifier.CommonName, identifier.SerialNumber)
+}
+
+// createdAtCommonNameRegex finds the creation time library-go puts in the common name of rotated signers, like
+// openshift-service-serving-signer@1725801899.
+var createdAtCommonNameRegex = regexp.MustCompile(`@(\d+)$`)
+
+// estimateExpiry returns when a certificate expires, if that can be known.  The raw data does not record the
+// validity window, only its length, so this only works for certificates with the creation time in the common name.
+func estimateExpiry(metadata certgraphapi.CertKeyMetadata) (time.Time, bool) {
+	match := createdAtCommonNameRegex.FindStringSubmatch(metadata.CertIdentifier.CommonName)
+	if match == nil {
+		return time.Time{}, false
+	}
+	createdAt, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	validity, err := certs.ParseValidityDuration(metadata.ValidityDuration)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(createdAt, 0).Add(validity).UTC(), true
+}
+
+func certKeyPairLocations(spec certgraphapi.CertKeyPairSpec) []string {
+	ret := []string{}
+	for _, location := range spec.SecretLocations {
+		ret = append(ret, fmt.Sprintf("ns/%v secret/%v", location.Namespace, location.Name))
+	}
+	for _, location := range spec.OnDiskLocations {
+		for _, path := range []string{location.Cert.Path, location.Key.Path} {
+			if len(path) > 0 {
+				ret = append(ret, fmt.Sprintf("file %v", path))
+			}
+		}
+	}
+	return ret
+}
+
+func caBundleLocations(spec certgraphapi.CertificateAuthorityBundleSpec) []string {
+	ret := []string{}
+	for _, location := range spec.ConfigMapLocations {
+		ret = append(ret, fmt.Sprintf("ns/%v configmap/%v", location.Namespace, location.Name))
+	}
+	for _, location := range spec.OnDiskLocations {
+		ret = append(ret, fmt.Sprintf("file %v", location.Path))
+	}
+	return ret
+}
+
+// BuildTrustGraph links the signers, CA bundles and leaf certificates of the raw data.  Certificates only record the
+// common name of their issuer, so a certificate is linked to every signer with that common name.  These are
+// highlighted:
+//  1. signers with a key that are in no CA bundle, so nothing trusts what they sign.
+//  2. CA bundles containing a certificate that expired before evaluationTime.
+//  3. leaf certificates whose issuer is in no CA bundle.
+func BuildTrustGraph(name string, pkiList *certgraphapi.PKIList, evaluationTime time.Time) *TrustGraph {
+	g := &TrustGraph{
+		Name:      name,
+		nodesByID: map[string]*Node{},
+	}
+	addNode := func(node *Node) *Node {
+		if existing, ok := g.nodesByID[node.ID]; ok {
+			existing.Locations = append(existing.Locations, node.Locations...)
+			return existing
+		}
+		g.nodesByID[node.ID] = node
+		g.Nodes = append(g.Nodes, node)
+		return node
+	}
+
+	signerIDsByCommonName := map[string][]string{}
+	addSigner := func(metadata certgraphapi.CertKeyMetadata, locations []string) *Node {
+		id := signerID(metadata.CertIdentifier)
+		if _, ok := g.nodesByID[id]; !ok {
+			signerIDsByCommonName[metadata.CertIdentifier.CommonName] = append(signerIDsByCommonName[metadata.CertIdentifier.CommonName], id)
+		}
+		return addNode(&Node{
+			ID:               id,
+			Kind:             SignerNode,
+			Name:             metadata.CertIdentifier.CommonName,
+			Locations:        locations,
+			ValidityDuration: metadata.ValidityDuration,
+		})
+	}
+
+	// certificates and their issuers, linked once every signer is known
+	issuedBy := map[string]string{}
+	signersWithKeys := []*Node{}
+	for _, certKeyPair := range pkiList.CertKeyPairs.Items {
+		metadata := certKeyPair.Spec.CertMetadata
+		if len(metadata.CertIdentifier.CommonName) == 0 {
+			// keys without certificates have nothing to link
+			continue
+		}
+		var node *Node
+		if certKeyPair.Spec.Details.SignerDetails != nil {
+			node = addSigner(metadata, certKeyPairLocations(certKeyPair.Spec))
+			signersWithKeys = append(signersWithKeys, node)
+		} else {
+			node = addNode(&Node{
+				ID:               "leaf:" + certKeyPair.Name,
+				Kind:             LeafNode,
+				Name:             metadata.CertIdentifier.CommonName,
+				Locations:        certKeyPairLocations(certKeyPair.Spec),
+				ValidityDuration: metadata.ValidityDuration,
+			})
+		}
+		if issuer := metadata.CertIdentifier.Issuer; issuer != nil && len(issuer.CommonName) > 0 {
+			issuedBy[node.ID] = issuer.CommonName
+		}
+	}
+
+	trustedCommonNames := map[string]bool{}
+	for _, caBundle := range pkiList.CertificateAuthorityBundles.Items {
+		bundle := addNode(&Node{
+			ID:        "ca-bundle:" + caBundle.Name,
+			Kind:      CABundleNode,
+			Name:      caBundle.Name,
+			Locations: caBundleLocations(caBundle.Spec),
+		})
+		for _, metadata := range caBundle.Spec.CertificateMetadata {
+			if len(metadata.CertIdentifier.CommonName) == 0 {
+				continue
+			}
+			signer := addSigner(metadata, nil)
+			trustedCommonNames[metadata.CertIdentifier.CommonName] = true
+			g.Edges = append(g.Edges, Edge{From: signer.ID, To: bundle.ID, Kind: TrustedByEdge})
+
+			if expiry, ok := estimateExpiry(metadata); ok && expiry.Before(evaluationTime) {
+				bundle.Problems = append(bundle.Problems, fmt.Sprintf("contains %v which expired at %v", metadata.CertIdentifier.CommonName, expiry.Format(time.RFC3339)))
+			}
+		}
+	}
+
+	for id, issuer := range issuedBy {
+		node := g.nodesByID[id]
+		for _, signer := range signerIDsByCommonName[issuer] {
+			if signer != id {
+				g.Edges = append(g.Edges, Edge{From: signer, To: id, Kind: SignsEdge})
+			}
+		}
+		if node.Kind == LeafNode && !trustedCommonNames[issuer] {
+			node.Problems = append(node.Problems, fmt.Sprintf("issuer %v is in no CA bundle", issuer))
+		}
+	}
+	for _, signer := range signersWithKeys {
+		if !hasEdgeOfKind(g.EdgesFrom(signer.ID), TrustedByEdge) {
+			signer.Problems = append(signer.Problems, "orphaned, the signer is in no CA bundle")
+		}
+	}
+
+	sort.Slice(g.Nodes, func(i, j int) bool { return g.Nodes[i].ID < g.Nodes[j].ID })
+	sort.Slice(g.Edges, func(i, j int) bool {
+		if g.Edges[i].From != g.Edges[j].From {
+			return g.Edges[i].From < g.Edges[j].From
+		}
+		if g.Edges[i].To != g.Edges[j].To {
+			return g.Edges[i].To < g.Edges[j].To
+		}
+		return g.Edges[i].Kind < g.Edges[j].Kind
+	})
+	for _, node := range g.Nodes {
+		sort.Strings(node.Locations)
+		node.Locations = slices.Compact(node.Locations)
+		sort.Strings(node.Problems)
+		node.Problems = slices.Compact(node.Problems)
+	}
+	g.Edges = slices.Compact(g.Edges)
+
+	return g
+}
+
+func hasEdgeOfKind(edges []Edge, kind EdgeKind) bool {
+	for _, edge := range edges {
+		if edge.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
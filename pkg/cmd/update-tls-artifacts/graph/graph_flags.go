@@ -0,0 +1,123 @@
+package graph
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+// GraphFlags gets bound to cobra commands and arguments.  It is used to validate input and then produce
+// the Options struct.  Options struct is intended to be embeddable and re-useable without cobra.
+type GraphFlags struct {
+	TLSInfoDir     string
+	RawDataFiles   []string
+	OutputDir      string
+	EvaluationTime string
+
+	genericclioptions.IOStreams
+}
+
+func NewGraphCommand(streams genericclioptions.IOStreams) *cobra.Command {
+	f := NewGraphFlags(streams)
+
+	cmd := &cobra.Command{
+		Use:   "graph",
+		Short: "Render the certificate trust graph of the TLS raw data.",
+		Long: templates.LongDesc(`
+		Render the certificate trust graph of the TLS raw data.
+
+		For every raw data file, the signers, the CA bundles trusting them and the certificates they sign are written
+		as <name>.dot, in the graphviz DOT language, and <name>.html, a self-contained page.  Signers that are in no
+		CA bundle, CA bundles containing expired certificates and leaf certificates whose issuer is in no CA bundle
+		are highlighted.
+
+		The raw data does not record when certificates expire, so expiry is only known for signers with their
+		creation time in the common name, like openshift-service-serving-signer@1725801899.  Use --evaluation-time
+		to check expiry at the time the raw data was collected.
+		`),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := f.Validate()
+			if err != nil {
+				return err
+			}
+
+			o, err := f.ToOptions()
+			if err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	f.BindFlags(cmd.Flags())
+
+	return cmd
+}
+
+func NewGraphFlags(streams genericclioptions.IOStreams) *GraphFlags {
+	return &GraphFlags{
+		TLSInfoDir: "tls",
+		OutputDir:  filepath.Join("_output", "tls-graph"),
+		IOStreams:  streams,
+	}
+}
+
+func (f *GraphFlags) BindFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&f.TLSInfoDir, "tls-dir", f.TLSInfoDir, "The directory containing the raw-data directory to render.")
+	flags.StringSliceVar(&f.RawDataFiles, "raw-data", f.RawDataFiles, "Render only these raw data files instead of every file in <tls-dir>/raw-data.")
+	flags.StringVar(&f.OutputDir, "output-dir", f.OutputDir, "The directory the DOT and HTML files are written to.")
+	flags.StringVar(&f.EvaluationTime, "evaluation-time", f.EvaluationTime, "The RFC3339 time to check certificate expiry at. Defaults to now.")
+}
+
+func (f *GraphFlags) Validate() error {
+	if len(f.TLSInfoDir) == 0 && len(f.RawDataFiles) == 0 {
+		return fmt.Errorf("--tls-dir or --raw-data must be specified")
+	}
+	if len(f.OutputDir) == 0 {
+		return fmt.Errorf("--output-dir must be specified")
+	}
+	if len(f.EvaluationTime) > 0 {
+		if _, err := time.Parse(time.RFC3339, f.EvaluationTime); err != nil {
+			return fmt.Errorf("--evaluation-time must be an RFC3339 time: %w", err)
+		}
+	}
+	return nil
+}
+
+func (f *GraphFlags) ToOptions() (*GraphOptions, error) {
+	rawDataFiles := f.RawDataFiles
+	if len(rawDataFiles) == 0 {
+		var err error
+		rawDataFiles, err = filepath.Glob(filepath.Join(f.TLSInfoDir, "raw-data", "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		if len(rawDataFiles) == 0 {
+			return nil, fmt.Errorf("no raw data files in %v", filepath.Join(f.TLSInfoDir, "raw-data"))
+		}
+	}
+
+	evaluationTime := time.Now()
+	if len(f.EvaluationTime) > 0 {
+		var err error
+		evaluationTime, err = time.Parse(time.RFC3339, f.EvaluationTime)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &GraphOptions{
+		RawDataFiles:   rawDataFiles,
+		OutputDir:      f.OutputDir,
+		EvaluationTime: evaluationTime,
+
+		IOStreams: f.IOStreams,
+	}, nil
+}
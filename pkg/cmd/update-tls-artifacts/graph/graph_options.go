@@ -0,0 +1,76 @@
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/openshift/library-go/pkg/certs/cert-inspection/certgraphapi"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+type GraphOptions struct {
+	RawDataFiles   []string
+	OutputDir      string
+	EvaluationTime time.Time
+
+	genericclioptions.IOStreams
+}
+
+func (o *GraphOptions) Run() error {
+	if err := os.MkdirAll(o.OutputDir, 0755); err != nil {
+		return fmt.Errorf("failure making directory %v: %w", o.OutputDir, err)
+	}
+
+	errs := []error{}
+	for _, rawDataFile := range o.RawDataFiles {
+		name := strings.TrimSuffix(filepath.Base(rawDataFile), filepath.Ext(rawDataFile))
+		pkiList, err := readRawData(rawDataFile)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failure reading raw data %v: %w", rawDataFile, err))
+			continue
+		}
+
+		g := BuildTrustGraph(name, pkiList, o.EvaluationTime)
+		if err := writeFile(filepath.Join(o.OutputDir, name+".dot"), g, WriteDOT); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := writeFile(filepath.Join(o.OutputDir, name+".html"), g, WriteHTML); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		fmt.Fprintf(o.Out, "%v: %d nodes, %d edges, %d highlighted\n", name, len(g.Nodes), len(g.Edges), g.NumProblems())
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+func readRawData(filename string) (*certgraphapi.PKIList, error) {
+	currBytes, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	ret := &certgraphapi.PKIList{}
+	if err := json.Unmarshal(currBytes, ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func writeFile(filename string, g *TrustGraph, render func(io.Writer, *TrustGraph) error) error {
+	out, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failure writing %v: %w", filename, err)
+	}
+	defer out.Close()
+	if err := render(out, g); err != nil {
+		return fmt.Errorf("failure writing %v: %w", filename, err)
+	}
+	return out.Close()
+}
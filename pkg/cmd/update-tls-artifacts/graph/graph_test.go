@@ -0,0 +1,123 @@
+package graph
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/library-go/pkg/certs/cert-inspection/certgraphapi"
+)
+
+func certificate(commonName, serial, issuer, validity string) certgraphapi.CertKeyMetadata {
+	return certgraphapi.CertKeyMetadata{
+		CertIdentifier: certgraphapi.CertIdentifier{
+			CommonName:   commonName,
+			SerialNumber: serial,
+			Issuer:       &certgraphapi.CertIdentifier{CommonName: issuer},
+		},
+		ValidityDuration: validity,
+	}
+}
+
+func TestBuildTrustGraph(t *testing.T) {
+	// created 2024-09-08T13:24:59Z
+	rotatedSigner := certificate("service-signer@1725801899", "1", "service-signer@1725801899", "365d")
+	previousSigner := certificate("service-signer@1694265899", "2", "service-signer@1694265899", "365d")
+
+	pkiList := &certgraphapi.PKIList{
+		CertKeyPairs: certgraphapi.CertKeyPairList{Items: []certgraphapi.CertKeyPair{
+			{Name: "service-signer", Spec: certgraphapi.CertKeyPairSpec{
+				SecretLocations: []certgraphapi.InClusterSecretLocation{{Namespace: "ns", Name: "signer"}},
+				CertMetadata:    rotatedSigner,
+				Details:         certgraphapi.CertKeyPairDetails{SignerDetails: &certgraphapi.SignerCertDetails{}},
+			}},
+			{Name: "orphan-signer", Spec: certgraphapi.CertKeyPairSpec{
+				SecretLocations: []certgraphapi.InClusterSecretLocation{{Namespace: "ns", Name: "orphan"}},
+				CertMetadata:    certificate("orphan-signer", "3", "orphan-signer", "10y"),
+				Details:         certgraphapi.CertKeyPairDetails{SignerDetails: &certgraphapi.SignerCertDetails{}},
+			}},
+			{Name: "serving::4", Spec: certgraphapi.CertKeyPairSpec{
+				SecretLocations: []certgraphapi.InClusterSecretLocation{{Namespace: "ns", Name: "serving"}},
+				CertMetadata:    certificate("serving", "4", "service-signer@1725801899", "2y"),
+				Details:         certgraphapi.CertKeyPairDetails{ServingCertDetails: &certgraphapi.ServingCertDetails{}},
+			}},
+			{Name: "untrusted::5", Spec: certgraphapi.CertKeyPairSpec{
+				OnDiskLocations: []certgraphapi.OnDiskCertKeyPairLocation{{Cert: certgraphapi.OnDiskLocation{Path: "/etc/untrusted.crt"}}},
+				CertMetadata:    certificate("untrusted", "5", "orphan-signer", "2y"),
+				Details:         certgraphapi.CertKeyPairDetails{ClientCertDetails: &certgraphapi.ClientCertDetails{}},
+			}},
+			{Name: "key-only", Spec: certgraphapi.CertKeyPairSpec{
+				OnDiskLocations: []certgraphapi.OnDiskCertKeyPairLocation{{Key: certgraphapi.OnDiskLocation{Path: "/etc/service-account.key"}}},
+			}},
+		}},
+		CertificateAuthorityBundles: certgraphapi.CertificateAuthorityBundleList{Items: []certgraphapi.CertificateAuthorityBundle{
+			{Name: "service-ca", Spec: certgraphapi.CertificateAuthorityBundleSpec{
+				ConfigMapLocations:  []certgraphapi.InClusterConfigMapLocation{{Namespace: "ns", Name: "service-ca"}},
+				CertificateMetadata: []certgraphapi.CertKeyMetadata{rotatedSigner, previousSigner},
+			}},
+		}},
+	}
+
+	g := BuildTrustGraph("test", pkiList, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	expectedNodes := []Node{
+		{ID: "ca-bundle:service-ca", Kind: CABundleNode, Name: "service-ca", Locations: []string{"ns/ns configmap/service-ca"}, Problems: []string{"contains service-signer@1694265899 which expired at 2024-09-08T13:24:59Z"}},
+		{ID: "leaf:serving::4", Kind: LeafNode, Name: "serving", Locations: []string{"ns/ns secret/serving"}, ValidityDuration: "2y"},
+		{ID: "leaf:untrusted::5", Kind: LeafNode, Name: "untrusted", Locations: []string{"file /etc/untrusted.crt"}, ValidityDuration: "2y", Problems: []string{"issuer orphan-signer is in no CA bundle"}},
+		{ID: "signer:orphan-signer::3", Kind: SignerNode, Name: "orphan-signer", Locations: []string{"ns/ns secret/orphan"}, ValidityDuration: "10y", Problems: []string{"orphaned, the signer is in no CA bundle"}},
+		{ID: "signer:service-signer@1694265899::2", Kind: SignerNode, Name: "service-signer@1694265899", ValidityDuration: "365d"},
+		{ID: "signer:service-signer@1725801899::1", Kind: SignerNode, Name: "service-signer@1725801899", Locations: []string{"ns/ns secret/signer"}, ValidityDuration: "365d"},
+	}
+	actualNodes := []Node{}
+	for _, node := range g.Nodes {
+		actualNodes = append(actualNodes, *node)
+	}
+	if !reflect.DeepEqual(expectedNodes, actualNodes) {
+		t.Errorf("unexpected nodes\n got: %#v\nwant: %#v", actualNodes, expectedNodes)
+	}
+
+	expectedEdges := []Edge{
+		{From: "signer:orphan-signer::3", To: "leaf:untrusted::5", Kind: SignsEdge},
+		{From: "signer:service-signer@1694265899::2", To: "ca-bundle:service-ca", Kind: TrustedByEdge},
+		{From: "signer:service-signer@1725801899::1", To: "ca-bundle:service-ca", Kind: TrustedByEdge},
+		{From: "signer:service-signer@1725801899::1", To: "leaf:serving::4", Kind: SignsEdge},
+	}
+	if !reflect.DeepEqual(expectedEdges, g.Edges) {
+		t.Errorf("unexpected edges\n got: %#v\nwant: %#v", g.Edges, expectedEdges)
+	}
+	if g.NumProblems() != 3 {
+		t.Errorf("expected 3 highlighted nodes, got %d", g.NumProblems())
+	}
+
+	dot := &bytes.Buffer{}
+	if err := WriteDOT(dot, g); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		`digraph "test" {`,
+		`"signer:service-signer@1725801899::1" -> "leaf:serving::4" [label="signs", style=solid];`,
+		`"signer:service-signer@1725801899::1" -> "ca-bundle:service-ca" [label="trusted by", style=dashed];`,
+		`tooltip="issuer orphan-signer is in no CA bundle"`,
+	} {
+		if !strings.Contains(dot.String(), expected) {
+			t.Errorf("DOT is missing %q:\n%s", expected, dot.String())
+		}
+	}
+
+	html := &bytes.Buffer{}
+	if err := WriteHTML(html, g); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		`<h2>Highlighted (3)</h2>`,
+		`<div class="node leaf problem" id="node-2">`,
+		`signed by <a href="#node-5">service-signer@1725801899</a>`,
+		`contains <a href="#node-4">service-signer@1694265899</a>`,
+	} {
+		if !strings.Contains(html.String(), expected) {
+			t.Errorf("HTML is missing %q:\n%s", expected, html.String())
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package graph
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+)
+
+var (
+	dotShapes = map[NodeKind]string{
+		SignerNode:   "ellipse",
+		CABundleNode: "folder",
+		LeafNode:     "box",
+	}
+	dotColors = map[NodeKind]string{
+		SignerNode:   "#cfe2f3",
+		CABundleNode: "#fff2cc",
+		LeafNode:     "#ffffff",
+	}
+)
+
+func dotQuote(in string) string {
+	ret := strings.ReplaceAll(in, `\`, `\\`)
+	ret = strings.ReplaceAll(ret, `"`, `\"`)
+	return `"` + ret + `"`
+}
+
+// WriteDOT writes the graph in the graphviz DOT language.  Highlighted nodes have a red border and their problems as
+// the tooltip.
+func WriteDOT(out io.Writer, g *TrustGraph) error {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "digraph %s {\n", dotQuote(g.Name))
+	fmt.Fprintf(buf, "  rankdir=LR;\n")
+	fmt.Fprintf(buf, "  node [style=filled, fontname=\"sans-serif\"];\n")
+	for _, node := range g.Nodes {
+		label := node.Name
+		if len(node.ValidityDuration) > 0 {
+			label += "\n" + node.ValidityDuration
+		}
+		attributes := []string{
+			"label=" + dotQuote(label),
+			"shape=" + dotShapes[node.Kind],
+			"fillcolor=" + dotQuote(dotColors[node.Kind]),
+		}
+		if len(node.Problems) > 0 {
+			attributes = append(attributes, "color=red", "penwidth=3", "tooltip="+dotQuote(strings.Join(node.Problems, "\n")))
+		}
+		fmt.Fprintf(buf, "  %s [%s];\n", dotQuote(node.ID), strings.Join(attributes, ", "))
+	}
+	for _, edge := range g.Edges {
+		style := "solid"
+		if edge.Kind == TrustedByEdge {
+			style = "dashed"
+		}
+		fmt.Fprintf(buf, "  %s -> %s [label=%s, style=%s];\n", dotQuote(edge.From), dotQuote(edge.To), dotQuote(string(edge.Kind)), style)
+	}
+	fmt.Fprintf(buf, "}\n")
+
+	_, err := out.Write(buf.Bytes())
+	return err
+}
+
+//go:embed graph.html
+var htmlTemplateContent string
+
+var htmlTemplate = template.Must(template.New("graph").Parse(htmlTemplateContent))
+
+type htmlNode struct {
+	*Node
+	Anchor string
+	In     []htmlLink
+	Out    []htmlLink
+}
+
+type htmlLink struct {
+	Kind   EdgeKind
+	Node   *Node
+	Anchor string
+}
+
+type htmlData struct {
+	Name        string
+	NumProblems int
+	Problems    []htmlLink
+	Signers     []htmlNode
+	CABundles   []htmlNode
+	Leaves      []htmlNode
+	DOT         string
+}
+
+// WriteHTML writes a self-contained page listing every signer, CA bundle and leaf certificate with links along the
+// edges, the highlighted nodes first, and the DOT source.
+func WriteHTML(out io.Writer, g *TrustGraph) error {
+	dot := &bytes.Buffer{}
+	if err := WriteDOT(dot, g); err != nil {
+		return err
+	}
+
+	data := htmlData{
+		Name:        g.Name,
+		NumProblems: g.NumProblems(),
+		DOT:         dot.String(),
+	}
+	anchors := map[string]string{}
+	for i, node := range g.Nodes {
+		anchors[node.ID] = fmt.Sprintf("node-%d", i)
+	}
+	for _, node := range g.Nodes {
+		curr := htmlNode{Node: node, Anchor: anchors[node.ID]}
+		for _, edge := range g.EdgesTo(node.ID) {
+			curr.In = append(curr.In, htmlLink{Kind: edge.Kind, Node: g.Node(edge.From), Anchor: anchors[edge.From]})
+		}
+		for _, edge := range g.EdgesFrom(node.ID) {
+			curr.Out = append(curr.Out, htmlLink{Kind: edge.Kind, Node: g.Node(edge.To), Anchor: anchors[edge.To]})
+		}
+		if len(node.Problems) > 0 {
+			data.Problems = append(data.Problems, htmlLink{Node: node, Anchor: curr.Anchor})
+		}
+		switch node.Kind {
+		case SignerNode:
+			data.Signers = append(data.Signers, curr)
+		case CABundleNode:
+			data.CABundles = append(data.CABundles, curr)
+		case LeafNode:
+			data.Leaves = append(data.Leaves, curr)
+		}
+	}
+
+	return htmlTemplate.Execute(out, data)
+}
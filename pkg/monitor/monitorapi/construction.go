@@ -140,6 +140,21 @@ func (b *LocatorBuilder) MachineFromName(machineName string) Locator {
 		Build()
 }
 
+func (b *LocatorBuilder) MachineConfigPoolFromName(poolName string) Locator {
+	return b.
+		withTargetType(LocatorTypeMachineConfigPool).
+		withMachineConfigPool(poolName).
+		Build()
+}
+
+// AdmissionWebhook locates a webhook by the name and type the kube-apiserver uses in its admission metrics.
+func (b *LocatorBuilder) AdmissionWebhook(webhookType, webhookName string) Locator {
+	b.targetType = LocatorTypeAdmissionWebhook
+	b.annotations[LocatorAdmissionWebhookTypeKey] = webhookType
+	b.annotations[LocatorAdmissionWebhookKey] = webhookName
+	return b.Build()
+}
+
 func (b *LocatorBuilder) NodeFromNameWithRow(nodeName, row string) Locator {
 	return b.
 		withTargetType(LocatorTypeNode).
@@ -162,6 +177,14 @@ func (b *LocatorBuilder) ClusterVersion(cv *v1.ClusterVersion) Locator {
 	return b.Build()
 }
 
+// UpgradeHop locates one upgrade of a multi-hop upgrade path, numbered from one.
+func (b *LocatorBuilder) UpgradeHop(hop string) Locator {
+	b.targetType = LocatorTypeClusterVersion
+	b.annotations[LocatorClusterVersionKey] = "cluster"
+	b.annotations[LocatorUpgradeHopKey] = hop
+	return b.Build()
+}
+
 func (b *LocatorBuilder) AlertFromPromSampleStream(alert *model.SampleStream) Locator {
 	b.targetType = LocatorTypeAlert
 
@@ -295,6 +318,11 @@ func (b *LocatorBuilder) withMachine(machineName string) *LocatorBuilder {
 	return b
 }
 
+func (b *LocatorBuilder) withMachineConfigPool(poolName string) *LocatorBuilder {
+	b.annotations[LocatorMachineConfigPoolKey] = poolName
+	return b
+}
+
 func (b *LocatorBuilder) withRow(row string) *LocatorBuilder {
 	b.annotations[LocatorRowKey] = row
 	return b
@@ -514,6 +542,18 @@ func (b *LocatorBuilder) E2ETest(testName string) Locator {
 	return b.Build()
 }
 
+// Resource locates any object by its group, resource, and name.  Namespace is empty for cluster scoped objects.
+func (b *LocatorBuilder) Resource(group, resource, namespace, name string) Locator {
+	b.targetType = LocatorTypeResource
+	b.annotations[LocatorGroupKey] = group
+	b.annotations[LocatorResourceKey] = resource
+	if len(namespace) > 0 {
+		b.annotations[LocatorNamespaceKey] = namespace
+	}
+	b.annotations[LocatorNameKey] = name
+	return b.Build()
+}
+
 func (b *LocatorBuilder) ClusterOperator(name string) Locator {
 	b.targetType = LocatorTypeClusterOperator
 	b.annotations[LocatorClusterOperatorKey] = name
@@ -118,6 +118,11 @@ const (
 	LocatorTypeKubeletSyncLoopProbe LocatorType = "KubeletSyncLoopProbe"
 	LocatorTypeKubeletSyncLoopPLEG  LocatorType = "KubeletSyncLoopPLEG"
 	LocatorTypeStaticPodInstall     LocatorType = "StaticPodInstall"
+
+	LocatorTypeResource LocatorType = "Resource"
+
+	LocatorTypeMachineConfigPool LocatorType = "MachineConfigPool"
+	LocatorTypeAdmissionWebhook  LocatorType = "AdmissionWebhook"
 )
 
 type LocatorKey string
@@ -154,6 +159,12 @@ const (
 	LocatorRowKey                   LocatorKey = "row"
 	LocatorServerKey                LocatorKey = "server"
 	LocatorMetricKey                LocatorKey = "metric"
+	LocatorGroupKey                 LocatorKey = "group"
+	LocatorResourceKey              LocatorKey = "resource"
+	LocatorMachineConfigPoolKey     LocatorKey = "machineconfigpool"
+	LocatorAdmissionWebhookKey      LocatorKey = "admission-webhook"
+	LocatorAdmissionWebhookTypeKey  LocatorKey = "admission-webhook-type"
+	LocatorUpgradeHopKey            LocatorKey = "upgrade-hop"
 
 	LocatorAPIUnreachableHostKey                  LocatorKey = "host"
 	LocatorOnPremKubeapiUnreachableFromHaproxyKey LocatorKey = "onprem-haproxy"
@@ -195,6 +206,7 @@ const (
 	PodReasonFailed                IntervalReason = "Failed"
 	PodReasonReady                 IntervalReason = "PodReady"
 	PodReasonNotReady              IntervalReason = "PodNotReady"
+	PodReasonStartupLatency        IntervalReason = "StartupLatency"
 
 	ContainerReasonContainerExit      IntervalReason = "ContainerExit"
 	ContainerReasonContainerStart     IntervalReason = "ContainerStart"
@@ -220,6 +232,11 @@ const (
 	NodeFailedLeaseBackoff IntervalReason = "FailedToUpdateLeaseInBackoff"
 	NodeDiskPressure       IntervalReason = "NodeDiskPressure"
 	NodeNoDiskPressure     IntervalReason = "NodeNoDiskPressure"
+	NodeMemoryPressure     IntervalReason = "NodeMemoryPressure"
+	NodeNoMemoryPressure   IntervalReason = "NodeNoMemoryPressure"
+	NodePIDPressure        IntervalReason = "NodePIDPressure"
+	NodeNoPIDPressure      IntervalReason = "NodeNoPIDPressure"
+	NodeUnderPressure      IntervalReason = "NodeUnderPressure"
 
 	MachineConfigChangeReason  IntervalReason = "MachineConfigChange"
 	MachineConfigReachedReason IntervalReason = "MachineConfigReached"
@@ -229,6 +246,58 @@ const (
 	MachinePhaseChanged IntervalReason = "MachinePhaseChange"
 	MachinePhase        IntervalReason = "MachinePhase"
 
+	MachineConfigPoolConditionChanged IntervalReason = "MachineConfigPoolConditionChange"
+	MachineConfigPoolCondition        IntervalReason = "MachineConfigPoolCondition"
+	MachineConfigNodeStateChanged     IntervalReason = "MachineConfigNodeStateChange"
+	MachineConfigNodeState            IntervalReason = "MachineConfigNodeState"
+	MachineConfigDrainRequested       IntervalReason = "MachineConfigDrainRequested"
+	MachineConfigDrainComplete        IntervalReason = "MachineConfigDrainComplete"
+	MachineConfigDrain                IntervalReason = "MachineConfigDrain"
+
+	CSRCreated         IntervalReason = "CSRCreated"
+	CSRApproved        IntervalReason = "CSRApproved"
+	CSRDenied          IntervalReason = "CSRDenied"
+	CSRFailed          IntervalReason = "CSRFailed"
+	CSRIssued          IntervalReason = "CSRIssued"
+	CSRPendingApproval IntervalReason = "CSRPendingApproval"
+	CSRPendingIssuance IntervalReason = "CSRPendingIssuance"
+	CSRNeverApproved   IntervalReason = "CSRNeverApproved"
+
+	AdmissionWebhookRejectedOnError IntervalReason = "AdmissionWebhookRejectedOnError"
+	AdmissionWebhookTimeout         IntervalReason = "AdmissionWebhookTimeout"
+
+	PersistentVolumeClaimCreated      IntervalReason = "PersistentVolumeClaimCreated"
+	PersistentVolumeClaimNodeSelected IntervalReason = "PersistentVolumeClaimNodeSelected"
+	PersistentVolumeClaimPhaseChanged IntervalReason = "PersistentVolumeClaimPhaseChanged"
+	PersistentVolumeClaimDeleted      IntervalReason = "PersistentVolumeClaimDeleted"
+	PersistentVolumeClaimPending      IntervalReason = "PersistentVolumeClaimPending"
+	PersistentVolumeClaimNeverBound   IntervalReason = "PersistentVolumeClaimNeverBound"
+	PersistentVolumeCreated           IntervalReason = "PersistentVolumeCreated"
+	PersistentVolumePhaseChanged      IntervalReason = "PersistentVolumePhaseChanged"
+	VolumeAttachmentCreated           IntervalReason = "VolumeAttachmentCreated"
+	VolumeAttachmentAttached          IntervalReason = "VolumeAttachmentAttached"
+	VolumeAttachmentError             IntervalReason = "VolumeAttachmentError"
+	VolumeAttachmentDetachRequested   IntervalReason = "VolumeAttachmentDetachRequested"
+	VolumeAttachmentDeleted           IntervalReason = "VolumeAttachmentDeleted"
+	VolumeAttaching                   IntervalReason = "VolumeAttaching"
+	VolumeDetaching                   IntervalReason = "VolumeDetaching"
+
+	EtcdWALFsyncSlow      IntervalReason = "EtcdWALFsyncSlow"
+	EtcdBackendCommitSlow IntervalReason = "EtcdBackendCommitSlow"
+	EtcdPeerRoundTripSlow IntervalReason = "EtcdPeerRoundTripSlow"
+	EtcdDatabaseNearQuota IntervalReason = "EtcdDatabaseNearQuota"
+
+	OLMClusterServiceVersionPhaseChanged IntervalReason = "OLMClusterServiceVersionPhaseChanged"
+	OLMClusterServiceVersionPhase        IntervalReason = "OLMClusterServiceVersionPhase"
+	OLMInstallPlanCreated                IntervalReason = "OLMInstallPlanCreated"
+	OLMInstallPlanPhaseChanged           IntervalReason = "OLMInstallPlanPhaseChanged"
+	OLMInstallPlanApproved               IntervalReason = "OLMInstallPlanApproved"
+	OLMInstallPlanWaitingForApproval     IntervalReason = "OLMInstallPlanWaitingForApproval"
+	OLMInstallPlanInstalling             IntervalReason = "OLMInstallPlanInstalling"
+	OLMSubscriptionStateChanged          IntervalReason = "OLMSubscriptionStateChanged"
+	OLMCatalogSourceStateChanged         IntervalReason = "OLMCatalogSourceStateChanged"
+	OLMCatalogSourceNotReady             IntervalReason = "OLMCatalogSourceNotReady"
+
 	OnPremHaproxyDetectsDown  IntervalReason = "OnPremHaproxyDetectsDown"
 	OnPremHaproxyStatusChange IntervalReason = "OnPremHaproxyStatusChange"
 
@@ -247,6 +316,7 @@ const (
 	UpgradeRollbackReason IntervalReason = "UpgradeRollback"
 	UpgradeFailedReason   IntervalReason = "UpgradeFailed"
 	UpgradeCompleteReason IntervalReason = "UpgradeComplete"
+	UpgradeHopReason      IntervalReason = "UpgradeHop"
 
 	NodeInstallerReason IntervalReason = "NodeInstaller"
 
@@ -259,9 +329,15 @@ const (
 
 	ReasonBadOperatorApply  IntervalReason = "BadOperatorApply"
 	ReasonKubeAPIServer500s IntervalReason = "KubeAPIServer500s"
+	ReasonKubeAPIServer5xx  IntervalReason = "KubeAPIServerHigh5xxRate"
+	ReasonKubeAPIServer429s IntervalReason = "KubeAPIServerHigh429Rate"
 
 	ReasonHighGeneration    IntervalReason = "HighGeneration"
 	ReasonInvalidGeneration IntervalReason = "GenerationViolation"
+
+	ResourceAddedReason    IntervalReason = "ResourceAdded"
+	ResourceModifiedReason IntervalReason = "ResourceModified"
+	ResourceRemovedReason  IntervalReason = "ResourceRemoved"
 )
 
 type AnnotationKey string
@@ -295,6 +371,33 @@ const (
 	AnnotationStatus         AnnotationKey = "status"
 	AnnotationCondition      AnnotationKey = "condition"
 	AnnotationPercentage     AnnotationKey = "percentage"
+	AnnotationAuthor         AnnotationKey = "author"
+	AnnotationCommit         AnnotationKey = "commit"
+	AnnotationPreviousStatus AnnotationKey = "previousStatus"
+	AnnotationPreviousState  AnnotationKey = "previousState"
+	AnnotationPool           AnnotationKey = "pool"
+	AnnotationSigner         AnnotationKey = "signer"
+	AnnotationComponent      AnnotationKey = "component"
+	AnnotationFailurePolicy  AnnotationKey = "failurePolicy"
+	AnnotationTimeout        AnnotationKey = "timeout"
+	AnnotationStorageClass   AnnotationKey = "storageClass"
+	AnnotationDriver         AnnotationKey = "driver"
+	AnnotationVolume         AnnotationKey = "volume"
+	AnnotationClaim          AnnotationKey = "claim"
+	AnnotationThreshold      AnnotationKey = "threshold"
+	AnnotationEtcdPeer       AnnotationKey = "peer"
+	AnnotationApproval       AnnotationKey = "approval"
+	AnnotationCSV            AnnotationKey = "csv"
+	AnnotationWorkload       AnnotationKey = "workload"
+	AnnotationScheduling     AnnotationKey = "scheduling"
+	AnnotationSandbox        AnnotationKey = "sandbox"
+	AnnotationImagePull      AnnotationKey = "imagePull"
+	AnnotationReadiness      AnnotationKey = "readiness"
+	AnnotationPriorityClass  AnnotationKey = "priorityClass"
+	AnnotationResource       AnnotationKey = "resource"
+	AnnotationVersion        AnnotationKey = "version"
+	AnnotationUpgradeHop     AnnotationKey = "hop"
+	AnnotationUpgradeHops    AnnotationKey = "hops"
 )
 
 // ConstructionOwner was originally meant to signify that an interval was derived from other intervals.
@@ -307,12 +410,17 @@ const (
 type ConstructionOwner string
 
 const (
-	ConstructionOwnerNodeLifecycle    = "node-lifecycle-constructor"
-	ConstructionOwnerPodLifecycle     = "pod-lifecycle-constructor"
-	ConstructionOwnerEtcdLifecycle    = "etcd-lifecycle-constructor"
-	ConstructionOwnerMachineLifecycle = "machine-lifecycle-constructor"
-	ConstructionOwnerLeaseChecker     = "lease-checker"
-	ConstructionOwnerOnPremHaproxy    = "on-prem-haproxy-constructor"
+	ConstructionOwnerNodeLifecycle              = "node-lifecycle-constructor"
+	ConstructionOwnerPodLifecycle               = "pod-lifecycle-constructor"
+	ConstructionOwnerEtcdLifecycle              = "etcd-lifecycle-constructor"
+	ConstructionOwnerMachineLifecycle           = "machine-lifecycle-constructor"
+	ConstructionOwnerMachineConfigPoolLifecycle = "machineconfigpool-lifecycle-constructor"
+	ConstructionOwnerCSRLifecycle               = "csr-lifecycle-constructor"
+	ConstructionOwnerVolumeLifecycle            = "volume-lifecycle-constructor"
+	ConstructionOwnerOLMLifecycle               = "olm-lifecycle-constructor"
+	ConstructionOwnerUpgradeHops                = "upgrade-hops-constructor"
+	ConstructionOwnerLeaseChecker               = "lease-checker"
+	ConstructionOwnerOnPremHaproxy              = "on-prem-haproxy-constructor"
 )
 
 type Message struct {
@@ -345,6 +453,7 @@ const (
 	SourcePodLog                    IntervalSource = "PodLog"
 	SourceEtcdLog                   IntervalSource = "EtcdLog"
 	SourceEtcdLeadership            IntervalSource = "EtcdLeadership"
+	SourceEtcdMetrics               IntervalSource = "EtcdMetrics"
 	SourcePodMonitor                IntervalSource = "PodMonitor"
 	SourceMetricsEndpointDown       IntervalSource = "MetricsEndpointDown"
 	APIServerGracefulShutdown       IntervalSource = "APIServerGracefulShutdown"
@@ -362,10 +471,18 @@ const (
 
 	SourceAPIUnreachableFromClient IntervalSource = "APIUnreachableFromClient"
 	SourceMachine                  IntervalSource = "MachineMonitor"
+	SourceMachineConfig            IntervalSource = "MachineConfigMonitor"
+	SourceCSRMonitor               IntervalSource = "CertificateSigningRequestMonitor"
+	SourceAdmissionWebhook         IntervalSource = "AdmissionWebhookMonitor"
+	SourceVolumeMonitor            IntervalSource = "VolumeMonitor"
+	SourceOLM                      IntervalSource = "OLMMonitor"
+	SourceUpgradeHop               IntervalSource = "UpgradeHopMonitor"
 
 	SourceGenerationMonitor IntervalSource = "GenerationMonitor"
 
 	SourceStaticPodInstallMonitor IntervalSource = "StaticPodInstallMonitor"
+
+	SourceResourceWatch IntervalSource = "ResourceWatch"
 )
 
 type Interval struct {
@@ -0,0 +1,40 @@
+package allowedlatency
+
+import (
+	_ "embed"
+	"sync"
+
+	"github.com/openshift/origin/pkg/monitortestlibrary/historicaldata"
+)
+
+// query_results.json holds the historical latencies that monitor tests use as thresholds, in the same format as the
+// backend disruption query results.  It is built from the tables the monitor tests upload with the data loader:
+//
+//   - csr_approval_latency has a row for every kubelet CSR, and csr-approval-latency-<signer> is the P95 and P99 of
+//     LatencySeconds across the CSRs of a signer.
+//   - pod_startup_latency has a row for every platform namespace and phase, and pod-startup-latency-<phase> is the P95
+//     and P99 of P95Seconds across those rows, so that the p95 of a namespace in a run is compared with how high that
+//     p95 gets across runs.
+//
+// Keys without enough job runs are ignored by the best matcher, and the monitor tests fall back to their defaults.
+//
+//go:embed query_results.json
+var queryResults []byte
+
+var (
+	readResults    sync.Once
+	historicalData *historicaldata.DisruptionBestMatcher
+)
+
+func GetCurrentResults() *historicaldata.DisruptionBestMatcher {
+	readResults.Do(
+		func() {
+			var err error
+			historicalData, err = historicaldata.NewDisruptionMatcher(queryResults)
+			if err != nil {
+				panic(err)
+			}
+		})
+
+	return historicalData
+}
@@ -0,0 +1,15 @@
+package allowedlatency
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestLatencyDataFileParsing makes sure query_results.json stays readable and only holds latencies.
+func TestLatencyDataFileParsing(t *testing.T) {
+	for key := range GetCurrentResults().HistoricalData {
+		if !strings.HasPrefix(key.BackendName, "csr-approval-latency-") && !strings.HasPrefix(key.BackendName, "pod-startup-latency-") {
+			t.Errorf("unexpected latency %q in query_results.json", key.BackendName)
+		}
+	}
+}
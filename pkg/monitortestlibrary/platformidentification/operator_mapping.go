@@ -243,6 +243,14 @@ func GetBugzillaComponentForOperator(operator string) string {
 	return ret
 }
 
+func GetBugzillaComponentForNamespace(namespace string) string {
+	ret, ok := namespaceToBugzillaComponent[namespace]
+	if !ok {
+		return "Unknown"
+	}
+	return ret
+}
+
 func addOperatorMapping(operator, bugzillaComponent string) error {
 	if !ValidBugzillaComponents.Has(bugzillaComponent) {
 		return fmt.Errorf("%q is not a valid bugzilla component", bugzillaComponent)
@@ -0,0 +1,108 @@
+package upgradehops
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+const (
+	hopStateCompleted  = "Completed"
+	hopStateFailed     = "Failed"
+	hopStateIncomplete = "Incomplete"
+)
+
+// eventFields parses the key/value tokens of an upgrade event note, like "version/4.14.0 image/... hop/1 hops/2".
+// Images contain slashes, so only the first slash of a token separates its key from its value.
+func eventFields(note string) map[string]string {
+	fields := map[string]string{}
+	for _, token := range strings.Fields(note) {
+		if key, value, ok := strings.Cut(token, "/"); ok {
+			fields[key] = value
+		}
+	}
+	return fields
+}
+
+// constructHopIntervals creates an interval for every hop of a multi-hop upgrade, from the event that started the hop
+// to the event that completed or failed it. A hop that was never finished ends when the next hop starts or at end.
+func constructHopIntervals(startingIntervals monitorapi.Intervals, end time.Time) monitorapi.Intervals {
+	constructedIntervals := monitorapi.Intervals{}
+
+	upgradeEvents := startingIntervals.Filter(func(interval monitorapi.Interval) bool {
+		if interval.Source != monitorapi.SourceKubeEvent || interval.Locator.Keys[monitorapi.LocatorClusterVersionKey] != "cluster" {
+			return false
+		}
+		switch interval.Message.Reason {
+		case monitorapi.UpgradeStartedReason, monitorapi.UpgradeCompleteReason, monitorapi.UpgradeFailedReason:
+			// single hop upgrades do not record the hop.
+			return len(eventFields(interval.Message.HumanMessage)[string(monitorapi.AnnotationUpgradeHop)]) > 0
+		}
+		return false
+	})
+	sort.SliceStable(upgradeEvents, func(i, j int) bool {
+		return upgradeEvents[i].From.Before(upgradeEvents[j].From)
+	})
+
+	var started *monitorapi.Interval
+	var startedFields map[string]string
+	closeHop := func(to time.Time, state string) {
+		hop := startedFields[string(monitorapi.AnnotationUpgradeHop)]
+		hops := startedFields[string(monitorapi.AnnotationUpgradeHops)]
+		version := startedFields[string(monitorapi.AnnotationVersion)]
+		image := startedFields[string(monitorapi.AnnotationImage)]
+		target := version
+		if len(target) == 0 {
+			target = image
+		}
+		level := monitorapi.Info
+		if state != hopStateCompleted {
+			level = monitorapi.Error
+		}
+		constructedIntervals = append(constructedIntervals,
+			monitorapi.NewInterval(monitorapi.SourceUpgradeHop, level).
+				Locator(monitorapi.NewLocator().UpgradeHop(hop)).
+				Message(monitorapi.NewMessage().Reason(monitorapi.UpgradeHopReason).
+					Constructed(monitorapi.ConstructionOwnerUpgradeHops).
+					WithAnnotation(monitorapi.AnnotationUpgradeHop, hop).
+					WithAnnotation(monitorapi.AnnotationUpgradeHops, hops).
+					WithAnnotation(monitorapi.AnnotationVersion, version).
+					WithAnnotation(monitorapi.AnnotationImage, image).
+					WithAnnotation(monitorapi.AnnotationState, state).
+					HumanMessage(fmt.Sprintf("upgrade hop %s of %s to %s %s", hop, hops, target, strings.ToLower(state)))).
+				Display().
+				Build(started.From, to),
+		)
+	}
+
+	for i := range upgradeEvents {
+		event := upgradeEvents[i]
+		fields := eventFields(event.Message.HumanMessage)
+		switch event.Message.Reason {
+		case monitorapi.UpgradeStartedReason:
+			if started != nil {
+				closeHop(event.From, hopStateIncomplete)
+			}
+			started = &upgradeEvents[i]
+			startedFields = fields
+		case monitorapi.UpgradeCompleteReason, monitorapi.UpgradeFailedReason:
+			if started == nil || fields[string(monitorapi.AnnotationUpgradeHop)] != startedFields[string(monitorapi.AnnotationUpgradeHop)] {
+				continue
+			}
+			state := hopStateCompleted
+			if event.Message.Reason == monitorapi.UpgradeFailedReason {
+				state = hopStateFailed
+			}
+			closeHop(event.From, state)
+			started = nil
+		}
+	}
+	if started != nil {
+		closeHop(end, hopStateIncomplete)
+	}
+
+	return constructedIntervals
+}
@@ -0,0 +1,110 @@
+package upgradehops
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+var start = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func upgradeEvent(at time.Duration, reason monitorapi.IntervalReason, note string) monitorapi.Interval {
+	return monitorapi.NewInterval(monitorapi.SourceKubeEvent, monitorapi.Info).
+		Locator(monitorapi.Locator{
+			Type: monitorapi.LocatorTypeKind,
+			Keys: map[monitorapi.LocatorKey]string{
+				monitorapi.LocatorClusterVersionKey: "cluster",
+				monitorapi.LocatorNamespaceKey:      "openshift-cluster-version",
+			},
+		}).
+		Message(monitorapi.NewMessage().Reason(reason).HumanMessage(note)).
+		Build(start.Add(at), start.Add(at))
+}
+
+func TestConstructComputedIntervals(t *testing.T) {
+	type hop struct {
+		state    string
+		version  string
+		image    string
+		duration time.Duration
+	}
+	testCases := []struct {
+		name              string
+		startingIntervals monitorapi.Intervals
+		expected          map[string]hop
+	}{
+		{
+			name: "single hop upgrades are not marked",
+			startingIntervals: monitorapi.Intervals{
+				upgradeEvent(0, monitorapi.UpgradeStartedReason, "version/4.14.0 image/quay.io/openshift/release:4.14.0"),
+				upgradeEvent(time.Hour, monitorapi.UpgradeCompleteReason, "version/4.14.0 image/quay.io/openshift/release:4.14.0"),
+			},
+			expected: map[string]hop{},
+		},
+		{
+			name: "eus upgrade",
+			startingIntervals: monitorapi.Intervals{
+				upgradeEvent(0, monitorapi.UpgradeStartedReason, "version/4.13.0 image/quay.io/openshift/release:4.13.0 hop/1 hops/2"),
+				upgradeEvent(40*time.Minute, monitorapi.UpgradeVersionReason, "version/4.13.0 image/4.13.0 hop/1 hops/2"),
+				upgradeEvent(50*time.Minute, monitorapi.UpgradeCompleteReason, "version/4.13.0 image/quay.io/openshift/release:4.13.0 hop/1 hops/2"),
+				upgradeEvent(55*time.Minute, monitorapi.UpgradeStartedReason, "version/ image/quay.io/openshift/release@sha256:1234 hop/2 hops/2"),
+				upgradeEvent(100*time.Minute, monitorapi.UpgradeFailedReason, "failed to settle operators: timed out hop/2 hops/2"),
+			},
+			expected: map[string]hop{
+				"1": {state: hopStateCompleted, version: "4.13.0", image: "quay.io/openshift/release:4.13.0", duration: 50 * time.Minute},
+				"2": {state: hopStateFailed, image: "quay.io/openshift/release@sha256:1234", duration: 45 * time.Minute},
+			},
+		},
+		{
+			name: "unfinished hops",
+			startingIntervals: monitorapi.Intervals{
+				upgradeEvent(0, monitorapi.UpgradeStartedReason, "version/4.15.0 image/ hop/1 hops/3"),
+				// an abandoned hop ends when the next one starts
+				upgradeEvent(30*time.Minute, monitorapi.UpgradeStartedReason, "version/4.16.0 image/ hop/2 hops/3"),
+				// the completion of another hop does not end the current one
+				upgradeEvent(35*time.Minute, monitorapi.UpgradeCompleteReason, "version/4.15.0 image/ hop/1 hops/3"),
+			},
+			expected: map[string]hop{
+				"1": {state: hopStateIncomplete, version: "4.15.0", duration: 30 * time.Minute},
+				"2": {state: hopStateIncomplete, version: "4.16.0", duration: 30 * time.Minute},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			end := start.Add(time.Hour)
+			constructed, err := NewUpgradeHops().ConstructComputedIntervals(context.TODO(), testCase.startingIntervals, nil, start, end)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			actual := map[string]hop{}
+			for _, interval := range constructed {
+				if interval.Source != monitorapi.SourceUpgradeHop || interval.Message.Reason != monitorapi.UpgradeHopReason {
+					t.Errorf("unexpected interval %v", interval)
+					continue
+				}
+				if !interval.Display {
+					t.Errorf("expected %v to be displayed", interval)
+				}
+				actual[interval.Locator.Keys[monitorapi.LocatorUpgradeHopKey]] = hop{
+					state:    interval.Message.Annotations[monitorapi.AnnotationState],
+					version:  interval.Message.Annotations[monitorapi.AnnotationVersion],
+					image:    interval.Message.Annotations[monitorapi.AnnotationImage],
+					duration: interval.To.Sub(interval.From),
+				}
+			}
+			if len(actual) != len(testCase.expected) {
+				t.Fatalf("expected hops %v, got %v", testCase.expected, actual)
+			}
+			for number, expected := range testCase.expected {
+				if actual[number] != expected {
+					t.Errorf("hop %s: expected %+v, got %+v", number, expected, actual[number])
+				}
+			}
+		})
+	}
+}
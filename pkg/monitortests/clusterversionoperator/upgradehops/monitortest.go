@@ -0,0 +1,48 @@
+package upgradehops
+
+import (
+	"context"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestframework"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+	"k8s.io/client-go/rest"
+)
+
+const MonitorName = "upgrade-hops"
+
+// upgradeHops marks the boundaries of each hop of a multi-hop upgrade on the timeline, using the cluster events the
+// upgrade test records when it starts and finishes a hop. The disruption summary serializer uses the hop intervals to
+// report disruption per hop.
+type upgradeHops struct {
+}
+
+func NewUpgradeHops() monitortestframework.MonitorTest {
+	return &upgradeHops{}
+}
+
+func (*upgradeHops) StartCollection(ctx context.Context, adminRESTConfig *rest.Config, recorder monitorapi.RecorderWriter) error {
+	return nil
+}
+
+func (*upgradeHops) CollectData(ctx context.Context, storageDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
+	return nil, nil, nil
+}
+
+func (*upgradeHops) ConstructComputedIntervals(ctx context.Context, startingIntervals monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, beginning, end time.Time) (monitorapi.Intervals, error) {
+	return constructHopIntervals(startingIntervals, end), nil
+}
+
+func (*upgradeHops) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
+	// the upgrade test reports the result of each hop itself.
+	return nil, nil
+}
+
+func (*upgradeHops) WriteContentToStorage(ctx context.Context, storageDir, timeSuffix string, finalIntervals monitorapi.Intervals, finalResourceState monitorapi.ResourcesMap) error {
+	return nil
+}
+
+func (*upgradeHops) Cleanup(ctx context.Context) error {
+	return nil
+}
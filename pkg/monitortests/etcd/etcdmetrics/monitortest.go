@@ -0,0 +1,363 @@
+package etcdmetrics
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	routeclient "github.com/openshift/client-go/route/clientset/versioned"
+	utilmetrics "github.com/openshift/library-go/test/library/metrics"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestframework"
+	"github.com/openshift/origin/pkg/monitortests/metrics"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+	exutil "github.com/openshift/origin/test/extended/util"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/kubernetes/test/e2e/framework"
+
+	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	prometheustypes "github.com/prometheus/common/model"
+)
+
+const (
+	MonitorName = "etcd-metrics"
+
+	// leaderChangeWindow is how far before a leader change we look for the previous leader struggling.  It matches the
+	// rate window of the queries, so anything the leader suffered that could have contributed is included.
+	leaderChangeWindow = 5 * time.Minute
+
+	leaderChangeTestName = "[sig-etcd] etcd-metrics leader changes should not follow slow disks or peers on the previous leader"
+)
+
+// etcdMetric is a leading indicator of etcd trouble and the limit etcd recommends for it.
+type etcdMetric struct {
+	reason      monitorapi.IntervalReason
+	description string
+	query       string
+	threshold   float64
+	format      func(value float64) string
+}
+
+func (m etcdMetric) testName() string {
+	return fmt.Sprintf("[sig-etcd] etcd-metrics members should have %s under %s", m.description, m.format(m.threshold))
+}
+
+func formatSeconds(value float64) string {
+	return time.Duration(value * float64(time.Second)).Round(time.Millisecond).String()
+}
+
+func formatRatio(value float64) string {
+	return fmt.Sprintf("%.0f%%", value*100)
+}
+
+// etcdMetrics are checked against the limits from the etcd hardware and tuning guidance.  Every query is grouped by
+// pod so intervals can be attributed to a member, peer round trips are also grouped by the peer they were sent To.
+var etcdMetrics = []etcdMetric{
+	{
+		reason:      monitorapi.EtcdWALFsyncSlow,
+		description: "p99 WAL fsync duration",
+		query:       `histogram_quantile(0.99, sum by (pod, le) (rate(etcd_disk_wal_fsync_duration_seconds_bucket{job=~".*etcd.*"}[5m])))`,
+		threshold:   0.010,
+		format:      formatSeconds,
+	},
+	{
+		reason:      monitorapi.EtcdBackendCommitSlow,
+		description: "p99 backend commit duration",
+		query:       `histogram_quantile(0.99, sum by (pod, le) (rate(etcd_disk_backend_commit_duration_seconds_bucket{job=~".*etcd.*"}[5m])))`,
+		threshold:   0.025,
+		format:      formatSeconds,
+	},
+	{
+		reason:      monitorapi.EtcdPeerRoundTripSlow,
+		description: "p99 peer round trip time",
+		query:       `histogram_quantile(0.99, sum by (pod, To, le) (rate(etcd_network_peer_round_trip_time_seconds_bucket{job=~".*etcd.*"}[5m])))`,
+		threshold:   0.050,
+		format:      formatSeconds,
+	},
+	{
+		reason:      monitorapi.EtcdDatabaseNearQuota,
+		description: "database size",
+		query:       `max by (pod) (etcd_mvcc_db_total_size_in_bytes{job=~".*etcd.*"} / etcd_server_quota_backend_bytes{job=~".*etcd.*"})`,
+		threshold:   0.8,
+		format:      formatRatio,
+	},
+}
+
+// NewMonitorTest returns a monitor test that queries the etcd disk, peer and database size metrics, records intervals
+// where a member exceeds the recommended limits, and correlates them with the leader changes the etcd log analyzer
+// constructs.  Members are identified by the member ID etcd reports in etcd_server_id, which is the same ID the
+// leadership intervals use for LocatorEtcdMemberKey.
+func NewMonitorTest() monitortestframework.MonitorTest {
+	return &monitorTest{}
+}
+
+type etcdMetricsMonitor struct {
+	// queries is keyed by the reason of the etcdMetric
+	queries   map[monitorapi.IntervalReason]metrics.QueryRunner
+	serverIDs metrics.QueryRunner
+}
+
+type monitorTest struct {
+	monitor            *etcdMetricsMonitor
+	notSupportedReason error
+}
+
+func (test *monitorTest) StartCollection(ctx context.Context, adminRESTConfig *rest.Config, recorder monitorapi.RecorderWriter) error {
+	kubeClient, err := kubernetes.NewForConfig(adminRESTConfig)
+	if err != nil {
+		return err
+	}
+	isMicroShift, err := exutil.IsMicroShiftCluster(kubeClient)
+	if err != nil {
+		return fmt.Errorf("unable to determine if cluster is MicroShift: %v", err)
+	}
+	if isMicroShift {
+		test.notSupportedReason = &monitortestframework.NotSupportedError{
+			Reason: "platform MicroShift not supported",
+		}
+		return test.notSupportedReason
+	}
+	routeClient, err := routeclient.NewForConfig(adminRESTConfig)
+	if err != nil {
+		return err
+	}
+
+	client, err := utilmetrics.NewPrometheusClient(ctx, kubeClient, routeClient)
+	if err != nil {
+		return err
+	}
+	test.monitor = newEtcdMetricsMonitor(client)
+
+	framework.Logf("monitor[%s]: monitor initialized", MonitorName)
+	return nil
+}
+
+func newEtcdMetricsMonitor(client prometheusv1.API) *etcdMetricsMonitor {
+	query := func(queryString string) metrics.QueryRunner {
+		return &metrics.PrometheusQueryRunner{
+			Client:      client,
+			QueryString: queryString,
+			Step:        time.Minute,
+		}
+	}
+
+	queries := map[monitorapi.IntervalReason]metrics.QueryRunner{}
+	for _, metric := range etcdMetrics {
+		queries[metric.reason] = query(metric.query)
+	}
+	return &etcdMetricsMonitor{
+		queries:   queries,
+		serverIDs: query(`max by (pod, server_id) (etcd_server_id{job=~".*etcd.*"})`),
+	}
+}
+
+func (test *monitorTest) CollectData(ctx context.Context, storageDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
+	if test.notSupportedReason != nil {
+		return nil, nil, test.notSupportedReason
+	}
+
+	m := test.monitor
+	if m == nil {
+		return monitorapi.Intervals{}, nil, fmt.Errorf("monitor test is not initialized")
+	}
+
+	podToMember, err := podsToMembers(ctx, m.serverIDs, beginning, end)
+	if err != nil {
+		return monitorapi.Intervals{}, nil, err
+	}
+
+	intervals := monitorapi.Intervals{}
+	for i := range etcdMetrics {
+		callback := &etcdMetricCallback{
+			metric:      &etcdMetrics[i],
+			podToMember: podToMember,
+		}
+		analyzer := metrics.ThresholdSeriesAnalyzer{Threshold: etcdMetrics[i].threshold}
+		if err := analyzer.Analyze(ctx, m.queries[etcdMetrics[i].reason], beginning, end, callback); err != nil {
+			return monitorapi.Intervals{}, nil, err
+		}
+		intervals = append(intervals, callback.intervals...)
+	}
+
+	sort.Sort(intervals)
+	return intervals, nil, nil
+}
+
+func (test *monitorTest) ConstructComputedIntervals(ctx context.Context, startingIntervals monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, beginning, end time.Time) (monitorapi.Intervals, error) {
+	return nil, test.notSupportedReason
+}
+
+func (test *monitorTest) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
+	if test.notSupportedReason != nil {
+		return nil, test.notSupportedReason
+	}
+
+	junits := []*junitapi.JUnitTestCase{}
+	for _, metric := range etcdMetrics {
+		junits = append(junits, exceededLimitJunits(finalIntervals, metric)...)
+	}
+	junits = append(junits, leaderChangeJunits(finalIntervals)...)
+	return junits, nil
+}
+
+func (test *monitorTest) WriteContentToStorage(ctx context.Context, storageDir, timeSuffix string, finalIntervals monitorapi.Intervals, finalResourceState monitorapi.ResourcesMap) error {
+	return test.notSupportedReason
+}
+
+func (test *monitorTest) Cleanup(ctx context.Context) error {
+	return test.notSupportedReason
+}
+
+// podsToMembers maps etcd pods to the member ID they reported.  A member replaced during the run has a new ID, the
+// last one reported wins.
+func podsToMembers(ctx context.Context, query metrics.QueryRunner, beginning, end time.Time) (map[string]string, error) {
+	result, err := query.RunQuery(ctx, beginning, end)
+	if err != nil {
+		return nil, err
+	}
+	matrix, ok := result.(prometheustypes.Matrix)
+	if !ok {
+		return nil, fmt.Errorf("expected a prometheus Matrix type, but got: %q, monitor: %s", result.Type().String(), MonitorName)
+	}
+
+	lastSeen := map[string]prometheustypes.Time{}
+	ret := map[string]string{}
+	for _, series := range matrix {
+		if len(series.Values) == 0 {
+			continue
+		}
+		pod := string(series.Metric["pod"])
+		last := series.Values[len(series.Values)-1].Timestamp
+		if seen, ok := lastSeen[pod]; ok && seen.After(last) {
+			continue
+		}
+		lastSeen[pod] = last
+		ret[pod] = string(series.Metric["server_id"])
+	}
+	return ret, nil
+}
+
+// exceededLimitJunits flakes when any member exceeded the limit.  The limits are what etcd recommends for production,
+// and CI disks and networks routinely miss them, so these show trends rather than fail.
+func exceededLimitJunits(finalIntervals monitorapi.Intervals, metric etcdMetric) []*junitapi.JUnitTestCase {
+	exceeded := []string{}
+	for _, interval := range finalIntervals {
+		if interval.Source == monitorapi.SourceEtcdMetrics && interval.Message.Reason == metric.reason {
+			exceeded = append(exceeded, interval.String())
+		}
+	}
+
+	junits := []*junitapi.JUnitTestCase{}
+	if len(exceeded) > 0 {
+		junits = append(junits, &junitapi.JUnitTestCase{
+			Name: metric.testName(),
+			FailureOutput: &junitapi.FailureOutput{
+				Output: fmt.Sprintf("%s exceeded %s %d times\n\n%v", metric.description, metric.format(metric.threshold), len(exceeded), strings.Join(exceeded, "\n")),
+			},
+		})
+	}
+	// this test only flakes
+	junits = append(junits, &junitapi.JUnitTestCase{Name: metric.testName()})
+	return junits
+}
+
+// leaderChangeJunits flakes when the member that lost leadership exceeded a limit shortly before, which points to the
+// disk or network as the reason for the election.
+func leaderChangeJunits(finalIntervals monitorapi.Intervals) []*junitapi.JUnitTestCase {
+	leaderships := monitorapi.Intervals{}
+	memberToExceeded := map[string]monitorapi.Intervals{}
+	for _, interval := range finalIntervals {
+		switch interval.Source {
+		case monitorapi.SourceEtcdLeadership:
+			leaderships = append(leaderships, interval)
+		case monitorapi.SourceEtcdMetrics:
+			member := interval.Locator.Keys[monitorapi.LocatorEtcdMemberKey]
+			memberToExceeded[member] = append(memberToExceeded[member], interval)
+		}
+	}
+	sort.SliceStable(leaderships, func(i, j int) bool {
+		return leaderships[i].From.Before(leaderships[j].From)
+	})
+
+	correlated := []string{}
+	// the first leadership interval is the leader we found, not a change.
+	for i := 1; i < len(leaderships); i++ {
+		changedAt := leaderships[i].From
+		previousLeader := leaderships[i-1].Locator.Keys[monitorapi.LocatorEtcdMemberKey]
+		if len(previousLeader) == 0 {
+			continue
+		}
+		causes := []string{}
+		for _, exceeded := range memberToExceeded[previousLeader] {
+			if exceeded.From.After(changedAt) || exceeded.To.Before(changedAt.Add(-leaderChangeWindow)) {
+				continue
+			}
+			causes = append(causes, "\t"+exceeded.String())
+		}
+		if len(causes) == 0 {
+			continue
+		}
+		correlated = append(correlated, fmt.Sprintf("leader changed from %s to %s at %s after:\n%s",
+			previousLeader, leaderships[i].Locator.Keys[monitorapi.LocatorEtcdMemberKey], changedAt.Format(time.RFC3339), strings.Join(causes, "\n")))
+	}
+
+	junits := []*junitapi.JUnitTestCase{}
+	if len(correlated) > 0 {
+		junits = append(junits, &junitapi.JUnitTestCase{
+			Name: leaderChangeTestName,
+			FailureOutput: &junitapi.FailureOutput{
+				Output: fmt.Sprintf("%d leader changes followed the previous leader exceeding etcd limits\n\n%v", len(correlated), strings.Join(correlated, "\n\n")),
+			},
+		})
+	}
+	// this test only flakes
+	junits = append(junits, &junitapi.JUnitTestCase{Name: leaderChangeTestName})
+	return junits
+}
+
+// etcdMetricCallback is passed to the metric analyzer to construct intervals for each member that exceeded a limit.
+type etcdMetricCallback struct {
+	metric      *etcdMetric
+	podToMember map[string]string
+
+	locator   monitorapi.Locator
+	intervals monitorapi.Intervals
+}
+
+func (b *etcdMetricCallback) Name() string { return MonitorName }
+func (b *etcdMetricCallback) StartSeries(metric prometheustypes.Metric) {
+	pod := string(metric["pod"])
+	// etcd pods are named after the node they run on.
+	b.locator = monitorapi.NewLocator().EtcdMemberFromNames(strings.TrimPrefix(pod, "etcd-"), b.podToMember[pod])
+}
+func (b *etcdMetricCallback) EndSeries() { b.locator = monitorapi.Locator{} }
+
+func (b *etcdMetricCallback) NewInterval(metric prometheustypes.Metric, start, end *prometheustypes.SamplePair) {
+	startTime := start.Timestamp.Time()
+	endTime := end.Timestamp.Time()
+	if start == end {
+		// a single sample, approximate the interval to [t-30s ... t+30s] like the other metric based intervals.
+		startTime = startTime.Add(-30 * time.Second)
+		endTime = endTime.Add(30 * time.Second)
+	}
+
+	message := monitorapi.NewMessage().Reason(b.metric.reason).
+		WithAnnotation(monitorapi.AnnotationThreshold, b.metric.format(b.metric.threshold))
+	humanMessage := fmt.Sprintf("%s exceeded %s, first %s, last %s", b.metric.description, b.metric.format(b.metric.threshold),
+		b.metric.format(float64(start.Value)), b.metric.format(float64(end.Value)))
+	if peer, ok := metric["To"]; ok {
+		message = message.WithAnnotation(monitorapi.AnnotationEtcdPeer, string(peer))
+		humanMessage = fmt.Sprintf("%s to peer %s", humanMessage, peer)
+	}
+
+	interval := monitorapi.NewInterval(monitorapi.SourceEtcdMetrics, monitorapi.Warning).
+		Locator(b.locator).
+		Message(message.HumanMessage(humanMessage)).
+		Display().
+		Build(startTime, endTime)
+	b.intervals = append(b.intervals, interval)
+}
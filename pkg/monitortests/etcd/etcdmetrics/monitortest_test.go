@@ -0,0 +1,116 @@
+package etcdmetrics
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortests/metrics"
+
+	prometheustypes "github.com/prometheus/common/model"
+)
+
+const (
+	serverIDs = `
+[
+  {"metric": {"pod": "etcd-master-0", "server_id": "8e9e05c52164694d"}, "values": [[1723587909, "1"], [1723588149, "1"]]},
+  {"metric": {"pod": "etcd-master-1", "server_id": "91bc3c398fb3c146"}, "values": [[1723587909, "1"], [1723588149, "1"]]}
+]`
+
+	walFsync = `
+[
+  {"metric": {"pod": "etcd-master-0"}, "values": [[1723587909, "0.004"], [1723587969, "0.04"], [1723588029, "0.08"], [1723588089, "0.005"], [1723588149, "NaN"]]},
+  {"metric": {"pod": "etcd-master-1"}, "values": [[1723587909, "0.004"], [1723587969, "0.005"], [1723588029, "0.006"], [1723588089, "0.005"], [1723588149, "0.004"]]}
+]`
+
+	peerRoundTrip = `
+[
+  {"metric": {"pod": "etcd-master-1", "To": "8e9e05c52164694d"}, "values": [[1723587909, "0.01"], [1723587969, "0.01"], [1723588029, "0.01"], [1723588089, "0.2"], [1723588149, "0.01"]]}
+]`
+
+	empty = `[]`
+)
+
+func TestEtcdMetricsMonitor(t *testing.T) {
+	test := &monitorTest{
+		monitor: &etcdMetricsMonitor{
+			queries: map[monitorapi.IntervalReason]metrics.QueryRunner{
+				monitorapi.EtcdWALFsyncSlow:      byteQuery(walFsync),
+				monitorapi.EtcdBackendCommitSlow: byteQuery(empty),
+				monitorapi.EtcdPeerRoundTripSlow: byteQuery(peerRoundTrip),
+				monitorapi.EtcdDatabaseNearQuota: byteQuery(empty),
+			},
+			serverIDs: byteQuery(serverIDs),
+		},
+	}
+
+	intervals, _, err := test.CollectData(context.Background(), "", time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error from CollectData: %v", err)
+	}
+	if len(intervals) != 2 {
+		t.Fatalf("expected 2 intervals, got %v", intervals)
+	}
+	fsync, peer := intervals[0], intervals[1]
+	if fsync.Message.Reason != monitorapi.EtcdWALFsyncSlow || fsync.Locator.Keys[monitorapi.LocatorEtcdMemberKey] != "8e9e05c52164694d" ||
+		fsync.Locator.Keys[monitorapi.LocatorNodeKey] != "master-0" {
+		t.Errorf("unexpected fsync interval: %v", fsync)
+	}
+	if !strings.Contains(fsync.Message.HumanMessage, "first 40ms, last 80ms") {
+		t.Errorf("unexpected fsync message: %v", fsync.Message.HumanMessage)
+	}
+	if peer.Message.Reason != monitorapi.EtcdPeerRoundTripSlow || peer.Locator.Keys[monitorapi.LocatorEtcdMemberKey] != "91bc3c398fb3c146" ||
+		peer.Message.Annotations[monitorapi.AnnotationEtcdPeer] != "8e9e05c52164694d" {
+		t.Errorf("unexpected peer interval: %v", peer)
+	}
+	if duration := peer.To.Sub(peer.From); duration != time.Minute {
+		t.Errorf("expected a single sample to be widened to a minute, got %v", duration)
+	}
+
+	// master-0 lost leadership while its disk was slow, master-1 lost it without exceeding a limit.
+	leadership := func(from, to int64, member string) monitorapi.Interval {
+		return monitorapi.NewInterval(monitorapi.SourceEtcdLeadership, monitorapi.Warning).
+			Locator(monitorapi.NewLocator().EtcdMemberFromNames("", member)).
+			Message(monitorapi.NewMessage().HumanMessage("")).
+			Build(time.Unix(from, 0), time.Unix(to, 0))
+	}
+	finalIntervals := append(intervals,
+		leadership(1723587000, 1723588000, "8e9e05c52164694d"),
+		leadership(1723588000, 1723590000, "91bc3c398fb3c146"),
+		leadership(1723590000, 1723591000, "8e9e05c52164694d"),
+	)
+
+	junits, err := test.EvaluateTestsFromConstructedIntervals(context.Background(), finalIntervals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	failures := map[string]string{}
+	for _, junit := range junits {
+		if junit.FailureOutput != nil {
+			failures[junit.Name] = junit.FailureOutput.Output
+		}
+	}
+	if len(failures) != 3 {
+		t.Errorf("expected the fsync, peer and leader change tests to flake, got %v", failures)
+	}
+	output, ok := failures[leaderChangeTestName]
+	if !ok {
+		t.Fatalf("expected the leader change to be correlated")
+	}
+	if !strings.Contains(output, "1 leader changes") || !strings.Contains(output, "from 8e9e05c52164694d to 91bc3c398fb3c146") {
+		t.Errorf("unexpected leader change output: %v", output)
+	}
+}
+
+type byteQuery []byte
+
+func (q byteQuery) RunQuery(ctx context.Context, start, end time.Time) (prometheustypes.Value, error) {
+	var matrix prometheustypes.Matrix
+	if err := json.Unmarshal([]byte(q), &matrix); err != nil {
+		return nil, err
+	}
+	return matrix, nil
+}
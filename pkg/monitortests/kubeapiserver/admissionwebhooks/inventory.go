@@ -0,0 +1,130 @@
+package admissionwebhooks
+
+import (
+	"context"
+	"sort"
+
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// these are the values of the type label the kube-apiserver uses in its webhook metrics.
+	webhookTypeValidating = "validating"
+	webhookTypeMutating   = "admit"
+
+	// defaultTimeoutSeconds is what the kube-apiserver uses when timeoutSeconds is not set.
+	defaultTimeoutSeconds = int32(10)
+)
+
+// webhookKey identifies a webhook the same way the kube-apiserver metrics do.
+type webhookKey struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+type webhook struct {
+	Type             string `json:"type"`
+	Name             string `json:"name"`
+	Configuration    string `json:"configuration"`
+	FailurePolicy    string `json:"failurePolicy"`
+	TimeoutSeconds   int32  `json:"timeoutSeconds"`
+	ServiceNamespace string `json:"serviceNamespace,omitempty"`
+	ServiceName      string `json:"serviceName,omitempty"`
+	URL              string `json:"url,omitempty"`
+	Component        string `json:"component"`
+	Platform         bool   `json:"platform"`
+}
+
+// webhookInventory is every webhook seen during the run.  Webhooks can be added and removed while tests run, so the
+// inventory is never pruned.
+type webhookInventory map[webhookKey]*webhook
+
+func (i webhookInventory) addFromCluster(ctx context.Context, kubeClient kubernetes.Interface) error {
+	validating, err := kubeClient.AdmissionregistrationV1().ValidatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, configuration := range validating.Items {
+		for _, hook := range configuration.Webhooks {
+			i.add(newWebhook(webhookTypeValidating, configuration.Name, hook.Name, hook.FailurePolicy, hook.TimeoutSeconds, hook.ClientConfig))
+		}
+	}
+
+	mutating, err := kubeClient.AdmissionregistrationV1().MutatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, configuration := range mutating.Items {
+		for _, hook := range configuration.Webhooks {
+			i.add(newWebhook(webhookTypeMutating, configuration.Name, hook.Name, hook.FailurePolicy, hook.TimeoutSeconds, hook.ClientConfig))
+		}
+	}
+	return nil
+}
+
+func (i webhookInventory) add(hook *webhook) {
+	i[webhookKey{Type: hook.Type, Name: hook.Name}] = hook
+}
+
+// get returns the webhook for the metric labels.  Webhooks we never listed still get the kube-apiserver defaults.
+func (i webhookInventory) get(webhookType, name string) *webhook {
+	if hook, ok := i[webhookKey{Type: webhookType, Name: name}]; ok {
+		return hook
+	}
+	return &webhook{
+		Type:           webhookType,
+		Name:           name,
+		FailurePolicy:  string(admissionregistrationv1.Fail),
+		TimeoutSeconds: defaultTimeoutSeconds,
+		Component:      "Unknown",
+	}
+}
+
+func (i webhookInventory) sorted() []*webhook {
+	ret := []*webhook{}
+	for _, hook := range i {
+		ret = append(ret, hook)
+	}
+	sort.Slice(ret, func(a, b int) bool {
+		if ret[a].Type != ret[b].Type {
+			return ret[a].Type < ret[b].Type
+		}
+		return ret[a].Name < ret[b].Name
+	})
+	return ret
+}
+
+func newWebhook(webhookType, configuration, name string, failurePolicy *admissionregistrationv1.FailurePolicyType, timeoutSeconds *int32, clientConfig admissionregistrationv1.WebhookClientConfig) *webhook {
+	hook := &webhook{
+		Type:           webhookType,
+		Name:           name,
+		Configuration:  configuration,
+		FailurePolicy:  string(admissionregistrationv1.Fail),
+		TimeoutSeconds: defaultTimeoutSeconds,
+	}
+	if failurePolicy != nil {
+		hook.FailurePolicy = string(*failurePolicy)
+	}
+	if timeoutSeconds != nil {
+		hook.TimeoutSeconds = *timeoutSeconds
+	}
+	if clientConfig.Service != nil {
+		hook.ServiceNamespace = clientConfig.Service.Namespace
+		hook.ServiceName = clientConfig.Service.Name
+	}
+	if clientConfig.URL != nil {
+		hook.URL = *clientConfig.URL
+	}
+
+	// the service namespace is the best indication of the owner, the configuration is usually named after the operator.
+	hook.Component = platformidentification.GetBugzillaComponentForNamespace(hook.ServiceNamespace)
+	if hook.Component == "Unknown" {
+		hook.Component = platformidentification.GetBugzillaComponentForOperator(configuration)
+	}
+	hook.Platform = platformidentification.IsPlatformNamespace(hook.ServiceNamespace) || hook.Component != "Unknown"
+	return hook
+}
@@ -0,0 +1,413 @@
+package admissionwebhooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	routeclient "github.com/openshift/client-go/route/clientset/versioned"
+	utilmetrics "github.com/openshift/library-go/test/library/metrics"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestframework"
+	"github.com/openshift/origin/pkg/monitortests/metrics"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+	exutil "github.com/openshift/origin/test/extended/util"
+	"github.com/sirupsen/logrus"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/kubernetes/test/e2e/framework"
+
+	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	prometheustypes "github.com/prometheus/common/model"
+)
+
+const (
+	MonitorName = "admission-webhook-health"
+
+	rejectionTestName = "[sig-api-machinery] admission-webhook-health platform admission webhooks should not reject requests because they failed"
+	timeoutTestName   = "[sig-api-machinery] admission-webhook-health platform admission webhooks should respond within their timeout"
+)
+
+// durationBuckets are the apiserver_admission_webhook_admission_duration_seconds buckets at or above the minimum
+// webhook timeout of one second.
+var durationBuckets = []float64{1, 2.5, 10, 25}
+
+// timeoutBucket is the largest histogram bucket that does not exceed the timeout.  The histogram cannot resolve
+// anything finer, so calls slower than this bucket are treated as having hit the timeout.
+func timeoutBucket(timeoutSeconds int32) float64 {
+	ret := durationBuckets[0]
+	for _, bucket := range durationBuckets {
+		if bucket <= float64(timeoutSeconds) {
+			ret = bucket
+		}
+	}
+	return ret
+}
+
+// NewMonitorTest returns a monitor test that inventories the admission webhooks in the cluster and uses the
+// kube-apiserver admission metrics to find when they misbehaved:
+//
+//	sum by (name, type) (rate(apiserver_admission_webhook_rejection_count{error_type="calling_webhook_error"}[2m]))
+//
+// is the rate of requests rejected because calling a webhook with failurePolicy=Fail failed, and for each timeout
+//
+//	sum by (name, type) (rate(apiserver_admission_webhook_admission_duration_seconds_count[2m])) -
+//	sum by (name, type) (rate(apiserver_admission_webhook_admission_duration_seconds_bucket{le="<timeout>"}[2m]))
+//
+// is the rate of calls slower than the webhook timeout.  Intervals are attributed to the owning component using the
+// namespace of the webhook service, or the name of the webhook configuration when that is not mapped.
+func NewMonitorTest() monitortestframework.MonitorTest {
+	return &monitorTest{
+		inventory: webhookInventory{},
+	}
+}
+
+type webhookMonitor struct {
+	analyzer   metrics.SeriesAnalyzer
+	rejections metrics.QueryRunner
+	// timeouts is keyed by histogram bucket, see timeoutBucket.
+	timeouts map[float64]metrics.QueryRunner
+
+	// latency and rejectionCounts are only summarized, not turned into intervals.
+	latency         metrics.QueryRunner
+	rejectionCounts metrics.QueryRunner
+}
+
+type monitorTest struct {
+	kubeClient kubernetes.Interface
+	inventory  webhookInventory
+	monitor    *webhookMonitor
+	summary    []webhookSummary
+
+	notSupportedReason error
+}
+
+// webhookSummary is written to storage so webhook behavior can be compared across runs.
+type webhookSummary struct {
+	webhook
+	MaxP99LatencySeconds float64 `json:"maxP99LatencySeconds"`
+	RejectedOnError      float64 `json:"rejectedOnError"`
+}
+
+func (test *monitorTest) StartCollection(ctx context.Context, adminRESTConfig *rest.Config, recorder monitorapi.RecorderWriter) error {
+	kubeClient, err := kubernetes.NewForConfig(adminRESTConfig)
+	if err != nil {
+		return err
+	}
+	isMicroShift, err := exutil.IsMicroShiftCluster(kubeClient)
+	if err != nil {
+		return fmt.Errorf("unable to determine if cluster is MicroShift: %v", err)
+	}
+	if isMicroShift {
+		test.notSupportedReason = &monitortestframework.NotSupportedError{
+			Reason: "platform MicroShift not supported",
+		}
+		return test.notSupportedReason
+	}
+	routeClient, err := routeclient.NewForConfig(adminRESTConfig)
+	if err != nil {
+		return err
+	}
+
+	client, err := utilmetrics.NewPrometheusClient(ctx, kubeClient, routeClient)
+	if err != nil {
+		return err
+	}
+
+	test.kubeClient = kubeClient
+	if err := test.inventory.addFromCluster(ctx, kubeClient); err != nil {
+		return err
+	}
+	test.monitor = newWebhookMonitor(client)
+
+	framework.Logf("monitor[%s]: monitor initialized, webhooks: %d", MonitorName, len(test.inventory))
+	return nil
+}
+
+func newWebhookMonitor(client prometheusv1.API) *webhookMonitor {
+	query := func(queryString string) metrics.QueryRunner {
+		return &metrics.PrometheusQueryRunner{
+			Client:      client,
+			QueryString: queryString,
+			Step:        time.Minute,
+		}
+	}
+
+	timeouts := map[float64]metrics.QueryRunner{}
+	for _, bucket := range durationBuckets {
+		timeouts[bucket] = query(fmt.Sprintf(
+			`sum by (name, type) (rate(apiserver_admission_webhook_admission_duration_seconds_count[2m])) - sum by (name, type) (rate(apiserver_admission_webhook_admission_duration_seconds_bucket{le="%v"}[2m]))`,
+			bucket))
+	}
+
+	return &webhookMonitor{
+		analyzer:        metrics.RateSeriesAnalyzer{},
+		rejections:      query(`sum by (name, type) (rate(apiserver_admission_webhook_rejection_count{error_type="calling_webhook_error"}[2m]))`),
+		timeouts:        timeouts,
+		latency:         query(`histogram_quantile(0.99, sum by (name, type, le) (rate(apiserver_admission_webhook_admission_duration_seconds_bucket[5m])))`),
+		rejectionCounts: query(`sum by (name, type) (increase(apiserver_admission_webhook_rejection_count{error_type="calling_webhook_error"}[1m]))`),
+	}
+}
+
+func (test *monitorTest) CollectData(ctx context.Context, storageDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
+	if test.notSupportedReason != nil {
+		return nil, nil, test.notSupportedReason
+	}
+
+	m := test.monitor
+	if m == nil {
+		return monitorapi.Intervals{}, nil, fmt.Errorf("monitor test is not initialized")
+	}
+
+	// pick up webhooks created while tests ran.
+	if test.kubeClient != nil {
+		if err := test.inventory.addFromCluster(ctx, test.kubeClient); err != nil {
+			logrus.WithError(err).Warning("unable to refresh the admission webhook inventory")
+		}
+	}
+
+	rejections := &webhookCallback{
+		reason:    monitorapi.AdmissionWebhookRejectedOnError,
+		inventory: test.inventory,
+		humanMessage: func(hook *webhook, duration time.Duration) string {
+			return fmt.Sprintf("kube-apiserver rejected requests because calling %s webhook %s failed, failurePolicy: %s, duration: %s", hook.Type, hook.Name, hook.FailurePolicy, duration)
+		},
+	}
+	if err := m.analyzer.Analyze(ctx, m.rejections, beginning, end, rejections); err != nil {
+		return monitorapi.Intervals{}, nil, err
+	}
+	intervals := rejections.intervals
+
+	for _, bucket := range durationBuckets {
+		bucket := bucket
+		timeouts := &webhookCallback{
+			reason:    monitorapi.AdmissionWebhookTimeout,
+			inventory: test.inventory,
+			include: func(hook *webhook) bool {
+				return timeoutBucket(hook.TimeoutSeconds) == bucket
+			},
+			humanMessage: func(hook *webhook, duration time.Duration) string {
+				return fmt.Sprintf("calls to %s webhook %s took longer than %vs, timeout: %ds, failurePolicy: %s, duration: %s", hook.Type, hook.Name, bucket, hook.TimeoutSeconds, hook.FailurePolicy, duration)
+			},
+		}
+		if err := m.analyzer.Analyze(ctx, m.timeouts[bucket], beginning, end, timeouts); err != nil {
+			return monitorapi.Intervals{}, nil, err
+		}
+		intervals = append(intervals, timeouts.intervals...)
+	}
+
+	// the summary is informational, so failing to produce it does not fail collection.
+	summary, err := summarize(ctx, m, test.inventory, beginning, end)
+	if err != nil {
+		logrus.WithError(err).Warning("unable to summarize admission webhook metrics")
+	}
+	test.summary = summary
+
+	sort.Sort(intervals)
+	return intervals, nil, nil
+}
+
+func (test *monitorTest) ConstructComputedIntervals(ctx context.Context, startingIntervals monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, beginning, end time.Time) (monitorapi.Intervals, error) {
+	return nil, test.notSupportedReason
+}
+
+func (test *monitorTest) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
+	if test.notSupportedReason != nil {
+		return nil, test.notSupportedReason
+	}
+
+	junits := []*junitapi.JUnitTestCase{}
+	junits = append(junits, test.platformWebhookJunits(finalIntervals, rejectionTestName, monitorapi.AdmissionWebhookRejectedOnError)...)
+	junits = append(junits, test.platformWebhookJunits(finalIntervals, timeoutTestName, monitorapi.AdmissionWebhookTimeout)...)
+	return junits, nil
+}
+
+func (test *monitorTest) WriteContentToStorage(ctx context.Context, storageDir, timeSuffix string, finalIntervals monitorapi.Intervals, finalResourceState monitorapi.ResourcesMap) error {
+	if test.notSupportedReason != nil {
+		return test.notSupportedReason
+	}
+	if len(test.summary) == 0 {
+		return nil
+	}
+
+	content, err := json.MarshalIndent(test.summary, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(storageDir, fmt.Sprintf("admission-webhooks_%s.json", timeSuffix)), content, 0644)
+}
+
+func (test *monitorTest) Cleanup(ctx context.Context) error {
+	return test.notSupportedReason
+}
+
+// platformWebhookJunits flakes when a platform webhook misbehaved.  Webhooks are often slow or failing because the
+// component serving them is being upgraded or disrupted, so these only fail once we know how often that happens.
+func (test *monitorTest) platformWebhookJunits(finalIntervals monitorapi.Intervals, testName string, reason monitorapi.IntervalReason) []*junitapi.JUnitTestCase {
+	componentToIntervals := map[string][]string{}
+	for _, interval := range finalIntervals {
+		if interval.Source != monitorapi.SourceAdmissionWebhook || interval.Message.Reason != reason {
+			continue
+		}
+		hook := test.inventory.get(interval.Locator.Keys[monitorapi.LocatorAdmissionWebhookTypeKey], interval.Locator.Keys[monitorapi.LocatorAdmissionWebhookKey])
+		if !hook.Platform {
+			continue
+		}
+		componentToIntervals[hook.Component] = append(componentToIntervals[hook.Component], interval.String())
+	}
+
+	junits := []*junitapi.JUnitTestCase{}
+	if len(componentToIntervals) > 0 {
+		components := []string{}
+		for component := range componentToIntervals {
+			components = append(components, component)
+		}
+		sort.Strings(components)
+
+		output := []string{}
+		for _, component := range components {
+			output = append(output, fmt.Sprintf("%s:\n%s", component, strings.Join(componentToIntervals[component], "\n")))
+		}
+		junits = append(junits, &junitapi.JUnitTestCase{
+			Name: testName,
+			FailureOutput: &junitapi.FailureOutput{
+				Output: fmt.Sprintf("admission webhooks owned by %d components misbehaved\n\n%s", len(components), strings.Join(output, "\n\n")),
+			},
+		})
+	}
+	// this test only flakes
+	junits = append(junits, &junitapi.JUnitTestCase{Name: testName})
+	return junits
+}
+
+// summarize reports the worst p99 latency and the number of requests rejected on error for every webhook seen.
+func summarize(ctx context.Context, m *webhookMonitor, inventory webhookInventory, beginning, end time.Time) ([]webhookSummary, error) {
+	summaries := map[webhookKey]*webhookSummary{}
+	summaryFor := func(metric prometheustypes.Metric) *webhookSummary {
+		hook := inventory.get(string(metric["type"]), string(metric["name"]))
+		key := webhookKey{Type: hook.Type, Name: hook.Name}
+		if _, ok := summaries[key]; !ok {
+			summaries[key] = &webhookSummary{webhook: *hook}
+		}
+		return summaries[key]
+	}
+	for _, hook := range inventory.sorted() {
+		summaryFor(prometheustypes.Metric{"type": prometheustypes.LabelValue(hook.Type), "name": prometheustypes.LabelValue(hook.Name)})
+	}
+
+	errs := []string{}
+	latency, err := runMatrixQuery(ctx, m.latency, beginning, end)
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+	for _, series := range latency {
+		summary := summaryFor(series.Metric)
+		for _, sample := range series.Values {
+			value := float64(sample.Value)
+			if !math.IsNaN(value) && !math.IsInf(value, 0) && value > summary.MaxP99LatencySeconds {
+				summary.MaxP99LatencySeconds = value
+			}
+		}
+	}
+
+	rejectionCounts, err := runMatrixQuery(ctx, m.rejectionCounts, beginning, end)
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+	for _, series := range rejectionCounts {
+		summary := summaryFor(series.Metric)
+		for _, sample := range series.Values {
+			if value := float64(sample.Value); !math.IsNaN(value) {
+				summary.RejectedOnError += value
+			}
+		}
+	}
+
+	ret := []webhookSummary{}
+	for _, summary := range summaries {
+		ret = append(ret, *summary)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Type != ret[j].Type {
+			return ret[i].Type < ret[j].Type
+		}
+		return ret[i].Name < ret[j].Name
+	})
+	if len(errs) > 0 {
+		return ret, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return ret, nil
+}
+
+func runMatrixQuery(ctx context.Context, query metrics.QueryRunner, beginning, end time.Time) (prometheustypes.Matrix, error) {
+	if query == nil {
+		return nil, nil
+	}
+	result, err := query.RunQuery(ctx, beginning, end)
+	if err != nil {
+		return nil, err
+	}
+	matrix, ok := result.(prometheustypes.Matrix)
+	if !ok {
+		return nil, fmt.Errorf("expected a prometheus Matrix type, but got: %q", result.Type().String())
+	}
+	return matrix, nil
+}
+
+// webhookCallback is passed to the metric analyzer to construct intervals for each webhook series.
+type webhookCallback struct {
+	reason       monitorapi.IntervalReason
+	inventory    webhookInventory
+	include      func(hook *webhook) bool
+	humanMessage func(hook *webhook, duration time.Duration) string
+
+	current   *webhook
+	intervals monitorapi.Intervals
+}
+
+func (b *webhookCallback) Name() string { return MonitorName }
+func (b *webhookCallback) StartSeries(metric prometheustypes.Metric) {
+	b.current = b.inventory.get(string(metric["type"]), string(metric["name"]))
+	if b.include != nil && !b.include(b.current) {
+		b.current = nil
+	}
+}
+func (b *webhookCallback) EndSeries() { b.current = nil }
+
+func (b *webhookCallback) NewInterval(metric prometheustypes.Metric, start, end *prometheustypes.SamplePair) {
+	if b.current == nil {
+		return
+	}
+	startTime := start.Timestamp.Time()
+	endTime := end.Timestamp.Time()
+	if start == end {
+		// a single sample, approximate the interval to [t-30s ... t+30s] like the other metric based intervals.
+		startTime = startTime.Add(-30 * time.Second)
+		endTime = endTime.Add(30 * time.Second)
+	}
+
+	// with failurePolicy=Ignore the request continues without the webhook, which is worth seeing but not an error.
+	level := monitorapi.Error
+	if b.current.FailurePolicy == string(admissionregistrationv1.Ignore) {
+		level = monitorapi.Warning
+	}
+
+	interval := monitorapi.NewInterval(monitorapi.SourceAdmissionWebhook, level).
+		Locator(monitorapi.NewLocator().AdmissionWebhook(b.current.Type, b.current.Name)).
+		Message(monitorapi.NewMessage().Reason(b.reason).
+			WithAnnotation(monitorapi.AnnotationComponent, b.current.Component).
+			WithAnnotation(monitorapi.AnnotationFailurePolicy, b.current.FailurePolicy).
+			WithAnnotation(monitorapi.AnnotationTimeout, fmt.Sprintf("%ds", b.current.TimeoutSeconds)).
+			HumanMessage(b.humanMessage(b.current, endTime.Sub(startTime)))).
+		Display().
+		Build(startTime, endTime)
+	b.intervals = append(b.intervals, interval)
+}
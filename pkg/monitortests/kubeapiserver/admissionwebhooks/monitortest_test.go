@@ -0,0 +1,151 @@
+package admissionwebhooks
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortests/metrics"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+
+	prometheustypes "github.com/prometheus/common/model"
+)
+
+func testInventory() webhookInventory {
+	ignore := admissionregistrationv1.Ignore
+	thirtySeconds := int32(30)
+	service := func(namespace string) admissionregistrationv1.WebhookClientConfig {
+		return admissionregistrationv1.WebhookClientConfig{Service: &admissionregistrationv1.ServiceReference{Namespace: namespace, Name: "webhook"}}
+	}
+
+	inventory := webhookInventory{}
+	inventory.add(newWebhook(webhookTypeValidating, "console", "validate.console.openshift.io", nil, nil, service("openshift-console-operator")))
+	inventory.add(newWebhook(webhookTypeMutating, "machine-api", "machine-api.openshift.io", &ignore, &thirtySeconds, service("openshift-machine-api")))
+	inventory.add(newWebhook(webhookTypeMutating, "e2e-test-webhook", "e2e-test-webhook.example.com", nil, nil, service("e2e-test-webhook-1234")))
+	return inventory
+}
+
+func TestAdmissionWebhookMonitor(t *testing.T) {
+	read := func(name string) byteQuery {
+		bytes, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatalf("failed to read file: %v", err)
+		}
+		return bytes
+	}
+
+	// every bucket sees every series, the inventory decides which bucket applies to a webhook.
+	timeouts := map[float64]metrics.QueryRunner{}
+	for _, bucket := range durationBuckets {
+		timeouts[bucket] = read("timeouts.json")
+	}
+	test := &monitorTest{
+		inventory: testInventory(),
+		monitor: &webhookMonitor{
+			analyzer:        metrics.RateSeriesAnalyzer{},
+			rejections:      read("rejections.json"),
+			timeouts:        timeouts,
+			latency:         read("latency.json"),
+			rejectionCounts: read("rejection_counts.json"),
+		},
+	}
+
+	intervals, _, err := test.CollectData(context.Background(), "", time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error from CollectData: %v", err)
+	}
+
+	type expectedInterval struct {
+		webhook   string
+		reason    monitorapi.IntervalReason
+		level     monitorapi.IntervalLevel
+		component string
+	}
+	expected := []expectedInterval{
+		{webhook: "machine-api.openshift.io", reason: monitorapi.AdmissionWebhookTimeout, level: monitorapi.Warning, component: "Cloud Compute"},
+		{webhook: "validate.console.openshift.io", reason: monitorapi.AdmissionWebhookRejectedOnError, level: monitorapi.Error, component: "Management Console"},
+		{webhook: "e2e-test-webhook.example.com", reason: monitorapi.AdmissionWebhookRejectedOnError, level: monitorapi.Error, component: "Unknown"},
+		{webhook: "validate.console.openshift.io", reason: monitorapi.AdmissionWebhookTimeout, level: monitorapi.Error, component: "Management Console"},
+	}
+	if len(intervals) != len(expected) {
+		t.Fatalf("expected %d intervals, got %d: %v", len(expected), len(intervals), intervals)
+	}
+	for i, want := range expected {
+		got := intervals[i]
+		if got.Locator.Keys[monitorapi.LocatorAdmissionWebhookKey] != want.webhook || got.Message.Reason != want.reason ||
+			got.Level != want.level || got.Message.Annotations[monitorapi.AnnotationComponent] != want.component {
+			t.Errorf("interval %d: expected %+v, got %v", i, want, got)
+		}
+		if duration := got.To.Sub(got.From); duration <= 0 {
+			t.Errorf("interval %d: expected duration to be positive, got: %s", i, duration)
+		}
+	}
+
+	junits, err := test.EvaluateTestsFromConstructedIntervals(context.Background(), intervals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	failures := map[string]string{}
+	for _, junit := range junits {
+		if junit.FailureOutput != nil {
+			failures[junit.Name] = junit.FailureOutput.Output
+		}
+	}
+	if len(junits) != 4 || len(failures) != 2 {
+		t.Fatalf("expected both tests to flake, got %d junits and failures %v", len(junits), failures)
+	}
+	if output := failures[rejectionTestName]; !strings.Contains(output, "Management Console") || strings.Contains(output, "e2e-test-webhook") {
+		t.Errorf("expected only the platform webhook to be reported, got %s", output)
+	}
+	if output := failures[timeoutTestName]; !strings.Contains(output, "Cloud Compute") || !strings.Contains(output, "Management Console") {
+		t.Errorf("expected both platform webhooks to be reported, got %s", output)
+	}
+
+	storageDir := t.TempDir()
+	if err := test.WriteContentToStorage(context.Background(), storageDir, "20240813", intervals, nil); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(filepath.Join(storageDir, "admission-webhooks_20240813.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	summaries := []webhookSummary{}
+	if err := json.Unmarshal(content, &summaries); err != nil {
+		t.Fatal(err)
+	}
+	if len(summaries) != 3 {
+		t.Fatalf("expected a summary for every webhook, got %v", summaries)
+	}
+	for _, summary := range summaries {
+		if summary.Name != "validate.console.openshift.io" {
+			continue
+		}
+		if summary.MaxP99LatencySeconds != 12.1 || summary.RejectedOnError != 9 {
+			t.Errorf("unexpected summary %+v", summary)
+		}
+	}
+}
+
+func TestTimeoutBucket(t *testing.T) {
+	for timeout, expected := range map[int32]float64{1: 1, 2: 1, 3: 2.5, 10: 10, 15: 10, 25: 25, 30: 25} {
+		if actual := timeoutBucket(timeout); actual != expected {
+			t.Errorf("timeout %d: expected %v, got %v", timeout, expected, actual)
+		}
+	}
+}
+
+type byteQuery []byte
+
+func (q byteQuery) RunQuery(ctx context.Context, start, end time.Time) (prometheustypes.Value, error) {
+	var matrix prometheustypes.Matrix
+	if err := json.Unmarshal([]byte(q), &matrix); err != nil {
+		return nil, err
+	}
+	return matrix, nil
+}
@@ -0,0 +1,466 @@
+package auditloganalyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openshift/origin/pkg/dataloader"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+)
+
+// latencyBuckets are the upper bounds, in seconds, of the latency histograms.  They match the buckets of the
+// kube-apiserver request duration metric so the two can be compared.
+var latencyBuckets = []float64{0.005, 0.025, 0.05, 0.1, 0.2, 0.4, 0.6, 0.8, 1, 1.25, 1.5, 2, 3, 4, 5, 6, 8, 10, 15, 20, 30, 45, 60}
+
+// longRunningSubresources hold the connection open for as long as the client wants, so their latency is meaningless.
+var longRunningSubresources = sets.NewString("exec", "attach", "portforward", "log", "proxy")
+
+var mutatingVerbs = sets.NewString("create", "update", "patch", "delete", "deletecollection", "apply")
+
+const (
+	// numberOfSlowestRequests is how many of the slowest requests are kept as examples.
+	numberOfSlowestRequests = 20
+
+	// errorRateWindow is how long requests are grouped for when checking error rates.  Any shorter and a handful of
+	// failures in a quiet second exceed the threshold.
+	errorRateWindow = time.Minute
+	// minimumRequestsForErrorRate keeps quiet windows from being reported.
+	minimumRequestsForErrorRate = 100
+	max5xxPercentage            = 1.0
+	max429Percentage            = 5.0
+)
+
+// latencySLO is an objective for the fraction of matching requests that finish within a target.  They follow the
+// upstream kubernetes API call latency SLOs.
+type latencySLO struct {
+	name      string
+	objective float64
+	target    time.Duration
+	matches   func(auditEvent *auditv1.Event) bool
+}
+
+var latencySLOs = []latencySLO{
+	{
+		name:      "mutating requests",
+		objective: 99,
+		target:    1 * time.Second,
+		matches: func(auditEvent *auditv1.Event) bool {
+			return mutatingVerbs.Has(auditEvent.Verb)
+		},
+	},
+	{
+		name:      "get requests",
+		objective: 99,
+		target:    1 * time.Second,
+		matches: func(auditEvent *auditv1.Event) bool {
+			return auditEvent.Verb == "get"
+		},
+	},
+	{
+		name:      "namespaced list requests",
+		objective: 99,
+		target:    5 * time.Second,
+		matches: func(auditEvent *auditv1.Event) bool {
+			return auditEvent.Verb == "list" && auditEvent.ObjectRef != nil && len(auditEvent.ObjectRef.Namespace) > 0
+		},
+	},
+	{
+		name:      "cluster-scoped list requests",
+		objective: 99,
+		target:    30 * time.Second,
+		matches: func(auditEvent *auditv1.Event) bool {
+			return auditEvent.Verb == "list" && (auditEvent.ObjectRef == nil || len(auditEvent.ObjectRef.Namespace) == 0)
+		},
+	},
+}
+
+func (s latencySLO) testName() string {
+	return fmt.Sprintf("[Jira:\"kube-apiserver\"] API SLO: %v%% of %s should complete within %v", s.objective, s.name, s.target)
+}
+
+type latencyKey struct {
+	Verb      string
+	Resource  string
+	UserAgent string
+}
+
+// latencyHistogram counts requests in latencyBuckets, with the last count for requests slower than every bucket.
+type latencyHistogram struct {
+	counts []int
+	total  int
+}
+
+func newLatencyHistogram() *latencyHistogram {
+	return &latencyHistogram{counts: make([]int, len(latencyBuckets)+1)}
+}
+
+func (h *latencyHistogram) observe(latency time.Duration) {
+	h.counts[sort.SearchFloat64s(latencyBuckets, latency.Seconds())]++
+	h.total++
+}
+
+// quantile returns the upper bound of the bucket holding the quantile, or +Inf if it is slower than every bucket.
+func (h *latencyHistogram) quantile(q float64) float64 {
+	rank := int(math.Ceil(q * float64(h.total)))
+	seen := 0
+	for i, count := range h.counts {
+		seen += count
+		if seen >= rank {
+			if i < len(latencyBuckets) {
+				return latencyBuckets[i]
+			}
+			break
+		}
+	}
+	return math.Inf(1)
+}
+
+type sloCounts struct {
+	total        int
+	withinTarget int
+	// slowest are the slowest requests that missed the target, sorted slowest first.
+	slowest []slowRequest
+}
+
+func (c sloCounts) percentage() float64 {
+	if c.total == 0 {
+		return 100
+	}
+	return float64(c.withinTarget) / float64(c.total) * 100
+}
+
+type windowCounts struct {
+	total     int
+	number5xx int
+	number429 int
+}
+
+type slowRequest struct {
+	AuditID        string    `json:"auditID"`
+	Verb           string    `json:"verb"`
+	RequestURI     string    `json:"requestURI"`
+	User           string    `json:"user"`
+	UserAgent      string    `json:"userAgent"`
+	ResponseCode   int32     `json:"responseCode"`
+	Received       time.Time `json:"received"`
+	LatencySeconds float64   `json:"latencySeconds"`
+}
+
+// requestLatency measures how long requests took from being received until the response completed, and how often
+// they failed with 5xx or 429.
+type requestLatency struct {
+	lock sync.Mutex
+
+	histograms map[latencyKey]*latencyHistogram
+	slos       []sloCounts
+	windows    map[time.Time]*windowCounts
+	// slowest is sorted slowest first.
+	slowest []slowRequest
+}
+
+func CheckRequestLatency() *requestLatency {
+	return &requestLatency{
+		histograms: map[latencyKey]*latencyHistogram{},
+		slos:       make([]sloCounts, len(latencySLOs)),
+		windows:    map[time.Time]*windowCounts{},
+	}
+}
+
+func (l *requestLatency) HandleAuditLogEvent(auditEvent *auditv1.Event, beginning, end *metav1.MicroTime) {
+	if beginning != nil && auditEvent.RequestReceivedTimestamp.Before(beginning) || end != nil && end.Before(&auditEvent.RequestReceivedTimestamp) {
+		return
+	}
+	if auditEvent.Stage != auditv1.StageResponseComplete || auditEvent.Verb == "watch" {
+		return
+	}
+	if auditEvent.ObjectRef != nil && longRunningSubresources.Has(auditEvent.ObjectRef.Subresource) {
+		return
+	}
+	latency := auditEvent.StageTimestamp.Sub(auditEvent.RequestReceivedTimestamp.Time)
+	var code int32
+	if auditEvent.ResponseStatus != nil {
+		code = auditEvent.ResponseStatus.Code
+	}
+	request := slowRequest{
+		AuditID:        string(auditEvent.AuditID),
+		Verb:           auditEvent.Verb,
+		RequestURI:     auditEvent.RequestURI,
+		User:           auditEvent.User.Username,
+		UserAgent:      auditEvent.UserAgent,
+		ResponseCode:   code,
+		Received:       auditEvent.RequestReceivedTimestamp.Time,
+		LatencySeconds: latency.Seconds(),
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	key := latencyKey{
+		Verb:      auditEvent.Verb,
+		Resource:  resourceForLatency(auditEvent.ObjectRef),
+		UserAgent: userAgentProduct(auditEvent.UserAgent),
+	}
+	histogram, ok := l.histograms[key]
+	if !ok {
+		histogram = newLatencyHistogram()
+		l.histograms[key] = histogram
+	}
+	histogram.observe(latency)
+
+	for i, slo := range latencySLOs {
+		if !slo.matches(auditEvent) {
+			continue
+		}
+		l.slos[i].total++
+		if latency <= slo.target {
+			l.slos[i].withinTarget++
+		} else {
+			l.slos[i].slowest = addSlowRequest(l.slos[i].slowest, request)
+		}
+	}
+
+	window := auditEvent.RequestReceivedTimestamp.Time.Truncate(errorRateWindow)
+	counts, ok := l.windows[window]
+	if !ok {
+		counts = &windowCounts{}
+		l.windows[window] = counts
+	}
+	counts.total++
+	switch {
+	case code >= 500:
+		counts.number5xx++
+	case code == 429:
+		counts.number429++
+	}
+
+	l.slowest = addSlowRequest(l.slowest, request)
+}
+
+// addSlowRequest keeps the numberOfSlowestRequests slowest requests, sorted slowest first.
+func addSlowRequest(slowest []slowRequest, request slowRequest) []slowRequest {
+	if len(slowest) == numberOfSlowestRequests && request.LatencySeconds <= slowest[len(slowest)-1].LatencySeconds {
+		return slowest
+	}
+	slowest = append(slowest, request)
+	sort.SliceStable(slowest, func(i, j int) bool {
+		return slowest[i].LatencySeconds > slowest[j].LatencySeconds
+	})
+	if len(slowest) > numberOfSlowestRequests {
+		slowest = slowest[:numberOfSlowestRequests]
+	}
+	return slowest
+}
+
+func resourceForLatency(obj *auditv1.ObjectReference) string {
+	if obj == nil {
+		return "nonresource"
+	}
+	resource := obj.Resource
+	if len(obj.APIGroup) > 0 {
+		resource = resource + "." + obj.APIGroup
+	}
+	if len(obj.Subresource) > 0 {
+		resource = resource + "/" + obj.Subresource
+	}
+	return resource
+}
+
+// userAgentProduct drops the version and platform, so "kube-controller-manager/v1.29.0 (linux/amd64) kubernetes/abcdef"
+// becomes "kube-controller-manager".
+func userAgentProduct(userAgent string) string {
+	product := strings.TrimSpace(strings.SplitN(userAgent, "/", 2)[0])
+	if len(product) == 0 {
+		return "unknown"
+	}
+	return product
+}
+
+// ErrorRateIntervals creates an interval for every run of windows where 5xx or 429 responses were more than their
+// allowed percentage of requests.
+func (l *requestLatency) ErrorRateIntervals() monitorapi.Intervals {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	windows := []time.Time{}
+	for window := range l.windows {
+		windows = append(windows, window)
+	}
+	sort.Slice(windows, func(i, j int) bool { return windows[i].Before(windows[j]) })
+
+	ret := monitorapi.Intervals{}
+	ret = append(ret, l.errorRateIntervals(windows, monitorapi.ReasonKubeAPIServer5xx, monitorapi.Error, "5xx", max5xxPercentage,
+		func(c *windowCounts) int { return c.number5xx })...)
+	ret = append(ret, l.errorRateIntervals(windows, monitorapi.ReasonKubeAPIServer429s, monitorapi.Warning, "429", max429Percentage,
+		func(c *windowCounts) int { return c.number429 })...)
+	return ret
+}
+
+func (l *requestLatency) errorRateIntervals(windows []time.Time, reason monitorapi.IntervalReason, level monitorapi.IntervalLevel, description string, maxPercentage float64, failuresFor func(*windowCounts) int) monitorapi.Intervals {
+	ret := monitorapi.Intervals{}
+	var from, to time.Time
+	failures, total := 0, 0
+	closeInterval := func() {
+		if from.IsZero() {
+			return
+		}
+		percentage := float64(failures) / float64(total) * 100
+		ret = append(ret,
+			monitorapi.NewInterval(monitorapi.SourceAuditLog, level).
+				Locator(monitorapi.NewLocator().KubeAPIServerWithLB("any")).
+				Message(monitorapi.NewMessage().
+					Reason(reason).
+					WithAnnotation(monitorapi.AnnotationCount, strconv.Itoa(failures)).
+					WithAnnotation(monitorapi.AnnotationPercentage, strconv.Itoa(int(percentage))).
+					HumanMessagef("%d of %d requests (%.1f%%) got %s responses, more than %v%%", failures, total, percentage, description, maxPercentage)).
+				Display().
+				Build(from, to))
+		from, to = time.Time{}, time.Time{}
+		failures, total = 0, 0
+	}
+
+	for _, window := range windows {
+		counts := l.windows[window]
+		windowFailures := failuresFor(counts)
+		overThreshold := counts.total >= minimumRequestsForErrorRate && float64(windowFailures)/float64(counts.total)*100 > maxPercentage
+		if !overThreshold || (!to.IsZero() && window.After(to)) {
+			closeInterval()
+		}
+		if !overThreshold {
+			continue
+		}
+		if from.IsZero() {
+			from = window
+		}
+		to = window.Add(errorRateWindow)
+		failures += windowFailures
+		total += counts.total
+	}
+	closeInterval()
+	return ret
+}
+
+// CreateJunits produces a junit for each latency SLO.  The SLOs are new, so missing them only flakes.
+func (l *requestLatency) CreateJunits() []*junitapi.JUnitTestCase {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	ret := []*junitapi.JUnitTestCase{}
+	for i, slo := range latencySLOs {
+		counts := l.slos[i]
+		if counts.percentage() >= slo.objective {
+			ret = append(ret, &junitapi.JUnitTestCase{Name: slo.testName()})
+			continue
+		}
+		slowest := []string{}
+		for _, request := range counts.slowest {
+			slowest = append(slowest, fmt.Sprintf("%.1fs %s %s user=%s auditID=%s", request.LatencySeconds, request.Verb, request.RequestURI, request.User, request.AuditID))
+		}
+		ret = append(ret,
+			&junitapi.JUnitTestCase{
+				Name: slo.testName(),
+				FailureOutput: &junitapi.FailureOutput{
+					Message: fmt.Sprintf("%.2f%% of %d %s completed within %v", counts.percentage(), counts.total, slo.name, slo.target),
+					Output:  fmt.Sprintf("slowest %s over %v:\n%s", slo.name, slo.target, strings.Join(slowest, "\n")),
+				},
+			},
+			// flake for now
+			&junitapi.JUnitTestCase{Name: slo.testName()},
+		)
+	}
+	return ret
+}
+
+// WriteContentToStorage writes the latency distributions and SLO report for the data loader, and the slowest requests
+// for people.
+func (l *requestLatency) WriteContentToStorage(artifactDir, timeSuffix string) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	latencyRows := []map[string]string{}
+	for key, histogram := range l.histograms {
+		latencyRows = append(latencyRows, map[string]string{
+			"Verb":         key.Verb,
+			"Resource":     key.Resource,
+			"UserAgent":    key.UserAgent,
+			"RequestCount": strconv.Itoa(histogram.total),
+			"P50Seconds":   formatBucket(histogram.quantile(0.50)),
+			"P95Seconds":   formatBucket(histogram.quantile(0.95)),
+			"P99Seconds":   formatBucket(histogram.quantile(0.99)),
+		})
+	}
+	latencyFile := dataloader.DataFile{
+		TableName: "audit_request_latency",
+		Schema: map[string]dataloader.DataType{
+			"Verb":         dataloader.DataTypeString,
+			"Resource":     dataloader.DataTypeString,
+			"UserAgent":    dataloader.DataTypeString,
+			"RequestCount": dataloader.DataTypeInteger,
+			"P50Seconds":   dataloader.DataTypeFloat64,
+			"P95Seconds":   dataloader.DataTypeFloat64,
+			"P99Seconds":   dataloader.DataTypeFloat64,
+		},
+		Rows: latencyRows,
+	}
+	fileName := filepath.Join(artifactDir, fmt.Sprintf("audit-request-latency%s-%s", timeSuffix, dataloader.AutoDataLoaderSuffix))
+	if err := dataloader.WriteDataFile(fileName, latencyFile); err != nil {
+		return err
+	}
+
+	sloRows := []map[string]string{}
+	for i, slo := range latencySLOs {
+		sloRows = append(sloRows, map[string]string{
+			"SLO":                 slo.name,
+			"ObjectivePercentage": strconv.FormatFloat(slo.objective, 'f', -1, 64),
+			"TargetSeconds":       strconv.FormatFloat(slo.target.Seconds(), 'f', -1, 64),
+			"RequestCount":        strconv.Itoa(l.slos[i].total),
+			"WithinTargetCount":   strconv.Itoa(l.slos[i].withinTarget),
+			"Percentage":          strconv.FormatFloat(l.slos[i].percentage(), 'f', 3, 64),
+		})
+	}
+	sloFile := dataloader.DataFile{
+		TableName: "audit_request_latency_slo",
+		Schema: map[string]dataloader.DataType{
+			"SLO":                 dataloader.DataTypeString,
+			"ObjectivePercentage": dataloader.DataTypeFloat64,
+			"TargetSeconds":       dataloader.DataTypeFloat64,
+			"RequestCount":        dataloader.DataTypeInteger,
+			"WithinTargetCount":   dataloader.DataTypeInteger,
+			"Percentage":          dataloader.DataTypeFloat64,
+		},
+		Rows: sloRows,
+	}
+	fileName = filepath.Join(artifactDir, fmt.Sprintf("audit-request-latency-slo%s-%s", timeSuffix, dataloader.AutoDataLoaderSuffix))
+	if err := dataloader.WriteDataFile(fileName, sloFile); err != nil {
+		return err
+	}
+
+	slowestBytes, err := json.MarshalIndent(l.slowest, "", "    ")
+	if err != nil {
+		return err
+	}
+	slowestPath := filepath.Join(artifactDir, fmt.Sprintf("audit-slowest-requests_%s.json", timeSuffix))
+	if err := os.WriteFile(slowestPath, slowestBytes, 0644); err != nil {
+		return fmt.Errorf("failed to write %v: %w", slowestPath, err)
+	}
+	return nil
+}
+
+// formatBucket writes requests slower than every bucket as the largest bucket, since the data loader needs a number.
+func formatBucket(seconds float64) string {
+	if math.IsInf(seconds, 1) {
+		seconds = latencyBuckets[len(latencyBuckets)-1]
+	}
+	return strconv.FormatFloat(seconds, 'f', -1, 64)
+}
@@ -0,0 +1,104 @@
+package auditloganalyzer
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+)
+
+func TestRequestLatency(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	i := 0
+	newEvent := func(verb, namespace, subresource string, received time.Time, latency time.Duration, code int32) *auditv1.Event {
+		i++
+		return &auditv1.Event{
+			AuditID:                  types.UID(fmt.Sprintf("audit-%d", i)),
+			Stage:                    auditv1.StageResponseComplete,
+			Verb:                     verb,
+			UserAgent:                "kube-controller-manager/v1.29.0 (linux/amd64) kubernetes/abcdef",
+			ObjectRef:                &auditv1.ObjectReference{Resource: "pods", Namespace: namespace, Subresource: subresource},
+			ResponseStatus:           &metav1.Status{Code: code},
+			RequestReceivedTimestamp: metav1.NewMicroTime(received),
+			StageTimestamp:           metav1.NewMicroTime(received.Add(latency)),
+		}
+	}
+
+	checker := CheckRequestLatency()
+	// fast creates, with two slow enough to miss the mutating SLO
+	for j := 0; j < 98; j++ {
+		checker.HandleAuditLogEvent(newEvent("create", "ns", "", start, 10*time.Millisecond, 201), nil, nil)
+	}
+	checker.HandleAuditLogEvent(newEvent("create", "ns", "", start, 2*time.Second, 201), nil, nil)
+	checker.HandleAuditLogEvent(newEvent("update", "ns", "", start, 3*time.Second, 200), nil, nil)
+	// a cluster-scoped list slower than any create, which must not be reported against the mutating SLO
+	checker.HandleAuditLogEvent(newEvent("list", "", "", start, 40*time.Second, 200), nil, nil)
+	// long running requests are ignored
+	checker.HandleAuditLogEvent(newEvent("watch", "ns", "", start, time.Hour, 200), nil, nil)
+	checker.HandleAuditLogEvent(newEvent("create", "ns", "exec", start, time.Hour, 101), nil, nil)
+
+	// a minute of lists where 5% fail, then a minute where none do, then another minute where 5% fail
+	for minute, failing := range []int{5, 0, 5} {
+		received := start.Add(time.Duration(minute+1) * time.Minute)
+		for j := 0; j < 100; j++ {
+			code := int32(200)
+			if j < failing {
+				code = 503
+			}
+			checker.HandleAuditLogEvent(newEvent("list", "ns", "", received, 100*time.Millisecond, code), nil, nil)
+		}
+	}
+
+	results := map[string][]bool{}
+	failureOutputs := map[string]string{}
+	for _, junit := range checker.CreateJunits() {
+		results[junit.Name] = append(results[junit.Name], junit.FailureOutput == nil)
+		if junit.FailureOutput != nil {
+			failureOutputs[junit.Name] = junit.FailureOutput.Output
+		}
+	}
+	if mutating := results[latencySLOs[0].testName()]; len(mutating) != 2 || mutating[0] || !mutating[1] {
+		t.Errorf("expected the mutating SLO to flake, got %v", mutating)
+	}
+	if list := results[latencySLOs[2].testName()]; len(list) != 1 || !list[0] {
+		t.Errorf("expected the namespaced list SLO to pass, got %v", list)
+	}
+	// each SLO lists only its own requests that missed the target
+	expectedMutating := "slowest mutating requests over 1s:\n3.0s update  user= auditID=audit-100\n2.0s create  user= auditID=audit-99"
+	if output := failureOutputs[latencySLOs[0].testName()]; output != expectedMutating {
+		t.Errorf("expected the mutating SLO to list the slow create and update, got %q", output)
+	}
+	expectedClusterList := "slowest cluster-scoped list requests over 30s:\n40.0s list  user= auditID=audit-101"
+	if output := failureOutputs[latencySLOs[3].testName()]; output != expectedClusterList {
+		t.Errorf("expected the cluster-scoped list SLO to list the slow list, got %q", output)
+	}
+
+	intervals := checker.ErrorRateIntervals()
+	if len(intervals) != 2 {
+		t.Fatalf("expected two 5xx intervals, got %v", intervals)
+	}
+	for minute, interval := range intervals {
+		if interval.Message.Reason != monitorapi.ReasonKubeAPIServer5xx || interval.To.Sub(interval.From) != time.Minute {
+			t.Errorf("unexpected interval %d: %v", minute, interval)
+		}
+	}
+
+	key := latencyKey{Verb: "create", Resource: "pods", UserAgent: "kube-controller-manager"}
+	histogram := checker.histograms[key]
+	if histogram == nil || histogram.total != 99 {
+		t.Fatalf("expected 99 creates, got %v", histogram)
+	}
+	if p50, p95 := histogram.quantile(0.5), histogram.quantile(0.95); p50 != 0.025 || p95 != 0.025 {
+		t.Errorf("expected p50 and p95 of 25ms, got %v and %v", p50, p95)
+	}
+	if max := histogram.quantile(1); max != 2 {
+		t.Errorf("expected max of 2s, got %v", max)
+	}
+	if len(checker.slowest) != numberOfSlowestRequests || checker.slowest[0].LatencySeconds != 40 {
+		t.Errorf("expected the cluster-scoped list to be the slowest request, got %v", checker.slowest)
+	}
+}
@@ -0,0 +1,139 @@
+package auditloganalyzer
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// AuditLogFile is an audit log found on disk and the apiserver that wrote it.
+type AuditLogFile struct {
+	Path string
+	// APIServer is the closest parent directory named after an apiserver, empty when there is none.
+	APIServer string
+}
+
+// FindAuditLogFiles finds audit logs under dir, for instance the audit_logs directory of a must-gather.
+// Both the plain files written by the apiservers and gzipped copies are found.  Files in a directory named
+// after an apiserver are only returned when that apiserver is requested, files outside of one are always returned.
+func FindAuditLogFiles(dir string, apiservers []string) ([]AuditLogFile, error) {
+	requested := sets.NewString(apiservers...)
+	known := sets.NewString(AllAPIServers...)
+
+	ret := []AuditLogFile{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		// must-gather prefixes the node name: <node>-audit-2024-05-01T12-00-00.000.log.gz
+		name := d.Name()
+		if !strings.Contains(name, "audit") {
+			return nil
+		}
+		if !strings.HasSuffix(name, ".log") && !strings.HasSuffix(name, ".log.gz") {
+			return nil
+		}
+
+		apiserver := ""
+		// dir itself is included, so that pointing at .../audit_logs/kube-apiserver works.
+		parents := strings.Split(filepath.ToSlash(filepath.Dir(path)), "/")
+		for i := len(parents) - 1; i >= 0; i-- {
+			if known.Has(parents[i]) {
+				apiserver = parents[i]
+				break
+			}
+		}
+		if len(apiserver) > 0 && !requested.Has(apiserver) {
+			return nil
+		}
+
+		ret = append(ret, AuditLogFile{Path: path, APIServer: apiserver})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// GetAuditLogSummaryFromDirectory runs the handlers over every audit log found by FindAuditLogFiles.  This allows
+// analyzing a must-gather or logs copied off of the nodes after the cluster is gone.
+func GetAuditLogSummaryFromDirectory(dir string, apiservers []string, beginning, end *time.Time, auditLogHandlers []AuditEventHandler) error {
+	auditLogFiles, err := FindAuditLogFiles(dir, apiservers)
+	if err != nil {
+		return err
+	}
+	if len(auditLogFiles) == 0 {
+		return fmt.Errorf("no audit logs for %v found in %q", apiservers, dir)
+	}
+
+	var microBeginning, microEnd *metav1.MicroTime
+	if nil != beginning {
+		micro := metav1.NewMicroTime(*beginning)
+		microBeginning = &micro
+	}
+	if nil != end {
+		micro := metav1.NewMicroTime(*end)
+		microEnd = &micro
+	}
+
+	wg := sync.WaitGroup{}
+	errCh := make(chan error, len(auditLogFiles))
+	for _, auditLogFile := range auditLogFiles {
+		wg.Add(1)
+		go func(auditLogFile AuditLogFile) {
+			defer wg.Done()
+			if err := handleAuditLogFile(auditLogFile.Path, microBeginning, microEnd, auditLogHandlers); err != nil {
+				errCh <- fmt.Errorf("failed to read %q: %w", auditLogFile.Path, err)
+			}
+		}(auditLogFile)
+	}
+	wg.Wait()
+	close(errCh)
+
+	errs := []error{}
+	for err := range errCh {
+		errs = append(errs, err)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func handleAuditLogFile(path string, beginning, end *metav1.MicroTime, auditLogHandlers []AuditEventHandler) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// decide by content instead of name, copies are not always named consistently.
+	reader := bufio.NewReader(file)
+	magic, err := reader.Peek(2)
+	if err != nil && err != io.EOF {
+		return err
+	}
+	var auditStream io.Reader = reader
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gzipReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return err
+		}
+		defer gzipReader.Close()
+		auditStream = gzipReader
+	}
+
+	return handleAuditLogStream(filepath.Base(path), auditStream, beginning, end, auditLogHandlers)
+}
@@ -0,0 +1,201 @@
+package auditloganalyzer
+
+import (
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
+)
+
+const auditLine = `{"kind":"Event","apiVersion":"audit.k8s.io/v1","level":"Metadata","auditID":"a","stage":"ResponseComplete","requestURI":"/api/v1/namespaces/openshift-etcd/configmaps","verb":"list","user":{"username":"system:admin"},"objectRef":{"resource":"configmaps","namespace":"openshift-etcd","apiVersion":"v1"},"responseStatus":{"code":200},"requestReceivedTimestamp":"2024-05-01T12:00:00.000000Z","stageTimestamp":"2024-05-01T12:00:00.100000Z"}
+`
+
+func writeAuditLog(t *testing.T, path string, gzipped bool) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if !gzipped {
+		if _, err := file.WriteString(auditLine); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	gzipWriter := gzip.NewWriter(file)
+	if _, err := gzipWriter.Write([]byte(auditLine)); err != nil {
+		t.Fatal(err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// mustGather lays out audit logs the way must-gather does.
+func mustGather(t *testing.T) string {
+	dir := t.TempDir()
+	writeAuditLog(t, filepath.Join(dir, "audit_logs", "kube-apiserver", "master-0-audit-2024-05-01T12-00-00.000.log.gz"), true)
+	writeAuditLog(t, filepath.Join(dir, "audit_logs", "kube-apiserver", "master-0-audit.log"), false)
+	writeAuditLog(t, filepath.Join(dir, "audit_logs", "openshift-apiserver", "master-0-audit.log.gz"), true)
+	writeAuditLog(t, filepath.Join(dir, "audit_logs", "oauth-apiserver", "master-0-audit.log.gz"), true)
+	writeAuditLog(t, filepath.Join(dir, "audit_logs", "kube-apiserver", "master-0-kube-apiserver.log"), false)
+	return dir
+}
+
+func TestFindAuditLogFiles(t *testing.T) {
+	dir := mustGather(t)
+
+	tests := []struct {
+		name       string
+		dir        string
+		apiservers []string
+		want       []string
+	}{
+		{
+			name:       "kube-apiserver",
+			dir:        dir,
+			apiservers: []string{"kube-apiserver"},
+			want:       []string{"kube-apiserver", "kube-apiserver"},
+		},
+		{
+			name:       "all",
+			dir:        dir,
+			apiservers: AllAPIServers,
+			want:       []string{"kube-apiserver", "kube-apiserver", "oauth-apiserver", "openshift-apiserver"},
+		},
+		{
+			name:       "pointing at an apiserver directory",
+			dir:        filepath.Join(dir, "audit_logs", "openshift-apiserver"),
+			apiservers: []string{"openshift-apiserver"},
+			want:       []string{"openshift-apiserver"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := FindAuditLogFiles(tt.dir, tt.apiservers)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []string{}
+			for _, file := range files {
+				got = append(got, file.APIServer)
+			}
+			sort.Strings(got)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnalyzeAuditLogDirectory(t *testing.T) {
+	dir := mustGather(t)
+	artifactDir := t.TempDir()
+
+	intervals, junits, err := AnalyzeAuditLogDirectory(dir, AllAPIServers, nil, nil, artifactDir, "offline")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(intervals) != 0 {
+		t.Errorf("expected no error rate intervals, got %v", intervals)
+	}
+	for _, junit := range junits {
+		if junit.FailureOutput != nil {
+			t.Errorf("unexpected failure %q: %v", junit.Name, junit.FailureOutput.Message)
+		}
+		if junit.Name == kubeAPIServer500sTestName {
+			t.Errorf("internal errors cannot be evaluated offline")
+		}
+	}
+	appliesTest := "users in ns/openshift-etcd must not produce too many applies"
+	found := false
+	for _, junit := range junits {
+		found = found || junit.Name == appliesTest
+	}
+	if !found {
+		t.Errorf("expected %q for the platform namespace in the audit log", appliesTest)
+	}
+
+	summary, err := os.ReadFile(filepath.Join(artifactDir, "audit-log-summary_offline.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// one request from each of the four audit logs
+	if !strings.Contains(string(summary), `"RequestFinishedCount": 4`) {
+		t.Errorf("expected four requests in the summary, got:\n%s", summary)
+	}
+}
+
+func TestAnalyzeAuditLogDirectoryErrorRate(t *testing.T) {
+	dir := t.TempDir()
+	lines := &strings.Builder{}
+	// 5 of 100 requests in a minute failed, more than the 1% of 5xx allowed.
+	for i := 0; i < 100; i++ {
+		code := "200"
+		if i < 5 {
+			code = "503"
+		}
+		line := strings.Replace(auditLine, `"auditID":"a"`, fmt.Sprintf(`"auditID":"a-%d"`, i), 1)
+		lines.WriteString(strings.Replace(line, `"code":200`, `"code":`+code, 1))
+	}
+	logPath := filepath.Join(dir, "audit_logs", "kube-apiserver", "master-0-audit.log")
+	if err := os.MkdirAll(filepath.Dir(logPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(logPath, []byte(lines.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	artifactDir := t.TempDir()
+
+	// the suffix carries its separator, the same as the monitor test's timeSuffix.
+	intervals, _, err := AnalyzeAuditLogDirectory(dir, AllAPIServers, nil, nil, artifactDir, "_offline")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(intervals) != 1 || intervals[0].Message.Reason != monitorapi.ReasonKubeAPIServer5xx {
+		t.Fatalf("expected one 5xx interval, got %v", intervals)
+	}
+	if count := intervals[0].Message.Annotations[monitorapi.AnnotationCount]; count != "5" {
+		t.Errorf("expected 5 failed requests, got %q", count)
+	}
+
+	written, err := monitorserialization.EventsFromFile(filepath.Join(artifactDir, "e2e-events_offline.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(written) != 1 || written[0].Message.Reason != monitorapi.ReasonKubeAPIServer5xx {
+		t.Errorf("expected the 5xx interval in the e2e-events file, got %v", written)
+	}
+}
+
+func TestHandleAuditLogStreamLongLines(t *testing.T) {
+	// a request body well past the 64KiB default of bufio.Scanner
+	longLine := strings.Replace(auditLine, `"level":"Metadata"`, `"level":"Request","requestObject":{"data":"`+strings.Repeat("x", 256*1024)+`"}`, 1)
+	stream := auditLine + longLine + auditLine
+
+	summarizer := NewAuditLogSummarizer()
+	if err := handleAuditLogStream("audit.log", strings.NewReader(stream), nil, nil, []AuditEventHandler{summarizer}); err != nil {
+		t.Fatal(err)
+	}
+	if count := summarizer.GetAuditLogSummary().requestCounts.requestFinishedCount; count != 3 {
+		t.Errorf("expected all 3 events to be read, got %d", count)
+	}
+
+	// an event larger than the limit stops the analysis, which must be reported
+	defer func(size int) { maxAuditLineSize = size }(maxAuditLineSize)
+	maxAuditLineSize = 128 * 1024
+	err := handleAuditLogStream("audit.log", strings.NewReader(stream), nil, nil, []AuditEventHandler{NewAuditLogSummarizer()})
+	if err == nil || !strings.Contains(err.Error(), `stopped reading "audit.log" after line 1`) {
+		t.Errorf("expected an error for the event over the limit, got %v", err)
+	}
+}
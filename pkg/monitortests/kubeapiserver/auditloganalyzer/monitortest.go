@@ -20,6 +20,8 @@ import (
 	"k8s.io/client-go/rest"
 )
 
+const kubeAPIServer500sTestName = "[Jira:kube-apiserver] kube-apiserver should not have internal failures"
+
 type auditLogAnalyzer struct {
 	adminRESTConfig *rest.Config
 
@@ -31,6 +33,7 @@ type auditLogAnalyzer struct {
 	invalidRequestsChecker        *invalidRequests
 	requestsDuringShutdownChecker *lateRequestTracking
 	violationChecker              *auditViolations
+	requestLatencyChecker         *requestLatency
 
 	countsForInstall *CountsForRun
 }
@@ -42,6 +45,7 @@ func NewAuditLogAnalyzer() monitortestframework.MonitorTest {
 		invalidRequestsChecker:        CheckForInvalidMutations(),
 		requestsDuringShutdownChecker: CheckForRequestsDuringShutdown(),
 		violationChecker:              CheckForViolations(),
+		requestLatencyChecker:         CheckRequestLatency(),
 	}
 }
 
@@ -79,18 +83,7 @@ func (w *auditLogAnalyzer) CollectData(ctx context.Context, storageDir string, b
 		return nil, nil, err
 	}
 
-	auditLogHandlers := []AuditEventHandler{
-		w.summarizer,
-		w.excessiveApplyChecker,
-		w.invalidRequestsChecker,
-		w.requestsDuringShutdownChecker,
-		w.violationChecker,
-	}
-	if w.requestCountTracking != nil {
-		auditLogHandlers = append(auditLogHandlers, w.requestCountTracking)
-	}
-
-	err = GetKubeAuditLogSummary(ctx, kubeClient, &beginning, &end, auditLogHandlers)
+	err = GetKubeAuditLogSummary(ctx, kubeClient, &beginning, &end, w.auditLogHandlers())
 
 	retIntervals := monitorapi.Intervals{}
 
@@ -150,17 +143,43 @@ func (w *auditLogAnalyzer) CollectData(ctx context.Context, storageDir string, b
 		}
 	}
 
+	retIntervals = append(retIntervals, w.requestLatencyChecker.ErrorRateIntervals()...)
+
 	return retIntervals, nil, err
 }
 
+func (w *auditLogAnalyzer) auditLogHandlers() []AuditEventHandler {
+	auditLogHandlers := []AuditEventHandler{
+		w.summarizer,
+		w.excessiveApplyChecker,
+		w.invalidRequestsChecker,
+		w.requestsDuringShutdownChecker,
+		w.violationChecker,
+		w.requestLatencyChecker,
+	}
+	if w.requestCountTracking != nil {
+		auditLogHandlers = append(auditLogHandlers, w.requestCountTracking)
+	}
+	return auditLogHandlers
+}
+
 func (*auditLogAnalyzer) ConstructComputedIntervals(ctx context.Context, startingIntervals monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, beginning, end time.Time) (monitorapi.Intervals, error) {
 	return nil, nil
 }
 
 func (w *auditLogAnalyzer) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
+	allPlatformNamespaces, err := watchnamespaces.GetAllPlatformNamespaces()
+	if err != nil {
+		return nil, fmt.Errorf("problem getting platform namespaces: %w", err)
+	}
+	return w.evaluateTests(finalIntervals, allPlatformNamespaces), nil
+}
+
+// evaluateTests is separate so that audit logs analyzed without a running cluster can supply the platform namespaces.
+func (w *auditLogAnalyzer) evaluateTests(finalIntervals monitorapi.Intervals, allPlatformNamespaces []string) []*junitapi.JUnitTestCase {
 	ret := []*junitapi.JUnitTestCase{}
 
-	fiveHundredsTestName := "[Jira:kube-apiserver] kube-apiserver should not have internal failures"
+	fiveHundredsTestName := kubeAPIServer500sTestName
 	apiserver500s := finalIntervals.Filter(func(eventInterval monitorapi.Interval) bool {
 		return eventInterval.Message.Reason == monitorapi.ReasonKubeAPIServer500s
 	})
@@ -188,11 +207,6 @@ func (w *auditLogAnalyzer) EvaluateTestsFromConstructedIntervals(ctx context.Con
 		})
 	}
 
-	allPlatformNamespaces, err := watchnamespaces.GetAllPlatformNamespaces()
-	if err != nil {
-		return nil, fmt.Errorf("problem getting platform namespaces: %w", err)
-	}
-
 	for _, namespace := range allPlatformNamespaces {
 		testName := fmt.Sprintf("users in ns/%s must not produce too many applies", namespace)
 		usersToApplies := w.excessiveApplyChecker.namespacesToUserToNumberOfApplies[namespace]
@@ -401,14 +415,18 @@ func (w *auditLogAnalyzer) EvaluateTestsFromConstructedIntervals(ctx context.Con
 	}
 
 	ret = append(ret, w.violationChecker.CreateJunits()...)
+	ret = append(ret, w.requestLatencyChecker.CreateJunits()...)
 
-	return ret, nil
+	return ret
 }
 
 func (w *auditLogAnalyzer) WriteContentToStorage(ctx context.Context, storageDir, timeSuffix string, finalIntervals monitorapi.Intervals, finalResourceState monitorapi.ResourcesMap) error {
 	if currErr := WriteAuditLogSummary(storageDir, timeSuffix, w.summarizer.auditLogSummary); currErr != nil {
 		return currErr
 	}
+	if currErr := w.requestLatencyChecker.WriteContentToStorage(storageDir, timeSuffix); currErr != nil {
+		return currErr
+	}
 
 	if w.requestCountTracking != nil {
 		err := w.requestCountTracking.CountsForRun.WriteContentToStorage(storageDir, "request-counts-by-second", timeSuffix)
@@ -6,6 +6,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -20,7 +21,15 @@ import (
 	"k8s.io/client-go/kubernetes"
 )
 
+// AllAPIServers are the apiservers that write audit logs on control plane nodes, named by their log directory.
+var AllAPIServers = []string{"kube-apiserver", "openshift-apiserver", "oauth-apiserver"}
+
 func GetKubeAuditLogSummary(ctx context.Context, kubeClient kubernetes.Interface, beginning, end *time.Time, auditLogHandlers []AuditEventHandler) error {
+	return GetAuditLogSummary(ctx, kubeClient, []string{"kube-apiserver"}, beginning, end, auditLogHandlers)
+}
+
+// GetAuditLogSummary streams the audit logs of the listed apiservers from every control plane node.
+func GetAuditLogSummary(ctx context.Context, kubeClient kubernetes.Interface, apiservers []string, beginning, end *time.Time, auditLogHandlers []AuditEventHandler) error {
 	masterOnly, err := labels.NewRequirement("node-role.kubernetes.io/master", selection.Exists, nil)
 	if err != nil {
 		panic(err)
@@ -49,10 +58,12 @@ func GetKubeAuditLogSummary(ctx context.Context, kubeClient kubernetes.Interface
 				micro := metav1.NewMicroTime(*end)
 				microEnd = &micro
 			}
-			err := getNodeKubeAuditLogSummary(ctx, kubeClient, nodeName, microBeginning, microEnd, auditLogHandlers)
-			if err != nil {
-				errCh <- err
-				return
+			for _, apiserver := range apiservers {
+				err := getAuditLogSummary(ctx, kubeClient, nodeName, apiserver, microBeginning, microEnd, auditLogHandlers)
+				if err != nil {
+					errCh <- err
+					return
+				}
 			}
 
 			lock.Lock()
@@ -74,9 +85,6 @@ type AuditEventHandler interface {
 	HandleAuditLogEvent(auditEvent *auditv1.Event, beginning, end *metav1.MicroTime)
 }
 
-func getNodeKubeAuditLogSummary(ctx context.Context, client kubernetes.Interface, nodeName string, beginning, end *metav1.MicroTime, auditLogHandlers []AuditEventHandler) error {
-	return getAuditLogSummary(ctx, client, nodeName, "kube-apiserver", beginning, end, auditLogHandlers)
-}
 func getAuditLogSummary(ctx context.Context, client kubernetes.Interface, nodeName, apiserver string, beginning, end *metav1.MicroTime, auditLogHandlers []AuditEventHandler) error {
 	auditLogFilenames, err := getAuditLogFilenames(ctx, client, nodeName, apiserver)
 	if err != nil {
@@ -108,27 +116,9 @@ func getAuditLogSummary(ctx context.Context, client kubernetes.Interface, nodeNa
 				return
 			}
 
-			scanner := bufio.NewScanner(auditStream)
-			line := 0
-			for scanner.Scan() {
-				line++
-				auditLine := scanner.Bytes()
-
-				if len(auditLine) == 0 {
-					continue
-				}
-
-				auditEvent := &auditv1.Event{}
-				if err := json.Unmarshal(auditLine, auditEvent); err != nil {
-					fmt.Printf("unable to decode %q line %d: %s to audit event: %v\n", auditLogFilename, line, string(auditLine), err)
-					continue
-				}
-
-				for _, auditLogHandler := range auditLogHandlers {
-					auditLogHandler.HandleAuditLogEvent(auditEvent, beginning, end)
-				}
+			if err := handleAuditLogStream(auditLogFilename, auditStream, beginning, end, auditLogHandlers); err != nil {
+				errCh <- fmt.Errorf("node/%s: %w", nodeName, err)
 			}
-
 		}(ctx, auditLogFilename)
 	}
 	wg.Wait()
@@ -155,3 +145,37 @@ func getAuditLogFilenames(ctx context.Context, client kubernetes.Interface, node
 
 	return filenames, nil
 }
+
+// maxAuditLineSize bounds a single audit event.  Events at the RequestResponse level carry whole request and response
+// bodies, so they are far larger than the default bufio.Scanner token.
+var maxAuditLineSize = 64 * 1024 * 1024
+
+// handleAuditLogStream decodes one audit event per line and hands each to every handler.  It returns the error that
+// stopped reading the stream, if any, so that a truncated analysis is not mistaken for a complete one.
+func handleAuditLogStream(auditLogFilename string, auditStream io.Reader, beginning, end *metav1.MicroTime, auditLogHandlers []AuditEventHandler) error {
+	scanner := bufio.NewScanner(auditStream)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxAuditLineSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		auditLine := scanner.Bytes()
+
+		if len(auditLine) == 0 {
+			continue
+		}
+
+		auditEvent := &auditv1.Event{}
+		if err := json.Unmarshal(auditLine, auditEvent); err != nil {
+			fmt.Printf("unable to decode %q line %d: %s to audit event: %v\n", auditLogFilename, line, string(auditLine), err)
+			continue
+		}
+
+		for _, auditLogHandler := range auditLogHandlers {
+			auditLogHandler.HandleAuditLogEvent(auditEvent, beginning, end)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("stopped reading %q after line %d: %w", auditLogFilename, line, err)
+	}
+	return nil
+}
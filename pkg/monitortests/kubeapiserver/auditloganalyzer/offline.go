@@ -0,0 +1,67 @@
+package auditloganalyzer
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+)
+
+// platformNamespaceCollector stands in for the namespace watch when there is no cluster to watch.  It records the
+// platform namespaces that requests were made in.
+type platformNamespaceCollector struct {
+	lock       sync.Mutex
+	namespaces sets.String
+}
+
+func (c *platformNamespaceCollector) HandleAuditLogEvent(auditEvent *auditv1.Event, beginning, end *metav1.MicroTime) {
+	if auditEvent.ObjectRef == nil || !platformidentification.IsPlatformNamespace(auditEvent.ObjectRef.Namespace) {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.namespaces.Insert(auditEvent.ObjectRef.Namespace)
+}
+
+// AnalyzeAuditLogDirectory runs every handler the monitor test uses over audit logs on disk and writes the same summary
+// artifacts to artifactDir, along with the intervals in an e2e-events file for the timeline.  It returns the intervals
+// and junits the monitor test would produce, except those that need the cluster itself: the count of internal errors
+// over time needs to know when the cluster was installed.
+func AnalyzeAuditLogDirectory(auditLogDir string, apiservers []string, beginning, end *time.Time, artifactDir, timeSuffix string) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
+	analyzer := NewAuditLogAnalyzer().(*auditLogAnalyzer)
+	namespaces := &platformNamespaceCollector{namespaces: sets.NewString()}
+
+	auditLogHandlers := append(analyzer.auditLogHandlers(), namespaces)
+	if err := GetAuditLogSummaryFromDirectory(auditLogDir, apiservers, beginning, end, auditLogHandlers); err != nil {
+		return nil, nil, err
+	}
+	if err := WriteAuditLogSummary(artifactDir, timeSuffix, analyzer.summarizer.GetAuditLogSummary()); err != nil {
+		return nil, nil, err
+	}
+	if err := analyzer.requestLatencyChecker.WriteContentToStorage(artifactDir, timeSuffix); err != nil {
+		return nil, nil, err
+	}
+
+	intervals := analyzer.requestLatencyChecker.ErrorRateIntervals()
+	if err := monitorserialization.EventsToFile(filepath.Join(artifactDir, fmt.Sprintf("e2e-events%s.json", timeSuffix)), intervals); err != nil {
+		return nil, nil, err
+	}
+
+	junits := []*junitapi.JUnitTestCase{}
+	for _, junit := range analyzer.evaluateTests(intervals, namespaces.namespaces.List()) {
+		if junit.Name == kubeAPIServer500sTestName {
+			continue
+		}
+		junits = append(junits, junit)
+	}
+	return intervals, junits, nil
+}
@@ -0,0 +1,159 @@
+package watchmachineconfigpools
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+
+	mcfgv1 "github.com/openshift/api/machineconfiguration/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func intervalsByLocator(intervals monitorapi.Intervals, reasons ...monitorapi.IntervalReason) map[string]monitorapi.Intervals {
+	ret := map[string]monitorapi.Intervals{}
+	for _, interval := range intervals {
+		for _, reason := range reasons {
+			if interval.Message.Reason != reason {
+				continue
+			}
+			key := interval.Locator.OldLocator()
+			ret[key] = append(ret[key], interval)
+		}
+	}
+	for _, locatorIntervals := range ret {
+		sort.SliceStable(locatorIntervals, func(i, j int) bool {
+			return locatorIntervals[i].From.Before(locatorIntervals[j].From)
+		})
+	}
+	return ret
+}
+
+// constructPoolConditionIntervals creates an interval for every period a pool condition was true.
+func constructPoolConditionIntervals(startingIntervals monitorapi.Intervals, end time.Time) monitorapi.Intervals {
+	constructedIntervals := monitorapi.Intervals{}
+
+	for _, poolChanges := range intervalsByLocator(startingIntervals, monitorapi.MachineConfigPoolConditionChanged) {
+		conditionToChanges := map[string]monitorapi.Intervals{}
+		for _, change := range poolChanges {
+			condition := change.Message.Annotations[monitorapi.AnnotationCondition]
+			conditionToChanges[condition] = append(conditionToChanges[condition], change)
+		}
+
+		for condition, changes := range conditionToChanges {
+			level := monitorapi.Error
+			if condition == string(mcfgv1.MachineConfigPoolUpdating) {
+				level = monitorapi.Info
+			}
+
+			var trueSince *monitorapi.Interval
+			closeInterval := func(to time.Time) {
+				constructedIntervals = append(constructedIntervals,
+					monitorapi.NewInterval(monitorapi.SourceMachineConfig, level).
+						Locator(trueSince.Locator).
+						Message(monitorapi.NewMessage().Reason(monitorapi.MachineConfigPoolCondition).
+							Constructed(monitorapi.ConstructionOwnerMachineConfigPoolLifecycle).
+							WithAnnotation(monitorapi.AnnotationCondition, condition).
+							HumanMessage(fmt.Sprintf("pool is %s, starting with %s", condition, trueSince.Message.HumanMessage))).
+						Display().
+						Build(trueSince.From, to),
+				)
+			}
+			for i := range changes {
+				isTrue := changes[i].Message.Annotations[monitorapi.AnnotationStatus] == string(corev1.ConditionTrue)
+				switch {
+				case isTrue && trueSince == nil:
+					trueSince = &changes[i]
+				case !isTrue && trueSince != nil:
+					closeInterval(changes[i].From)
+					trueSince = nil
+				}
+			}
+			if trueSince != nil {
+				closeInterval(end)
+			}
+		}
+	}
+
+	return constructedIntervals
+}
+
+// constructNodeStateIntervals creates an interval for every period the machine-config-daemon was not done with a node.
+func constructNodeStateIntervals(startingIntervals monitorapi.Intervals, end time.Time) monitorapi.Intervals {
+	constructedIntervals := monitorapi.Intervals{}
+
+	for _, changes := range intervalsByLocator(startingIntervals, monitorapi.MachineConfigNodeStateChanged) {
+		for i, change := range changes {
+			state := change.Message.Annotations[monitorapi.AnnotationState]
+			if state == machineConfigDaemonStateDone {
+				continue
+			}
+			to := end
+			if i+1 < len(changes) {
+				to = changes[i+1].From
+			}
+			level := monitorapi.Error
+			if state == machineConfigDaemonStateWorking {
+				level = monitorapi.Info
+			}
+			constructedIntervals = append(constructedIntervals,
+				monitorapi.NewInterval(monitorapi.SourceMachineConfig, level).
+					Locator(change.Locator).
+					Message(monitorapi.NewMessage().Reason(monitorapi.MachineConfigNodeState).
+						Constructed(monitorapi.ConstructionOwnerMachineConfigPoolLifecycle).
+						WithAnnotation(monitorapi.AnnotationState, state).
+						WithAnnotation(monitorapi.AnnotationPool, change.Message.Annotations[monitorapi.AnnotationPool]).
+						WithAnnotation(monitorapi.AnnotationConfig, change.Message.Annotations[monitorapi.AnnotationConfig]).
+						HumanMessage(fmt.Sprintf("machine-config-daemon is %s", state))).
+					Display().
+					Build(change.From, to),
+			)
+		}
+	}
+
+	return constructedIntervals
+}
+
+// constructDrainIntervals creates an interval from each drain request to its completion.
+func constructDrainIntervals(startingIntervals monitorapi.Intervals, end time.Time) monitorapi.Intervals {
+	constructedIntervals := monitorapi.Intervals{}
+
+	for _, changes := range intervalsByLocator(startingIntervals, monitorapi.MachineConfigDrainRequested, monitorapi.MachineConfigDrainComplete) {
+		var requested *monitorapi.Interval
+		closeInterval := func(to time.Time, completed bool) {
+			constructedIntervals = append(constructedIntervals,
+				monitorapi.NewInterval(monitorapi.SourceMachineConfig, monitorapi.Info).
+					Locator(requested.Locator).
+					Message(monitorapi.NewMessage().Reason(monitorapi.MachineConfigDrain).
+						Constructed(monitorapi.ConstructionOwnerMachineConfigPoolLifecycle).
+						WithAnnotation(monitorapi.AnnotationPool, requested.Message.Annotations[monitorapi.AnnotationPool]).
+						WithAnnotation(monitorapi.AnnotationConfig, requested.Message.Annotations[monitorapi.AnnotationConfig]).
+						WithAnnotation(monitorapi.AnnotationDuration, to.Sub(requested.From).Round(time.Second).String()).
+						HumanMessage(fmt.Sprintf("drain for %s, completed=%v", requested.Message.Annotations[monitorapi.AnnotationConfig], completed))).
+					Display().
+					Build(requested.From, to),
+			)
+		}
+		for i := range changes {
+			switch changes[i].Message.Reason {
+			case monitorapi.MachineConfigDrainRequested:
+				// a new request replaces one that never completed.
+				if requested != nil {
+					closeInterval(changes[i].From, false)
+				}
+				requested = &changes[i]
+			case monitorapi.MachineConfigDrainComplete:
+				if requested != nil {
+					closeInterval(changes[i].From, true)
+					requested = nil
+				}
+			}
+		}
+		if requested != nil {
+			closeInterval(end, false)
+		}
+	}
+
+	return constructedIntervals
+}
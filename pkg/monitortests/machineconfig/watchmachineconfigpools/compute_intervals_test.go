@@ -0,0 +1,135 @@
+package watchmachineconfigpools
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var start = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func poolConditionChange(at time.Duration, pool, condition, status string) monitorapi.Interval {
+	return monitorapi.NewInterval(monitorapi.SourceMachineConfig, monitorapi.Info).
+		Locator(monitorapi.NewLocator().MachineConfigPoolFromName(pool)).
+		Message(monitorapi.NewMessage().Reason(monitorapi.MachineConfigPoolConditionChanged).
+			WithAnnotation(monitorapi.AnnotationCondition, condition).
+			WithAnnotation(monitorapi.AnnotationStatus, status).
+			HumanMessage("changed")).
+		Build(start.Add(at), start.Add(at))
+}
+
+func nodeChange(at time.Duration, node string, reason monitorapi.IntervalReason, state string) monitorapi.Interval {
+	return monitorapi.NewInterval(monitorapi.SourceMachineConfig, monitorapi.Info).
+		Locator(monitorapi.NewLocator().NodeFromName(node)).
+		Message(monitorapi.NewMessage().Reason(reason).
+			WithAnnotation(monitorapi.AnnotationState, state).
+			WithAnnotation(monitorapi.AnnotationConfig, "rendered-worker-1234").
+			HumanMessage("changed")).
+		Build(start.Add(at), start.Add(at))
+}
+
+func durations(intervals monitorapi.Intervals, reason monitorapi.IntervalReason) []time.Duration {
+	ret := []time.Duration{}
+	for _, interval := range intervals {
+		if interval.Message.Reason == reason {
+			ret = append(ret, interval.To.Sub(interval.From))
+		}
+	}
+	return ret
+}
+
+func TestConstructComputedIntervals(t *testing.T) {
+	startingIntervals := monitorapi.Intervals{
+		poolConditionChange(0, "worker", "Updating", "True"),
+		poolConditionChange(time.Minute, "worker", "NodeDegraded", "True"),
+		poolConditionChange(3*time.Minute, "worker", "NodeDegraded", "False"),
+		poolConditionChange(40*time.Minute, "worker", "Updating", "False"),
+		// still degraded at the end
+		poolConditionChange(50*time.Minute, "master", "RenderDegraded", "True"),
+
+		nodeChange(0, "node-a", monitorapi.MachineConfigNodeStateChanged, "Working"),
+		nodeChange(time.Minute, "node-a", monitorapi.MachineConfigDrainRequested, ""),
+		nodeChange(3*time.Minute, "node-a", monitorapi.MachineConfigDrainComplete, ""),
+		nodeChange(10*time.Minute, "node-a", monitorapi.MachineConfigNodeStateChanged, "Done"),
+		nodeChange(10*time.Minute, "node-b", monitorapi.MachineConfigNodeStateChanged, "Working"),
+		nodeChange(45*time.Minute, "node-b", monitorapi.MachineConfigNodeStateChanged, "Degraded"),
+	}
+	end := start.Add(time.Hour)
+
+	w := &machineConfigPoolWatcher{}
+	constructed, err := w.ConstructComputedIntervals(context.TODO(), startingIntervals, nil, start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := durations(constructed, monitorapi.MachineConfigPoolCondition); len(got) != 3 {
+		t.Fatalf("expected three pool condition intervals, got %v", got)
+	}
+	if got := durations(constructed, monitorapi.MachineConfigDrain); len(got) != 1 || got[0] != 2*time.Minute {
+		t.Errorf("expected a two minute drain, got %v", got)
+	}
+	stateDurations := map[string]time.Duration{}
+	for _, interval := range constructed {
+		if interval.Message.Reason == monitorapi.MachineConfigNodeState {
+			stateDurations[interval.Locator.Keys[monitorapi.LocatorNodeKey]+"/"+interval.Message.Annotations[monitorapi.AnnotationState]] = interval.To.Sub(interval.From)
+		}
+	}
+	expectedStates := map[string]time.Duration{
+		"node-a/Working":  10 * time.Minute,
+		"node-b/Working":  35 * time.Minute,
+		"node-b/Degraded": 15 * time.Minute,
+	}
+	if len(stateDurations) != len(expectedStates) {
+		t.Errorf("expected %v, got %v", expectedStates, stateDurations)
+	}
+	for key, expected := range expectedStates {
+		if stateDurations[key] != expected {
+			t.Errorf("%s: expected %v, got %v", key, expected, stateDurations[key])
+		}
+	}
+
+	junits, err := w.EvaluateTestsFromConstructedIntervals(context.TODO(), append(startingIntervals, constructed...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	failed := map[string]bool{}
+	for _, junit := range junits {
+		failed[junit.Name] = junit.FailureOutput != nil
+	}
+	if !failed[nodeUpdateTestName] {
+		t.Errorf("expected node-b to exceed the update duration")
+	}
+	if !failed[poolDegradedTestName] {
+		t.Errorf("expected degraded pools to fail outside of an upgrade")
+	}
+
+	upgrade := monitorapi.NewInterval(monitorapi.SourceKubeEvent, monitorapi.Info).
+		Locator(monitorapi.Locator{Type: monitorapi.LocatorTypeClusterVersion, Keys: map[monitorapi.LocatorKey]string{monitorapi.LocatorClusterVersionKey: "cluster"}}).
+		Message(monitorapi.NewMessage().Reason(monitorapi.UpgradeStartedReason).HumanMessage("upgrade")).
+		Build(start, start)
+	junits = poolDegradedJunits(append(constructed, upgrade))
+	if len(junits) != 1 || junits[0].FailureOutput != nil {
+		t.Errorf("expected degraded pools to pass during an upgrade, got %v", junits)
+	}
+}
+
+func TestPoolForNode(t *testing.T) {
+	tests := []struct {
+		desiredConfig string
+		expected      string
+	}{
+		{desiredConfig: "rendered-worker-5d3a2c1b", expected: "worker"},
+		{desiredConfig: "rendered-infra-gpu-5d3a2c1b", expected: "infra-gpu"},
+		{desiredConfig: "", expected: "unknown"},
+	}
+	for _, tc := range tests {
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{desiredConfigAnnotation: tc.desiredConfig}}}
+		if actual := poolForNode(node); actual != tc.expected {
+			t.Errorf("%q: expected %q, got %q", tc.desiredConfig, tc.expected, actual)
+		}
+	}
+}
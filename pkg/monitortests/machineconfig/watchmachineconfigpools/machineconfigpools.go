@@ -0,0 +1,133 @@
+package watchmachineconfigpools
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestlibrary"
+
+	mcfgv1 "github.com/openshift/api/machineconfiguration/v1"
+	mcfgclient "github.com/openshift/client-go/machineconfiguration/clientset/versioned"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/tools/cache"
+)
+
+// watchedPoolConditions are the pool conditions that describe a rollout.  Everything else is noise on the timeline.
+var watchedPoolConditions = []mcfgv1.MachineConfigPoolConditionType{
+	mcfgv1.MachineConfigPoolUpdating,
+	mcfgv1.MachineConfigPoolDegraded,
+	mcfgv1.MachineConfigPoolNodeDegraded,
+	mcfgv1.MachineConfigPoolRenderDegraded,
+}
+
+func startMachineConfigPoolMonitoring(ctx context.Context, m monitorapi.RecorderWriter, client mcfgclient.Interface) {
+	poolChangeFns := []func(pool, oldPool *mcfgv1.MachineConfigPool) []monitorapi.Interval{
+		func(pool, oldPool *mcfgv1.MachineConfigPool) []monitorapi.Interval {
+			var intervals []monitorapi.Interval
+			if pool == nil {
+				return intervals
+			}
+			now := time.Now()
+
+			for _, conditionType := range watchedPoolConditions {
+				condition := findPoolCondition(pool, conditionType)
+				if condition == nil {
+					continue
+				}
+				previousStatus := "<missing>"
+				if oldPool != nil {
+					if previous := findPoolCondition(oldPool, conditionType); previous != nil {
+						previousStatus = string(previous.Status)
+					}
+				}
+				if previousStatus == string(condition.Status) {
+					continue
+				}
+				// pools that are healthy when we start watching aren't interesting.
+				if oldPool == nil && condition.Status != corev1.ConditionTrue {
+					continue
+				}
+
+				level := monitorapi.Info
+				if conditionType != mcfgv1.MachineConfigPoolUpdating && condition.Status == corev1.ConditionTrue {
+					level = monitorapi.Warning
+				}
+				intervals = append(intervals,
+					monitorapi.NewInterval(monitorapi.SourceMachineConfig, level).
+						Locator(monitorapi.NewLocator().MachineConfigPoolFromName(pool.Name)).
+						Message(monitorapi.NewMessage().Reason(monitorapi.MachineConfigPoolConditionChanged).
+							WithAnnotation(monitorapi.AnnotationCondition, string(conditionType)).
+							WithAnnotation(monitorapi.AnnotationStatus, string(condition.Status)).
+							WithAnnotation(monitorapi.AnnotationPreviousStatus, previousStatus).
+							HumanMessage(fmt.Sprintf("%s changed from %s to %s (%s): %s", conditionType, previousStatus, condition.Status, condition.Reason, condition.Message))).
+						Build(now, now))
+			}
+			return intervals
+		},
+	}
+
+	listWatch := cache.NewListWatchFromClient(client.MachineconfigurationV1().RESTClient(), "machineconfigpools", "", fields.Everything())
+	customStore := monitortestlibrary.NewMonitoringStore(
+		"machineconfigpools",
+		toCreateFns(poolChangeFns),
+		toUpdateFns(poolChangeFns),
+		toDeleteFns(poolChangeFns),
+		m,
+		m,
+	)
+	reflector := cache.NewReflector(listWatch, &mcfgv1.MachineConfigPool{}, customStore, 0)
+	go reflector.Run(ctx.Done())
+}
+
+func findPoolCondition(pool *mcfgv1.MachineConfigPool, conditionType mcfgv1.MachineConfigPoolConditionType) *mcfgv1.MachineConfigPoolCondition {
+	for i := range pool.Status.Conditions {
+		if pool.Status.Conditions[i].Type == conditionType {
+			return &pool.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+func toCreateFns(poolUpdateFns []func(pool, oldPool *mcfgv1.MachineConfigPool) []monitorapi.Interval) []monitortestlibrary.ObjCreateFunc {
+	ret := []monitortestlibrary.ObjCreateFunc{}
+
+	for i := range poolUpdateFns {
+		fn := poolUpdateFns[i]
+		ret = append(ret, func(obj interface{}) []monitorapi.Interval {
+			return fn(obj.(*mcfgv1.MachineConfigPool), nil)
+		})
+	}
+
+	return ret
+}
+
+func toDeleteFns(poolUpdateFns []func(pool, oldPool *mcfgv1.MachineConfigPool) []monitorapi.Interval) []monitortestlibrary.ObjDeleteFunc {
+	ret := []monitortestlibrary.ObjDeleteFunc{}
+
+	for i := range poolUpdateFns {
+		fn := poolUpdateFns[i]
+		ret = append(ret, func(obj interface{}) []monitorapi.Interval {
+			return fn(nil, obj.(*mcfgv1.MachineConfigPool))
+		})
+	}
+	return ret
+}
+
+func toUpdateFns(poolUpdateFns []func(pool, oldPool *mcfgv1.MachineConfigPool) []monitorapi.Interval) []monitortestlibrary.ObjUpdateFunc {
+	ret := []monitortestlibrary.ObjUpdateFunc{}
+
+	for i := range poolUpdateFns {
+		fn := poolUpdateFns[i]
+		ret = append(ret, func(obj, oldObj interface{}) []monitorapi.Interval {
+			if oldObj == nil {
+				return fn(obj.(*mcfgv1.MachineConfigPool), nil)
+			}
+			return fn(obj.(*mcfgv1.MachineConfigPool), oldObj.(*mcfgv1.MachineConfigPool))
+		})
+	}
+
+	return ret
+}
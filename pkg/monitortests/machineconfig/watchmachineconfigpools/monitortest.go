@@ -0,0 +1,155 @@
+package watchmachineconfigpools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	mcfgclient "github.com/openshift/client-go/machineconfiguration/clientset/versioned"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestframework"
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	MonitorName = "machineconfigpool-lifecycle"
+
+	// maxNodeUpdateDuration covers drain, the OS update, and the reboot of a single node.
+	maxNodeUpdateDuration = 30 * time.Minute
+)
+
+var (
+	nodeUpdateTestName   = fmt.Sprintf("[sig-mco] machineconfigpool-lifecycle nodes should finish a machine config update within %v", maxNodeUpdateDuration)
+	poolDegradedTestName = "[sig-mco] machineconfigpool-lifecycle pools should not degrade when the cluster is not upgrading"
+)
+
+type machineConfigPoolWatcher struct {
+	notSupportedReason error
+}
+
+func NewMachineConfigPoolWatcher() monitortestframework.MonitorTest {
+	return &machineConfigPoolWatcher{}
+}
+
+func (w *machineConfigPoolWatcher) StartCollection(ctx context.Context, adminRESTConfig *rest.Config, recorder monitorapi.RecorderWriter) error {
+	kubeClient, err := kubernetes.NewForConfig(adminRESTConfig)
+	if err != nil {
+		return err
+	}
+	// MicroShift and hosted control planes do not run the machine-config-operator in the cluster.
+	if _, err := kubeClient.Discovery().ServerResourcesForGroupVersion("machineconfiguration.openshift.io/v1"); err != nil {
+		if apierrors.IsNotFound(err) {
+			w.notSupportedReason = &monitortestframework.NotSupportedError{Reason: "machineconfigpools are not served by this cluster"}
+			return w.notSupportedReason
+		}
+		return err
+	}
+	mcfgClient, err := mcfgclient.NewForConfig(adminRESTConfig)
+	if err != nil {
+		return err
+	}
+
+	startMachineConfigPoolMonitoring(ctx, recorder, mcfgClient)
+	startNodeMachineConfigMonitoring(ctx, recorder, kubeClient)
+
+	return nil
+}
+
+func (w *machineConfigPoolWatcher) CollectData(ctx context.Context, storageDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
+	// because we are sharing a recorder that we're streaming into, we don't need to have a separate data collection step.
+	return nil, nil, w.notSupportedReason
+}
+
+func (w *machineConfigPoolWatcher) ConstructComputedIntervals(ctx context.Context, startingIntervals monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, beginning, end time.Time) (monitorapi.Intervals, error) {
+	if w.notSupportedReason != nil {
+		return nil, w.notSupportedReason
+	}
+
+	constructedIntervals := monitorapi.Intervals{}
+	constructedIntervals = append(constructedIntervals, constructPoolConditionIntervals(startingIntervals, end)...)
+	constructedIntervals = append(constructedIntervals, constructNodeStateIntervals(startingIntervals, end)...)
+	constructedIntervals = append(constructedIntervals, constructDrainIntervals(startingIntervals, end)...)
+	return constructedIntervals, nil
+}
+
+func (w *machineConfigPoolWatcher) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
+	if w.notSupportedReason != nil {
+		return nil, w.notSupportedReason
+	}
+
+	junits := []*junitapi.JUnitTestCase{}
+	junits = append(junits, nodeUpdateDurationJunits(finalIntervals)...)
+	junits = append(junits, poolDegradedJunits(finalIntervals)...)
+	return junits, nil
+}
+
+func (w *machineConfigPoolWatcher) WriteContentToStorage(ctx context.Context, storageDir, timeSuffix string, finalIntervals monitorapi.Intervals, finalResourceState monitorapi.ResourcesMap) error {
+	return w.notSupportedReason
+}
+
+func (w *machineConfigPoolWatcher) Cleanup(ctx context.Context) error {
+	// TODO wire up the start to a context we can kill here
+	return w.notSupportedReason
+}
+
+func nodeUpdateDurationJunits(finalIntervals monitorapi.Intervals) []*junitapi.JUnitTestCase {
+	failures := []string{}
+	for _, interval := range finalIntervals {
+		if interval.Source != monitorapi.SourceMachineConfig || interval.Message.Reason != monitorapi.MachineConfigNodeState {
+			continue
+		}
+		if interval.Message.Annotations[monitorapi.AnnotationState] != machineConfigDaemonStateWorking {
+			continue
+		}
+		if duration := interval.To.Sub(interval.From); duration > maxNodeUpdateDuration {
+			failures = append(failures, fmt.Sprintf("%v took %v to update to %s", interval.Locator.OldLocator(), duration.Round(time.Second), interval.Message.Annotations[monitorapi.AnnotationConfig]))
+		}
+	}
+
+	if len(failures) > 0 {
+		return []*junitapi.JUnitTestCase{
+			{
+				Name: nodeUpdateTestName,
+				FailureOutput: &junitapi.FailureOutput{
+					Output: fmt.Sprintf("%d nodes took longer than %v to update\n\n%v", len(failures), maxNodeUpdateDuration, strings.Join(failures, "\n")),
+				},
+			},
+		}
+	}
+	return []*junitapi.JUnitTestCase{{Name: nodeUpdateTestName}}
+}
+
+func poolDegradedJunits(finalIntervals monitorapi.Intervals) []*junitapi.JUnitTestCase {
+	degraded := []string{}
+	for _, interval := range finalIntervals {
+		if interval.Source != monitorapi.SourceMachineConfig || interval.Message.Reason != monitorapi.MachineConfigPoolCondition {
+			continue
+		}
+		if interval.Level != monitorapi.Error {
+			continue
+		}
+		degraded = append(degraded, interval.String())
+	}
+
+	// pools routinely report degraded nodes while the machine-config-operator rolls out during an upgrade.
+	if platformidentification.DidUpgradeHappenDuringCollection(finalIntervals, time.Time{}, time.Time{}) {
+		return []*junitapi.JUnitTestCase{{Name: poolDegradedTestName, SystemOut: strings.Join(degraded, "\n")}}
+	}
+	if len(degraded) > 0 {
+		return []*junitapi.JUnitTestCase{
+			{
+				Name: poolDegradedTestName,
+				FailureOutput: &junitapi.FailureOutput{
+					Output: fmt.Sprintf("machineconfigpools degraded %d times\n\n%v", len(degraded), strings.Join(degraded, "\n")),
+				},
+			},
+		}
+	}
+	return []*junitapi.JUnitTestCase{{Name: poolDegradedTestName}}
+}
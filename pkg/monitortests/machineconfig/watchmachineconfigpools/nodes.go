@@ -0,0 +1,168 @@
+package watchmachineconfigpools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestlibrary"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// These are written by the machine-config-daemon and the machine-config-controller's drain controller.
+const (
+	machineConfigDaemonStateAnnotation = "machineconfiguration.openshift.io/state"
+	desiredConfigAnnotation            = "machineconfiguration.openshift.io/desiredConfig"
+	desiredDrainAnnotation             = "machineconfiguration.openshift.io/desiredDrain"
+	lastAppliedDrainAnnotation         = "machineconfiguration.openshift.io/lastAppliedDrain"
+
+	machineConfigDaemonStateDone     = "Done"
+	machineConfigDaemonStateWorking  = "Working"
+	machineConfigDaemonStateDegraded = "Degraded"
+
+	// desiredDrain is either drain-<rendered config> or uncordon-<rendered config>
+	drainRequestPrefix = "drain-"
+)
+
+func startNodeMachineConfigMonitoring(ctx context.Context, m monitorapi.RecorderWriter, client kubernetes.Interface) {
+	nodeChangeFns := []func(node, oldNode *corev1.Node) []monitorapi.Interval{
+		func(node, oldNode *corev1.Node) []monitorapi.Interval {
+			var intervals []monitorapi.Interval
+			if node == nil {
+				return intervals
+			}
+
+			oldState := "<missing>"
+			if oldNode != nil {
+				if state, ok := oldNode.Annotations[machineConfigDaemonStateAnnotation]; ok {
+					oldState = state
+				}
+			}
+			newState, ok := node.Annotations[machineConfigDaemonStateAnnotation]
+			if !ok || oldState == newState {
+				return intervals
+			}
+			// nodes that are done when we start watching aren't interesting.
+			if oldNode == nil && newState == machineConfigDaemonStateDone {
+				return intervals
+			}
+
+			level := monitorapi.Info
+			if newState != machineConfigDaemonStateDone && newState != machineConfigDaemonStateWorking {
+				level = monitorapi.Warning
+			}
+			now := time.Now()
+			intervals = append(intervals,
+				monitorapi.NewInterval(monitorapi.SourceMachineConfig, level).
+					Locator(monitorapi.NewLocator().NodeFromName(node.Name)).
+					Message(monitorapi.NewMessage().Reason(monitorapi.MachineConfigNodeStateChanged).
+						WithAnnotation(monitorapi.AnnotationState, newState).
+						WithAnnotation(monitorapi.AnnotationPreviousState, oldState).
+						WithAnnotation(monitorapi.AnnotationPool, poolForNode(node)).
+						WithAnnotation(monitorapi.AnnotationConfig, node.Annotations[desiredConfigAnnotation]).
+						HumanMessage(fmt.Sprintf("machine-config-daemon state changed from %s to %s", oldState, newState))).
+					Build(now, now))
+			return intervals
+		},
+
+		func(node, oldNode *corev1.Node) []monitorapi.Interval {
+			var intervals []monitorapi.Interval
+			if node == nil {
+				return intervals
+			}
+
+			desiredDrain := node.Annotations[desiredDrainAnnotation]
+			if !strings.HasPrefix(desiredDrain, drainRequestPrefix) {
+				return intervals
+			}
+			oldDesiredDrain, oldLastAppliedDrain := "", ""
+			if oldNode != nil {
+				oldDesiredDrain = oldNode.Annotations[desiredDrainAnnotation]
+				oldLastAppliedDrain = oldNode.Annotations[lastAppliedDrainAnnotation]
+			}
+			lastAppliedDrain := node.Annotations[lastAppliedDrainAnnotation]
+
+			now := time.Now()
+			// a drain in progress when we start watching is still worth showing.
+			if desiredDrain != oldDesiredDrain && desiredDrain != lastAppliedDrain {
+				intervals = append(intervals,
+					monitorapi.NewInterval(monitorapi.SourceMachineConfig, monitorapi.Info).
+						Locator(monitorapi.NewLocator().NodeFromName(node.Name)).
+						Message(monitorapi.NewMessage().Reason(monitorapi.MachineConfigDrainRequested).
+							WithAnnotation(monitorapi.AnnotationConfig, strings.TrimPrefix(desiredDrain, drainRequestPrefix)).
+							WithAnnotation(monitorapi.AnnotationPool, poolForNode(node)).
+							HumanMessage("drain requested")).
+						Build(now, now))
+			}
+			if oldNode != nil && lastAppliedDrain == desiredDrain && oldLastAppliedDrain != lastAppliedDrain {
+				intervals = append(intervals,
+					monitorapi.NewInterval(monitorapi.SourceMachineConfig, monitorapi.Info).
+						Locator(monitorapi.NewLocator().NodeFromName(node.Name)).
+						Message(monitorapi.NewMessage().Reason(monitorapi.MachineConfigDrainComplete).
+							WithAnnotation(monitorapi.AnnotationConfig, strings.TrimPrefix(desiredDrain, drainRequestPrefix)).
+							WithAnnotation(monitorapi.AnnotationPool, poolForNode(node)).
+							HumanMessage("drain complete")).
+						Build(now, now))
+			}
+			return intervals
+		},
+	}
+
+	listWatch := cache.NewListWatchFromClient(client.CoreV1().RESTClient(), "nodes", "", fields.Everything())
+	customStore := monitortestlibrary.NewMonitoringStore(
+		"nodes",
+		toNodeCreateFns(nodeChangeFns),
+		toNodeUpdateFns(nodeChangeFns),
+		[]monitortestlibrary.ObjDeleteFunc{},
+		m,
+		m,
+	)
+	reflector := cache.NewReflector(listWatch, &corev1.Node{}, customStore, 0)
+	go reflector.Run(ctx.Done())
+}
+
+// poolForNode returns the pool that rendered the node's desired config.  Rendered configs are named
+// rendered-<pool>-<hash>, which avoids evaluating every pool's node selector.
+func poolForNode(node *corev1.Node) string {
+	renderedConfig := strings.TrimPrefix(node.Annotations[desiredConfigAnnotation], "rendered-")
+	hashIndex := strings.LastIndex(renderedConfig, "-")
+	if hashIndex <= 0 {
+		return "unknown"
+	}
+	return renderedConfig[:hashIndex]
+}
+
+func toNodeCreateFns(nodeUpdateFns []func(node, oldNode *corev1.Node) []monitorapi.Interval) []monitortestlibrary.ObjCreateFunc {
+	ret := []monitortestlibrary.ObjCreateFunc{}
+
+	for i := range nodeUpdateFns {
+		fn := nodeUpdateFns[i]
+		ret = append(ret, func(obj interface{}) []monitorapi.Interval {
+			return fn(obj.(*corev1.Node), nil)
+		})
+	}
+
+	return ret
+}
+
+func toNodeUpdateFns(nodeUpdateFns []func(node, oldNode *corev1.Node) []monitorapi.Interval) []monitortestlibrary.ObjUpdateFunc {
+	ret := []monitortestlibrary.ObjUpdateFunc{}
+
+	for i := range nodeUpdateFns {
+		fn := nodeUpdateFns[i]
+		ret = append(ret, func(obj, oldObj interface{}) []monitorapi.Interval {
+			if oldObj == nil {
+				return fn(obj.(*corev1.Node), nil)
+			}
+			return fn(obj.(*corev1.Node), oldObj.(*corev1.Node))
+		})
+	}
+
+	return ret
+}
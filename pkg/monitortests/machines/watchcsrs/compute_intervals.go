@@ -0,0 +1,86 @@
+package watchcsrs
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+// constructCSRIntervals creates pending approval and pending issuance intervals for every CSR created after
+// beginning.  CSRs that existed before we started watching are history and are left alone.
+func constructCSRIntervals(startingIntervals monitorapi.Intervals, beginning, end time.Time) monitorapi.Intervals {
+	constructedIntervals := monitorapi.Intervals{}
+
+	csrToChanges := map[string]monitorapi.Intervals{}
+	for _, interval := range startingIntervals {
+		if interval.Source != monitorapi.SourceCSRMonitor {
+			continue
+		}
+		name := interval.Locator.Keys[monitorapi.LocatorNameKey]
+		csrToChanges[name] = append(csrToChanges[name], interval)
+	}
+
+	for _, changes := range csrToChanges {
+		var created, decided, issued *monitorapi.Interval
+		for i := range changes {
+			change := &changes[i]
+			switch change.Message.Reason {
+			case monitorapi.CSRCreated:
+				created = change
+			case monitorapi.CSRApproved, monitorapi.CSRDenied, monitorapi.CSRFailed:
+				if decided == nil || change.From.Before(decided.From) {
+					decided = change
+				}
+			case monitorapi.CSRIssued:
+				issued = change
+			}
+		}
+		if created == nil || created.From.Before(beginning) {
+			continue
+		}
+
+		if decided == nil {
+			constructedIntervals = append(constructedIntervals,
+				monitorapi.NewInterval(monitorapi.SourceCSRMonitor, monitorapi.Warning).
+					Locator(created.Locator).
+					Message(csrConstructedMessage(created, monitorapi.CSRNeverApproved, end.Sub(created.From)).
+						HumanMessage("never approved or denied")).
+					Display().
+					Build(created.From, end),
+			)
+			continue
+		}
+
+		constructedIntervals = append(constructedIntervals,
+			monitorapi.NewInterval(monitorapi.SourceCSRMonitor, monitorapi.Info).
+				Locator(created.Locator).
+				Message(csrConstructedMessage(created, monitorapi.CSRPendingApproval, decided.From.Sub(created.From)).
+					HumanMessage(fmt.Sprintf("waiting for a decision, ended with %s", decided.Message.Reason))).
+				Display().
+				Build(created.From, decided.From),
+		)
+		if decided.Message.Reason == monitorapi.CSRApproved && issued != nil {
+			constructedIntervals = append(constructedIntervals,
+				monitorapi.NewInterval(monitorapi.SourceCSRMonitor, monitorapi.Info).
+					Locator(created.Locator).
+					Message(csrConstructedMessage(created, monitorapi.CSRPendingIssuance, issued.From.Sub(decided.From)).
+						HumanMessage("approved, waiting for the signer")).
+					Display().
+					Build(decided.From, issued.From),
+			)
+		}
+	}
+
+	sort.Sort(constructedIntervals)
+	return constructedIntervals
+}
+
+func csrConstructedMessage(created *monitorapi.Interval, reason monitorapi.IntervalReason, duration time.Duration) *monitorapi.MessageBuilder {
+	return monitorapi.NewMessage().Reason(reason).
+		Constructed(monitorapi.ConstructionOwnerCSRLifecycle).
+		WithAnnotation(monitorapi.AnnotationSigner, created.Message.Annotations[monitorapi.AnnotationSigner]).
+		WithAnnotation(monitorapi.AnnotationNode, created.Message.Annotations[monitorapi.AnnotationNode]).
+		WithAnnotation(monitorapi.AnnotationDuration, duration.Round(time.Second).String())
+}
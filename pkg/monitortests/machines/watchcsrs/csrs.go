@@ -0,0 +1,180 @@
+package watchcsrs
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestlibrary"
+
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+const nodeUserPrefix = "system:node:"
+
+// decisionReasons maps the terminal CSR conditions to the interval recorded for them.
+var decisionReasons = map[certificatesv1.RequestConditionType]monitorapi.IntervalReason{
+	certificatesv1.CertificateApproved: monitorapi.CSRApproved,
+	certificatesv1.CertificateDenied:   monitorapi.CSRDenied,
+	certificatesv1.CertificateFailed:   monitorapi.CSRFailed,
+}
+
+func startCSRMonitoring(ctx context.Context, m monitorapi.RecorderWriter, client kubernetes.Interface) {
+	csrChangeFns := []func(csr, oldCSR *certificatesv1.CertificateSigningRequest) []monitorapi.Interval{
+		// this is first so csr created shows up first when queried
+		func(csr, oldCSR *certificatesv1.CertificateSigningRequest) []monitorapi.Interval {
+			var intervals []monitorapi.Interval
+			if csr == nil || oldCSR != nil {
+				return intervals
+			}
+			created := csr.CreationTimestamp.Time
+			intervals = append(intervals,
+				monitorapi.NewInterval(monitorapi.SourceCSRMonitor, monitorapi.Info).
+					Locator(csrLocator(csr.Name)).
+					Message(csrMessage(csr, monitorapi.CSRCreated).
+						HumanMessage(fmt.Sprintf("created by %s", csr.Spec.Username))).
+					Build(created, created))
+			return intervals
+		},
+
+		func(csr, oldCSR *certificatesv1.CertificateSigningRequest) []monitorapi.Interval {
+			var intervals []monitorapi.Interval
+			if csr == nil {
+				return intervals
+			}
+			now := time.Now()
+			for _, condition := range csr.Status.Conditions {
+				reason, ok := decisionReasons[condition.Type]
+				if !ok || condition.Status != corev1.ConditionTrue {
+					continue
+				}
+				if oldCSR != nil && hasCondition(oldCSR, condition.Type) {
+					continue
+				}
+				// the condition carries when the decision was made, which is accurate even for CSRs listed late.
+				decided := condition.LastUpdateTime.Time
+				if decided.IsZero() {
+					decided = now
+				}
+				level := monitorapi.Info
+				if condition.Type != certificatesv1.CertificateApproved {
+					level = monitorapi.Warning
+				}
+				intervals = append(intervals,
+					monitorapi.NewInterval(monitorapi.SourceCSRMonitor, level).
+						Locator(csrLocator(csr.Name)).
+						Message(csrMessage(csr, reason).
+							HumanMessage(fmt.Sprintf("%s: %s: %s", condition.Type, condition.Reason, condition.Message))).
+						Build(decided, decided))
+			}
+			return intervals
+		},
+
+		// the issued certificate carries no timestamp, so this is only recorded when observed.
+		func(csr, oldCSR *certificatesv1.CertificateSigningRequest) []monitorapi.Interval {
+			var intervals []monitorapi.Interval
+			if csr == nil || oldCSR == nil {
+				return intervals
+			}
+			if len(oldCSR.Status.Certificate) > 0 || len(csr.Status.Certificate) == 0 {
+				return intervals
+			}
+			now := time.Now()
+			intervals = append(intervals,
+				monitorapi.NewInterval(monitorapi.SourceCSRMonitor, monitorapi.Info).
+					Locator(csrLocator(csr.Name)).
+					Message(csrMessage(csr, monitorapi.CSRIssued).
+						HumanMessage("certificate issued")).
+					Build(now, now))
+			return intervals
+		},
+	}
+
+	listWatch := cache.NewListWatchFromClient(client.CertificatesV1().RESTClient(), "certificatesigningrequests", "", fields.Everything())
+	customStore := monitortestlibrary.NewMonitoringStore(
+		"certificatesigningrequests",
+		toCreateFns(csrChangeFns),
+		toUpdateFns(csrChangeFns),
+		[]monitortestlibrary.ObjDeleteFunc{},
+		m,
+		m,
+	)
+	reflector := cache.NewReflector(listWatch, &certificatesv1.CertificateSigningRequest{}, customStore, 0)
+	go reflector.Run(ctx.Done())
+}
+
+func csrLocator(name string) monitorapi.Locator {
+	return monitorapi.NewLocator().Resource("certificates.k8s.io", "certificatesigningrequests", "", name)
+}
+
+func csrMessage(csr *certificatesv1.CertificateSigningRequest, reason monitorapi.IntervalReason) *monitorapi.MessageBuilder {
+	return monitorapi.NewMessage().Reason(reason).
+		WithAnnotation(monitorapi.AnnotationSigner, csr.Spec.SignerName).
+		WithAnnotation(monitorapi.AnnotationNode, requestingNode(csr))
+}
+
+// requestingNode returns the node a kubelet CSR is for.  Serving certificates are requested by the node itself,
+// client certificates are requested by the bootstrap user on behalf of the node named in the request.
+func requestingNode(csr *certificatesv1.CertificateSigningRequest) string {
+	if strings.HasPrefix(csr.Spec.Username, nodeUserPrefix) {
+		return strings.TrimPrefix(csr.Spec.Username, nodeUserPrefix)
+	}
+	block, _ := pem.Decode(csr.Spec.Request)
+	if block == nil {
+		return ""
+	}
+	request, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return ""
+	}
+	if strings.HasPrefix(request.Subject.CommonName, nodeUserPrefix) {
+		return strings.TrimPrefix(request.Subject.CommonName, nodeUserPrefix)
+	}
+	return ""
+}
+
+func hasCondition(csr *certificatesv1.CertificateSigningRequest, conditionType certificatesv1.RequestConditionType) bool {
+	for _, condition := range csr.Status.Conditions {
+		if condition.Type == conditionType && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+func toCreateFns(csrUpdateFns []func(csr, oldCSR *certificatesv1.CertificateSigningRequest) []monitorapi.Interval) []monitortestlibrary.ObjCreateFunc {
+	ret := []monitortestlibrary.ObjCreateFunc{}
+
+	for i := range csrUpdateFns {
+		fn := csrUpdateFns[i]
+		ret = append(ret, func(obj interface{}) []monitorapi.Interval {
+			return fn(obj.(*certificatesv1.CertificateSigningRequest), nil)
+		})
+	}
+
+	return ret
+}
+
+func toUpdateFns(csrUpdateFns []func(csr, oldCSR *certificatesv1.CertificateSigningRequest) []monitorapi.Interval) []monitortestlibrary.ObjUpdateFunc {
+	ret := []monitortestlibrary.ObjUpdateFunc{}
+
+	for i := range csrUpdateFns {
+		fn := csrUpdateFns[i]
+		ret = append(ret, func(obj, oldObj interface{}) []monitorapi.Interval {
+			if oldObj == nil {
+				return fn(obj.(*certificatesv1.CertificateSigningRequest), nil)
+			}
+			return fn(obj.(*certificatesv1.CertificateSigningRequest), oldObj.(*certificatesv1.CertificateSigningRequest))
+		})
+	}
+
+	return ret
+}
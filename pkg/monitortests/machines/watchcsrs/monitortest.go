@@ -0,0 +1,234 @@
+package watchcsrs
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openshift/origin/pkg/dataloader"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestframework"
+	"github.com/openshift/origin/pkg/monitortestlibrary/historicaldata"
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+	"github.com/sirupsen/logrus"
+
+	certificatesv1 "k8s.io/api/certificates/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	MonitorName = "csr-lifecycle"
+
+	// defaultApprovalLatency applies to signers we have neither historical data nor a specific default for.
+	defaultApprovalLatency = 10 * time.Minute
+)
+
+var (
+	// approvalLatencySigners are the signers whose approval latency is an invariant.  Kubelets cannot join or
+	// serve until these are approved, and the defaults are used when there is no historical data.
+	approvalLatencySigners = map[string]time.Duration{
+		certificatesv1.KubeletServingSignerName:             5 * time.Minute,
+		certificatesv1.KubeAPIServerClientKubeletSignerName: 10 * time.Minute,
+	}
+
+	neverApprovedTestName = "[sig-cluster-lifecycle] csr-lifecycle certificatesigningrequests should be approved or denied"
+)
+
+func approvalLatencyTestName(signer string) string {
+	return fmt.Sprintf("[sig-cluster-lifecycle] csr-lifecycle certificatesigningrequests for %s should be approved promptly", signer)
+}
+
+type csrWatcher struct {
+	historicalData *historicaldata.DisruptionBestMatcher
+	jobType        *platformidentification.JobType
+}
+
+func NewCSRWatcher() monitortestframework.MonitorTest {
+	return &csrWatcher{}
+}
+
+// NewCSRWatcherWithHistoricalData takes approval latency thresholds from historical data.  Data is keyed by
+// HistoricalDataName and the P99 is the threshold.  The default monitor tests read it from the allowedlatency query
+// results, which are built from the approval latencies written by WriteContentToStorage, and signers without enough
+// data there keep the static defaults.
+func NewCSRWatcherWithHistoricalData(historicalData *historicaldata.DisruptionBestMatcher) monitortestframework.MonitorTest {
+	return &csrWatcher{historicalData: historicalData}
+}
+
+// HistoricalDataName is the name historical approval latency is recorded under for a signer.
+func HistoricalDataName(signer string) string {
+	return "csr-approval-latency-" + signer
+}
+
+func (w *csrWatcher) StartCollection(ctx context.Context, adminRESTConfig *rest.Config, recorder monitorapi.RecorderWriter) error {
+	kubeClient, err := kubernetes.NewForConfig(adminRESTConfig)
+	if err != nil {
+		return err
+	}
+	if w.historicalData != nil {
+		w.jobType, err = platformidentification.GetJobType(ctx, adminRESTConfig)
+		if err != nil {
+			// static thresholds still apply.
+			logrus.WithError(err).Warning("unable to determine job type for csr approval latency")
+		}
+	}
+
+	startCSRMonitoring(ctx, recorder, kubeClient)
+
+	return nil
+}
+
+func (w *csrWatcher) CollectData(ctx context.Context, storageDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
+	// because we are sharing a recorder that we're streaming into, we don't need to have a separate data collection step.
+	return nil, nil, nil
+}
+
+func (*csrWatcher) ConstructComputedIntervals(ctx context.Context, startingIntervals monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, beginning, end time.Time) (monitorapi.Intervals, error) {
+	return constructCSRIntervals(startingIntervals, beginning, end), nil
+}
+
+func (w *csrWatcher) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
+	junits := []*junitapi.JUnitTestCase{}
+	signers := []string{}
+	for signer := range approvalLatencySigners {
+		signers = append(signers, signer)
+	}
+	sort.Strings(signers)
+	for _, signer := range signers {
+		junits = append(junits, w.approvalLatencyJunits(finalIntervals, signer)...)
+	}
+	junits = append(junits, w.neverApprovedJunits(finalIntervals)...)
+	return junits, nil
+}
+
+func (*csrWatcher) WriteContentToStorage(ctx context.Context, storageDir, timeSuffix string, finalIntervals monitorapi.Intervals, finalResourceState monitorapi.ResourcesMap) error {
+	return writeApprovalLatencies(storageDir, timeSuffix, finalIntervals)
+}
+
+// writeApprovalLatencies records how long every CSR for the kubelet signers waited for a decision, which is the data
+// the historical approval latency thresholds are computed from.  CSRs that never got a decision are recorded with how
+// long they waited before the run ended.
+func writeApprovalLatencies(storageDir, timeSuffix string, finalIntervals monitorapi.Intervals) error {
+	rows := []map[string]string{}
+	for _, interval := range finalIntervals {
+		if interval.Source != monitorapi.SourceCSRMonitor {
+			continue
+		}
+		if interval.Message.Reason != monitorapi.CSRPendingApproval && interval.Message.Reason != monitorapi.CSRNeverApproved {
+			continue
+		}
+		signer := interval.Message.Annotations[monitorapi.AnnotationSigner]
+		if _, ok := approvalLatencySigners[signer]; !ok {
+			continue
+		}
+		rows = append(rows, map[string]string{
+			"Signer":         signer,
+			"Node":           interval.Message.Annotations[monitorapi.AnnotationNode],
+			"Decided":        fmt.Sprintf("%t", interval.Message.Reason == monitorapi.CSRPendingApproval),
+			"LatencySeconds": fmt.Sprintf("%.3f", interval.To.Sub(interval.From).Seconds()),
+		})
+	}
+
+	dataFile := dataloader.DataFile{
+		TableName: "csr_approval_latency",
+		Schema: map[string]dataloader.DataType{
+			"Signer":         dataloader.DataTypeString,
+			"Node":           dataloader.DataTypeString,
+			"Decided":        dataloader.DataTypeString,
+			"LatencySeconds": dataloader.DataTypeFloat64,
+		},
+		Rows: rows,
+	}
+	fileName := filepath.Join(storageDir, fmt.Sprintf("csr-approval-latency%s-%s", timeSuffix, dataloader.AutoDataLoaderSuffix))
+	return dataloader.WriteDataFile(fileName, dataFile)
+}
+
+func (*csrWatcher) Cleanup(ctx context.Context) error {
+	// TODO wire up the start to a context we can kill here
+	return nil
+}
+
+// approvalLatencyThreshold returns the P99 from historical data when there is enough of it, otherwise the default.
+func (w *csrWatcher) approvalLatencyThreshold(signer string) (time.Duration, string) {
+	if w.historicalData != nil && w.jobType != nil {
+		p99, details, err := w.historicalData.BestMatchP99(HistoricalDataName(signer), *w.jobType)
+		if err == nil && p99 != nil {
+			return *p99, fmt.Sprintf("historical P99 %s", details)
+		}
+	}
+	if threshold, ok := approvalLatencySigners[signer]; ok {
+		return threshold, "default"
+	}
+	return defaultApprovalLatency, "default"
+}
+
+// slowCSRs returns the CSRs for the signer that waited longer than the threshold for a decision, including those that never got one.
+func slowCSRs(finalIntervals monitorapi.Intervals, signer string, threshold time.Duration) []string {
+	slow := []string{}
+	for _, interval := range finalIntervals {
+		if interval.Source != monitorapi.SourceCSRMonitor || interval.Message.Annotations[monitorapi.AnnotationSigner] != signer {
+			continue
+		}
+		if interval.Message.Reason != monitorapi.CSRPendingApproval && interval.Message.Reason != monitorapi.CSRNeverApproved {
+			continue
+		}
+		if interval.To.Sub(interval.From) <= threshold {
+			continue
+		}
+		slow = append(slow, interval.String())
+	}
+	return slow
+}
+
+func (w *csrWatcher) approvalLatencyJunits(finalIntervals monitorapi.Intervals, signer string) []*junitapi.JUnitTestCase {
+	testName := approvalLatencyTestName(signer)
+	threshold, thresholdSource := w.approvalLatencyThreshold(signer)
+
+	slow := slowCSRs(finalIntervals, signer, threshold)
+	if len(slow) == 0 {
+		return []*junitapi.JUnitTestCase{{Name: testName}}
+	}
+	return []*junitapi.JUnitTestCase{
+		{
+			Name: testName,
+			FailureOutput: &junitapi.FailureOutput{
+				Output: fmt.Sprintf("%d certificatesigningrequests for %s waited longer than %v (%s) to be approved\n\n%v",
+					len(slow), signer, threshold, thresholdSource, strings.Join(slow, "\n")),
+			},
+		},
+	}
+}
+
+// neverApprovedJunits flakes when any CSR was left pending for longer than its signer's threshold.  Only the kubelet
+// signers can fail, in approvalLatencyJunits, because other signers are often approved by hand or by a test.
+func (w *csrWatcher) neverApprovedJunits(finalIntervals monitorapi.Intervals) []*junitapi.JUnitTestCase {
+	pending := []string{}
+	for _, interval := range finalIntervals {
+		if interval.Source != monitorapi.SourceCSRMonitor || interval.Message.Reason != monitorapi.CSRNeverApproved {
+			continue
+		}
+		threshold, _ := w.approvalLatencyThreshold(interval.Message.Annotations[monitorapi.AnnotationSigner])
+		if interval.To.Sub(interval.From) <= threshold {
+			continue
+		}
+		pending = append(pending, interval.String())
+	}
+
+	junits := []*junitapi.JUnitTestCase{}
+	if len(pending) > 0 {
+		junits = append(junits, &junitapi.JUnitTestCase{
+			Name: neverApprovedTestName,
+			FailureOutput: &junitapi.FailureOutput{
+				Output: fmt.Sprintf("%d certificatesigningrequests were never approved or denied\n\n%v", len(pending), strings.Join(pending, "\n")),
+			},
+		})
+	}
+	// this test only flakes
+	junits = append(junits, &junitapi.JUnitTestCase{Name: neverApprovedTestName})
+	return junits
+}
@@ -0,0 +1,194 @@
+package watchcsrs
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/dataloader"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestlibrary/historicaldata"
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+
+	certificatesv1 "k8s.io/api/certificates/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var start = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func csrChange(at time.Duration, name, signer string, reason monitorapi.IntervalReason) monitorapi.Interval {
+	return monitorapi.NewInterval(monitorapi.SourceCSRMonitor, monitorapi.Info).
+		Locator(csrLocator(name)).
+		Message(monitorapi.NewMessage().Reason(reason).
+			WithAnnotation(monitorapi.AnnotationSigner, signer).
+			WithAnnotation(monitorapi.AnnotationNode, "node-a").
+			HumanMessage("changed")).
+		Build(start.Add(at), start.Add(at))
+}
+
+func TestConstructAndEvaluate(t *testing.T) {
+	serving := certificatesv1.KubeletServingSignerName
+	client := certificatesv1.KubeAPIServerClientKubeletSignerName
+	startingIntervals := monitorapi.Intervals{
+		// before monitoring began, ignored
+		csrChange(-time.Hour, "csr-old", serving, monitorapi.CSRCreated),
+
+		csrChange(0, "csr-fast", serving, monitorapi.CSRCreated),
+		csrChange(10*time.Second, "csr-fast", serving, monitorapi.CSRApproved),
+		csrChange(12*time.Second, "csr-fast", serving, monitorapi.CSRIssued),
+
+		csrChange(time.Minute, "csr-slow", client, monitorapi.CSRCreated),
+		csrChange(20*time.Minute, "csr-slow", client, monitorapi.CSRApproved),
+
+		csrChange(2*time.Minute, "csr-pending", "example.com/custom", monitorapi.CSRCreated),
+	}
+	end := start.Add(time.Hour)
+
+	w := &csrWatcher{}
+	constructed, err := w.ConstructComputedIntervals(context.TODO(), startingIntervals, nil, start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reasons := map[string][]monitorapi.IntervalReason{}
+	for _, interval := range constructed {
+		name := interval.Locator.Keys[monitorapi.LocatorNameKey]
+		reasons[name] = append(reasons[name], interval.Message.Reason)
+	}
+	expected := map[string][]monitorapi.IntervalReason{
+		"csr-fast":    {monitorapi.CSRPendingApproval, monitorapi.CSRPendingIssuance},
+		"csr-slow":    {monitorapi.CSRPendingApproval},
+		"csr-pending": {monitorapi.CSRNeverApproved},
+	}
+	if len(reasons) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, reasons)
+	}
+	for name, expectedReasons := range expected {
+		if len(reasons[name]) != len(expectedReasons) {
+			t.Errorf("%s: expected %v, got %v", name, expectedReasons, reasons[name])
+			continue
+		}
+		for i := range expectedReasons {
+			if reasons[name][i] != expectedReasons[i] {
+				t.Errorf("%s: expected %v, got %v", name, expectedReasons, reasons[name])
+			}
+		}
+	}
+
+	failures := func(w *csrWatcher) map[string]int {
+		junits, err := w.EvaluateTestsFromConstructedIntervals(context.TODO(), append(startingIntervals, constructed...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ret := map[string]int{}
+		for _, junit := range junits {
+			if junit.FailureOutput != nil {
+				ret[junit.Name]++
+			}
+		}
+		return ret
+	}
+
+	defaults := failures(w)
+	if defaults[approvalLatencyTestName(serving)] != 0 {
+		t.Errorf("serving CSRs were approved quickly")
+	}
+	if defaults[approvalLatencyTestName(client)] != 1 {
+		t.Errorf("expected the client CSR to exceed the default threshold")
+	}
+	if defaults[neverApprovedTestName] != 1 {
+		t.Errorf("expected the pending CSR to be reported")
+	}
+
+	// historical data is tighter than the default for serving CSRs.
+	jobType := platformidentification.JobType{Release: "4.16", Platform: "aws", Architecture: "amd64", Network: "ovn", Topology: "ha"}
+	historical := historicaldata.NewDisruptionMatcherWithHistoricalData(map[historicaldata.DataKey]historicaldata.DisruptionStatisticalData{
+		{BackendName: HistoricalDataName(serving), JobType: jobType}: {
+			DataKey: historicaldata.DataKey{BackendName: HistoricalDataName(serving), JobType: jobType},
+			P99:     5,
+			JobRuns: 1000,
+		},
+	})
+	withHistory := failures(&csrWatcher{historicalData: historical, jobType: &jobType})
+	if withHistory[approvalLatencyTestName(serving)] != 1 {
+		t.Errorf("expected the serving CSR to exceed the historical threshold")
+	}
+
+	// the historical data is built from the approval latencies of the kubelet signers
+	storageDir := t.TempDir()
+	if err := w.WriteContentToStorage(context.TODO(), storageDir, "_test", append(startingIntervals, constructed...), nil); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(filepath.Join(storageDir, "csr-approval-latency_test-autodl.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dataFile := dataloader.DataFile{}
+	if err := json.Unmarshal(content, &dataFile); err != nil {
+		t.Fatal(err)
+	}
+	latencies := map[string]string{}
+	for _, row := range dataFile.Rows {
+		latencies[row["Signer"]] = row["LatencySeconds"]
+	}
+	expectedLatencies := map[string]string{serving: "10.000", client: "1140.000"}
+	if !reflect.DeepEqual(expectedLatencies, latencies) {
+		t.Errorf("expected approval latencies %v, got %v", expectedLatencies, latencies)
+	}
+}
+
+func TestRequestingNode(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{Subject: pkix.Name{CommonName: "system:node:node-b"}}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})
+
+	tests := []struct {
+		name     string
+		csr      *certificatesv1.CertificateSigningRequest
+		expected string
+	}{
+		{
+			name:     "serving requested by the node",
+			csr:      &certificatesv1.CertificateSigningRequest{Spec: certificatesv1.CertificateSigningRequestSpec{Username: "system:node:node-a"}},
+			expected: "node-a",
+		},
+		{
+			name: "client requested by the bootstrapper",
+			csr: &certificatesv1.CertificateSigningRequest{
+				ObjectMeta: metav1.ObjectMeta{Name: "csr-1"},
+				Spec: certificatesv1.CertificateSigningRequestSpec{
+					Username: "system:serviceaccount:openshift-machine-config-operator:node-bootstrapper",
+					Request:  request,
+				},
+			},
+			expected: "node-b",
+		},
+		{
+			name:     "not for a node",
+			csr:      &certificatesv1.CertificateSigningRequest{Spec: certificatesv1.CertificateSigningRequestSpec{Username: "alice"}},
+			expected: "",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := requestingNode(tc.csr); actual != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
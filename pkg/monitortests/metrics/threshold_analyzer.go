@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	prometheustypes "github.com/prometheus/common/model"
+)
+
+// ThresholdSeriesAnalyzer analyzes a time series of values that have a limit,
+// such as a latency quantile from histogram_quantile. It scans a prometheus
+// Matrix type time series, and for each sequence of values above Threshold
+// discovered, it publishes it as an interval of interest via the given Callback.
+// NaN values, which histogram_quantile returns when there were no observations,
+// are treated as being under the threshold.
+type ThresholdSeriesAnalyzer struct {
+	Threshold float64
+}
+
+func (a ThresholdSeriesAnalyzer) Analyze(ctx context.Context, query QueryRunner, start, end time.Time, callback Callback) error {
+	result, err := query.RunQuery(ctx, start, end)
+	if err != nil {
+		return fmt.Errorf("query returned error, monitor: %s, err: %w", callback.Name(), err)
+	}
+	if result.Type() != prometheustypes.ValMatrix {
+		return fmt.Errorf("expected a prometheus Matrix type, but got: %q, monitor: %s", result.Type().String(), callback.Name())
+	}
+	matrix := result.(prometheustypes.Matrix)
+
+	for _, series := range matrix {
+		func() {
+			callback.StartSeries(series.Metric)
+			defer callback.EndSeries()
+
+			var intervalStart, intervalEnd *prometheustypes.SamplePair
+			for i := range series.Values {
+				current := series.Values[i]
+				value := float64(current.Value)
+				switch {
+				case math.IsNaN(value) || value <= a.Threshold:
+					if intervalStart != nil {
+						callback.NewInterval(series.Metric, intervalStart, intervalEnd)
+						intervalStart = nil
+					}
+				default:
+					if intervalStart == nil {
+						intervalStart = &current
+					}
+				}
+
+				intervalEnd = &current
+			}
+
+			if intervalStart != nil {
+				callback.NewInterval(series.Metric, intervalStart, intervalEnd)
+			}
+		}()
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	prometheustypes "github.com/prometheus/common/model"
+)
+
+func TestThresholdSeriesAnalyzer(t *testing.T) {
+	sample := func(timestamp int64, value float64) prometheustypes.SamplePair {
+		return prometheustypes.SamplePair{
+			Timestamp: prometheustypes.TimeFromUnix(timestamp),
+			Value:     prometheustypes.SampleValue(value),
+		}
+	}
+
+	tests := []struct {
+		name       string
+		query      fakeQuery
+		exceedings []interval
+	}{
+		{
+			name: "series under the threshold",
+			query: `
+[
+  {
+    "metric": {
+      "pod": "etcd-master-0"
+    },
+    "values": [
+      [1723589469, "0.004"],
+      [1723589529, "NaN"],
+      [1723589589, "0.01"]
+    ]
+  }
+]`,
+		},
+		{
+			name: "series exceeding the threshold twice",
+			query: `
+[
+  {
+    "metric": {
+      "pod": "etcd-master-0"
+    },
+    "values": [
+      [1723589469, "0.004"],
+      [1723589529, "0.02"],
+      [1723589589, "0.03"],
+      [1723589649, "NaN"],
+      [1723589709, "0.005"],
+      [1723589769, "0.5"]
+    ]
+  }
+]`,
+			exceedings: []interval{
+				{From: sample(1723589529, 0.02), To: sample(1723589589, 0.03)},
+				{From: sample(1723589769, 0.5), To: sample(1723589769, 0.5)},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			callback := &fakeCallback{}
+
+			analyzer := ThresholdSeriesAnalyzer{Threshold: 0.01}
+			if err := analyzer.Analyze(context.Background(), test.query, time.Time{}, time.Time{}, callback); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if want, got := test.exceedings, callback.disruptions; !reflect.DeepEqual(want, got) {
+				t.Errorf("unexpected intervals: %s", cmp.Diff(want, got))
+			}
+			if want, got := 1, callback.countStart; want != got {
+				t.Errorf("expected series start count: %d, but got: %d", want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package prometheusrulelint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	prometheustypes "github.com/prometheus/common/model"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+var (
+	// validSeverities follows the OpenShift alerting consistency guidelines.  "none" is only used by dead man's switch
+	// alerts like Watchdog that are expected to always fire.
+	validSeverities = sets.NewString("critical", "warning", "info", "none")
+
+	requiredAnnotations = []string{"summary", "description", "runbook_url"}
+)
+
+// lintNamespace returns a human readable violation for every badly defined alert across all the PrometheusRules
+// in a single namespace.  The rules must all belong to the same namespace for duplicate detection to be meaningful.
+func lintNamespace(rules []*monitoringv1.PrometheusRule) []string {
+	violations := []string{}
+
+	// alert names must be unique within a namespace, even severity tiers of the same condition should be named apart
+	// so that silences and runbooks can target them.
+	alertNameToLocations := map[string][]string{}
+
+	for _, rule := range rules {
+		for _, group := range rule.Spec.Groups {
+			for _, alert := range group.Rules {
+				if len(alert.Alert) == 0 {
+					// recording rules have no alerting conventions to enforce.
+					continue
+				}
+				location := fmt.Sprintf("prometheusrule/%s group/%s alert/%s", rule.Name, group.Name, alert.Alert)
+				for _, violation := range lintAlert(alert) {
+					violations = append(violations, fmt.Sprintf("%s: %s", location, violation))
+				}
+
+				alertNameToLocations[alert.Alert] = append(alertNameToLocations[alert.Alert], location)
+			}
+		}
+	}
+
+	for _, locations := range alertNameToLocations {
+		if len(locations) < 2 {
+			continue
+		}
+		sort.Strings(locations)
+		violations = append(violations, fmt.Sprintf("%s: duplicated by %s", locations[0], strings.Join(locations[1:], ", ")))
+	}
+
+	sort.Strings(violations)
+	return violations
+}
+
+// lintAlert checks a single alerting rule.
+func lintAlert(alert monitoringv1.Rule) []string {
+	violations := []string{}
+
+	severity, hasSeverity := alert.Labels["severity"]
+	switch {
+	case !hasSeverity:
+		violations = append(violations, "missing severity label")
+	case !validSeverities.Has(severity):
+		violations = append(violations, fmt.Sprintf("severity label %q must be one of %v", severity, validSeverities.List()))
+	}
+
+	for _, annotation := range requiredAnnotations {
+		if len(strings.TrimSpace(alert.Annotations[annotation])) == 0 {
+			violations = append(violations, fmt.Sprintf("missing %s annotation", annotation))
+		}
+	}
+
+	if severity == "critical" {
+		switch {
+		case alert.For == nil || len(*alert.For) == 0:
+			violations = append(violations, "critical alerts must set a non-zero for: duration")
+		default:
+			duration, err := prometheustypes.ParseDuration(string(*alert.For))
+			switch {
+			case err != nil:
+				violations = append(violations, fmt.Sprintf("unable to parse for: %q: %v", *alert.For, err))
+			case duration == 0:
+				violations = append(violations, "critical alerts must set a non-zero for: duration")
+			}
+		}
+	}
+
+	if err := checkExprDelimiters(alert.Expr.String()); err != nil {
+		violations = append(violations, fmt.Sprintf("unbalanced delimiters in expr: %v", err))
+	}
+
+	return violations
+}
+
+// checkExprDelimiters only checks that an expression is non-empty, terminates its strings, and balances its
+// parentheses, brackets, and braces.  It is not PromQL validation: the prometheus parser is not vendored, so unknown
+// functions, bad operators, and bad range syntax in a balanced expression all pass.
+func checkExprDelimiters(expr string) error {
+	if len(strings.TrimSpace(expr)) == 0 {
+		return fmt.Errorf("expression is empty")
+	}
+
+	closerToOpener := map[rune]rune{')': '(', ']': '[', '}': '{'}
+	openers := []rune{}
+	var inString rune
+	escaped := false
+	inComment := false
+
+	for i, c := range expr {
+		switch {
+		case inComment:
+			if c == '\n' {
+				inComment = false
+			}
+
+		case inString != 0:
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\' && inString != '`':
+				escaped = true
+			case c == inString:
+				inString = 0
+			case c == '\n' && inString != '`':
+				return fmt.Errorf("unterminated string starting before position %d", i)
+			}
+
+		case c == '#':
+			inComment = true
+
+		case c == '"' || c == '\'' || c == '`':
+			inString = c
+
+		case c == '(' || c == '[' || c == '{':
+			openers = append(openers, c)
+
+		case c == ')' || c == ']' || c == '}':
+			if len(openers) == 0 || openers[len(openers)-1] != closerToOpener[c] {
+				return fmt.Errorf("unexpected %q at position %d", c, i)
+			}
+			openers = openers[:len(openers)-1]
+		}
+	}
+
+	if inString != 0 {
+		return fmt.Errorf("unterminated string")
+	}
+	if len(openers) > 0 {
+		return fmt.Errorf("unclosed %q", openers[len(openers)-1])
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package prometheusrulelint
+
+import (
+	"reflect"
+	"testing"
+
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func duration(d string) *monitoringv1.Duration {
+	ret := monitoringv1.Duration(d)
+	return &ret
+}
+
+func goodAlert(name, severity string) monitoringv1.Rule {
+	return monitoringv1.Rule{
+		Alert: name,
+		Expr:  intstr.FromString(`sum(rate(foo_total{job="bar"}[5m])) by (instance) > 0`),
+		For:   duration("15m"),
+		Labels: map[string]string{
+			"severity": severity,
+		},
+		Annotations: map[string]string{
+			"summary":     "summary",
+			"description": "description",
+			"runbook_url": "https://example.com/runbook.md",
+		},
+	}
+}
+
+func prometheusRule(name string, alerts ...monitoringv1.Rule) *monitoringv1.PrometheusRule {
+	return &monitoringv1.PrometheusRule{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-foo", Name: name},
+		Spec: monitoringv1.PrometheusRuleSpec{
+			Groups: []monitoringv1.RuleGroup{{Name: "group", Rules: alerts}},
+		},
+	}
+}
+
+func Test_lintNamespace(t *testing.T) {
+	noRunbook := goodAlert("NoRunbook", "warning")
+	delete(noRunbook.Annotations, "runbook_url")
+
+	noSeverity := goodAlert("NoSeverity", "warning")
+	delete(noSeverity.Labels, "severity")
+
+	criticalNoFor := goodAlert("CriticalNoFor", "critical")
+	criticalNoFor.For = nil
+
+	criticalZeroFor := goodAlert("CriticalZeroFor", "critical")
+	criticalZeroFor.For = duration("0s")
+
+	warningNoFor := goodAlert("WarningNoFor", "warning")
+	warningNoFor.For = nil
+
+	badExpr := goodAlert("BadExpr", "warning")
+	badExpr.Expr = intstr.FromString(`sum(rate(foo_total[5m]) > 0`)
+
+	criticalTier := goodAlert("Tiered", "critical")
+	warningTier := goodAlert("Tiered", "warning")
+
+	tests := []struct {
+		name  string
+		rules []*monitoringv1.PrometheusRule
+		want  []string
+	}{
+		{
+			name:  "well formed",
+			rules: []*monitoringv1.PrometheusRule{prometheusRule("rules", goodAlert("Good", "critical"), warningNoFor)},
+			want:  []string{},
+		},
+		{
+			name:  "recording rules are ignored",
+			rules: []*monitoringv1.PrometheusRule{prometheusRule("rules", monitoringv1.Rule{Record: "foo:sum", Expr: intstr.FromString("sum(foo)")})},
+			want:  []string{},
+		},
+		{
+			name:  "missing annotation",
+			rules: []*monitoringv1.PrometheusRule{prometheusRule("rules", noRunbook)},
+			want:  []string{"prometheusrule/rules group/group alert/NoRunbook: missing runbook_url annotation"},
+		},
+		{
+			name:  "missing severity",
+			rules: []*monitoringv1.PrometheusRule{prometheusRule("rules", noSeverity)},
+			want:  []string{"prometheusrule/rules group/group alert/NoSeverity: missing severity label"},
+		},
+		{
+			name:  "unknown severity",
+			rules: []*monitoringv1.PrometheusRule{prometheusRule("rules", goodAlert("Unknown", "page"))},
+			want:  []string{`prometheusrule/rules group/group alert/Unknown: severity label "page" must be one of [critical info none warning]`},
+		},
+		{
+			name:  "critical without for",
+			rules: []*monitoringv1.PrometheusRule{prometheusRule("rules", criticalNoFor, criticalZeroFor)},
+			want: []string{
+				"prometheusrule/rules group/group alert/CriticalNoFor: critical alerts must set a non-zero for: duration",
+				"prometheusrule/rules group/group alert/CriticalZeroFor: critical alerts must set a non-zero for: duration",
+			},
+		},
+		{
+			name:  "bad expression",
+			rules: []*monitoringv1.PrometheusRule{prometheusRule("rules", badExpr)},
+			want:  []string{`prometheusrule/rules group/group alert/BadExpr: unbalanced delimiters in expr: unclosed '('`},
+		},
+		{
+			name:  "severity tiers are duplicates",
+			rules: []*monitoringv1.PrometheusRule{prometheusRule("rules", criticalTier, warningTier)},
+			want:  []string{"prometheusrule/rules group/group alert/Tiered: duplicated by prometheusrule/rules group/group alert/Tiered"},
+		},
+		{
+			name: "duplicated across rules",
+			rules: []*monitoringv1.PrometheusRule{
+				prometheusRule("a", goodAlert("Dup", "warning")),
+				prometheusRule("b", goodAlert("Dup", "warning")),
+			},
+			want: []string{"prometheusrule/a group/group alert/Dup: duplicated by prometheusrule/b group/group alert/Dup"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lintNamespace(tt.rules); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lintNamespace() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_checkExprDelimiters(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr bool
+	}{
+		{expr: `up == 0`},
+		{expr: `sum by (namespace) (rate(foo{a="b)"}[5m]))`},
+		{expr: "max(foo{a='it\\'s'}) # trailing (comment"},
+		{expr: "label_replace(up, \"dst\", `$1`, \"src\", \"(.*)\")"},
+		// balanced but invalid PromQL is out of scope
+		{expr: `notafunction(up[5x]) +* 1`},
+		{expr: ``, wantErr: true},
+		{expr: `sum(rate(foo[5m])`, wantErr: true},
+		{expr: `foo{a="b"]`, wantErr: true},
+		{expr: `foo{a="b}`, wantErr: true},
+		{expr: `foo)`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			if err := checkExprDelimiters(tt.expr); (err != nil) != tt.wantErr {
+				t.Errorf("checkExprDelimiters() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
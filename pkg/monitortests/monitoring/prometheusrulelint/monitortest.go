@@ -0,0 +1,111 @@
+package prometheusrulelint
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	prometheusoperatorv1client "github.com/prometheus-operator/prometheus-operator/pkg/client/versioned/typed/monitoring/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/rest"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestframework"
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+)
+
+const (
+	MonitorName = "prometheus-rule-lint"
+)
+
+type prometheusRuleLint struct {
+	monitoringClient prometheusoperatorv1client.MonitoringV1Interface
+}
+
+// NewPrometheusRuleLint returns a monitor test that statically checks every alert defined in platform PrometheusRules.
+// The alert tests in allowedalerts judge alerts after they fire, this catches alerts that are badly defined in
+// the first place: missing or unknown severities, missing summary/description/runbook_url annotations, critical
+// alerts that fire without a for: duration, expressions with unbalanced delimiters, and duplicated alert names.
+func NewPrometheusRuleLint() monitortestframework.MonitorTest {
+	return &prometheusRuleLint{}
+}
+
+func (w *prometheusRuleLint) StartCollection(ctx context.Context, adminRESTConfig *rest.Config, recorder monitorapi.RecorderWriter) error {
+	var err error
+	w.monitoringClient, err = prometheusoperatorv1client.NewForConfig(adminRESTConfig)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (w *prometheusRuleLint) CollectData(ctx context.Context, storageDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
+	if w.monitoringClient == nil {
+		return nil, nil, fmt.Errorf("monitor test is not initialized")
+	}
+
+	prometheusRules, err := w.monitoringClient.PrometheusRules(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil, &monitortestframework.NotSupportedError{Reason: "PrometheusRule resource is not available"}
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list PrometheusRules: %w", err)
+	}
+
+	return nil, lintPrometheusRules(prometheusRules.Items), nil
+}
+
+func (*prometheusRuleLint) ConstructComputedIntervals(ctx context.Context, startingIntervals monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, beginning, end time.Time) (monitorapi.Intervals, error) {
+	return nil, nil
+}
+
+func (*prometheusRuleLint) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
+	return nil, nil
+}
+
+func (*prometheusRuleLint) WriteContentToStorage(ctx context.Context, storageDir, timeSuffix string, finalIntervals monitorapi.Intervals, finalResourceState monitorapi.ResourcesMap) error {
+	return nil
+}
+
+func (*prometheusRuleLint) Cleanup(ctx context.Context) error {
+	return nil
+}
+
+// lintPrometheusRules produces one junit per platform namespace that owns PrometheusRules.  Namespaces created by
+// tests are ignored, they are free to define whatever alerts they need to exercise.
+func lintPrometheusRules(prometheusRules []*monitoringv1.PrometheusRule) []*junitapi.JUnitTestCase {
+	namespaceToRules := map[string][]*monitoringv1.PrometheusRule{}
+	for _, prometheusRule := range prometheusRules {
+		namespace := prometheusRule.Namespace
+		if !platformidentification.KnownNamespaces.Has(namespace) && !strings.HasPrefix(namespace, "openshift-") {
+			continue
+		}
+		namespaceToRules[namespace] = append(namespaceToRules[namespace], prometheusRule)
+	}
+
+	ret := []*junitapi.JUnitTestCase{}
+	for _, namespace := range sets.StringKeySet(namespaceToRules).List() {
+		bzComponent := platformidentification.GetBugzillaComponentForNamespace(namespace)
+		testName := fmt.Sprintf("[sig-instrumentation][%s] alerting rules in ns/%s should be well formed", bzComponent, namespace)
+
+		violations := lintNamespace(namespaceToRules[namespace])
+		if len(violations) > 0 {
+			ret = append(ret, &junitapi.JUnitTestCase{
+				Name:      testName,
+				SystemOut: strings.Join(violations, "\n"),
+				FailureOutput: &junitapi.FailureOutput{
+					Output: fmt.Sprintf("%d alerting rule violations\n\n%s", len(violations), strings.Join(violations, "\n")),
+				},
+			})
+		}
+		// mark flaky until existing violations are cleaned up by the owning teams.
+		ret = append(ret, &junitapi.JUnitTestCase{Name: testName})
+	}
+
+	return ret
+}
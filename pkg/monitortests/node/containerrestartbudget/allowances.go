@@ -0,0 +1,82 @@
+package containerrestartbudget
+
+import (
+	"strings"
+	"time"
+)
+
+// budget is how much a single container may restart, be OOMKilled, or sit in CrashLoopBackOff before its namespace flakes.
+type budget struct {
+	restarts         int
+	oomKills         int
+	crashLoopBackOff time.Duration
+}
+
+// defaultBudget leaves room for a container to lose a leader election or a health check during an upgrade.  Nothing
+// in the platform should run out of memory.
+var defaultBudget = budget{
+	restarts:         3,
+	oomKills:         0,
+	crashLoopBackOff: 5 * time.Minute,
+}
+
+// allowance raises the budget of containers with a known bug.  Every allowance must link the bug and expires so that
+// it is revisited: once expired it no longer applies and the container is held to the default budget again.
+type allowance struct {
+	namespace string
+	// podPrefix matches pod names, so generated suffixes do not need to be known.  Empty matches every pod.
+	podPrefix string
+	// container is the exact container name.  Empty matches every container.
+	container string
+
+	// budget fields left at zero keep the default.
+	budget  budget
+	jira    string
+	expires time.Time
+}
+
+// allowances are checked in order and the first unexpired match wins.
+var allowances = []allowance{
+	// {
+	// 	namespace: "openshift-example",
+	// 	podPrefix: "example-operator-",
+	// 	container: "operator",
+	// 	budget:    budget{restarts: 10},
+	// 	jira:      "https://issues.redhat.com/browse/OCPBUGS-XXXXX",
+	// 	expires:   time.Date(2024, time.December, 1, 0, 0, 0, 0, time.UTC),
+	// },
+}
+
+func (a allowance) matches(c containerKey, now time.Time) bool {
+	if !now.Before(a.expires) {
+		return false
+	}
+	if a.namespace != c.namespace {
+		return false
+	}
+	if !strings.HasPrefix(c.pod, a.podPrefix) {
+		return false
+	}
+	return len(a.container) == 0 || a.container == c.container
+}
+
+// budgetFor returns the budget for a container and the allowance it came from, if any.
+func budgetFor(allowances []allowance, c containerKey, now time.Time) (budget, *allowance) {
+	for i := range allowances {
+		if !allowances[i].matches(c, now) {
+			continue
+		}
+		ret := defaultBudget
+		if allowances[i].budget.restarts > 0 {
+			ret.restarts = allowances[i].budget.restarts
+		}
+		if allowances[i].budget.oomKills > 0 {
+			ret.oomKills = allowances[i].budget.oomKills
+		}
+		if allowances[i].budget.crashLoopBackOff > 0 {
+			ret.crashLoopBackOff = allowances[i].budget.crashLoopBackOff
+		}
+		return ret, &allowances[i]
+	}
+	return defaultBudget, nil
+}
@@ -0,0 +1,217 @@
+package containerrestartbudget
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openshift/origin/pkg/dataloader"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestframework"
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+
+	"k8s.io/client-go/rest"
+)
+
+const (
+	MonitorName = "container-restart-budget"
+
+	oomKilled        = "OOMKilled"
+	crashLoopBackOff = "CrashLoopBackOff"
+)
+
+type containerRestartBudget struct {
+}
+
+func NewContainerRestartBudget() monitortestframework.MonitorTest {
+	return &containerRestartBudget{}
+}
+
+func (*containerRestartBudget) StartCollection(ctx context.Context, adminRESTConfig *rest.Config, recorder monitorapi.RecorderWriter) error {
+	return nil
+}
+
+func (*containerRestartBudget) CollectData(ctx context.Context, storageDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
+	return nil, nil, nil
+}
+
+func (*containerRestartBudget) ConstructComputedIntervals(ctx context.Context, startingIntervals monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, beginning, end time.Time) (monitorapi.Intervals, error) {
+	return nil, nil
+}
+
+func (*containerRestartBudget) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
+	return evaluateBudgets(countContainerRestarts(finalIntervals), allowances, time.Now()), nil
+}
+
+func (*containerRestartBudget) WriteContentToStorage(ctx context.Context, storageDir, timeSuffix string, finalIntervals monitorapi.Intervals, finalResourceState monitorapi.ResourcesMap) error {
+	counts := countContainerRestarts(finalIntervals)
+	rows := []map[string]string{}
+	for _, key := range sortedContainers(counts) {
+		count := counts[key]
+		rows = append(rows, map[string]string{
+			"Namespace":               key.namespace,
+			"Pod":                     key.pod,
+			"Container":               key.container,
+			"Restarts":                fmt.Sprintf("%d", count.restarts),
+			"OOMKills":                fmt.Sprintf("%d", count.oomKills),
+			"CrashLoopBackOffSeconds": fmt.Sprintf("%.0f", count.crashLoopBackOff.Seconds()),
+		})
+	}
+
+	dataFile := dataloader.DataFile{
+		TableName: "platform_container_restarts",
+		Schema: map[string]dataloader.DataType{
+			"Namespace":               dataloader.DataTypeString,
+			"Pod":                     dataloader.DataTypeString,
+			"Container":               dataloader.DataTypeString,
+			"Restarts":                dataloader.DataTypeInteger,
+			"OOMKills":                dataloader.DataTypeInteger,
+			"CrashLoopBackOffSeconds": dataloader.DataTypeInteger,
+		},
+		Rows: rows,
+	}
+	fileName := filepath.Join(storageDir, fmt.Sprintf("platform-container-restarts%s-%s", timeSuffix, dataloader.AutoDataLoaderSuffix))
+	return dataloader.WriteDataFile(fileName, dataFile)
+}
+
+func (*containerRestartBudget) Cleanup(ctx context.Context) error {
+	return nil
+}
+
+type containerKey struct {
+	namespace string
+	pod       string
+	container string
+}
+
+func (c containerKey) String() string {
+	return fmt.Sprintf("ns/%s pod/%s container/%s", c.namespace, c.pod, c.container)
+}
+
+type containerCount struct {
+	restarts         int
+	oomKills         int
+	crashLoopBackOff time.Duration
+}
+
+// countContainerRestarts counts restarts and OOMKills from the restart intervals of the pod monitor and
+// CrashLoopBackOff durations from the container lifecycle intervals constructed from it, for containers in known
+// platform namespaces.  A restart interval carries how far the restart count moved and why the container last
+// terminated, because the container exit is only recorded for the first termination of a container.
+func countContainerRestarts(finalIntervals monitorapi.Intervals) map[containerKey]*containerCount {
+	counts := map[containerKey]*containerCount{}
+	for _, interval := range finalIntervals {
+		key := containerKey{
+			namespace: interval.Locator.Keys[monitorapi.LocatorNamespaceKey],
+			pod:       interval.Locator.Keys[monitorapi.LocatorPodKey],
+			container: interval.Locator.Keys[monitorapi.LocatorContainerKey],
+		}
+		if len(key.container) == 0 || !platformidentification.KnownNamespaces.Has(key.namespace) {
+			continue
+		}
+
+		switch {
+		case interval.Source == monitorapi.SourcePodMonitor && interval.Message.Reason == monitorapi.ContainerReasonRestarted:
+			restarts := 1
+			if count, err := strconv.Atoi(interval.Message.Annotations[monitorapi.AnnotationCount]); err == nil && count > 0 {
+				restarts = count
+			}
+			countFor(counts, key).restarts += restarts
+			// only the last termination's reason is known, so every restart in the interval is counted with it
+			if interval.Message.Cause == oomKilled {
+				countFor(counts, key).oomKills += restarts
+			}
+		case interval.Source == monitorapi.SourcePodState && interval.Message.Reason == monitorapi.ContainerReasonContainerWait:
+			if interval.Message.Cause == crashLoopBackOff {
+				countFor(counts, key).crashLoopBackOff += interval.To.Sub(interval.From)
+			}
+		}
+	}
+	return counts
+}
+
+func countFor(counts map[containerKey]*containerCount, key containerKey) *containerCount {
+	if _, ok := counts[key]; !ok {
+		counts[key] = &containerCount{}
+	}
+	return counts[key]
+}
+
+func sortedContainers(counts map[containerKey]*containerCount) []containerKey {
+	keys := []containerKey{}
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+	return keys
+}
+
+func testNameForNamespace(namespace string) string {
+	return fmt.Sprintf("[Jira:%q] [sig-node] containers in ns/%s should not restart, be OOMKilled, or crashloop more than allowed",
+		platformidentification.GetBugzillaComponentForNamespace(namespace), namespace)
+}
+
+// evaluateBudgets produces a junit for every known platform namespace.  Until the allowances are seeded from CI
+// results, containers over budget only flake, whether or not they have an allowance.
+func evaluateBudgets(counts map[containerKey]*containerCount, allowances []allowance, now time.Time) []*junitapi.JUnitTestCase {
+	failures := map[string][]string{}
+	flakes := map[string][]string{}
+	for _, key := range sortedContainers(counts) {
+		count := counts[key]
+		containerBudget, containerAllowance := budgetFor(allowances, key, now)
+		over := overBudget(*count, containerBudget)
+		if len(over) == 0 {
+			continue
+		}
+		if containerAllowance != nil {
+			flakes[key.namespace] = append(flakes[key.namespace],
+				fmt.Sprintf("%v %s, allowed by %s until %s", key, strings.Join(over, ", "), containerAllowance.jira, containerAllowance.expires.Format("2006-01-02")))
+			continue
+		}
+		failures[key.namespace] = append(failures[key.namespace], fmt.Sprintf("%v %s", key, strings.Join(over, ", ")))
+	}
+
+	ret := []*junitapi.JUnitTestCase{}
+	for _, namespace := range platformidentification.KnownNamespaces.List() {
+		testName := testNameForNamespace(namespace)
+		output := []string{}
+		if len(failures[namespace]) > 0 {
+			output = append(output, fmt.Sprintf("%d containers were over budget\n\n%v", len(failures[namespace]), strings.Join(failures[namespace], "\n")))
+		}
+		if len(flakes[namespace]) > 0 {
+			output = append(output, fmt.Sprintf("%d containers were over budget with known bugs\n\n%v", len(flakes[namespace]), strings.Join(flakes[namespace], "\n")))
+		}
+		if len(output) > 0 {
+			ret = append(ret, &junitapi.JUnitTestCase{
+				Name: testName,
+				FailureOutput: &junitapi.FailureOutput{
+					Output: strings.Join(output, "\n\n"),
+				},
+			})
+		}
+		// start as a flake
+		ret = append(ret, &junitapi.JUnitTestCase{Name: testName})
+	}
+	return ret
+}
+
+func overBudget(count containerCount, b budget) []string {
+	over := []string{}
+	if count.restarts > b.restarts {
+		over = append(over, fmt.Sprintf("restarted %d times (allowed %d)", count.restarts, b.restarts))
+	}
+	if count.oomKills > b.oomKills {
+		over = append(over, fmt.Sprintf("was OOMKilled %d times (allowed %d)", count.oomKills, b.oomKills))
+	}
+	if count.crashLoopBackOff > b.crashLoopBackOff {
+		over = append(over, fmt.Sprintf("was in CrashLoopBackOff for %v (allowed %v)", count.crashLoopBackOff.Round(time.Second), b.crashLoopBackOff))
+	}
+	return over
+}
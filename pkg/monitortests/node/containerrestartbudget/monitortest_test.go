@@ -0,0 +1,116 @@
+package containerrestartbudget
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+func TestEvaluateBudgets(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	container := func(namespace, pod, name string) monitorapi.Locator {
+		return monitorapi.NewLocator().ContainerFromNames(namespace, pod, "uid", name)
+	}
+	restarted := func(locator monitorapi.Locator, count, cause string) monitorapi.Interval {
+		return monitorapi.NewInterval(monitorapi.SourcePodMonitor, monitorapi.Warning).
+			Locator(locator).
+			Message(monitorapi.NewMessage().Reason(monitorapi.ContainerReasonRestarted).WithAnnotation(monitorapi.AnnotationCount, count).Cause(cause)).
+			Build(now, now)
+	}
+	exited := func(locator monitorapi.Locator, cause string) monitorapi.Interval {
+		return monitorapi.NewInterval(monitorapi.SourcePodMonitor, monitorapi.Error).
+			Locator(locator).
+			Message(monitorapi.NewMessage().Reason(monitorapi.ContainerReasonContainerExit).Cause(cause)).
+			Build(now, now)
+	}
+	waiting := func(locator monitorapi.Locator, cause string, duration time.Duration) monitorapi.Interval {
+		return monitorapi.NewInterval(monitorapi.SourcePodState, monitorapi.Info).
+			Locator(locator).
+			Message(monitorapi.NewMessage().Reason(monitorapi.ContainerReasonContainerWait).Cause(cause).Constructed(monitorapi.ConstructionOwnerPodLifecycle)).
+			Build(now, now.Add(duration))
+	}
+
+	etcdOperator := container("openshift-etcd-operator", "etcd-operator-7c9d-abcde", "etcd-operator")
+	dnsOperator := container("openshift-dns-operator", "dns-operator-55f9-abcde", "dns-operator")
+	console := container("openshift-console", "console-5d8f7-abcde", "console")
+	authOperator := container("openshift-authentication-operator", "authentication-operator-6b8f-abcde", "authentication-operator")
+	e2e := container("e2e-test", "pod", "container")
+
+	finalIntervals := monitorapi.Intervals{
+		// two restarts is in budget, and container exits are not counted
+		restarted(etcdOperator, "1", "Error"),
+		restarted(etcdOperator, "1", ""),
+		exited(etcdOperator, "Error"),
+
+		// the restart count moved by more than one between updates
+		restarted(authOperator, "1", "Error"),
+		restarted(authOperator, "3", "Error"),
+
+		// any OOMKill is over budget, including one after the first termination, and they count like restarts do
+		restarted(dnsOperator, "1", "Error"),
+		restarted(dnsOperator, "2", oomKilled),
+		exited(dnsOperator, oomKilled),
+
+		// crashlooping for longer than the default budget and the allowance, which flakes
+		waiting(console, crashLoopBackOff, 6*time.Minute),
+		waiting(console, crashLoopBackOff, 2*time.Minute),
+		waiting(console, "ContainerCreating", time.Hour),
+
+		// not a platform namespace
+		exited(e2e, oomKilled),
+	}
+
+	counts := countContainerRestarts(finalIntervals)
+	if len(counts) != 4 {
+		t.Fatalf("expected four containers, got %v", counts)
+	}
+	if count := counts[containerKey{namespace: "openshift-authentication-operator", pod: "authentication-operator-6b8f-abcde", container: "authentication-operator"}]; count.restarts != 4 {
+		t.Errorf("expected 4 restarts, got %v", count.restarts)
+	}
+	if count := counts[containerKey{namespace: "openshift-dns-operator", pod: "dns-operator-55f9-abcde", container: "dns-operator"}]; count.restarts != 3 || count.oomKills != 2 {
+		t.Errorf("expected 3 restarts and 2 OOMKills, got %v and %v", count.restarts, count.oomKills)
+	}
+	if count := counts[containerKey{namespace: "openshift-console", pod: "console-5d8f7-abcde", container: "console"}]; count.crashLoopBackOff != 8*time.Minute {
+		t.Errorf("expected 8m of CrashLoopBackOff, got %v", count.crashLoopBackOff)
+	}
+
+	testAllowances := []allowance{
+		{
+			namespace: "openshift-console",
+			podPrefix: "console-",
+			budget:    budget{crashLoopBackOff: 7 * time.Minute},
+			jira:      "https://issues.redhat.com/browse/OCPBUGS-1",
+			expires:   now.Add(time.Hour),
+		},
+	}
+	results := map[string][]bool{}
+	for _, junit := range evaluateBudgets(counts, testAllowances, now) {
+		results[junit.Name] = append(results[junit.Name], junit.FailureOutput == nil)
+	}
+	expected := map[string][]bool{
+		testNameForNamespace("openshift-etcd-operator"):           {true},
+		testNameForNamespace("openshift-authentication-operator"): {false, true},
+		testNameForNamespace("openshift-dns-operator"):            {false, true},
+		testNameForNamespace("openshift-console"):                 {false, true},
+	}
+	for name, expectedResults := range expected {
+		if len(results[name]) != len(expectedResults) {
+			t.Errorf("%s: expected %v, got %v", name, expectedResults, results[name])
+			continue
+		}
+		for i := range expectedResults {
+			if results[name][i] != expectedResults[i] {
+				t.Errorf("%s: expected %v, got %v", name, expectedResults, results[name])
+			}
+		}
+	}
+
+	// once the allowance expires the console is held to the default budget.
+	for _, junit := range evaluateBudgets(counts, testAllowances, now.Add(2*time.Hour)) {
+		if junit.Name == testNameForNamespace("openshift-console") && junit.FailureOutput != nil && strings.Contains(junit.FailureOutput.Output, "known bugs") {
+			t.Errorf("expected the expired allowance not to apply, got %v", junit.FailureOutput.Output)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package watchnodes
+
+import (
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestlibrary/statetracker"
+)
+
+// pressureStates maps the instants recorded when a pressure condition begins and ends to the state they are tracked as.
+var pressureStates = map[monitorapi.IntervalReason]struct {
+	state  string
+	starts bool
+}{
+	monitorapi.NodeDiskPressure:     {state: "DiskPressure", starts: true},
+	monitorapi.NodeNoDiskPressure:   {state: "DiskPressure"},
+	monitorapi.NodeMemoryPressure:   {state: "MemoryPressure", starts: true},
+	monitorapi.NodeNoMemoryPressure: {state: "MemoryPressure"},
+	monitorapi.NodePIDPressure:      {state: "PIDPressure", starts: true},
+	monitorapi.NodeNoPIDPressure:    {state: "PIDPressure"},
+}
+
+// intervalsFromEvents_NodePressure creates an interval for each node and pressure condition for as long as the kubelet
+// reported it.  Each condition gets its own row so overlapping pressure is visible.
+func intervalsFromEvents_NodePressure(events monitorapi.Intervals, beginning, end time.Time) monitorapi.Intervals {
+	var intervals monitorapi.Intervals
+	nodeStateTracker := statetracker.NewStateTracker(monitorapi.ConstructionOwnerNodeLifecycle, monitorapi.SourceNodeState, beginning)
+
+	for _, event := range events {
+		if event.Source != monitorapi.SourceNodeMonitor {
+			continue
+		}
+		pressure, ok := pressureStates[event.Message.Reason]
+		if !ok {
+			continue
+		}
+		nodeLocator := monitorapi.NewLocator().NodeFromName(event.Locator.Keys[monitorapi.LocatorNodeKey])
+		state := statetracker.State(pressure.state, pressure.state, monitorapi.NodeUnderPressure)
+		if pressure.starts {
+			nodeStateTracker.OpenInterval(nodeLocator, state, event.From)
+			continue
+		}
+		mb := monitorapi.NewMessage().Reason(monitorapi.NodeUnderPressure).
+			Constructed(monitorapi.ConstructionOwnerNodeLifecycle).
+			WithAnnotation(monitorapi.AnnotationState, pressure.state).
+			HumanMessagef("kubelet reported %s", pressure.state)
+		intervals = append(intervals, nodeStateTracker.CloseInterval(nodeLocator, state,
+			statetracker.SimpleInterval(monitorapi.SourceNodeState, monitorapi.Warning, mb), event.From)...)
+	}
+	intervals = append(intervals, nodeStateTracker.CloseAllIntervals(map[string]map[string]string{}, end)...)
+
+	return intervals
+}
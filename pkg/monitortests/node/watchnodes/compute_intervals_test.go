@@ -0,0 +1,81 @@
+package watchnodes
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+func TestNodePressureAndEvictions(t *testing.T) {
+	beginning := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	end := beginning.Add(time.Hour)
+	nodeEvent := func(node string, reason monitorapi.IntervalReason, at time.Duration) monitorapi.Interval {
+		return monitorapi.NewInterval(monitorapi.SourceNodeMonitor, monitorapi.Warning).
+			Locator(monitorapi.NewLocator().NodeFromName(node)).
+			Message(monitorapi.NewMessage().Reason(reason)).
+			Build(beginning.Add(at), beginning.Add(at))
+	}
+	evicted := func(namespace, node string, at time.Duration) monitorapi.Interval {
+		return monitorapi.NewInterval(monitorapi.SourcePodMonitor, monitorapi.Warning).
+			Locator(monitorapi.NewLocator().PodFromNames(namespace, "pod", "uid")).
+			Message(monitorapi.NewMessage().Reason(monitorapi.PodReasonEvicted).
+				Node(node).
+				WithAnnotation(monitorapi.AnnotationPriorityClass, "system-cluster-critical").
+				WithAnnotation(monitorapi.AnnotationResource, "memory")).
+			Build(beginning.Add(at), beginning.Add(at))
+	}
+
+	startingIntervals := monitorapi.Intervals{
+		nodeEvent("worker-a", monitorapi.NodeMemoryPressure, 10*time.Minute),
+		nodeEvent("worker-a", monitorapi.NodeDiskPressure, 12*time.Minute),
+		nodeEvent("worker-a", monitorapi.NodeNoMemoryPressure, 20*time.Minute),
+		// was under pressure when monitoring began
+		nodeEvent("worker-b", monitorapi.NodeNoPIDPressure, 5*time.Minute),
+	}
+	constructed := intervalsFromEvents_NodePressure(startingIntervals, beginning, end)
+
+	type pressure struct {
+		node     string
+		state    string
+		from, to time.Duration
+	}
+	expected := map[pressure]bool{
+		{node: "worker-a", state: "MemoryPressure", from: 10 * time.Minute, to: 20 * time.Minute}: true,
+		{node: "worker-a", state: "DiskPressure", from: 12 * time.Minute, to: time.Hour}:          true,
+		{node: "worker-b", state: "PIDPressure", from: 0, to: 5 * time.Minute}:                    true,
+	}
+	if len(constructed) != len(expected) {
+		t.Fatalf("expected %d intervals, got %v", len(expected), constructed)
+	}
+	for _, interval := range constructed {
+		actual := pressure{
+			node:  interval.Locator.Keys[monitorapi.LocatorNodeKey],
+			state: interval.Message.Annotations[monitorapi.AnnotationState],
+			from:  interval.From.Sub(beginning),
+			to:    interval.To.Sub(beginning),
+		}
+		if !expected[actual] || interval.Message.Reason != monitorapi.NodeUnderPressure {
+			t.Errorf("unexpected interval %v", interval)
+		}
+	}
+
+	finalIntervals := append(startingIntervals, constructed...)
+	if junits := platformPodEvictions(finalIntervals); len(junits) != 1 || junits[0].FailureOutput != nil {
+		t.Errorf("expected a pass without evictions, got %v", junits)
+	}
+
+	finalIntervals = append(finalIntervals,
+		evicted("openshift-monitoring", "worker-a", 15*time.Minute),
+		evicted("e2e-test", "worker-a", 15*time.Minute),
+	)
+	junits := platformPodEvictions(finalIntervals)
+	if len(junits) != 1 || junits[0].FailureOutput == nil {
+		t.Fatalf("expected a failure for the platform eviction, got %v", junits)
+	}
+	output := junits[0].FailureOutput.Output
+	if !strings.Contains(output, "1 platform pods") || !strings.Contains(output, "reported MemoryPressure") || !strings.Contains(output, "reported DiskPressure") {
+		t.Errorf("unexpected output: %s", output)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/openshift/origin/pkg/monitortestframework"
 
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
 	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -39,6 +40,7 @@ func (w *nodeWatcher) CollectData(ctx context.Context, storageDir string, beginn
 
 func (*nodeWatcher) ConstructComputedIntervals(ctx context.Context, startingIntervals monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, beginning, end time.Time) (monitorapi.Intervals, error) {
 	constructedIntervals := monitorapi.Intervals{}
+	constructedIntervals = append(constructedIntervals, intervalsFromEvents_NodePressure(startingIntervals, beginning, end)...)
 
 	return constructedIntervals, nil
 }
@@ -49,6 +51,7 @@ func (*nodeWatcher) EvaluateTestsFromConstructedIntervals(ctx context.Context, f
 	junits = append(junits, unexpectedNodeNotReadyJunit(finalIntervals)...)
 	junits = append(junits, unreachableNodeTaint(finalIntervals)...)
 	junits = append(junits, nodeDiskPressure(finalIntervals)...)
+	junits = append(junits, platformPodEvictions(finalIntervals)...)
 	return junits, nil
 }
 
@@ -148,3 +151,50 @@ func nodeDiskPressure(finalIntervals monitorapi.Intervals) []*junitapi.JUnitTest
 
 	return tests
 }
+
+// platformPodEvictions fails when the kubelet evicts a platform pod, which means the node ran out of a resource the
+// platform needs.  Each eviction lists the pressure its node was reporting to identify resource-starved nodes.
+// Upgrades roll every node, so evictions during them only flake.
+func platformPodEvictions(finalIntervals monitorapi.Intervals) []*junitapi.JUnitTestCase {
+	const testName = "[sig-node] kubelet should not evict pods in platform namespaces"
+
+	pressureIntervals := finalIntervals.Filter(func(eventInterval monitorapi.Interval) bool {
+		return eventInterval.Source == monitorapi.SourceNodeState && eventInterval.Message.Reason == monitorapi.NodeUnderPressure
+	})
+
+	var failures []string
+	for _, eviction := range finalIntervals {
+		if eviction.Source != monitorapi.SourcePodMonitor || eviction.Message.Reason != monitorapi.PodReasonEvicted {
+			continue
+		}
+		if !platformidentification.IsPlatformNamespace(eviction.Locator.Keys[monitorapi.LocatorNamespaceKey]) {
+			continue
+		}
+		failure := fmt.Sprintf("%v priorityClass=%q", eviction.String(), eviction.Message.Annotations[monitorapi.AnnotationPriorityClass])
+		node := eviction.Message.Annotations[monitorapi.AnnotationNode]
+		for _, pressure := range pressureIntervals {
+			if pressure.Locator.Keys[monitorapi.LocatorNodeKey] != node || eviction.From.Before(pressure.From) || eviction.From.After(pressure.To) {
+				continue
+			}
+			failure += fmt.Sprintf(" while node/%s reported %s", node, pressure.Message.Annotations[monitorapi.AnnotationState])
+		}
+		failures = append(failures, failure)
+	}
+
+	if len(failures) == 0 {
+		return []*junitapi.JUnitTestCase{{Name: testName}}
+	}
+	tests := []*junitapi.JUnitTestCase{
+		{
+			Name:      testName,
+			SystemOut: strings.Join(failures, "\n"),
+			FailureOutput: &junitapi.FailureOutput{
+				Output: fmt.Sprintf("kubelet evicted %d platform pods:\n\n%v", len(failures), strings.Join(failures, "\n")),
+			},
+		},
+	}
+	if platformidentification.DidUpgradeHappenDuringCollection(finalIntervals, time.Time{}, time.Time{}) {
+		tests = append(tests, &junitapi.JUnitTestCase{Name: testName})
+	}
+	return tests
+}
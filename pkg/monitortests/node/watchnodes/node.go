@@ -210,48 +210,11 @@ func startNodeMonitoring(ctx context.Context, m monitorapi.RecorderWriter, clien
 			}
 			return intervals
 		},
-		// Watch for node reporting DiskPressure and create a point in time interval if we see this.
-		// We don't particularly care how long it was reported for, only that it happened.
-		func(node, oldNode *corev1.Node) []monitorapi.Interval {
-			var intervals []monitorapi.Interval
-
-			var oldNodeDiskPressure bool
-			if oldNode != nil {
-				for _, c := range oldNode.Status.Conditions {
-					if c.Type == corev1.NodeDiskPressure && c.Status == corev1.ConditionTrue {
-						oldNodeDiskPressure = true
-					}
-				}
-			}
-			var newNodeDiskPressure bool
-			if node != nil {
-				for _, c := range node.Status.Conditions {
-					if c.Type == corev1.NodeDiskPressure && c.Status == corev1.ConditionTrue {
-						newNodeDiskPressure = true
-					}
-				}
-			}
-			now := time.Now()
-			if !oldNodeDiskPressure && newNodeDiskPressure {
-				intervals = append(intervals,
-					monitorapi.NewInterval(monitorapi.SourceNodeMonitor, monitorapi.Warning).
-						Locator(monitorapi.NewLocator().NodeFromName(node.Name)).
-						Message(monitorapi.NewMessage().Reason(monitorapi.NodeDiskPressure).
-							HumanMessage("kubelet began reporting disk pressure")).
-						Display().
-						Build(now, now))
-			}
-			if oldNodeDiskPressure && !newNodeDiskPressure {
-				intervals = append(intervals,
-					monitorapi.NewInterval(monitorapi.SourceNodeMonitor, monitorapi.Info).
-						Locator(monitorapi.NewLocator().NodeFromName(node.Name)).
-						Message(monitorapi.NewMessage().Reason(monitorapi.NodeNoDiskPressure).
-							HumanMessage("kubelet is not reporting disk pressure")).
-						Display().
-						Build(now, now))
-			}
-			return intervals
-		},
+		// Watch for node reporting resource pressure and create a point in time interval when it begins and ends.
+		// These are paired into NodeUnderPressure intervals when intervals are constructed.
+		nodePressureFn(corev1.NodeDiskPressure, monitorapi.NodeDiskPressure, monitorapi.NodeNoDiskPressure, "disk"),
+		nodePressureFn(corev1.NodeMemoryPressure, monitorapi.NodeMemoryPressure, monitorapi.NodeNoMemoryPressure, "memory"),
+		nodePressureFn(corev1.NodePIDPressure, monitorapi.NodePIDPressure, monitorapi.NodeNoPIDPressure, "PID"),
 	}
 
 	nodeInformer := informercorev1.NewNodeInformer(client, time.Hour, nil)
@@ -301,6 +264,50 @@ func startNodeMonitoring(ctx context.Context, m monitorapi.RecorderWriter, clien
 	go nodeInformer.Run(ctx.Done())
 }
 
+// nodePressureFn creates a point in time interval when the kubelet begins and stops reporting a pressure condition.
+func nodePressureFn(conditionType corev1.NodeConditionType, startReason, endReason monitorapi.IntervalReason, resource string) func(node, oldNode *corev1.Node) []monitorapi.Interval {
+	return func(node, oldNode *corev1.Node) []monitorapi.Interval {
+		var intervals []monitorapi.Interval
+
+		var oldNodePressure bool
+		if oldNode != nil {
+			for _, c := range oldNode.Status.Conditions {
+				if c.Type == conditionType && c.Status == corev1.ConditionTrue {
+					oldNodePressure = true
+				}
+			}
+		}
+		var newNodePressure bool
+		if node != nil {
+			for _, c := range node.Status.Conditions {
+				if c.Type == conditionType && c.Status == corev1.ConditionTrue {
+					newNodePressure = true
+				}
+			}
+		}
+		now := time.Now()
+		if !oldNodePressure && newNodePressure {
+			intervals = append(intervals,
+				monitorapi.NewInterval(monitorapi.SourceNodeMonitor, monitorapi.Warning).
+					Locator(monitorapi.NewLocator().NodeFromName(node.Name)).
+					Message(monitorapi.NewMessage().Reason(startReason).
+						HumanMessagef("kubelet began reporting %s pressure", resource)).
+					Display().
+					Build(now, now))
+		}
+		if oldNodePressure && !newNodePressure {
+			intervals = append(intervals,
+				monitorapi.NewInterval(monitorapi.SourceNodeMonitor, monitorapi.Info).
+					Locator(monitorapi.NewLocator().NodeFromName(node.Name)).
+					Message(monitorapi.NewMessage().Reason(endReason).
+						HumanMessagef("kubelet is not reporting %s pressure", resource)).
+					Display().
+					Build(now, now))
+		}
+		return intervals
+	}
+}
+
 func nodeRoles(node *corev1.Node) string {
 	const roleLabel = "node-role.kubernetes.io/"
 	var roles []string
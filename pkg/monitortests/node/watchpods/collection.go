@@ -3,6 +3,7 @@ package watchpods
 import (
 	"context"
 	"fmt"
+	"regexp"
 	"time"
 
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
@@ -298,11 +299,20 @@ func startPodMonitoring(ctx context.Context, recorderWriter monitorapi.RecorderW
 			}
 
 			if containerStatus.RestartCount != oldContainerStatus.RestartCount {
+				// the restart count can move by more than one between updates, so record how far it moved.
+				message := monitorapi.NewMessage().Reason(monitorapi.ContainerReasonRestarted)
+				if restarts := containerStatus.RestartCount - oldContainerStatus.RestartCount; restarts > 0 {
+					message = message.WithAnnotation(monitorapi.AnnotationCount, fmt.Sprintf("%d", restarts))
+				}
+				// the container exit is only recorded for the first termination, so carry the reason of every later one.
+				if lastTerminated := containerStatus.LastTerminationState.Terminated; lastTerminated != nil &&
+					!sameTermination(lastTerminated, oldContainerStatus.LastTerminationState.Terminated) {
+					message = message.Cause(lastTerminated.Reason)
+				}
 				intervals = append(intervals, monitorapi.NewInterval(monitorapi.SourcePodMonitor, monitorapi.Warning).
 					Locator(monitorapi.NewLocator().ContainerFromPod(pod, containerName)).
-					Message(
-						monitorapi.NewMessage().Reason(monitorapi.ContainerReasonRestarted),
-					).BuildNow())
+					Message(message).
+					BuildNow())
 			}
 		}
 
@@ -398,7 +408,12 @@ func startPodMonitoring(ctx context.Context, recorderWriter monitorapi.RecorderW
 				case "Evicted":
 					intervals = append(intervals, monitorapi.NewInterval(monitorapi.SourcePodMonitor, monitorapi.Warning).
 						Locator(monitorapi.NewLocator().PodFromPod(pod)).
-						Message(monitorapi.NewMessage().Reason(monitorapi.PodReasonEvicted).HumanMessage(pod.Status.Message)).
+						Message(monitorapi.NewMessage().Reason(monitorapi.PodReasonEvicted).
+							Node(pod.Spec.NodeName).
+							WithAnnotation(monitorapi.AnnotationPriorityClass, pod.Spec.PriorityClassName).
+							WithAnnotation(monitorapi.AnnotationResource, evictedResource(pod.Status.Message)).
+							HumanMessage(pod.Status.Message)).
+						Display().
 						BuildNow())
 				case "Preempting":
 					intervals = append(intervals, monitorapi.NewInterval(monitorapi.SourcePodMonitor, monitorapi.Error).
@@ -589,6 +604,14 @@ func toUpdateFns(podUpdateFns []func(pod, oldPod *corev1.Pod) []monitorapi.Inter
 	return ret
 }
 
+// sameTermination returns true if both states describe the same terminated container.
+func sameTermination(a, b *corev1.ContainerStateTerminated) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.ContainerID == b.ContainerID && a.FinishedAt.Equal(&b.FinishedAt)
+}
+
 func lastContainerTimeFromStatus(current *corev1.ContainerStatus) time.Time {
 	if state := current.LastTerminationState.Terminated; state != nil && !state.FinishedAt.Time.IsZero() {
 		return state.FinishedAt.Time
@@ -608,6 +631,17 @@ func intervalsForTransitioningContainer(pod *corev1.Pod, current *corev1.Contain
 	}
 }
 
+var evictedResourceRegex = regexp.MustCompile(`low on resource: ([\w-]+)`)
+
+// evictedResource finds the resource the kubelet was starved of in an eviction message, like
+// "The node was low on resource: memory. Threshold quantity: 100Mi, available: 88Mi."
+func evictedResource(message string) string {
+	if m := evictedResourceRegex.FindStringSubmatch(message); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
 func isMirrorPod(pod *corev1.Pod) bool {
 	return len(pod.Annotations["kubernetes.io/config.mirror"]) > 0
 }
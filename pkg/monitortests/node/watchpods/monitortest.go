@@ -8,7 +8,10 @@ import (
 
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
 	"github.com/openshift/origin/pkg/monitortestframework"
+	"github.com/openshift/origin/pkg/monitortestlibrary/historicaldata"
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
 	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+	"github.com/sirupsen/logrus"
 	coreinformers "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -17,12 +20,23 @@ import (
 type podWatcher struct {
 	kubeClient  kubernetes.Interface
 	podInformer coreinformers.PodInformer
+
+	historicalData *historicaldata.DisruptionBestMatcher
+	jobType        *platformidentification.JobType
 }
 
 func NewPodWatcher() monitortestframework.MonitorTest {
 	return &podWatcher{}
 }
 
+// NewPodWatcherWithHistoricalData takes pod startup latency thresholds from historical data.  Data is keyed by
+// HistoricalStartupLatencyName, and the P99 across runs of the p95 of platform namespaces is the threshold.  The
+// default monitor tests read it from the allowedlatency query results, which are built from the latencies written by
+// WriteContentToStorage.
+func NewPodWatcherWithHistoricalData(historicalData *historicaldata.DisruptionBestMatcher) monitortestframework.MonitorTest {
+	return &podWatcher{historicalData: historicalData}
+}
+
 func (w *podWatcher) StartCollection(ctx context.Context, adminRESTConfig *rest.Config, recorder monitorapi.RecorderWriter) error {
 	var err error
 	w.kubeClient, err = kubernetes.NewForConfig(adminRESTConfig)
@@ -30,6 +44,14 @@ func (w *podWatcher) StartCollection(ctx context.Context, adminRESTConfig *rest.
 		return err
 	}
 
+	if w.historicalData != nil {
+		w.jobType, err = platformidentification.GetJobType(ctx, adminRESTConfig)
+		if err != nil {
+			// default thresholds still apply.
+			logrus.WithError(err).Warning("unable to determine job type for pod startup latency")
+		}
+	}
+
 	w.podInformer = startPodMonitoring(ctx, recorder, w.kubeClient)
 
 	return nil
@@ -44,13 +66,15 @@ func (*podWatcher) ConstructComputedIntervals(ctx context.Context, startingInter
 	constructedIntervals := monitorapi.Intervals{}
 	constructedIntervals = append(constructedIntervals, createPodIntervalsFromInstants(startingIntervals, recordedResources, beginning, end)...)
 	constructedIntervals = append(constructedIntervals, intervalsFromEvents_PodChanges(startingIntervals, beginning, end)...)
+	constructedIntervals = append(constructedIntervals, constructPodStartupIntervals(startingIntervals, recordedResources, beginning)...)
 
 	return constructedIntervals, nil
 }
 
 func (w *podWatcher) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
+	ret := w.podStartupLatencyJunits(finalIntervals)
 	if w.podInformer == nil {
-		return nil, nil
+		return ret, nil
 	}
 
 	cacheFailures, err := checkCacheState(ctx, w.kubeClient, w.podInformer)
@@ -59,7 +83,6 @@ func (w *podWatcher) EvaluateTestsFromConstructedIntervals(ctx context.Context,
 	}
 
 	testName := "[sig-apimachinery] informers must match live results at the same resource version"
-	ret := []*junitapi.JUnitTestCase{}
 	if len(cacheFailures) > 0 {
 		ret = append(ret,
 			&junitapi.JUnitTestCase{
@@ -88,7 +111,7 @@ func (w *podWatcher) EvaluateTestsFromConstructedIntervals(ctx context.Context,
 }
 
 func (*podWatcher) WriteContentToStorage(ctx context.Context, storageDir, timeSuffix string, finalIntervals monitorapi.Intervals, finalResourceState monitorapi.ResourcesMap) error {
-	return nil
+	return writePodStartupLatencies(storageDir, timeSuffix, finalIntervals)
 }
 
 func (*podWatcher) Cleanup(ctx context.Context) error {
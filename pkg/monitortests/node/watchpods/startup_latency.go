@@ -0,0 +1,358 @@
+package watchpods
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openshift/origin/pkg/dataloader"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// startupPhase is one step of a pod starting, named as it is written to the data file.
+type startupPhase string
+
+const (
+	// scheduling is from creation until a node is assigned.
+	phaseScheduling startupPhase = "Scheduling"
+	// sandbox is from scheduling until the first container starts, less the time spent pulling images.
+	phaseSandbox startupPhase = "SandboxCreation"
+	// imagePull is the time the kubelet reported pulling images before the first container started.
+	phaseImagePull startupPhase = "ImagePull"
+	// readiness is from the first container starting until every container is ready.
+	phaseReadiness startupPhase = "Readiness"
+)
+
+var startupPhaseAnnotations = map[startupPhase]monitorapi.AnnotationKey{
+	phaseScheduling: monitorapi.AnnotationScheduling,
+	phaseSandbox:    monitorapi.AnnotationSandbox,
+	phaseImagePull:  monitorapi.AnnotationImagePull,
+	phaseReadiness:  monitorapi.AnnotationReadiness,
+}
+
+// defaultStartupThresholds apply to phases without enough historical data.  They bound the p95 of each phase for a
+// platform namespace.  Upgrades drain nodes, so scheduling and image pulls are given the most room.
+var defaultStartupThresholds = map[startupPhase]time.Duration{
+	phaseScheduling: 2 * time.Minute,
+	phaseSandbox:    1 * time.Minute,
+	phaseImagePull:  3 * time.Minute,
+	phaseReadiness:  5 * time.Minute,
+}
+
+// podStartup tracks the instants a pod passed through while starting.
+type podStartup struct {
+	pod       *corev1.Pod
+	scheduled time.Time
+	started   time.Time
+	// containersReady is when each container in the spec first became ready.  Init containers are not included.
+	containersReady map[string]time.Time
+	imagePull       time.Duration
+}
+
+// constructPodStartupIntervals creates an interval from creation until ready for every pod created after beginning,
+// annotated with how long it spent in each startupPhase.  Pods that existed before beginning are skipped because all
+// of their transitions are observed at once when the watch starts.
+func constructPodStartupIntervals(startingIntervals monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, beginning time.Time) monitorapi.Intervals {
+	startups := map[monitorapi.InstanceKey]*podStartup{}
+	// kubelet events do not carry the pod UID, so image pulls are matched by name to the most recently created pod.
+	byName := map[monitorapi.NamespacedReference]*podStartup{}
+	for _, obj := range recordedResources["pods"] {
+		pod, ok := obj.(*corev1.Pod)
+		if !ok || isMirrorPod(pod) || pod.CreationTimestamp.Time.Before(beginning) {
+			continue
+		}
+		startup := &podStartup{pod: pod, containersReady: map[string]time.Time{}}
+		startups[monitorapi.InstanceKey{Namespace: pod.Namespace, Name: pod.Name, UID: string(pod.UID)}] = startup
+		nameKey := monitorapi.NamespacedReference{Namespace: pod.Namespace, Name: pod.Name}
+		if existing, ok := byName[nameKey]; !ok || existing.pod.CreationTimestamp.Before(&pod.CreationTimestamp) {
+			byName[nameKey] = startup
+		}
+	}
+	if len(startups) == 0 {
+		return nil
+	}
+
+	pulls := monitorapi.Intervals{}
+	for _, interval := range startingIntervals {
+		if interval.Source == monitorapi.SourceKubeEvent && interval.Message.Reason == "Pulled" {
+			pulls = append(pulls, interval)
+			continue
+		}
+		if interval.Source != monitorapi.SourcePodMonitor {
+			continue
+		}
+		podRef := monitorapi.PodFrom(interval.Locator)
+		startup, ok := startups[monitorapi.InstanceKey{Namespace: podRef.Namespace, Name: podRef.Name, UID: podRef.UID}]
+		if !ok {
+			continue
+		}
+		switch interval.Message.Reason {
+		case monitorapi.PodReasonScheduled:
+			if startup.scheduled.IsZero() {
+				startup.scheduled = interval.From
+			}
+		case monitorapi.ContainerReasonContainerStart:
+			if startup.started.IsZero() {
+				startup.started = interval.From
+			}
+		case monitorapi.ContainerReasonReady:
+			containerName := interval.Locator.Keys[monitorapi.LocatorContainerKey]
+			if _, ok := startup.containersReady[containerName]; !ok {
+				startup.containersReady[containerName] = interval.From
+			}
+		}
+	}
+
+	for _, pull := range pulls {
+		startup, ok := byName[monitorapi.NamespacedReference{
+			Namespace: pull.Locator.Keys[monitorapi.LocatorNamespaceKey],
+			Name:      pull.Locator.Keys[monitorapi.LocatorPodKey],
+		}]
+		if !ok || startup.started.IsZero() || pull.From.After(startup.started) {
+			continue
+		}
+		// images that were already present have no duration.
+		if duration, err := time.ParseDuration(pull.Message.Annotations[monitorapi.AnnotationDuration]); err == nil {
+			startup.imagePull += duration
+		}
+	}
+
+	ret := monitorapi.Intervals{}
+	for _, startup := range startups {
+		ready, ok := startup.readyTime()
+		if !ok || startup.scheduled.IsZero() || startup.started.IsZero() {
+			continue
+		}
+		created := startup.pod.CreationTimestamp.Time
+		phases := map[startupPhase]time.Duration{
+			phaseScheduling: nonNegative(startup.scheduled.Sub(created)),
+			phaseSandbox:    nonNegative(startup.started.Sub(startup.scheduled) - startup.imagePull),
+			phaseImagePull:  startup.imagePull,
+			phaseReadiness:  nonNegative(ready.Sub(startup.started)),
+		}
+
+		message := monitorapi.NewMessage().Reason(monitorapi.PodReasonStartupLatency).
+			Constructed(monitorapi.ConstructionOwnerPodLifecycle).
+			WithAnnotation(monitorapi.AnnotationWorkload, workloadFor(startup.pod))
+		for phase, annotation := range startupPhaseAnnotations {
+			message = message.WithAnnotation(annotation, phases[phase].Round(time.Millisecond).String())
+		}
+		ret = append(ret,
+			monitorapi.NewInterval(monitorapi.SourcePodState, monitorapi.Info).
+				Locator(monitorapi.NewLocator().PodFromPod(startup.pod)).
+				Message(message.HumanMessagef("scheduled after %v, started after %v (%v pulling images), ready after %v",
+					phases[phaseScheduling].Round(time.Second),
+					startup.started.Sub(created).Round(time.Second),
+					phases[phaseImagePull].Round(time.Second),
+					ready.Sub(created).Round(time.Second))).
+				Build(created, ready),
+		)
+	}
+	sort.Sort(ret)
+	return ret
+}
+
+// readyTime is when the last container in the spec first became ready.
+func (s *podStartup) readyTime() (time.Time, bool) {
+	ready := time.Time{}
+	for _, container := range s.pod.Spec.Containers {
+		containerReady, ok := s.containersReady[container.Name]
+		if !ok {
+			return time.Time{}, false
+		}
+		if containerReady.After(ready) {
+			ready = containerReady
+		}
+	}
+	return ready, !ready.IsZero()
+}
+
+func nonNegative(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// workloadFor names the controller that created the pod, looking through replicasets to their deployment.
+func workloadFor(pod *corev1.Pod) string {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return "pod/" + pod.Name
+	}
+	if owner.Kind == "ReplicaSet" {
+		if hash := pod.Labels["pod-template-hash"]; len(hash) > 0 {
+			return "deployment/" + strings.TrimSuffix(owner.Name, "-"+hash)
+		}
+	}
+	return strings.ToLower(owner.Kind) + "/" + owner.Name
+}
+
+type startupLatencyKey struct {
+	namespace string
+	// workload is empty for the summary of the whole namespace.
+	workload string
+	phase    startupPhase
+}
+
+type startupLatency struct {
+	startupLatencyKey
+	count         int
+	p50, p95, p99 time.Duration
+}
+
+func summarizePodStartupLatencies(finalIntervals monitorapi.Intervals) []startupLatency {
+	durations := map[startupLatencyKey][]time.Duration{}
+	for _, interval := range finalIntervals {
+		if interval.Source != monitorapi.SourcePodState || interval.Message.Reason != monitorapi.PodReasonStartupLatency {
+			continue
+		}
+		namespace := interval.Locator.Keys[monitorapi.LocatorNamespaceKey]
+		workload := interval.Message.Annotations[monitorapi.AnnotationWorkload]
+		for phase, annotation := range startupPhaseAnnotations {
+			duration, err := time.ParseDuration(interval.Message.Annotations[annotation])
+			if err != nil {
+				continue
+			}
+			for _, key := range []startupLatencyKey{
+				{namespace: namespace, phase: phase},
+				{namespace: namespace, workload: workload, phase: phase},
+			} {
+				durations[key] = append(durations[key], duration)
+			}
+		}
+	}
+
+	ret := []startupLatency{}
+	for key, values := range durations {
+		sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+		ret = append(ret, startupLatency{
+			startupLatencyKey: key,
+			count:             len(values),
+			p50:               percentile(values, 0.50),
+			p95:               percentile(values, 0.95),
+			p99:               percentile(values, 0.99),
+		})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].namespace != ret[j].namespace {
+			return ret[i].namespace < ret[j].namespace
+		}
+		if ret[i].workload != ret[j].workload {
+			return ret[i].workload < ret[j].workload
+		}
+		return ret[i].phase < ret[j].phase
+	})
+	return ret
+}
+
+// percentile uses the nearest rank of sorted values.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
+
+func writePodStartupLatencies(storageDir, timeSuffix string, finalIntervals monitorapi.Intervals) error {
+	rows := []map[string]string{}
+	for _, latency := range summarizePodStartupLatencies(finalIntervals) {
+		rows = append(rows, map[string]string{
+			"Namespace":  latency.namespace,
+			"Workload":   latency.workload,
+			"Phase":      string(latency.phase),
+			"Count":      fmt.Sprintf("%d", latency.count),
+			"P50Seconds": fmt.Sprintf("%.3f", latency.p50.Seconds()),
+			"P95Seconds": fmt.Sprintf("%.3f", latency.p95.Seconds()),
+			"P99Seconds": fmt.Sprintf("%.3f", latency.p99.Seconds()),
+		})
+	}
+
+	dataFile := dataloader.DataFile{
+		TableName: "pod_startup_latency",
+		Schema: map[string]dataloader.DataType{
+			"Namespace":  dataloader.DataTypeString,
+			"Workload":   dataloader.DataTypeString,
+			"Phase":      dataloader.DataTypeString,
+			"Count":      dataloader.DataTypeInteger,
+			"P50Seconds": dataloader.DataTypeFloat64,
+			"P95Seconds": dataloader.DataTypeFloat64,
+			"P99Seconds": dataloader.DataTypeFloat64,
+		},
+		Rows: rows,
+	}
+	fileName := filepath.Join(storageDir, fmt.Sprintf("pod-startup-latency%s-%s", timeSuffix, dataloader.AutoDataLoaderSuffix))
+	return dataloader.WriteDataFile(fileName, dataFile)
+}
+
+// HistoricalStartupLatencyName is the name historical data for the p95 latency of platform namespaces is recorded
+// under for a startup phase, like "pod-startup-latency-imagepull".  Its P99 is how high that p95 gets across runs.
+func HistoricalStartupLatencyName(phase string) string {
+	return "pod-startup-latency-" + strings.ToLower(phase)
+}
+
+func startupLatencyTestName(phase startupPhase) string {
+	return fmt.Sprintf("[sig-node] pods in platform namespaces should have a p95 %s latency within the allowed threshold", strings.ToLower(string(phase)))
+}
+
+// startupLatencyThreshold returns the P99 across runs of the p95 from historical data when there is enough of it,
+// otherwise the default.
+func (w *podWatcher) startupLatencyThreshold(phase startupPhase) (time.Duration, string) {
+	if w.historicalData != nil && w.jobType != nil {
+		p99, details, err := w.historicalData.BestMatchP99(HistoricalStartupLatencyName(string(phase)), *w.jobType)
+		if err == nil && p99 != nil {
+			return *p99, fmt.Sprintf("historical P99 of the p95 %s", details)
+		}
+	}
+	return defaultStartupThresholds[phase], "default"
+}
+
+// podStartupLatencyJunits compares the p95 of every platform namespace to the threshold for each phase.  These are
+// new, so they only flake.
+func (w *podWatcher) podStartupLatencyJunits(finalIntervals monitorapi.Intervals) []*junitapi.JUnitTestCase {
+	phases := []startupPhase{phaseScheduling, phaseSandbox, phaseImagePull, phaseReadiness}
+	thresholds := map[startupPhase]time.Duration{}
+	thresholdSources := map[startupPhase]string{}
+	for _, phase := range phases {
+		thresholds[phase], thresholdSources[phase] = w.startupLatencyThreshold(phase)
+	}
+
+	slow := map[startupPhase][]string{}
+	for _, latency := range summarizePodStartupLatencies(finalIntervals) {
+		if len(latency.workload) > 0 || !platformidentification.IsPlatformNamespace(latency.namespace) {
+			continue
+		}
+		if latency.p95 > thresholds[latency.phase] {
+			slow[latency.phase] = append(slow[latency.phase],
+				fmt.Sprintf("namespace/%s p95=%v over %d pods", latency.namespace, latency.p95.Round(time.Second), latency.count))
+		}
+	}
+
+	ret := []*junitapi.JUnitTestCase{}
+	for _, phase := range phases {
+		testName := startupLatencyTestName(phase)
+		if len(slow[phase]) > 0 {
+			ret = append(ret, &junitapi.JUnitTestCase{
+				Name: testName,
+				FailureOutput: &junitapi.FailureOutput{
+					Output: fmt.Sprintf("%d namespaces had a p95 %s latency over %v (%s)\n\n%v",
+						len(slow[phase]), strings.ToLower(string(phase)), thresholds[phase], thresholdSources[phase], strings.Join(slow[phase], "\n")),
+				},
+			})
+		}
+		ret = append(ret, &junitapi.JUnitTestCase{Name: testName})
+	}
+	return ret
+}
@@ -0,0 +1,169 @@
+package watchpods
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestlibrary/historicaldata"
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+)
+
+func TestConstructPodStartupIntervals(t *testing.T) {
+	beginning := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	newPod := func(namespace, name, uid string, created time.Time) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:         namespace,
+				Name:              name,
+				UID:               types.UID(uid),
+				CreationTimestamp: metav1.NewTime(created),
+				Labels:            map[string]string{"pod-template-hash": "5d8f7"},
+				OwnerReferences: []metav1.OwnerReference{
+					{Kind: "ReplicaSet", Name: "console-5d8f7", Controller: pointer.Bool(true)},
+				},
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "console"}, {Name: "proxy"}},
+			},
+		}
+	}
+	console := newPod("openshift-console", "console-5d8f7-abcde", "uid-1", beginning.Add(time.Minute))
+	// existed before monitoring started
+	oldConsole := newPod("openshift-console", "console-5d8f7-vwxyz", "uid-2", beginning.Add(-time.Hour))
+	// never becomes ready
+	stuck := newPod("openshift-console", "console-5d8f7-stuck", "uid-3", beginning.Add(time.Minute))
+
+	podEvent := func(pod *corev1.Pod, container string, reason monitorapi.IntervalReason, at time.Duration) monitorapi.Interval {
+		locator := monitorapi.NewLocator().PodFromPod(pod)
+		if len(container) > 0 {
+			locator = monitorapi.NewLocator().ContainerFromPod(pod, container)
+		}
+		return monitorapi.NewInterval(monitorapi.SourcePodMonitor, monitorapi.Info).
+			Locator(locator).
+			Message(monitorapi.NewMessage().Reason(reason)).
+			Build(pod.CreationTimestamp.Add(at), pod.CreationTimestamp.Add(at))
+	}
+	pulled := func(pod *corev1.Pod, at time.Duration, duration string) monitorapi.Interval {
+		return monitorapi.NewInterval(monitorapi.SourceKubeEvent, monitorapi.Info).
+			Locator(monitorapi.NewLocator().PodFromNames(pod.Namespace, pod.Name, "")).
+			Message(monitorapi.NewMessage().Reason("Pulled").WithAnnotation(monitorapi.AnnotationDuration, duration)).
+			Build(pod.CreationTimestamp.Add(at), pod.CreationTimestamp.Add(at))
+	}
+
+	startingIntervals := monitorapi.Intervals{
+		podEvent(console, "", monitorapi.PodReasonCreated, 0),
+		podEvent(console, "", monitorapi.PodReasonScheduled, 2*time.Second),
+		pulled(console, 20*time.Second, "12.500s"),
+		// pulled for a container that starts later, not part of the first start
+		pulled(console, 40*time.Second, "5.000s"),
+		podEvent(console, "console", monitorapi.ContainerReasonContainerStart, 30*time.Second),
+		podEvent(console, "console", monitorapi.ContainerReasonReady, 45*time.Second),
+		podEvent(console, "proxy", monitorapi.ContainerReasonReady, time.Minute),
+		podEvent(console, "proxy", monitorapi.ContainerReasonReady, 2*time.Minute),
+
+		podEvent(oldConsole, "", monitorapi.PodReasonScheduled, time.Hour),
+		podEvent(oldConsole, "console", monitorapi.ContainerReasonContainerStart, time.Hour),
+		podEvent(oldConsole, "console", monitorapi.ContainerReasonReady, time.Hour),
+		podEvent(oldConsole, "proxy", monitorapi.ContainerReasonReady, time.Hour),
+
+		podEvent(stuck, "", monitorapi.PodReasonScheduled, time.Second),
+		podEvent(stuck, "console", monitorapi.ContainerReasonContainerStart, 10*time.Second),
+		podEvent(stuck, "console", monitorapi.ContainerReasonReady, 20*time.Second),
+	}
+	recordedResources := monitorapi.ResourcesMap{
+		"pods": monitorapi.InstanceMap{
+			{Namespace: console.Namespace, Name: console.Name, UID: string(console.UID)}:          console,
+			{Namespace: oldConsole.Namespace, Name: oldConsole.Name, UID: string(oldConsole.UID)}: oldConsole,
+			{Namespace: stuck.Namespace, Name: stuck.Name, UID: string(stuck.UID)}:                stuck,
+		},
+	}
+
+	constructed := constructPodStartupIntervals(startingIntervals, recordedResources, beginning)
+	if len(constructed) != 1 {
+		t.Fatalf("expected one interval, got %v", constructed)
+	}
+	interval := constructed[0]
+	if interval.Locator.Keys[monitorapi.LocatorPodKey] != console.Name {
+		t.Errorf("unexpected pod: %v", interval.Locator)
+	}
+	if !interval.From.Equal(console.CreationTimestamp.Time) || !interval.To.Equal(console.CreationTimestamp.Add(time.Minute)) {
+		t.Errorf("unexpected times: %v - %v", interval.From, interval.To)
+	}
+	expected := map[monitorapi.AnnotationKey]string{
+		monitorapi.AnnotationWorkload:   "deployment/console",
+		monitorapi.AnnotationScheduling: "2s",
+		monitorapi.AnnotationSandbox:    "15.5s",
+		monitorapi.AnnotationImagePull:  "12.5s",
+		monitorapi.AnnotationReadiness:  "30s",
+	}
+	for key, value := range expected {
+		if actual := interval.Message.Annotations[key]; actual != value {
+			t.Errorf("expected %s=%q, got %q", key, value, actual)
+		}
+	}
+
+	latencies := summarizePodStartupLatencies(constructed)
+	// one namespace and one workload for each of the four phases
+	if len(latencies) != 8 {
+		t.Fatalf("expected 8 latencies, got %v", latencies)
+	}
+	if latencies[0].workload != "" || latencies[4].workload != "deployment/console" {
+		t.Errorf("expected namespace summaries before workloads, got %v", latencies)
+	}
+}
+
+func TestPodStartupLatencyJunits(t *testing.T) {
+	startup := func(namespace string, readiness time.Duration) monitorapi.Interval {
+		return monitorapi.NewInterval(monitorapi.SourcePodState, monitorapi.Info).
+			Locator(monitorapi.NewLocator().PodFromNames(namespace, "pod", "uid")).
+			Message(monitorapi.NewMessage().Reason(monitorapi.PodReasonStartupLatency).
+				WithAnnotation(monitorapi.AnnotationWorkload, "pod/pod").
+				WithAnnotation(monitorapi.AnnotationScheduling, "1s").
+				WithAnnotation(monitorapi.AnnotationSandbox, "1s").
+				WithAnnotation(monitorapi.AnnotationImagePull, "0s").
+				WithAnnotation(monitorapi.AnnotationReadiness, readiness.String())).
+			Build(time.Now(), time.Now())
+	}
+	finalIntervals := monitorapi.Intervals{
+		startup("openshift-console", 10*time.Minute),
+		// not a platform namespace
+		startup("e2e-test", time.Hour),
+	}
+	failures := func(w *podWatcher) map[string]int {
+		junits := w.podStartupLatencyJunits(finalIntervals)
+		failures := map[string]int{}
+		for _, junit := range junits {
+			if junit.FailureOutput != nil {
+				failures[junit.Name]++
+			}
+		}
+		return failures
+	}
+
+	if defaults := failures(&podWatcher{}); len(defaults) != 1 || defaults[startupLatencyTestName(phaseReadiness)] != 1 {
+		t.Errorf("expected only readiness to flake, got %v", defaults)
+	}
+
+	// historical data is tighter than the default for sandbox creation and looser for readiness.
+	jobType := platformidentification.JobType{Release: "4.16", Platform: "aws", Architecture: "amd64", Network: "ovn", Topology: "ha"}
+	statistics := func(phase startupPhase, p99 float64) (historicaldata.DataKey, historicaldata.DisruptionStatisticalData) {
+		key := historicaldata.DataKey{BackendName: HistoricalStartupLatencyName(string(phase)), JobType: jobType}
+		return key, historicaldata.DisruptionStatisticalData{DataKey: key, P99: p99, JobRuns: 1000}
+	}
+	data := map[historicaldata.DataKey]historicaldata.DisruptionStatisticalData{}
+	for phase, p99 := range map[startupPhase]float64{phaseSandbox: 0.5, phaseReadiness: 900} {
+		key, value := statistics(phase, p99)
+		data[key] = value
+	}
+	historical := historicaldata.NewDisruptionMatcherWithHistoricalData(data)
+	withHistory := failures(&podWatcher{historicalData: historical, jobType: &jobType})
+	if len(withHistory) != 1 || withHistory[startupLatencyTestName(phaseSandbox)] != 1 {
+		t.Errorf("expected only sandbox creation to flake, got %v", withHistory)
+	}
+}
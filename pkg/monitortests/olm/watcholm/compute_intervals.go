@@ -0,0 +1,176 @@
+package watcholm
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+func intervalsByLocator(intervals monitorapi.Intervals, reasons ...monitorapi.IntervalReason) map[string]monitorapi.Intervals {
+	ret := map[string]monitorapi.Intervals{}
+	for _, interval := range intervals {
+		if interval.Source != monitorapi.SourceOLM {
+			continue
+		}
+		for _, reason := range reasons {
+			if interval.Message.Reason != reason {
+				continue
+			}
+			key := interval.Locator.OldLocator()
+			ret[key] = append(ret[key], interval)
+		}
+	}
+	for _, locatorIntervals := range ret {
+		sort.SliceStable(locatorIntervals, func(i, j int) bool {
+			return locatorIntervals[i].From.Before(locatorIntervals[j].From)
+		})
+	}
+	return ret
+}
+
+// constructStateIntervals creates an interval for every period an object spent in a state that include returns true for.
+// The previous state is kept, so that states the object was already in when the watch started can be told apart.
+func constructStateIntervals(startingIntervals monitorapi.Intervals, end time.Time, changedReason, reason monitorapi.IntervalReason, include func(state string) bool, levelFor func(state string) monitorapi.IntervalLevel) monitorapi.Intervals {
+	constructedIntervals := monitorapi.Intervals{}
+
+	for _, changes := range intervalsByLocator(startingIntervals, changedReason) {
+		for i, change := range changes {
+			state := change.Message.Annotations[monitorapi.AnnotationState]
+			if !include(state) {
+				continue
+			}
+			to := end
+			if i+1 < len(changes) {
+				to = changes[i+1].From
+			}
+			constructedIntervals = append(constructedIntervals,
+				monitorapi.NewInterval(monitorapi.SourceOLM, levelFor(state)).
+					Locator(change.Locator).
+					Message(monitorapi.NewMessage().Reason(reason).
+						Constructed(monitorapi.ConstructionOwnerOLMLifecycle).
+						WithAnnotation(monitorapi.AnnotationState, state).
+						WithAnnotation(monitorapi.AnnotationPreviousState, change.Message.Annotations[monitorapi.AnnotationPreviousState]).
+						HumanMessage(change.Message.HumanMessage)).
+					Display().
+					Build(change.From, to),
+			)
+		}
+	}
+
+	return constructedIntervals
+}
+
+// constructCSVPhaseIntervals creates an interval for every period a CSV was not Succeeded.
+func constructCSVPhaseIntervals(startingIntervals monitorapi.Intervals, end time.Time) monitorapi.Intervals {
+	return constructStateIntervals(startingIntervals, end,
+		monitorapi.OLMClusterServiceVersionPhaseChanged, monitorapi.OLMClusterServiceVersionPhase,
+		func(phase string) bool {
+			return len(phase) > 0 && phase != csvPhaseSucceeded
+		},
+		failedIsError,
+	)
+}
+
+// constructCatalogSourceIntervals creates an interval for every period a catalog source connection was not READY.
+// Catalog sources that are not served over grpc have no connection state and are left alone.
+func constructCatalogSourceIntervals(startingIntervals monitorapi.Intervals, end time.Time) monitorapi.Intervals {
+	return constructStateIntervals(startingIntervals, end,
+		monitorapi.OLMCatalogSourceStateChanged, monitorapi.OLMCatalogSourceNotReady,
+		func(state string) bool {
+			return len(state) > 0 && state != catalogSourceReady
+		},
+		func(string) monitorapi.IntervalLevel {
+			return monitorapi.Warning
+		},
+	)
+}
+
+// constructInstallPlanIntervals creates intervals for how long each install plan created after beginning waited for
+// approval and then took to install.  Automatic install plans install from creation.
+func constructInstallPlanIntervals(startingIntervals monitorapi.Intervals, beginning, end time.Time) monitorapi.Intervals {
+	constructedIntervals := monitorapi.Intervals{}
+
+	for _, changes := range intervalsByLocator(startingIntervals,
+		monitorapi.OLMInstallPlanCreated, monitorapi.OLMInstallPlanPhaseChanged, monitorapi.OLMInstallPlanApproved) {
+
+		var created, requiresApproval, approved, finished *monitorapi.Interval
+		for i := range changes {
+			change := &changes[i]
+			switch change.Message.Reason {
+			case monitorapi.OLMInstallPlanCreated:
+				created = change
+			case monitorapi.OLMInstallPlanApproved:
+				if approved == nil {
+					approved = change
+				}
+			case monitorapi.OLMInstallPlanPhaseChanged:
+				switch change.Message.Annotations[monitorapi.AnnotationState] {
+				case installPlanApproval:
+					if requiresApproval == nil {
+						requiresApproval = change
+					}
+				case installPlanComplete, installPlanFailed:
+					if finished == nil {
+						finished = change
+					}
+				}
+			}
+		}
+		if created == nil || created.From.Before(beginning) {
+			continue
+		}
+		message := func(reason monitorapi.IntervalReason, duration time.Duration) *monitorapi.MessageBuilder {
+			return monitorapi.NewMessage().Reason(reason).
+				Constructed(monitorapi.ConstructionOwnerOLMLifecycle).
+				WithAnnotation(monitorapi.AnnotationApproval, created.Message.Annotations[monitorapi.AnnotationApproval]).
+				WithAnnotation(monitorapi.AnnotationCSV, created.Message.Annotations[monitorapi.AnnotationCSV]).
+				WithAnnotation(monitorapi.AnnotationDuration, duration.Round(time.Second).String())
+		}
+
+		installingSince := created
+		if requiresApproval != nil {
+			if approved == nil {
+				// manual approval may never be intended, so this is not a warning.
+				constructedIntervals = append(constructedIntervals,
+					monitorapi.NewInterval(monitorapi.SourceOLM, monitorapi.Info).
+						Locator(created.Locator).
+						Message(message(monitorapi.OLMInstallPlanWaitingForApproval, end.Sub(requiresApproval.From)).
+							HumanMessage("never approved")).
+						Display().
+						Build(requiresApproval.From, end),
+				)
+				continue
+			}
+			constructedIntervals = append(constructedIntervals,
+				monitorapi.NewInterval(monitorapi.SourceOLM, monitorapi.Info).
+					Locator(created.Locator).
+					Message(message(monitorapi.OLMInstallPlanWaitingForApproval, approved.From.Sub(requiresApproval.From)).
+						HumanMessage("waiting for approval")).
+					Display().
+					Build(requiresApproval.From, approved.From),
+			)
+			installingSince = approved
+		}
+
+		level := monitorapi.Warning
+		to := end
+		humanMessage := "never completed"
+		if finished != nil {
+			to = finished.From
+			level = failedIsError(finished.Message.Annotations[monitorapi.AnnotationState])
+			humanMessage = fmt.Sprintf("installing, ended %s", finished.Message.Annotations[monitorapi.AnnotationState])
+		}
+		constructedIntervals = append(constructedIntervals,
+			monitorapi.NewInterval(monitorapi.SourceOLM, level).
+				Locator(created.Locator).
+				Message(message(monitorapi.OLMInstallPlanInstalling, to.Sub(installingSince.From)).
+					HumanMessage(humanMessage)).
+				Display().
+				Build(installingSince.From, to),
+		)
+	}
+
+	return constructedIntervals
+}
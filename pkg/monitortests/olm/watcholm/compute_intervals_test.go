@@ -0,0 +1,181 @@
+package watcholm
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var start = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func olmChange(at time.Duration, resource, namespace, name string, reason monitorapi.IntervalReason, annotations map[monitorapi.AnnotationKey]string) monitorapi.Interval {
+	message := monitorapi.NewMessage().Reason(reason).HumanMessage("changed")
+	for key, value := range annotations {
+		message = message.WithAnnotation(key, value)
+	}
+	return monitorapi.NewInterval(monitorapi.SourceOLM, monitorapi.Info).
+		Locator(monitorapi.NewLocator().Resource("operators.coreos.com", resource, namespace, name)).
+		Message(message).
+		Build(start.Add(at), start.Add(at))
+}
+
+func stateChange(at time.Duration, resource, namespace, name string, reason monitorapi.IntervalReason, previousState, state string) monitorapi.Interval {
+	return olmChange(at, resource, namespace, name, reason, map[monitorapi.AnnotationKey]string{
+		monitorapi.AnnotationState:         state,
+		monitorapi.AnnotationPreviousState: previousState,
+	})
+}
+
+func TestConstructAndEvaluate(t *testing.T) {
+	csvChanged := monitorapi.OLMClusterServiceVersionPhaseChanged
+	planChanged := monitorapi.OLMInstallPlanPhaseChanged
+	catalogChanged := monitorapi.OLMCatalogSourceStateChanged
+	manual := map[monitorapi.AnnotationKey]string{monitorapi.AnnotationApproval: "Manual", monitorapi.AnnotationCSV: "etcd.v0.9.4"}
+	automatic := map[monitorapi.AnnotationKey]string{monitorapi.AnnotationApproval: "Automatic", monitorapi.AnnotationCSV: "etcd.v0.9.4"}
+
+	startingIntervals := monitorapi.Intervals{
+		stateChange(0, "clusterserviceversions", "e2e-1", "etcd.v0.9.4", csvChanged, "", "Pending"),
+		stateChange(time.Minute, "clusterserviceversions", "e2e-1", "etcd.v0.9.4", csvChanged, "Pending", "Installing"),
+		stateChange(2*time.Minute, "clusterserviceversions", "e2e-1", "etcd.v0.9.4", csvChanged, "Installing", "Succeeded"),
+		stateChange(10*time.Minute, "clusterserviceversions", "e2e-1", "etcd.v0.9.4", csvChanged, "Succeeded", "Failed"),
+		// already succeeded when we started
+		stateChange(0, "clusterserviceversions", "openshift-operators", "packageserver", csvChanged, "", "Succeeded"),
+
+		olmChange(0, "installplans", "e2e-1", "install-auto", monitorapi.OLMInstallPlanCreated, automatic),
+		stateChange(30*time.Second, "installplans", "e2e-1", "install-auto", planChanged, "", "Installing"),
+		stateChange(90*time.Second, "installplans", "e2e-1", "install-auto", planChanged, "Installing", "Complete"),
+
+		olmChange(0, "installplans", "e2e-1", "install-manual", monitorapi.OLMInstallPlanCreated, manual),
+		stateChange(10*time.Second, "installplans", "e2e-1", "install-manual", planChanged, "", "RequiresApproval"),
+		olmChange(5*time.Minute, "installplans", "e2e-1", "install-manual", monitorapi.OLMInstallPlanApproved, manual),
+		stateChange(6*time.Minute, "installplans", "e2e-1", "install-manual", planChanged, "RequiresApproval", "Failed"),
+
+		// before monitoring began, ignored
+		olmChange(-time.Hour, "installplans", "e2e-1", "install-old", monitorapi.OLMInstallPlanCreated, automatic),
+
+		stateChange(0, "catalogsources", "openshift-marketplace", "redhat-operators", catalogChanged, "", "READY"),
+		stateChange(20*time.Minute, "catalogsources", "openshift-marketplace", "redhat-operators", catalogChanged, "READY", "CONNECTING"),
+		stateChange(22*time.Minute, "catalogsources", "openshift-marketplace", "redhat-operators", catalogChanged, "CONNECTING", "READY"),
+		// already unready when we started, as on a disconnected cluster
+		stateChange(0, "catalogsources", "openshift-marketplace", "community-operators", catalogChanged, "", "TRANSIENT_FAILURE"),
+		stateChange(0, "catalogsources", "openshift-marketplace", "certified-operators", catalogChanged, "", "READY"),
+		stateChange(30*time.Minute, "catalogsources", "openshift-marketplace", "certified-operators", catalogChanged, "READY", "TRANSIENT_FAILURE"),
+	}
+	end := start.Add(time.Hour)
+
+	w := &olmWatcher{}
+	constructed, err := w.ConstructComputedIntervals(context.TODO(), startingIntervals, nil, start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type summary struct {
+		reason   monitorapi.IntervalReason
+		level    monitorapi.IntervalLevel
+		duration time.Duration
+	}
+	expected := map[string][]summary{
+		"etcd.v0.9.4": {
+			{reason: monitorapi.OLMClusterServiceVersionPhase, level: monitorapi.Info, duration: time.Minute},
+			{reason: monitorapi.OLMClusterServiceVersionPhase, level: monitorapi.Info, duration: time.Minute},
+			{reason: monitorapi.OLMClusterServiceVersionPhase, level: monitorapi.Error, duration: 50 * time.Minute},
+		},
+		"install-auto": {
+			{reason: monitorapi.OLMInstallPlanInstalling, level: monitorapi.Info, duration: 90 * time.Second},
+		},
+		"install-manual": {
+			{reason: monitorapi.OLMInstallPlanWaitingForApproval, level: monitorapi.Info, duration: 4*time.Minute + 50*time.Second},
+			{reason: monitorapi.OLMInstallPlanInstalling, level: monitorapi.Error, duration: time.Minute},
+		},
+		"redhat-operators": {
+			{reason: monitorapi.OLMCatalogSourceNotReady, level: monitorapi.Warning, duration: 2 * time.Minute},
+		},
+		"community-operators": {
+			{reason: monitorapi.OLMCatalogSourceNotReady, level: monitorapi.Warning, duration: time.Hour},
+		},
+		"certified-operators": {
+			{reason: monitorapi.OLMCatalogSourceNotReady, level: monitorapi.Warning, duration: 30 * time.Minute},
+		},
+	}
+	actual := map[string][]summary{}
+	for _, interval := range constructed {
+		name := interval.Locator.Keys[monitorapi.LocatorNameKey]
+		actual[name] = append(actual[name], summary{reason: interval.Message.Reason, level: interval.Level, duration: interval.To.Sub(interval.From)})
+	}
+	if len(actual) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, actual)
+	}
+	for name, expectedSummaries := range expected {
+		if len(actual[name]) != len(expectedSummaries) {
+			t.Errorf("%s: expected %v, got %v", name, expectedSummaries, actual[name])
+			continue
+		}
+		for i := range expectedSummaries {
+			if actual[name][i] != expectedSummaries[i] {
+				t.Errorf("%s: expected %v, got %v", name, expectedSummaries, actual[name])
+			}
+		}
+	}
+
+	junits, err := w.EvaluateTestsFromConstructedIntervals(context.TODO(), append(startingIntervals, constructed...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := map[string][]bool{}
+	for _, junit := range junits {
+		results[junit.Name] = append(results[junit.Name], junit.FailureOutput == nil)
+	}
+	// the CSV is in a test namespace so it flakes, and unready catalog sources only flake.
+	if csv := results[csvFailedTestName]; len(csv) != 2 || csv[0] || !csv[1] {
+		t.Errorf("expected the failed CSV to flake, got %v", csv)
+	}
+	if catalog := results[catalogSourceReadyTestName]; len(catalog) != 2 || catalog[0] || !catalog[1] {
+		t.Errorf("expected the unready catalog source to flake, got %v", catalog)
+	}
+	for _, junit := range junits {
+		if junit.Name != catalogSourceReadyTestName || junit.FailureOutput == nil {
+			continue
+		}
+		if !strings.Contains(junit.FailureOutput.Output, "certified-operators") || strings.Contains(junit.FailureOutput.Output, "community-operators") {
+			t.Errorf("expected only the catalog source that became unready, got %v", junit.FailureOutput.Output)
+		}
+	}
+}
+
+func TestStateChangeFn(t *testing.T) {
+	csv := func(phase string, labels map[string]interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"namespace": "e2e-1", "name": "etcd.v0.9.4", "labels": labels},
+			"status":   map[string]interface{}{"phase": phase, "message": "install strategy failed"},
+		}}
+	}
+	fn := stateChangeFn(monitorapi.OLMClusterServiceVersionPhaseChanged, olmLocator("clusterserviceversions"), failedIsError, "status", "phase")
+
+	tests := []struct {
+		name          string
+		obj, oldObj   *unstructured.Unstructured
+		expectedLevel monitorapi.IntervalLevel
+		expected      int
+	}{
+		{name: "first seen", obj: csv("Pending", nil), expected: 1, expectedLevel: monitorapi.Info},
+		{name: "unchanged", obj: csv("Pending", nil), oldObj: csv("Pending", nil)},
+		{name: "failed", obj: csv("Failed", nil), oldObj: csv("Succeeded", nil), expected: 1, expectedLevel: monitorapi.Error},
+		{name: "copied", obj: csv("Failed", map[string]interface{}{copiedFromLabel: "openshift-operators"}), oldObj: csv("Succeeded", nil)},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			intervals := fn(tc.obj, tc.oldObj)
+			if len(intervals) != tc.expected {
+				t.Fatalf("expected %d intervals, got %v", tc.expected, intervals)
+			}
+			if tc.expected > 0 && intervals[0].Level != tc.expectedLevel {
+				t.Errorf("expected level %v, got %v", tc.expectedLevel, intervals[0].Level)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package watcholm
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestframework"
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	MonitorName = "olm-lifecycle"
+
+	// maxCatalogSourceNotReady allows for the catalog pod being rescheduled, which happens during upgrades.
+	maxCatalogSourceNotReady = 5 * time.Minute
+
+	csvFailedTestName = "[sig-operator] olm-lifecycle clusterserviceversions should not enter the Failed phase"
+)
+
+var catalogSourceReadyTestName = fmt.Sprintf("[sig-operator] olm-lifecycle catalogsources should not be unready for longer than %v", maxCatalogSourceNotReady)
+
+type olmWatcher struct {
+	notSupportedReason error
+}
+
+func NewOLMWatcher() monitortestframework.MonitorTest {
+	return &olmWatcher{}
+}
+
+func (w *olmWatcher) StartCollection(ctx context.Context, adminRESTConfig *rest.Config, recorder monitorapi.RecorderWriter) error {
+	kubeClient, err := kubernetes.NewForConfig(adminRESTConfig)
+	if err != nil {
+		return err
+	}
+	// OLM is an optional capability.
+	if _, err := kubeClient.Discovery().ServerResourcesForGroupVersion(olmGroupVersion); err != nil {
+		if apierrors.IsNotFound(err) {
+			w.notSupportedReason = &monitortestframework.NotSupportedError{
+				Reason: fmt.Sprintf("%s is not served", olmGroupVersion),
+			}
+			return w.notSupportedReason
+		}
+		return err
+	}
+	dynamicClient, err := dynamic.NewForConfig(adminRESTConfig)
+	if err != nil {
+		return err
+	}
+
+	startClusterServiceVersionMonitoring(ctx, recorder, dynamicClient)
+	startInstallPlanMonitoring(ctx, recorder, dynamicClient)
+	startSubscriptionMonitoring(ctx, recorder, dynamicClient)
+	startCatalogSourceMonitoring(ctx, recorder, dynamicClient)
+
+	return nil
+}
+
+func (w *olmWatcher) CollectData(ctx context.Context, storageDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
+	// because we are sharing a recorder that we're streaming into, we don't need to have a separate data collection step.
+	return nil, nil, w.notSupportedReason
+}
+
+func (w *olmWatcher) ConstructComputedIntervals(ctx context.Context, startingIntervals monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, beginning, end time.Time) (monitorapi.Intervals, error) {
+	if w.notSupportedReason != nil {
+		return nil, w.notSupportedReason
+	}
+	constructedIntervals := monitorapi.Intervals{}
+	constructedIntervals = append(constructedIntervals, constructCSVPhaseIntervals(startingIntervals, end)...)
+	constructedIntervals = append(constructedIntervals, constructInstallPlanIntervals(startingIntervals, beginning, end)...)
+	constructedIntervals = append(constructedIntervals, constructCatalogSourceIntervals(startingIntervals, end)...)
+	sort.Sort(constructedIntervals)
+	return constructedIntervals, nil
+}
+
+func (w *olmWatcher) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
+	if w.notSupportedReason != nil {
+		return nil, w.notSupportedReason
+	}
+	junits := []*junitapi.JUnitTestCase{}
+	junits = append(junits, csvFailedJunits(finalIntervals)...)
+	junits = append(junits, catalogSourceReadyJunits(finalIntervals)...)
+	return junits, nil
+}
+
+func (w *olmWatcher) WriteContentToStorage(ctx context.Context, storageDir, timeSuffix string, finalIntervals monitorapi.Intervals, finalResourceState monitorapi.ResourcesMap) error {
+	return w.notSupportedReason
+}
+
+func (w *olmWatcher) Cleanup(ctx context.Context) error {
+	return w.notSupportedReason
+}
+
+// platformJunits fails when there are problems in platform namespaces and only flakes for the rest, because tests
+// install operators that are expected to fail.
+func platformJunits(testName, summary string, platform, other []string) []*junitapi.JUnitTestCase {
+	switch {
+	case len(platform) > 0:
+		return []*junitapi.JUnitTestCase{
+			{
+				Name: testName,
+				FailureOutput: &junitapi.FailureOutput{
+					Output: fmt.Sprintf("%d %s\n\n%v", len(platform)+len(other), summary, strings.Join(append(platform, other...), "\n")),
+				},
+			},
+		}
+	case len(other) > 0:
+		return []*junitapi.JUnitTestCase{
+			{
+				Name: testName,
+				FailureOutput: &junitapi.FailureOutput{
+					Output: fmt.Sprintf("%d %s\n\n%v", len(other), summary, strings.Join(other, "\n")),
+				},
+			},
+			{Name: testName},
+		}
+	default:
+		return []*junitapi.JUnitTestCase{{Name: testName}}
+	}
+}
+
+// csvFailedJunits only counts CSVs we saw enter Failed, not the ones that already were when we started watching.
+func csvFailedJunits(finalIntervals monitorapi.Intervals) []*junitapi.JUnitTestCase {
+	platform, other := []string{}, []string{}
+	for _, interval := range finalIntervals {
+		if interval.Source != monitorapi.SourceOLM || interval.Message.Reason != monitorapi.OLMClusterServiceVersionPhaseChanged {
+			continue
+		}
+		if interval.Message.Annotations[monitorapi.AnnotationState] != csvPhaseFailed || len(interval.Message.Annotations[monitorapi.AnnotationPreviousState]) == 0 {
+			continue
+		}
+		if platformidentification.IsPlatformNamespace(interval.Locator.Keys[monitorapi.LocatorNamespaceKey]) {
+			platform = append(platform, interval.String())
+		} else {
+			other = append(other, interval.String())
+		}
+	}
+	return platformJunits(csvFailedTestName, "clusterserviceversions entered the Failed phase", platform, other)
+}
+
+// catalogSourceReadyJunits only counts catalog sources we saw leave READY, not the ones that were already unready
+// when we started watching, like the default catalog sources of a disconnected cluster.  The default catalog sources
+// pull their index from the internet, so this only flakes, even in platform namespaces.
+func catalogSourceReadyJunits(finalIntervals monitorapi.Intervals) []*junitapi.JUnitTestCase {
+	notReady := []string{}
+	for _, interval := range finalIntervals {
+		if interval.Source != monitorapi.SourceOLM || interval.Message.Reason != monitorapi.OLMCatalogSourceNotReady {
+			continue
+		}
+		if len(interval.Message.Annotations[monitorapi.AnnotationPreviousState]) == 0 {
+			continue
+		}
+		if interval.To.Sub(interval.From) <= maxCatalogSourceNotReady {
+			continue
+		}
+		notReady = append(notReady, interval.String())
+	}
+	return platformJunits(catalogSourceReadyTestName, "catalogsources were not READY", nil, notReady)
+}
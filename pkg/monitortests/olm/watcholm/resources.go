@@ -0,0 +1,109 @@
+package watcholm
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	csvPhaseFailed      = "Failed"
+	csvPhaseSucceeded   = "Succeeded"
+	installPlanApproval = "RequiresApproval"
+	installPlanComplete = "Complete"
+	installPlanFailed   = "Failed"
+	catalogSourceReady  = "READY"
+)
+
+func olmLocator(resource string) func(obj *unstructured.Unstructured) monitorapi.Locator {
+	return func(obj *unstructured.Unstructured) monitorapi.Locator {
+		return monitorapi.NewLocator().Resource("operators.coreos.com", resource, obj.GetNamespace(), obj.GetName())
+	}
+}
+
+func failedIsError(state string) monitorapi.IntervalLevel {
+	if state == csvPhaseFailed {
+		return monitorapi.Error
+	}
+	return monitorapi.Info
+}
+
+func startClusterServiceVersionMonitoring(ctx context.Context, m monitorapi.RecorderWriter, client dynamic.Interface) {
+	startOLMResourceMonitoring(ctx, m, client, "clusterserviceversions", []changeFunc{
+		stateChangeFn(monitorapi.OLMClusterServiceVersionPhaseChanged, olmLocator("clusterserviceversions"), failedIsError, "status", "phase"),
+	})
+}
+
+func startSubscriptionMonitoring(ctx context.Context, m monitorapi.RecorderWriter, client dynamic.Interface) {
+	startOLMResourceMonitoring(ctx, m, client, "subscriptions", []changeFunc{
+		stateChangeFn(monitorapi.OLMSubscriptionStateChanged, olmLocator("subscriptions"), infoLevel, "status", "state"),
+	})
+}
+
+func startCatalogSourceMonitoring(ctx context.Context, m monitorapi.RecorderWriter, client dynamic.Interface) {
+	startOLMResourceMonitoring(ctx, m, client, "catalogsources", []changeFunc{
+		stateChangeFn(monitorapi.OLMCatalogSourceStateChanged, olmLocator("catalogsources"), func(state string) monitorapi.IntervalLevel {
+			if state == catalogSourceReady {
+				return monitorapi.Info
+			}
+			return monitorapi.Warning
+		}, "status", "connectionState", "lastObservedState"),
+	})
+}
+
+func startInstallPlanMonitoring(ctx context.Context, m monitorapi.RecorderWriter, client dynamic.Interface) {
+	locator := olmLocator("installplans")
+	startOLMResourceMonitoring(ctx, m, client, "installplans", []changeFunc{
+		// this is first so install plan created shows up first when queried
+		func(plan, oldPlan *unstructured.Unstructured) []monitorapi.Interval {
+			var intervals []monitorapi.Interval
+			if plan == nil || oldPlan != nil {
+				return intervals
+			}
+			created := plan.GetCreationTimestamp().Time
+			intervals = append(intervals,
+				monitorapi.NewInterval(monitorapi.SourceOLM, monitorapi.Info).
+					Locator(locator(plan)).
+					Message(installPlanMessage(plan, monitorapi.OLMInstallPlanCreated).
+						HumanMessage("InstallPlan created")).
+					Build(created, created))
+			return intervals
+		},
+
+		stateChangeFn(monitorapi.OLMInstallPlanPhaseChanged, locator, failedIsError, "status", "phase"),
+
+		// approval carries no timestamp, so this is only recorded when observed.
+		func(plan, oldPlan *unstructured.Unstructured) []monitorapi.Interval {
+			var intervals []monitorapi.Interval
+			if plan == nil || oldPlan == nil {
+				return intervals
+			}
+			approved, _, _ := unstructured.NestedBool(plan.Object, "spec", "approved")
+			wasApproved, _, _ := unstructured.NestedBool(oldPlan.Object, "spec", "approved")
+			if !approved || wasApproved {
+				return intervals
+			}
+			now := time.Now()
+			intervals = append(intervals,
+				monitorapi.NewInterval(monitorapi.SourceOLM, monitorapi.Info).
+					Locator(locator(plan)).
+					Message(installPlanMessage(plan, monitorapi.OLMInstallPlanApproved).
+						HumanMessage("InstallPlan approved")).
+					Build(now, now))
+			return intervals
+		},
+	})
+}
+
+func installPlanMessage(plan *unstructured.Unstructured, reason monitorapi.IntervalReason) *monitorapi.MessageBuilder {
+	approval, _, _ := unstructured.NestedString(plan.Object, "spec", "approval")
+	csvs, _, _ := unstructured.NestedStringSlice(plan.Object, "spec", "clusterServiceVersionNames")
+	return monitorapi.NewMessage().Reason(reason).
+		WithAnnotation(monitorapi.AnnotationApproval, approval).
+		WithAnnotation(monitorapi.AnnotationCSV, strings.Join(csvs, ","))
+}
@@ -0,0 +1,113 @@
+package watcholm
+
+import (
+	"context"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestlibrary"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+)
+
+const olmGroupVersion = "operators.coreos.com/v1alpha1"
+
+// copiedFromLabel marks the copies OLM makes of a CSV in every namespace an operator watches.  They mirror the
+// original and would only multiply its intervals.
+const copiedFromLabel = "olm.copiedFrom"
+
+type changeFunc func(obj, oldObj *unstructured.Unstructured) []monitorapi.Interval
+
+// startOLMResourceMonitoring watches an OLM resource in all namespaces.  The OLM API is not vendored, so objects are
+// read as unstructured.
+func startOLMResourceMonitoring(ctx context.Context, m monitorapi.RecorderWriter, client dynamic.Interface, resource string, changeFns []changeFunc) {
+	resourceClient := client.Resource(schema.GroupVersionResource{Group: "operators.coreos.com", Version: "v1alpha1", Resource: resource})
+	listWatch := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return resourceClient.List(ctx, options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return resourceClient.Watch(ctx, options)
+		},
+	}
+	customStore := monitortestlibrary.NewMonitoringStore(
+		resource,
+		toCreateFns(changeFns),
+		toUpdateFns(changeFns),
+		[]monitortestlibrary.ObjDeleteFunc{},
+		m,
+		m,
+	)
+	reflector := cache.NewReflector(listWatch, &unstructured.Unstructured{}, customStore, 0)
+	go reflector.Run(ctx.Done())
+}
+
+// stateChangeFn records an interval whenever the string at fields changes, including the state an object is first
+// seen in so intervals can be constructed for objects that were already in a bad state.
+func stateChangeFn(reason monitorapi.IntervalReason, locator func(obj *unstructured.Unstructured) monitorapi.Locator, levelFor func(state string) monitorapi.IntervalLevel, fields ...string) changeFunc {
+	return func(obj, oldObj *unstructured.Unstructured) []monitorapi.Interval {
+		var intervals []monitorapi.Interval
+		if obj == nil || len(obj.GetLabels()[copiedFromLabel]) > 0 {
+			return intervals
+		}
+		state, _, _ := unstructured.NestedString(obj.Object, fields...)
+		previousState := ""
+		if oldObj != nil {
+			previousState, _, _ = unstructured.NestedString(oldObj.Object, fields...)
+		}
+		if state == previousState {
+			return intervals
+		}
+
+		message, _, _ := unstructured.NestedString(obj.Object, "status", "message")
+		now := time.Now()
+		intervals = append(intervals,
+			monitorapi.NewInterval(monitorapi.SourceOLM, levelFor(state)).
+				Locator(locator(obj)).
+				Message(monitorapi.NewMessage().Reason(reason).
+					WithAnnotation(monitorapi.AnnotationState, state).
+					WithAnnotation(monitorapi.AnnotationPreviousState, previousState).
+					HumanMessagef("%s changed from %q to %q: %s", fields[len(fields)-1], previousState, state, message)).
+				Build(now, now))
+		return intervals
+	}
+}
+
+func infoLevel(string) monitorapi.IntervalLevel {
+	return monitorapi.Info
+}
+
+func toCreateFns(changeFns []changeFunc) []monitortestlibrary.ObjCreateFunc {
+	ret := []monitortestlibrary.ObjCreateFunc{}
+
+	for i := range changeFns {
+		fn := changeFns[i]
+		ret = append(ret, func(obj interface{}) []monitorapi.Interval {
+			return fn(obj.(*unstructured.Unstructured), nil)
+		})
+	}
+
+	return ret
+}
+
+func toUpdateFns(changeFns []changeFunc) []monitortestlibrary.ObjUpdateFunc {
+	ret := []monitortestlibrary.ObjUpdateFunc{}
+
+	for i := range changeFns {
+		fn := changeFns[i]
+		ret = append(ret, func(obj, oldObj interface{}) []monitorapi.Interval {
+			if oldObj == nil {
+				return fn(obj.(*unstructured.Unstructured), nil)
+			}
+			return fn(obj.(*unstructured.Unstructured), oldObj.(*unstructured.Unstructured))
+		})
+	}
+
+	return ret
+}
@@ -0,0 +1,177 @@
+package watchvolumes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestlibrary"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// selectedNodeAnnotation is set by the scheduler once a pod using a WaitForFirstConsumer claim is scheduled.
+	selectedNodeAnnotation = "volume.kubernetes.io/selected-node"
+
+	storageProvisionerAnnotation     = "volume.kubernetes.io/storage-provisioner"
+	betaStorageProvisionerAnnotation = "volume.beta.kubernetes.io/storage-provisioner"
+)
+
+func startClaimMonitoring(ctx context.Context, m monitorapi.RecorderWriter, client kubernetes.Interface) {
+	claimChangeFns := []func(claim, oldClaim *corev1.PersistentVolumeClaim) []monitorapi.Interval{
+		// this is first so claim created shows up first when queried
+		func(claim, oldClaim *corev1.PersistentVolumeClaim) []monitorapi.Interval {
+			var intervals []monitorapi.Interval
+			if claim == nil || oldClaim != nil {
+				return intervals
+			}
+			created := claim.CreationTimestamp.Time
+			intervals = append(intervals,
+				monitorapi.NewInterval(monitorapi.SourceVolumeMonitor, monitorapi.Info).
+					Locator(claimLocator(claim.Namespace, claim.Name)).
+					Message(claimMessage(claim, monitorapi.PersistentVolumeClaimCreated).
+						HumanMessage("PersistentVolumeClaim created")).
+					Build(created, created))
+			return intervals
+		},
+
+		func(claim, oldClaim *corev1.PersistentVolumeClaim) []monitorapi.Interval {
+			var intervals []monitorapi.Interval
+			if claim == nil || oldClaim == nil {
+				return intervals
+			}
+			node, ok := claim.Annotations[selectedNodeAnnotation]
+			if !ok || len(oldClaim.Annotations[selectedNodeAnnotation]) > 0 {
+				return intervals
+			}
+			now := time.Now()
+			intervals = append(intervals,
+				monitorapi.NewInterval(monitorapi.SourceVolumeMonitor, monitorapi.Info).
+					Locator(claimLocator(claim.Namespace, claim.Name)).
+					Message(claimMessage(claim, monitorapi.PersistentVolumeClaimNodeSelected).
+						WithAnnotation(monitorapi.AnnotationNode, node).
+						HumanMessage(fmt.Sprintf("scheduler selected node %s for the first consumer", node))).
+					Build(now, now))
+			return intervals
+		},
+
+		func(claim, oldClaim *corev1.PersistentVolumeClaim) []monitorapi.Interval {
+			var intervals []monitorapi.Interval
+			// changes are only timed when observed, claims listed at start carry no useful timing.
+			if claim == nil || oldClaim == nil {
+				return intervals
+			}
+			oldPhase := oldClaim.Status.Phase
+			if claim.Status.Phase == oldPhase {
+				return intervals
+			}
+			level := monitorapi.Info
+			if claim.Status.Phase == corev1.ClaimLost {
+				level = monitorapi.Error
+			}
+			now := time.Now()
+			intervals = append(intervals,
+				monitorapi.NewInterval(monitorapi.SourceVolumeMonitor, level).
+					Locator(claimLocator(claim.Namespace, claim.Name)).
+					Message(claimMessage(claim, monitorapi.PersistentVolumeClaimPhaseChanged).
+						WithAnnotation(monitorapi.AnnotationPhase, string(claim.Status.Phase)).
+						WithAnnotation(monitorapi.AnnotationPreviousPhase, string(oldPhase)).
+						HumanMessage(fmt.Sprintf("PersistentVolumeClaim phase changed from %s to %s", oldPhase, claim.Status.Phase))).
+					Build(now, now))
+			return intervals
+		},
+
+		// this is last so claim deleted shows up last when queried
+		func(claim, oldClaim *corev1.PersistentVolumeClaim) []monitorapi.Interval {
+			var intervals []monitorapi.Interval
+			if claim != nil || oldClaim == nil {
+				return intervals
+			}
+			now := time.Now()
+			intervals = append(intervals,
+				monitorapi.NewInterval(monitorapi.SourceVolumeMonitor, monitorapi.Info).
+					Locator(claimLocator(oldClaim.Namespace, oldClaim.Name)).
+					Message(claimMessage(oldClaim, monitorapi.PersistentVolumeClaimDeleted).
+						HumanMessage("PersistentVolumeClaim deleted")).
+					Build(now, now))
+			return intervals
+		},
+	}
+
+	listWatch := cache.NewListWatchFromClient(client.CoreV1().RESTClient(), "persistentvolumeclaims", "", fields.Everything())
+	customStore := monitortestlibrary.NewMonitoringStore(
+		"persistentvolumeclaims",
+		toClaimCreateFns(claimChangeFns),
+		toClaimUpdateFns(claimChangeFns),
+		toClaimDeleteFns(claimChangeFns),
+		m,
+		m,
+	)
+	reflector := cache.NewReflector(listWatch, &corev1.PersistentVolumeClaim{}, customStore, 0)
+	go reflector.Run(ctx.Done())
+}
+
+func claimLocator(namespace, name string) monitorapi.Locator {
+	return monitorapi.NewLocator().Resource("", "persistentvolumeclaims", namespace, name)
+}
+
+func claimMessage(claim *corev1.PersistentVolumeClaim, reason monitorapi.IntervalReason) *monitorapi.MessageBuilder {
+	storageClass := ""
+	if claim.Spec.StorageClassName != nil {
+		storageClass = *claim.Spec.StorageClassName
+	}
+	driver := claim.Annotations[storageProvisionerAnnotation]
+	if len(driver) == 0 {
+		driver = claim.Annotations[betaStorageProvisionerAnnotation]
+	}
+	return monitorapi.NewMessage().Reason(reason).
+		WithAnnotation(monitorapi.AnnotationStorageClass, storageClass).
+		WithAnnotation(monitorapi.AnnotationDriver, driver).
+		WithAnnotation(monitorapi.AnnotationVolume, claim.Spec.VolumeName)
+}
+
+func toClaimCreateFns(claimUpdateFns []func(claim, oldClaim *corev1.PersistentVolumeClaim) []monitorapi.Interval) []monitortestlibrary.ObjCreateFunc {
+	ret := []monitortestlibrary.ObjCreateFunc{}
+
+	for i := range claimUpdateFns {
+		fn := claimUpdateFns[i]
+		ret = append(ret, func(obj interface{}) []monitorapi.Interval {
+			return fn(obj.(*corev1.PersistentVolumeClaim), nil)
+		})
+	}
+
+	return ret
+}
+
+func toClaimDeleteFns(claimUpdateFns []func(claim, oldClaim *corev1.PersistentVolumeClaim) []monitorapi.Interval) []monitortestlibrary.ObjDeleteFunc {
+	ret := []monitortestlibrary.ObjDeleteFunc{}
+
+	for i := range claimUpdateFns {
+		fn := claimUpdateFns[i]
+		ret = append(ret, func(obj interface{}) []monitorapi.Interval {
+			return fn(nil, obj.(*corev1.PersistentVolumeClaim))
+		})
+	}
+	return ret
+}
+
+func toClaimUpdateFns(claimUpdateFns []func(claim, oldClaim *corev1.PersistentVolumeClaim) []monitorapi.Interval) []monitortestlibrary.ObjUpdateFunc {
+	ret := []monitortestlibrary.ObjUpdateFunc{}
+
+	for i := range claimUpdateFns {
+		fn := claimUpdateFns[i]
+		ret = append(ret, func(obj, oldObj interface{}) []monitorapi.Interval {
+			if oldObj == nil {
+				return fn(obj.(*corev1.PersistentVolumeClaim), nil)
+			}
+			return fn(obj.(*corev1.PersistentVolumeClaim), oldObj.(*corev1.PersistentVolumeClaim))
+		})
+	}
+
+	return ret
+}
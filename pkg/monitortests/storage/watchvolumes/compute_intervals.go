@@ -0,0 +1,215 @@
+package watchvolumes
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func intervalsByLocator(intervals monitorapi.Intervals, reasons ...monitorapi.IntervalReason) map[string]monitorapi.Intervals {
+	ret := map[string]monitorapi.Intervals{}
+	for _, interval := range intervals {
+		if interval.Source != monitorapi.SourceVolumeMonitor {
+			continue
+		}
+		for _, reason := range reasons {
+			if interval.Message.Reason != reason {
+				continue
+			}
+			key := interval.Locator.OldLocator()
+			ret[key] = append(ret[key], interval)
+		}
+	}
+	for _, locatorIntervals := range ret {
+		sort.SliceStable(locatorIntervals, func(i, j int) bool {
+			return locatorIntervals[i].From.Before(locatorIntervals[j].From)
+		})
+	}
+	return ret
+}
+
+// constructClaimIntervals creates an interval for how long each claim created after beginning waited to be bound.
+// Claims that wait for their first consumer are only pending once the scheduler has selected a node, and claims
+// nothing ever started provisioning for are left alone because there is nothing to measure.
+func constructClaimIntervals(startingIntervals monitorapi.Intervals, beginning, end time.Time) monitorapi.Intervals {
+	constructedIntervals := monitorapi.Intervals{}
+
+	for _, changes := range intervalsByLocator(startingIntervals,
+		monitorapi.PersistentVolumeClaimCreated, monitorapi.PersistentVolumeClaimNodeSelected,
+		monitorapi.PersistentVolumeClaimPhaseChanged, monitorapi.PersistentVolumeClaimDeleted) {
+
+		var created, nodeSelected, bound, deleted *monitorapi.Interval
+		for i := range changes {
+			change := &changes[i]
+			switch change.Message.Reason {
+			case monitorapi.PersistentVolumeClaimCreated:
+				created = change
+			case monitorapi.PersistentVolumeClaimNodeSelected:
+				if nodeSelected == nil {
+					nodeSelected = change
+				}
+			case monitorapi.PersistentVolumeClaimPhaseChanged:
+				if bound == nil && change.Message.Annotations[monitorapi.AnnotationPhase] == string(corev1.ClaimBound) {
+					bound = change
+				}
+			case monitorapi.PersistentVolumeClaimDeleted:
+				deleted = change
+			}
+		}
+		// claims that were already bound when first listed have no useful timing.
+		if created == nil || created.From.Before(beginning) || len(created.Message.Annotations[monitorapi.AnnotationVolume]) > 0 {
+			continue
+		}
+
+		// the provisioner annotations are added after creation, so the latest change knows the most.
+		latest := &changes[len(changes)-1]
+		pendingSince := created.From
+		if nodeSelected != nil {
+			pendingSince = nodeSelected.From
+		}
+
+		if bound != nil {
+			constructedIntervals = append(constructedIntervals,
+				monitorapi.NewInterval(monitorapi.SourceVolumeMonitor, monitorapi.Info).
+					Locator(created.Locator).
+					Message(volumeConstructedMessage(latest, monitorapi.PersistentVolumeClaimPending, bound.From.Sub(pendingSince)).
+						HumanMessage(fmt.Sprintf("waiting to be bound to %s", bound.Message.Annotations[monitorapi.AnnotationVolume]))).
+					Display().
+					Build(pendingSince, bound.From),
+			)
+			continue
+		}
+
+		if nodeSelected == nil && len(latest.Message.Annotations[monitorapi.AnnotationDriver]) == 0 {
+			continue
+		}
+		to := end
+		humanMessage := "never bound"
+		if deleted != nil {
+			to = deleted.From
+			humanMessage = "deleted before it was bound"
+		}
+		constructedIntervals = append(constructedIntervals,
+			monitorapi.NewInterval(monitorapi.SourceVolumeMonitor, monitorapi.Warning).
+				Locator(created.Locator).
+				Message(volumeConstructedMessage(latest, monitorapi.PersistentVolumeClaimNeverBound, to.Sub(pendingSince)).
+					HumanMessage(humanMessage)).
+				Display().
+				Build(pendingSince, to),
+		)
+	}
+
+	sort.Sort(constructedIntervals)
+	return constructedIntervals
+}
+
+// constructAttachmentIntervals creates intervals for how long each volume took to attach and to detach.  Attaches are
+// only measured for attachments created after beginning, detaches for those requested after beginning.
+func constructAttachmentIntervals(startingIntervals monitorapi.Intervals, beginning, end time.Time) monitorapi.Intervals {
+	constructedIntervals := monitorapi.Intervals{}
+	storageClasses := volumeStorageClasses(startingIntervals)
+
+	for _, changes := range intervalsByLocator(startingIntervals,
+		monitorapi.VolumeAttachmentCreated, monitorapi.VolumeAttachmentAttached,
+		monitorapi.VolumeAttachmentDetachRequested, monitorapi.VolumeAttachmentDeleted) {
+
+		var created, attached, detachRequested, deleted *monitorapi.Interval
+		for i := range changes {
+			change := &changes[i]
+			switch change.Message.Reason {
+			case monitorapi.VolumeAttachmentCreated:
+				created = change
+			case monitorapi.VolumeAttachmentAttached:
+				if attached == nil {
+					attached = change
+				}
+			case monitorapi.VolumeAttachmentDetachRequested:
+				detachRequested = change
+			case monitorapi.VolumeAttachmentDeleted:
+				deleted = change
+			}
+		}
+		message := func(from *monitorapi.Interval, reason monitorapi.IntervalReason, duration time.Duration) *monitorapi.MessageBuilder {
+			return volumeConstructedMessage(from, reason, duration).
+				WithAnnotation(monitorapi.AnnotationNode, from.Message.Annotations[monitorapi.AnnotationNode]).
+				WithAnnotation(monitorapi.AnnotationStorageClass, storageClasses[from.Message.Annotations[monitorapi.AnnotationVolume]])
+		}
+
+		if created != nil && !created.From.Before(beginning) {
+			switch {
+			case attached != nil:
+				constructedIntervals = append(constructedIntervals,
+					monitorapi.NewInterval(monitorapi.SourceVolumeMonitor, monitorapi.Info).
+						Locator(created.Locator).
+						Message(message(created, monitorapi.VolumeAttaching, attached.From.Sub(created.From)).
+							HumanMessage("attaching")).
+						Display().
+						Build(created.From, attached.From),
+				)
+			default:
+				to := end
+				if detachRequested != nil {
+					to = detachRequested.From
+				} else if deleted != nil {
+					to = deleted.From
+				}
+				constructedIntervals = append(constructedIntervals,
+					monitorapi.NewInterval(monitorapi.SourceVolumeMonitor, monitorapi.Warning).
+						Locator(created.Locator).
+						Message(message(created, monitorapi.VolumeAttaching, to.Sub(created.From)).
+							HumanMessage("never attached")).
+						Display().
+						Build(created.From, to),
+				)
+			}
+		}
+
+		if detachRequested != nil && !detachRequested.From.Before(beginning) {
+			level := monitorapi.Info
+			humanMessage := "detaching"
+			to := end
+			if deleted != nil {
+				to = deleted.From
+			} else {
+				level = monitorapi.Warning
+				humanMessage = "never detached"
+			}
+			constructedIntervals = append(constructedIntervals,
+				monitorapi.NewInterval(monitorapi.SourceVolumeMonitor, level).
+					Locator(detachRequested.Locator).
+					Message(message(detachRequested, monitorapi.VolumeDetaching, to.Sub(detachRequested.From)).
+						HumanMessage(humanMessage)).
+					Display().
+					Build(detachRequested.From, to),
+			)
+		}
+	}
+
+	sort.Sort(constructedIntervals)
+	return constructedIntervals
+}
+
+// volumeStorageClasses maps persistent volume names to their storage class.
+func volumeStorageClasses(intervals monitorapi.Intervals) map[string]string {
+	ret := map[string]string{}
+	for _, interval := range intervals {
+		if interval.Source != monitorapi.SourceVolumeMonitor || interval.Message.Reason != monitorapi.PersistentVolumeCreated {
+			continue
+		}
+		ret[interval.Locator.Keys[monitorapi.LocatorNameKey]] = interval.Message.Annotations[monitorapi.AnnotationStorageClass]
+	}
+	return ret
+}
+
+func volumeConstructedMessage(from *monitorapi.Interval, reason monitorapi.IntervalReason, duration time.Duration) *monitorapi.MessageBuilder {
+	return monitorapi.NewMessage().Reason(reason).
+		Constructed(monitorapi.ConstructionOwnerVolumeLifecycle).
+		WithAnnotation(monitorapi.AnnotationStorageClass, from.Message.Annotations[monitorapi.AnnotationStorageClass]).
+		WithAnnotation(monitorapi.AnnotationDriver, from.Message.Annotations[monitorapi.AnnotationDriver]).
+		WithAnnotation(monitorapi.AnnotationVolume, from.Message.Annotations[monitorapi.AnnotationVolume]).
+		WithAnnotation(monitorapi.AnnotationDuration, duration.Round(time.Second).String())
+}
@@ -0,0 +1,150 @@
+package watchvolumes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+var start = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func volumeChange(at time.Duration, locator monitorapi.Locator, reason monitorapi.IntervalReason, annotations map[monitorapi.AnnotationKey]string) monitorapi.Interval {
+	message := monitorapi.NewMessage().Reason(reason).HumanMessage("changed")
+	for key, value := range annotations {
+		message = message.WithAnnotation(key, value)
+	}
+	return monitorapi.NewInterval(monitorapi.SourceVolumeMonitor, monitorapi.Info).
+		Locator(locator).
+		Message(message).
+		Build(start.Add(at), start.Add(at))
+}
+
+func TestConstructComputedIntervals(t *testing.T) {
+	gp3 := map[monitorapi.AnnotationKey]string{
+		monitorapi.AnnotationStorageClass: "gp3-csi",
+		monitorapi.AnnotationDriver:       "ebs.csi.aws.com",
+	}
+	bound := map[monitorapi.AnnotationKey]string{
+		monitorapi.AnnotationStorageClass: "gp3-csi",
+		monitorapi.AnnotationDriver:       "ebs.csi.aws.com",
+		monitorapi.AnnotationPhase:        "Bound",
+		monitorapi.AnnotationVolume:       "pvc-1234",
+	}
+	attachment := map[monitorapi.AnnotationKey]string{
+		monitorapi.AnnotationDriver: "ebs.csi.aws.com",
+		monitorapi.AnnotationNode:   "node-a",
+		monitorapi.AnnotationVolume: "pvc-1234",
+	}
+	fastClaim := claimLocator("e2e-1", "fast")
+	waitingClaim := claimLocator("e2e-1", "waiting")
+	unprovisionedClaim := claimLocator("e2e-1", "no-consumer")
+	stuckClaim := claimLocator("e2e-1", "stuck")
+	attached := volumeAttachmentLocator("csi-attached")
+	neverDetached := volumeAttachmentLocator("csi-never-detached")
+
+	startingIntervals := monitorapi.Intervals{
+		volumeChange(-time.Hour, persistentVolumeLocator("pvc-1234"), monitorapi.PersistentVolumeCreated, gp3),
+		// before monitoring began, ignored
+		volumeChange(-time.Hour, claimLocator("e2e-1", "old"), monitorapi.PersistentVolumeClaimCreated, gp3),
+
+		volumeChange(0, fastClaim, monitorapi.PersistentVolumeClaimCreated, gp3),
+		volumeChange(20*time.Second, fastClaim, monitorapi.PersistentVolumeClaimPhaseChanged, bound),
+
+		// wait for first consumer, pending from node selection
+		volumeChange(0, waitingClaim, monitorapi.PersistentVolumeClaimCreated, nil),
+		volumeChange(10*time.Minute, waitingClaim, monitorapi.PersistentVolumeClaimNodeSelected, gp3),
+		volumeChange(11*time.Minute, waitingClaim, monitorapi.PersistentVolumeClaimPhaseChanged, bound),
+
+		// nothing ever started provisioning, ignored
+		volumeChange(0, unprovisionedClaim, monitorapi.PersistentVolumeClaimCreated, nil),
+
+		volumeChange(time.Minute, stuckClaim, monitorapi.PersistentVolumeClaimCreated, gp3),
+
+		volumeChange(30*time.Second, attached, monitorapi.VolumeAttachmentCreated, attachment),
+		volumeChange(50*time.Second, attached, monitorapi.VolumeAttachmentAttached, attachment),
+		volumeChange(20*time.Minute, attached, monitorapi.VolumeAttachmentDetachRequested, attachment),
+		volumeChange(21*time.Minute, attached, monitorapi.VolumeAttachmentDeleted, attachment),
+
+		volumeChange(-time.Hour, neverDetached, monitorapi.VolumeAttachmentCreated, attachment),
+		volumeChange(40*time.Minute, neverDetached, monitorapi.VolumeAttachmentDetachRequested, attachment),
+	}
+	end := start.Add(time.Hour)
+
+	w := &volumeWatcher{}
+	constructed, err := w.ConstructComputedIntervals(context.TODO(), startingIntervals, nil, start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type expectedInterval struct {
+		reason   monitorapi.IntervalReason
+		level    monitorapi.IntervalLevel
+		duration time.Duration
+	}
+	expected := map[string][]expectedInterval{
+		fastClaim.OldLocator():    {{reason: monitorapi.PersistentVolumeClaimPending, level: monitorapi.Info, duration: 20 * time.Second}},
+		waitingClaim.OldLocator(): {{reason: monitorapi.PersistentVolumeClaimPending, level: monitorapi.Info, duration: time.Minute}},
+		stuckClaim.OldLocator():   {{reason: monitorapi.PersistentVolumeClaimNeverBound, level: monitorapi.Warning, duration: 59 * time.Minute}},
+		attached.OldLocator(): {
+			{reason: monitorapi.VolumeAttaching, level: monitorapi.Info, duration: 20 * time.Second},
+			{reason: monitorapi.VolumeDetaching, level: monitorapi.Info, duration: time.Minute},
+		},
+		neverDetached.OldLocator(): {{reason: monitorapi.VolumeDetaching, level: monitorapi.Warning, duration: 20 * time.Minute}},
+	}
+	actual := map[string][]expectedInterval{}
+	for _, interval := range constructed {
+		key := interval.Locator.OldLocator()
+		actual[key] = append(actual[key], expectedInterval{reason: interval.Message.Reason, level: interval.Level, duration: interval.To.Sub(interval.From)})
+		if interval.Message.Reason == monitorapi.VolumeAttaching && interval.Message.Annotations[monitorapi.AnnotationStorageClass] != "gp3-csi" {
+			t.Errorf("expected the attachment to be attributed to the volume storage class, got %v", interval)
+		}
+	}
+	if len(actual) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, actual)
+	}
+	for key, expectedIntervals := range expected {
+		if len(actual[key]) != len(expectedIntervals) {
+			t.Errorf("%s: expected %v, got %v", key, expectedIntervals, actual[key])
+			continue
+		}
+		for i := range expectedIntervals {
+			if actual[key][i] != expectedIntervals[i] {
+				t.Errorf("%s: expected %v, got %v", key, expectedIntervals, actual[key])
+			}
+		}
+	}
+
+	junits, err := w.EvaluateTestsFromConstructedIntervals(context.TODO(), append(startingIntervals, constructed...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	failures := map[string]int{}
+	for _, junit := range junits {
+		if junit.FailureOutput != nil {
+			failures[junit.Name]++
+		}
+	}
+	if failures[claimPendingTestName] != 1 {
+		t.Errorf("expected the stuck claim to be reported")
+	}
+	if failures[detachTestName] != 1 {
+		t.Errorf("expected the stuck detach to be reported")
+	}
+
+	latencies := summarizeVolumeLatencies(constructed)
+	expectedLatencies := []volumeLatency{
+		{Operation: "attach", Driver: "ebs.csi.aws.com", StorageClass: "gp3-csi", Count: 1, P50Seconds: 20, P90Seconds: 20, MaxSeconds: 20},
+		{Operation: "detach", Driver: "ebs.csi.aws.com", StorageClass: "gp3-csi", Count: 1, P50Seconds: 60, P90Seconds: 60, MaxSeconds: 60},
+		{Operation: "provision", Driver: "ebs.csi.aws.com", StorageClass: "gp3-csi", Count: 2, P50Seconds: 20, P90Seconds: 60, MaxSeconds: 60},
+	}
+	if len(latencies) != len(expectedLatencies) {
+		t.Fatalf("expected %v, got %v", expectedLatencies, latencies)
+	}
+	for i := range expectedLatencies {
+		if latencies[i] != expectedLatencies[i] {
+			t.Errorf("expected %v, got %v", expectedLatencies[i], latencies[i])
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package watchvolumes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestframework"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	MonitorName = "volume-lifecycle"
+
+	// maxClaimPendingDuration is generous, provisioning in every cloud we test on finishes well inside it.
+	maxClaimPendingDuration = 5 * time.Minute
+	// maxDetachDuration is past the six minutes after which the attach-detach controller force detaches a volume
+	// that is still mounted, so a detach that takes longer is stuck rather than waiting on the node.
+	maxDetachDuration = 7 * time.Minute
+)
+
+var (
+	claimPendingTestName = fmt.Sprintf("[sig-storage] volume-lifecycle persistentvolumeclaims should be bound within %v", maxClaimPendingDuration)
+	detachTestName       = fmt.Sprintf("[sig-storage] volume-lifecycle volumeattachments should be detached within %v after their pods are deleted", maxDetachDuration)
+)
+
+type volumeWatcher struct{}
+
+func NewVolumeWatcher() monitortestframework.MonitorTest {
+	return &volumeWatcher{}
+}
+
+func (w *volumeWatcher) StartCollection(ctx context.Context, adminRESTConfig *rest.Config, recorder monitorapi.RecorderWriter) error {
+	kubeClient, err := kubernetes.NewForConfig(adminRESTConfig)
+	if err != nil {
+		return err
+	}
+
+	startClaimMonitoring(ctx, recorder, kubeClient)
+	startPersistentVolumeMonitoring(ctx, recorder, kubeClient)
+	startVolumeAttachmentMonitoring(ctx, recorder, kubeClient)
+
+	return nil
+}
+
+func (w *volumeWatcher) CollectData(ctx context.Context, storageDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
+	// because we are sharing a recorder that we're streaming into, we don't need to have a separate data collection step.
+	return nil, nil, nil
+}
+
+func (*volumeWatcher) ConstructComputedIntervals(ctx context.Context, startingIntervals monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, beginning, end time.Time) (monitorapi.Intervals, error) {
+	constructedIntervals := monitorapi.Intervals{}
+	constructedIntervals = append(constructedIntervals, constructClaimIntervals(startingIntervals, beginning, end)...)
+	constructedIntervals = append(constructedIntervals, constructAttachmentIntervals(startingIntervals, beginning, end)...)
+	return constructedIntervals, nil
+}
+
+func (*volumeWatcher) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
+	junits := []*junitapi.JUnitTestCase{}
+	junits = append(junits, claimPendingJunits(finalIntervals)...)
+	junits = append(junits, detachJunits(finalIntervals)...)
+	return junits, nil
+}
+
+func (*volumeWatcher) WriteContentToStorage(ctx context.Context, storageDir, timeSuffix string, finalIntervals monitorapi.Intervals, finalResourceState monitorapi.ResourcesMap) error {
+	latencies := summarizeVolumeLatencies(finalIntervals)
+	if len(latencies) == 0 {
+		return nil
+	}
+	content, err := json.MarshalIndent(latencies, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(storageDir, fmt.Sprintf("volume-latency_%s.json", timeSuffix)), content, 0644)
+}
+
+func (*volumeWatcher) Cleanup(ctx context.Context) error {
+	// TODO wire up the start to a context we can kill here
+	return nil
+}
+
+func longIntervals(finalIntervals monitorapi.Intervals, threshold time.Duration, reasons ...monitorapi.IntervalReason) []string {
+	ret := []string{}
+	for _, interval := range finalIntervals {
+		if interval.Source != monitorapi.SourceVolumeMonitor || interval.To.Sub(interval.From) <= threshold {
+			continue
+		}
+		for _, reason := range reasons {
+			if interval.Message.Reason == reason {
+				ret = append(ret, interval.String())
+			}
+		}
+	}
+	return ret
+}
+
+// claimPendingJunits flakes when a claim waited too long to be bound.  Tests deliberately create claims that can never
+// be bound, so this only fails once we can tell those apart.
+func claimPendingJunits(finalIntervals monitorapi.Intervals) []*junitapi.JUnitTestCase {
+	stuck := longIntervals(finalIntervals, maxClaimPendingDuration, monitorapi.PersistentVolumeClaimPending, monitorapi.PersistentVolumeClaimNeverBound)
+
+	junits := []*junitapi.JUnitTestCase{}
+	if len(stuck) > 0 {
+		junits = append(junits, &junitapi.JUnitTestCase{
+			Name: claimPendingTestName,
+			FailureOutput: &junitapi.FailureOutput{
+				Output: fmt.Sprintf("%d persistentvolumeclaims were pending for longer than %v\n\n%v", len(stuck), maxClaimPendingDuration, strings.Join(stuck, "\n")),
+			},
+		})
+	}
+	// this test only flakes
+	junits = append(junits, &junitapi.JUnitTestCase{Name: claimPendingTestName})
+	return junits
+}
+
+// detachJunits fails when a detach outlived the pods using the volume by more than the force detach timeout.  The
+// attach-detach controller only requests the detach once no pod on the node uses the volume.
+func detachJunits(finalIntervals monitorapi.Intervals) []*junitapi.JUnitTestCase {
+	stuck := longIntervals(finalIntervals, maxDetachDuration, monitorapi.VolumeDetaching)
+	if len(stuck) == 0 {
+		return []*junitapi.JUnitTestCase{{Name: detachTestName}}
+	}
+	return []*junitapi.JUnitTestCase{
+		{
+			Name: detachTestName,
+			FailureOutput: &junitapi.FailureOutput{
+				Output: fmt.Sprintf("%d volumes took longer than %v to detach\n\n%v", len(stuck), maxDetachDuration, strings.Join(stuck, "\n")),
+			},
+		},
+	}
+}
+
+// volumeLatency summarizes one operation for a driver and storage class so storage regressions show up as numbers.
+type volumeLatency struct {
+	Operation    string  `json:"operation"`
+	Driver       string  `json:"driver"`
+	StorageClass string  `json:"storageClass"`
+	Count        int     `json:"count"`
+	P50Seconds   float64 `json:"p50Seconds"`
+	P90Seconds   float64 `json:"p90Seconds"`
+	MaxSeconds   float64 `json:"maxSeconds"`
+}
+
+// operationForReason only includes operations that completed, the ones that did not are reported by the tests.
+var operationForReason = map[monitorapi.IntervalReason]string{
+	monitorapi.PersistentVolumeClaimPending: "provision",
+	monitorapi.VolumeAttaching:              "attach",
+	monitorapi.VolumeDetaching:              "detach",
+}
+
+func summarizeVolumeLatencies(finalIntervals monitorapi.Intervals) []volumeLatency {
+	type latencyKey struct {
+		operation, driver, storageClass string
+	}
+	durations := map[latencyKey][]float64{}
+	for _, interval := range finalIntervals {
+		if interval.Source != monitorapi.SourceVolumeMonitor || interval.Level != monitorapi.Info {
+			continue
+		}
+		operation, ok := operationForReason[interval.Message.Reason]
+		if !ok {
+			continue
+		}
+		key := latencyKey{
+			operation:    operation,
+			driver:       interval.Message.Annotations[monitorapi.AnnotationDriver],
+			storageClass: interval.Message.Annotations[monitorapi.AnnotationStorageClass],
+		}
+		durations[key] = append(durations[key], interval.To.Sub(interval.From).Seconds())
+	}
+
+	ret := []volumeLatency{}
+	for key, values := range durations {
+		sort.Float64s(values)
+		ret = append(ret, volumeLatency{
+			Operation:    key.operation,
+			Driver:       key.driver,
+			StorageClass: key.storageClass,
+			Count:        len(values),
+			P50Seconds:   percentile(values, 0.5),
+			P90Seconds:   percentile(values, 0.9),
+			MaxSeconds:   values[len(values)-1],
+		})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Operation != ret[j].Operation {
+			return ret[i].Operation < ret[j].Operation
+		}
+		if ret[i].Driver != ret[j].Driver {
+			return ret[i].Driver < ret[j].Driver
+		}
+		return ret[i].StorageClass < ret[j].StorageClass
+	})
+	return ret
+}
+
+// percentile uses the nearest rank of sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
@@ -0,0 +1,123 @@
+package watchvolumes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestlibrary"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+const provisionedByAnnotation = "pv.kubernetes.io/provisioned-by"
+
+func startPersistentVolumeMonitoring(ctx context.Context, m monitorapi.RecorderWriter, client kubernetes.Interface) {
+	volumeChangeFns := []func(volume, oldVolume *corev1.PersistentVolume) []monitorapi.Interval{
+		// this is first so volume created shows up first when queried.  It carries the storage class and driver so
+		// attachments, which only name the volume, can be attributed.
+		func(volume, oldVolume *corev1.PersistentVolume) []monitorapi.Interval {
+			var intervals []monitorapi.Interval
+			if volume == nil || oldVolume != nil {
+				return intervals
+			}
+			created := volume.CreationTimestamp.Time
+			intervals = append(intervals,
+				monitorapi.NewInterval(monitorapi.SourceVolumeMonitor, monitorapi.Info).
+					Locator(persistentVolumeLocator(volume.Name)).
+					Message(persistentVolumeMessage(volume, monitorapi.PersistentVolumeCreated).
+						HumanMessage("PersistentVolume created")).
+					Build(created, created))
+			return intervals
+		},
+
+		func(volume, oldVolume *corev1.PersistentVolume) []monitorapi.Interval {
+			var intervals []monitorapi.Interval
+			if volume == nil || oldVolume == nil {
+				return intervals
+			}
+			oldPhase := oldVolume.Status.Phase
+			if volume.Status.Phase == oldPhase {
+				return intervals
+			}
+			level := monitorapi.Info
+			if volume.Status.Phase == corev1.VolumeFailed {
+				level = monitorapi.Error
+			}
+			now := time.Now()
+			intervals = append(intervals,
+				monitorapi.NewInterval(monitorapi.SourceVolumeMonitor, level).
+					Locator(persistentVolumeLocator(volume.Name)).
+					Message(persistentVolumeMessage(volume, monitorapi.PersistentVolumePhaseChanged).
+						WithAnnotation(monitorapi.AnnotationPhase, string(volume.Status.Phase)).
+						WithAnnotation(monitorapi.AnnotationPreviousPhase, string(oldPhase)).
+						HumanMessage(fmt.Sprintf("PersistentVolume phase changed from %s to %s: %s", oldPhase, volume.Status.Phase, volume.Status.Message))).
+					Build(now, now))
+			return intervals
+		},
+	}
+
+	listWatch := cache.NewListWatchFromClient(client.CoreV1().RESTClient(), "persistentvolumes", "", fields.Everything())
+	customStore := monitortestlibrary.NewMonitoringStore(
+		"persistentvolumes",
+		toPersistentVolumeCreateFns(volumeChangeFns),
+		toPersistentVolumeUpdateFns(volumeChangeFns),
+		[]monitortestlibrary.ObjDeleteFunc{},
+		m,
+		m,
+	)
+	reflector := cache.NewReflector(listWatch, &corev1.PersistentVolume{}, customStore, 0)
+	go reflector.Run(ctx.Done())
+}
+
+func persistentVolumeLocator(name string) monitorapi.Locator {
+	return monitorapi.NewLocator().Resource("", "persistentvolumes", "", name)
+}
+
+func persistentVolumeMessage(volume *corev1.PersistentVolume, reason monitorapi.IntervalReason) *monitorapi.MessageBuilder {
+	driver := volume.Annotations[provisionedByAnnotation]
+	if volume.Spec.CSI != nil {
+		driver = volume.Spec.CSI.Driver
+	}
+	claim := ""
+	if volume.Spec.ClaimRef != nil {
+		claim = volume.Spec.ClaimRef.Namespace + "/" + volume.Spec.ClaimRef.Name
+	}
+	return monitorapi.NewMessage().Reason(reason).
+		WithAnnotation(monitorapi.AnnotationStorageClass, volume.Spec.StorageClassName).
+		WithAnnotation(monitorapi.AnnotationDriver, driver).
+		WithAnnotation(monitorapi.AnnotationClaim, claim)
+}
+
+func toPersistentVolumeCreateFns(volumeUpdateFns []func(volume, oldVolume *corev1.PersistentVolume) []monitorapi.Interval) []monitortestlibrary.ObjCreateFunc {
+	ret := []monitortestlibrary.ObjCreateFunc{}
+
+	for i := range volumeUpdateFns {
+		fn := volumeUpdateFns[i]
+		ret = append(ret, func(obj interface{}) []monitorapi.Interval {
+			return fn(obj.(*corev1.PersistentVolume), nil)
+		})
+	}
+
+	return ret
+}
+
+func toPersistentVolumeUpdateFns(volumeUpdateFns []func(volume, oldVolume *corev1.PersistentVolume) []monitorapi.Interval) []monitortestlibrary.ObjUpdateFunc {
+	ret := []monitortestlibrary.ObjUpdateFunc{}
+
+	for i := range volumeUpdateFns {
+		fn := volumeUpdateFns[i]
+		ret = append(ret, func(obj, oldObj interface{}) []monitorapi.Interval {
+			if oldObj == nil {
+				return fn(obj.(*corev1.PersistentVolume), nil)
+			}
+			return fn(obj.(*corev1.PersistentVolume), oldObj.(*corev1.PersistentVolume))
+		})
+	}
+
+	return ret
+}
@@ -0,0 +1,201 @@
+package watchvolumes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestlibrary"
+
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+func startVolumeAttachmentMonitoring(ctx context.Context, m monitorapi.RecorderWriter, client kubernetes.Interface) {
+	attachmentChangeFns := []func(attachment, oldAttachment *storagev1.VolumeAttachment) []monitorapi.Interval{
+		// this is first so attachment created shows up first when queried.  The attach-detach controller creates the
+		// attachment when a pod using the volume is scheduled, so this is when attach was requested.
+		func(attachment, oldAttachment *storagev1.VolumeAttachment) []monitorapi.Interval {
+			var intervals []monitorapi.Interval
+			if attachment == nil || oldAttachment != nil {
+				return intervals
+			}
+			created := attachment.CreationTimestamp.Time
+			intervals = append(intervals,
+				monitorapi.NewInterval(monitorapi.SourceVolumeMonitor, monitorapi.Info).
+					Locator(volumeAttachmentLocator(attachment.Name)).
+					Message(volumeAttachmentMessage(attachment, monitorapi.VolumeAttachmentCreated).
+						HumanMessage(fmt.Sprintf("VolumeAttachment created for node %s", attachment.Spec.NodeName))).
+					Build(created, created))
+			return intervals
+		},
+
+		// attachment status carries no timestamp, so this is only recorded when observed.
+		func(attachment, oldAttachment *storagev1.VolumeAttachment) []monitorapi.Interval {
+			var intervals []monitorapi.Interval
+			if attachment == nil || oldAttachment == nil {
+				return intervals
+			}
+			if oldAttachment.Status.Attached || !attachment.Status.Attached {
+				return intervals
+			}
+			now := time.Now()
+			intervals = append(intervals,
+				monitorapi.NewInterval(monitorapi.SourceVolumeMonitor, monitorapi.Info).
+					Locator(volumeAttachmentLocator(attachment.Name)).
+					Message(volumeAttachmentMessage(attachment, monitorapi.VolumeAttachmentAttached).
+						HumanMessage("volume attached")).
+					Build(now, now))
+			return intervals
+		},
+
+		func(attachment, oldAttachment *storagev1.VolumeAttachment) []monitorapi.Interval {
+			var intervals []monitorapi.Interval
+			if attachment == nil {
+				return intervals
+			}
+			for _, operation := range []struct {
+				name     string
+				err      *storagev1.VolumeError
+				previous *storagev1.VolumeError
+			}{
+				{name: "attach", err: attachment.Status.AttachError, previous: oldAttachmentError(oldAttachment, true)},
+				{name: "detach", err: attachment.Status.DetachError, previous: oldAttachmentError(oldAttachment, false)},
+			} {
+				if operation.err == nil {
+					continue
+				}
+				if operation.previous != nil && operation.previous.Message == operation.err.Message {
+					continue
+				}
+				at := operation.err.Time.Time
+				if at.IsZero() {
+					at = time.Now()
+				}
+				intervals = append(intervals,
+					monitorapi.NewInterval(monitorapi.SourceVolumeMonitor, monitorapi.Warning).
+						Locator(volumeAttachmentLocator(attachment.Name)).
+						Message(volumeAttachmentMessage(attachment, monitorapi.VolumeAttachmentError).
+							HumanMessage(fmt.Sprintf("%s failed: %s", operation.name, operation.err.Message))).
+						Build(at, at))
+			}
+			return intervals
+		},
+
+		func(attachment, oldAttachment *storagev1.VolumeAttachment) []monitorapi.Interval {
+			var intervals []monitorapi.Interval
+			if attachment == nil || attachment.DeletionTimestamp == nil {
+				return intervals
+			}
+			if oldAttachment != nil && oldAttachment.DeletionTimestamp != nil {
+				return intervals
+			}
+			requested := attachment.DeletionTimestamp.Time
+			intervals = append(intervals,
+				monitorapi.NewInterval(monitorapi.SourceVolumeMonitor, monitorapi.Info).
+					Locator(volumeAttachmentLocator(attachment.Name)).
+					Message(volumeAttachmentMessage(attachment, monitorapi.VolumeAttachmentDetachRequested).
+						HumanMessage("detach requested")).
+					Build(requested, requested))
+			return intervals
+		},
+
+		// this is last so attachment deleted shows up last when queried.  The attachment is deleted once detached.
+		func(attachment, oldAttachment *storagev1.VolumeAttachment) []monitorapi.Interval {
+			var intervals []monitorapi.Interval
+			if attachment != nil || oldAttachment == nil {
+				return intervals
+			}
+			now := time.Now()
+			intervals = append(intervals,
+				monitorapi.NewInterval(monitorapi.SourceVolumeMonitor, monitorapi.Info).
+					Locator(volumeAttachmentLocator(oldAttachment.Name)).
+					Message(volumeAttachmentMessage(oldAttachment, monitorapi.VolumeAttachmentDeleted).
+						HumanMessage("VolumeAttachment deleted")).
+					Build(now, now))
+			return intervals
+		},
+	}
+
+	listWatch := cache.NewListWatchFromClient(client.StorageV1().RESTClient(), "volumeattachments", "", fields.Everything())
+	customStore := monitortestlibrary.NewMonitoringStore(
+		"volumeattachments",
+		toVolumeAttachmentCreateFns(attachmentChangeFns),
+		toVolumeAttachmentUpdateFns(attachmentChangeFns),
+		toVolumeAttachmentDeleteFns(attachmentChangeFns),
+		m,
+		m,
+	)
+	reflector := cache.NewReflector(listWatch, &storagev1.VolumeAttachment{}, customStore, 0)
+	go reflector.Run(ctx.Done())
+}
+
+func oldAttachmentError(oldAttachment *storagev1.VolumeAttachment, attach bool) *storagev1.VolumeError {
+	switch {
+	case oldAttachment == nil:
+		return nil
+	case attach:
+		return oldAttachment.Status.AttachError
+	default:
+		return oldAttachment.Status.DetachError
+	}
+}
+
+func volumeAttachmentLocator(name string) monitorapi.Locator {
+	return monitorapi.NewLocator().Resource("storage.k8s.io", "volumeattachments", "", name)
+}
+
+func volumeAttachmentMessage(attachment *storagev1.VolumeAttachment, reason monitorapi.IntervalReason) *monitorapi.MessageBuilder {
+	volume := ""
+	if attachment.Spec.Source.PersistentVolumeName != nil {
+		volume = *attachment.Spec.Source.PersistentVolumeName
+	}
+	return monitorapi.NewMessage().Reason(reason).
+		WithAnnotation(monitorapi.AnnotationDriver, attachment.Spec.Attacher).
+		WithAnnotation(monitorapi.AnnotationNode, attachment.Spec.NodeName).
+		WithAnnotation(monitorapi.AnnotationVolume, volume)
+}
+
+func toVolumeAttachmentCreateFns(attachmentUpdateFns []func(attachment, oldAttachment *storagev1.VolumeAttachment) []monitorapi.Interval) []monitortestlibrary.ObjCreateFunc {
+	ret := []monitortestlibrary.ObjCreateFunc{}
+
+	for i := range attachmentUpdateFns {
+		fn := attachmentUpdateFns[i]
+		ret = append(ret, func(obj interface{}) []monitorapi.Interval {
+			return fn(obj.(*storagev1.VolumeAttachment), nil)
+		})
+	}
+
+	return ret
+}
+
+func toVolumeAttachmentDeleteFns(attachmentUpdateFns []func(attachment, oldAttachment *storagev1.VolumeAttachment) []monitorapi.Interval) []monitortestlibrary.ObjDeleteFunc {
+	ret := []monitortestlibrary.ObjDeleteFunc{}
+
+	for i := range attachmentUpdateFns {
+		fn := attachmentUpdateFns[i]
+		ret = append(ret, func(obj interface{}) []monitorapi.Interval {
+			return fn(nil, obj.(*storagev1.VolumeAttachment))
+		})
+	}
+	return ret
+}
+
+func toVolumeAttachmentUpdateFns(attachmentUpdateFns []func(attachment, oldAttachment *storagev1.VolumeAttachment) []monitorapi.Interval) []monitortestlibrary.ObjUpdateFunc {
+	ret := []monitortestlibrary.ObjUpdateFunc{}
+
+	for i := range attachmentUpdateFns {
+		fn := attachmentUpdateFns[i]
+		ret = append(ret, func(obj, oldObj interface{}) []monitorapi.Interval {
+			if oldObj == nil {
+				return fn(obj.(*storagev1.VolumeAttachment), nil)
+			}
+			return fn(obj.(*storagev1.VolumeAttachment), oldObj.(*storagev1.VolumeAttachment))
+		})
+	}
+
+	return ret
+}
@@ -41,7 +41,15 @@ func (*disruptionSummarySerializer) EvaluateTestsFromConstructedIntervals(ctx co
 
 func (*disruptionSummarySerializer) WriteContentToStorage(ctx context.Context, storageDir, timeSuffix string, finalIntervals monitorapi.Intervals, finalResourceState monitorapi.ResourcesMap) error {
 	backendDisruption := computeDisruptionData(finalIntervals)
-	return writeDisruptionData(filepath.Join(storageDir, fmt.Sprintf("backend-disruption%s.json", timeSuffix)), backendDisruption)
+	if err := writeDisruptionData(filepath.Join(storageDir, fmt.Sprintf("backend-disruption%s.json", timeSuffix)), backendDisruption); err != nil {
+		return err
+	}
+
+	// the name must not match backend-disruption*.json, which risk analysis reads as the disruption of the whole run.
+	if hopDisruption := computeUpgradeHopDisruptionData(finalIntervals); hopDisruption != nil {
+		return writeDisruptionData(filepath.Join(storageDir, fmt.Sprintf("upgrade-hop-disruption%s.json", timeSuffix)), hopDisruption)
+	}
+	return nil
 }
 
 func (*disruptionSummarySerializer) Cleanup(ctx context.Context) error {
@@ -75,7 +83,26 @@ type BackendDisruption struct {
 	TargetAPI        string
 }
 
-func writeDisruptionData(filename string, disruption *BackendDisruptionList) error {
+// UpgradeHopDisruptionList holds the disruption observed during each hop of a multi-hop upgrade.
+type UpgradeHopDisruptionList struct {
+	Hops []*UpgradeHopDisruption
+}
+
+type UpgradeHopDisruption struct {
+	// Hop is the position of the hop in the upgrade path, starting at one
+	Hop     string
+	Version string
+	Image   string
+	// State is Completed, Failed, or Incomplete
+	State string
+	From  metav1.Time
+	To    metav1.Time
+
+	// BackendDisruptions is keyed by name, like in BackendDisruptionList, and only counts disruption during the hop
+	BackendDisruptions map[string]*BackendDisruption
+}
+
+func writeDisruptionData(filename string, disruption interface{}) error {
 	jsonContent, err := json.MarshalIndent(disruption, "", "    ")
 	if err != nil {
 		return err
@@ -126,3 +153,28 @@ func computeDisruptionData(eventIntervals monitorapi.Intervals) *BackendDisrupti
 
 	return ret
 }
+
+// computeUpgradeHopDisruptionData computes the disruption within each hop interval marked by the upgrade-hops monitor
+// test, or returns nil if the run did not upgrade through more than one hop.
+func computeUpgradeHopDisruptionData(eventIntervals monitorapi.Intervals) *UpgradeHopDisruptionList {
+	hops := eventIntervals.Filter(func(eventInterval monitorapi.Interval) bool {
+		return eventInterval.Source == monitorapi.SourceUpgradeHop && eventInterval.Message.Reason == monitorapi.UpgradeHopReason
+	})
+	if len(hops) == 0 {
+		return nil
+	}
+
+	ret := &UpgradeHopDisruptionList{}
+	for _, hop := range hops {
+		ret.Hops = append(ret.Hops, &UpgradeHopDisruption{
+			Hop:                hop.Message.Annotations[monitorapi.AnnotationUpgradeHop],
+			Version:            hop.Message.Annotations[monitorapi.AnnotationVersion],
+			Image:              hop.Message.Annotations[monitorapi.AnnotationImage],
+			State:              hop.Message.Annotations[monitorapi.AnnotationState],
+			From:               metav1.NewTime(hop.From),
+			To:                 metav1.NewTime(hop.To),
+			BackendDisruptions: computeDisruptionData(eventIntervals.Cut(hop.From, hop.To)).BackendDisruptions,
+		})
+	}
+	return ret
+}
@@ -454,3 +454,57 @@ func TestComputeDisruptionData(t *testing.T) {
 		})
 	}
 }
+
+func TestComputeUpgradeHopDisruptionData(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	disruption := func(from, to time.Duration) monitorapi.Interval {
+		return monitorapi.Interval{
+			Condition: monitorapi.Condition{
+				Level: monitorapi.Error,
+				Locator: monitorapi.Locator{
+					Type: monitorapi.LocatorTypeDisruption,
+					Keys: map[monitorapi.LocatorKey]string{
+						monitorapi.LocatorBackendDisruptionNameKey: "kube-api-new-connections",
+						monitorapi.LocatorDisruptionKey:            "kube-api",
+						monitorapi.LocatorConnectionKey:            "new",
+					},
+				},
+				Message: monitorapi.Message{
+					Reason:       monitorapi.DisruptionBeganEventReason,
+					HumanMessage: "foo",
+				},
+			},
+			From:   start.Add(from),
+			To:     start.Add(to),
+			Source: monitorapi.SourceDisruption,
+		}
+	}
+	hop := func(number, state string, from, to time.Duration) monitorapi.Interval {
+		return monitorapi.NewInterval(monitorapi.SourceUpgradeHop, monitorapi.Info).
+			Locator(monitorapi.NewLocator().UpgradeHop(number)).
+			Message(monitorapi.NewMessage().Reason(monitorapi.UpgradeHopReason).
+				WithAnnotation(monitorapi.AnnotationUpgradeHop, number).
+				WithAnnotation(monitorapi.AnnotationState, state).
+				HumanMessage("hop")).
+			Build(start.Add(from), start.Add(to))
+	}
+
+	assert.Nil(t, computeUpgradeHopDisruptionData(monitorapi.Intervals{disruption(0, time.Minute)}))
+
+	hops := computeUpgradeHopDisruptionData(monitorapi.Intervals{
+		disruption(10*time.Minute, 12*time.Minute),
+		hop("1", "Completed", 0, 30*time.Minute),
+		// disruption across the hop boundary is split between the hops
+		disruption(29*time.Minute, 33*time.Minute),
+		hop("2", "Failed", 30*time.Minute, 60*time.Minute),
+	})
+	if !assert.Len(t, hops.Hops, 2) {
+		return
+	}
+	assert.Equal(t, "1", hops.Hops[0].Hop)
+	assert.Equal(t, "Completed", hops.Hops[0].State)
+	assert.Equal(t, metav1.Duration{Duration: 3 * time.Minute}, hops.Hops[0].BackendDisruptions["kube-api-new-connections"].DisruptedDuration)
+	assert.Equal(t, "2", hops.Hops[1].Hop)
+	assert.Equal(t, "Failed", hops.Hops[1].State)
+	assert.Equal(t, metav1.Duration{Duration: 3 * time.Minute}, hops.Hops[1].BackendDisruptions["kube-api-new-connections"].DisruptedDuration)
+}
@@ -19,11 +19,18 @@ type resourceObserverEventHandler interface {
 // for cache correctness and latency, but it keeps me from having rip out more logic than I want to.
 // It doesn't logically need to run because there is no sync method.  it's all handled by the gitStorage.
 // if you ask for a resource that doesn't exist, it will simply repeated error until it appears while watching all the other types.
+// Every notification passes through the filter before it reaches the gitStorage.
 func WireResourceInformersToGitRepo(
 	dynamicInformerFactory dynamicinformer.DynamicSharedInformerFactory,
 	gitStorage resourceObserverEventHandler,
 	resourcesToWatch []schema.GroupVersionResource,
+	filter ResourceFilter,
 ) {
+	gitStorage = &filteringEventHandler{
+		delegate: gitStorage,
+		filter:   filter,
+	}
+
 	for i := range resourcesToWatch {
 		resourceToWatch := resourcesToWatch[i]
 		// we got mapping, lets run the dynamicInformer for the config and install GIT storageHandler event handlers
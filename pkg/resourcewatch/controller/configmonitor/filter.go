@@ -0,0 +1,90 @@
+package configmonitor
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ResourceFilter describes which observed objects reach storage and what they look like when they get there.
+type ResourceFilter struct {
+	// Namespaces limits namespaced objects to these namespaces.  Empty means all namespaces.
+	// Cluster scoped objects are never filtered.
+	Namespaces sets.String
+	// Redactions are applied to every object before it is handed to storage.
+	Redactions []RedactionRule
+}
+
+// filteringEventHandler applies a ResourceFilter in front of another handler.  Namespace filtering happens here
+// instead of in the informers so that a single informer can serve cluster scoped and namespaced resources alike.
+type filteringEventHandler struct {
+	delegate resourceObserverEventHandler
+	filter   ResourceFilter
+}
+
+func (h *filteringEventHandler) OnAdd(gvr schema.GroupVersionResource, obj interface{}) {
+	if !h.included(obj) {
+		return
+	}
+	h.delegate.OnAdd(gvr, h.redact(gvr, obj))
+}
+
+func (h *filteringEventHandler) OnUpdate(gvr schema.GroupVersionResource, oldObj, obj interface{}) {
+	if !h.included(obj) {
+		return
+	}
+	h.delegate.OnUpdate(gvr, h.redact(gvr, oldObj), h.redact(gvr, obj))
+}
+
+func (h *filteringEventHandler) OnDelete(gvr schema.GroupVersionResource, obj interface{}) {
+	if !h.included(obj) {
+		return
+	}
+	// deletes only need the identity of the object, there is no content to redact.
+	h.delegate.OnDelete(gvr, obj)
+}
+
+func (h *filteringEventHandler) included(obj interface{}) bool {
+	if len(h.filter.Namespaces) == 0 {
+		return true
+	}
+	objUnstructured, ok := toUnstructured(obj)
+	if !ok {
+		// let the delegate report the problem.
+		return true
+	}
+	namespace := objUnstructured.GetNamespace()
+	return len(namespace) == 0 || h.filter.Namespaces.Has(namespace)
+}
+
+func (h *filteringEventHandler) redact(gvr schema.GroupVersionResource, obj interface{}) interface{} {
+	objUnstructured, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return obj
+	}
+
+	var redacted *unstructured.Unstructured
+	for _, rule := range h.filter.Redactions {
+		if !rule.AppliesTo(gvr) {
+			continue
+		}
+		// never mutate the informer cache.
+		if redacted == nil {
+			redacted = objUnstructured.DeepCopy()
+		}
+		rule.Redact(redacted)
+	}
+	if redacted == nil {
+		return obj
+	}
+	return redacted
+}
+
+func toUnstructured(obj interface{}) (*unstructured.Unstructured, bool) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	objUnstructured, ok := obj.(*unstructured.Unstructured)
+	return objUnstructured, ok
+}
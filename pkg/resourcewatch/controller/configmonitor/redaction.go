@@ -0,0 +1,147 @@
+package configmonitor
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// RedactionRule removes a field from observed objects before they are written to storage.
+// Rules have the form [<resource>[.<group>]:]<path>, for example
+//
+//	status.conditions[*].lastHeartbeatTime
+//	secrets:data
+//	deployments.apps:metadata.annotations[kubectl.kubernetes.io/last-applied-configuration]
+//
+// A path is a list of field names separated by dots.  [*] descends into every item of a list and [key] selects a
+// map key that contains dots.  Without a resource prefix the rule applies to every watched resource.
+type RedactionRule struct {
+	// groupResource limits the rule to a single resource, nil means every resource.
+	groupResource *schema.GroupResource
+	steps         []redactionStep
+
+	raw string
+}
+
+type redactionStep struct {
+	field    string
+	eachItem bool
+}
+
+func ParseRedactionRule(rule string) (RedactionRule, error) {
+	ret := RedactionRule{raw: rule}
+
+	path := rule
+	if colon := strings.Index(rule, ":"); colon >= 0 {
+		if bracket := strings.Index(rule, "["); bracket < 0 || colon < bracket {
+			groupResource := schema.ParseGroupResource(rule[:colon])
+			if len(groupResource.Resource) == 0 {
+				return RedactionRule{}, fmt.Errorf("redaction rule %q has an empty resource", rule)
+			}
+			ret.groupResource = &groupResource
+			path = rule[colon+1:]
+		}
+	}
+
+	steps, err := parseRedactionPath(path)
+	if err != nil {
+		return RedactionRule{}, fmt.Errorf("redaction rule %q is invalid: %w", rule, err)
+	}
+	ret.steps = steps
+	return ret, nil
+}
+
+func parseRedactionPath(path string) ([]redactionStep, error) {
+	steps := []redactionStep{}
+	field := strings.Builder{}
+	flushField := func() {
+		if field.Len() > 0 {
+			steps = append(steps, redactionStep{field: field.String()})
+			field.Reset()
+		}
+	}
+
+	for i := 0; i < len(path); i++ {
+		switch c := path[i]; c {
+		case '.':
+			if field.Len() == 0 && (i == 0 || path[i-1] != ']') {
+				return nil, fmt.Errorf("empty field name at position %d", i)
+			}
+			flushField()
+		case '[':
+			flushField()
+			end := strings.Index(path[i:], "]")
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed [ at position %d", i)
+			}
+			key := path[i+1 : i+end]
+			switch {
+			case len(key) == 0:
+				return nil, fmt.Errorf("empty [] at position %d", i)
+			case key == "*":
+				steps = append(steps, redactionStep{eachItem: true})
+			default:
+				steps = append(steps, redactionStep{field: key})
+			}
+			i += end
+		default:
+			field.WriteByte(c)
+		}
+	}
+	flushField()
+
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("path is empty")
+	}
+	if steps[len(steps)-1].eachItem {
+		return nil, fmt.Errorf("path must end with a field name, not [*]")
+	}
+	return steps, nil
+}
+
+func (r RedactionRule) String() string {
+	return r.raw
+}
+
+// AppliesTo returns true if the rule should be applied to objects of this resource.
+func (r RedactionRule) AppliesTo(gvr schema.GroupVersionResource) bool {
+	if r.groupResource == nil {
+		return true
+	}
+	return *r.groupResource == gvr.GroupResource()
+}
+
+// Redact removes the field from obj in place.  Missing fields are ignored.
+func (r RedactionRule) Redact(obj *unstructured.Unstructured) {
+	redact(obj.Object, r.steps)
+}
+
+func redact(obj interface{}, steps []redactionStep) {
+	if len(steps) == 0 {
+		return
+	}
+	step := steps[0]
+
+	if step.eachItem {
+		items, ok := obj.([]interface{})
+		if !ok {
+			return
+		}
+		for _, item := range items {
+			redact(item, steps[1:])
+		}
+		return
+	}
+
+	fields, ok := obj.(map[string]interface{})
+	if !ok {
+		return
+	}
+	if len(steps) == 1 {
+		delete(fields, step.field)
+		return
+	}
+	redact(fields[step.field], steps[1:])
+}
@@ -0,0 +1,187 @@
+package configmonitor
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+var (
+	nodesGVR   = schema.GroupVersionResource{Version: "v1", Resource: "nodes"}
+	secretsGVR = schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+)
+
+func TestRedactionRule(t *testing.T) {
+	tests := []struct {
+		name      string
+		rule      string
+		gvr       schema.GroupVersionResource
+		obj       map[string]interface{}
+		want      map[string]interface{}
+		wantParse bool
+	}{
+		{
+			name: "list items",
+			rule: "status.conditions[*].lastHeartbeatTime",
+			gvr:  nodesGVR,
+			obj: map[string]interface{}{
+				"status": map[string]interface{}{
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Ready", "lastHeartbeatTime": "now"},
+						map[string]interface{}{"type": "DiskPressure", "lastHeartbeatTime": "now"},
+					},
+				},
+			},
+			want: map[string]interface{}{
+				"status": map[string]interface{}{
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Ready"},
+						map[string]interface{}{"type": "DiskPressure"},
+					},
+				},
+			},
+		},
+		{
+			name: "scoped to resource",
+			rule: "secrets:data",
+			gvr:  secretsGVR,
+			obj:  map[string]interface{}{"kind": "Secret", "data": map[string]interface{}{"password": "c2VjcmV0"}},
+			want: map[string]interface{}{"kind": "Secret"},
+		},
+		{
+			name: "scoped to other resource",
+			rule: "secrets:data",
+			gvr:  nodesGVR,
+			obj:  map[string]interface{}{"data": "kept"},
+			want: map[string]interface{}{"data": "kept"},
+		},
+		{
+			name: "key with dots",
+			rule: "deployments.apps:metadata.annotations[kubectl.kubernetes.io/last-applied-configuration]",
+			gvr:  schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+			obj: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]interface{}{"kubectl.kubernetes.io/last-applied-configuration": "{}", "other": "kept"},
+				},
+			},
+			want: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]interface{}{"other": "kept"},
+				},
+			},
+		},
+		{
+			name: "missing field",
+			rule: "status.conditions[*].lastHeartbeatTime",
+			gvr:  nodesGVR,
+			obj:  map[string]interface{}{"status": "unexpected"},
+			want: map[string]interface{}{"status": "unexpected"},
+		},
+		{
+			name:      "trailing wildcard",
+			rule:      "status.conditions[*]",
+			wantParse: true,
+		},
+		{
+			name:      "empty field",
+			rule:      "status..conditions",
+			wantParse: true,
+		},
+		{
+			name:      "unclosed bracket",
+			rule:      "metadata.annotations[foo",
+			wantParse: true,
+		},
+		{
+			name:      "empty resource",
+			rule:      ":data",
+			wantParse: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRedactionRule(tt.rule)
+			if (err != nil) != tt.wantParse {
+				t.Fatalf("ParseRedactionRule() error = %v, wantErr %v", err, tt.wantParse)
+			}
+			if err != nil {
+				return
+			}
+
+			obj := &unstructured.Unstructured{Object: tt.obj}
+			if rule.AppliesTo(tt.gvr) {
+				rule.Redact(obj)
+			}
+			if !reflect.DeepEqual(obj.Object, tt.want) {
+				t.Errorf("Redact() = %#v, want %#v", obj.Object, tt.want)
+			}
+		})
+	}
+}
+
+type recordingEventHandler struct {
+	added   []*unstructured.Unstructured
+	deleted []interface{}
+}
+
+func (r *recordingEventHandler) OnAdd(gvr schema.GroupVersionResource, obj interface{}) {
+	r.added = append(r.added, obj.(*unstructured.Unstructured))
+}
+
+func (r *recordingEventHandler) OnUpdate(gvr schema.GroupVersionResource, _, obj interface{}) {
+	r.added = append(r.added, obj.(*unstructured.Unstructured))
+}
+
+func (r *recordingEventHandler) OnDelete(gvr schema.GroupVersionResource, obj interface{}) {
+	r.deleted = append(r.deleted, obj)
+}
+
+func TestFilteringEventHandler(t *testing.T) {
+	rule, err := ParseRedactionRule("secrets:data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := &recordingEventHandler{}
+	handler := &filteringEventHandler{
+		delegate: recorder,
+		filter: ResourceFilter{
+			Namespaces: sets.NewString("openshift-etcd"),
+			Redactions: []RedactionRule{rule},
+		},
+	}
+
+	inNamespace := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"namespace": "openshift-etcd", "name": "a"},
+		"data":     map[string]interface{}{"key": "value"},
+	}}
+	otherNamespace := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"namespace": "default", "name": "b"},
+	}}
+	clusterScoped := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "c"},
+	}}
+
+	handler.OnAdd(secretsGVR, inNamespace)
+	handler.OnAdd(secretsGVR, otherNamespace)
+	handler.OnAdd(nodesGVR, clusterScoped)
+	handler.OnDelete(secretsGVR, otherNamespace)
+
+	if len(recorder.added) != 2 {
+		t.Fatalf("expected 2 objects, got %d", len(recorder.added))
+	}
+	if _, ok := recorder.added[0].Object["data"]; ok {
+		t.Errorf("expected data to be redacted, got %#v", recorder.added[0].Object)
+	}
+	if _, ok := inNamespace.Object["data"]; !ok {
+		t.Errorf("original object must not be mutated")
+	}
+	if recorder.added[1].GetName() != "c" {
+		t.Errorf("expected cluster scoped object to be recorded, got %v", recorder.added[1].GetName())
+	}
+	if len(recorder.deleted) != 0 {
+		t.Errorf("expected deletes outside the namespaces to be dropped, got %d", len(recorder.deleted))
+	}
+}
@@ -0,0 +1,231 @@
+package operator
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/discovery"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/origin/pkg/resourcewatch/controller/configmonitor"
+	"github.com/openshift/origin/pkg/resourcewatch/storage"
+)
+
+// ResourceWatchConfig controls what run-resourcewatch observes and what it writes to the repository.
+// It can be read from a file and then amended by flags.
+type ResourceWatchConfig struct {
+	// RepositoryPath is the git repository to commit changes to.
+	RepositoryPath string `json:"repositoryPath,omitempty"`
+
+	// DisableDefaultResources skips the built-in list of resources, leaving only IncludeResources and DiscoverGroups.
+	DisableDefaultResources bool `json:"disableDefaultResources,omitempty"`
+	// IncludeResources are additional resources to watch in group/version/resource form.  Core resources may omit
+	// the group: v1/configmaps.
+	IncludeResources []string `json:"includeResources,omitempty"`
+	// ExcludeResources are removed from the final set of watched resources, using the same form as IncludeResources.
+	ExcludeResources []string `json:"excludeResources,omitempty"`
+	// DiscoverGroups lists API groups for which every listable and watchable resource is watched, using the
+	// preferred version reported by discovery.  Discovery happens once at startup.
+	DiscoverGroups []string `json:"discoverGroups,omitempty"`
+
+	// Namespaces limits namespaced resources to these namespaces.  Cluster scoped resources are always recorded.
+	Namespaces []string `json:"namespaces,omitempty"`
+	// LabelSelector limits every watch to objects matching the selector.
+	LabelSelector string `json:"labelSelector,omitempty"`
+
+	// Redactions are fields removed before objects are written.  See configmonitor.RedactionRule for the syntax.
+	Redactions []string `json:"redactions,omitempty"`
+
+	// MaxBatchSize is the number of changes after which a commit is made.  1 commits every change on its own.
+	MaxBatchSize int `json:"maxBatchSize,omitempty"`
+	// MaxBatchDelay is the longest an observed change waits before it is committed.
+	MaxBatchDelay metav1.Duration `json:"maxBatchDelay,omitempty"`
+	// QueueSize bounds the number of observed changes waiting to be committed.  Informers are slowed down
+	// when it is full.
+	QueueSize int `json:"queueSize,omitempty"`
+	// MetricsBindAddress serves prometheus metrics on /metrics, for instance :9090.  Empty disables metrics.
+	MetricsBindAddress string `json:"metricsBindAddress,omitempty"`
+}
+
+func NewResourceWatchConfig() *ResourceWatchConfig {
+	repositoryPath := "/repository"
+	if repositoryPathEnv := os.Getenv("REPOSITORY_PATH"); len(repositoryPathEnv) > 0 {
+		repositoryPath = repositoryPathEnv
+	}
+	batchOptions := storage.DefaultBatchOptions()
+	return &ResourceWatchConfig{
+		RepositoryPath: repositoryPath,
+		MaxBatchSize:   batchOptions.MaxBatchSize,
+		MaxBatchDelay:  metav1.Duration{Duration: batchOptions.MaxBatchDelay},
+		QueueSize:      batchOptions.QueueSize,
+	}
+}
+
+// ReadResourceWatchConfig reads a yaml or json config file.  Values not present in the file keep their defaults.
+func ReadResourceWatchConfig(filename string) (*ResourceWatchConfig, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	config := NewResourceWatchConfig()
+	if err := yaml.UnmarshalStrict(content, config); err != nil {
+		return nil, fmt.Errorf("failed to parse %q: %w", filename, err)
+	}
+	return config, nil
+}
+
+func (c *ResourceWatchConfig) Validate() error {
+	if len(c.RepositoryPath) == 0 {
+		return fmt.Errorf("repository path is required")
+	}
+	for _, resource := range append(append([]string{}, c.IncludeResources...), c.ExcludeResources...) {
+		if _, err := ParseGroupVersionResource(resource); err != nil {
+			return err
+		}
+	}
+	if _, err := labels.Parse(c.LabelSelector); err != nil {
+		return fmt.Errorf("invalid label selector %q: %w", c.LabelSelector, err)
+	}
+	if _, err := c.resourceFilter(); err != nil {
+		return err
+	}
+	if c.MaxBatchSize < 1 {
+		return fmt.Errorf("max batch size must be at least 1, got %d", c.MaxBatchSize)
+	}
+	if c.MaxBatchDelay.Duration <= 0 {
+		return fmt.Errorf("max batch delay must be positive, got %v", c.MaxBatchDelay.Duration)
+	}
+	if c.QueueSize < 1 {
+		return fmt.Errorf("queue size must be at least 1, got %d", c.QueueSize)
+	}
+	return nil
+}
+
+func (c *ResourceWatchConfig) batchOptions() storage.BatchOptions {
+	return storage.BatchOptions{
+		MaxBatchSize:  c.MaxBatchSize,
+		MaxBatchDelay: c.MaxBatchDelay.Duration,
+		QueueSize:     c.QueueSize,
+	}
+}
+
+func (c *ResourceWatchConfig) resourceFilter() (configmonitor.ResourceFilter, error) {
+	ret := configmonitor.ResourceFilter{
+		Namespaces: sets.NewString(c.Namespaces...),
+	}
+	for _, redaction := range c.Redactions {
+		rule, err := configmonitor.ParseRedactionRule(redaction)
+		if err != nil {
+			return configmonitor.ResourceFilter{}, err
+		}
+		ret.Redactions = append(ret.Redactions, rule)
+	}
+	return ret, nil
+}
+
+// resourcesToWatch combines the defaults, the explicit includes, and the discovered groups, and then removes the
+// excludes.  The result is de-duplicated and keeps the order of first appearance.
+func (c *ResourceWatchConfig) resourcesToWatch(discoveryClient discovery.DiscoveryInterface) ([]schema.GroupVersionResource, error) {
+	candidates := []schema.GroupVersionResource{}
+	if !c.DisableDefaultResources {
+		candidates = append(candidates, DefaultResourcesToWatch()...)
+	}
+	for _, resource := range c.IncludeResources {
+		gvr, err := ParseGroupVersionResource(resource)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, gvr)
+	}
+	if len(c.DiscoverGroups) > 0 {
+		discovered, err := discoverGroupResources(discoveryClient, sets.NewString(c.DiscoverGroups...))
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, discovered...)
+	}
+
+	excluded := sets.New[schema.GroupVersionResource]()
+	for _, resource := range c.ExcludeResources {
+		gvr, err := ParseGroupVersionResource(resource)
+		if err != nil {
+			return nil, err
+		}
+		excluded.Insert(gvr)
+	}
+
+	ret := []schema.GroupVersionResource{}
+	seen := sets.New[schema.GroupVersionResource]()
+	for _, gvr := range candidates {
+		if excluded.Has(gvr) || seen.Has(gvr) {
+			continue
+		}
+		seen.Insert(gvr)
+		ret = append(ret, gvr)
+	}
+	return ret, nil
+}
+
+// discoverGroupResources returns every resource in the groups that can be listed and watched.
+// Partial discovery failures for other groups are tolerated, failures for a requested group are not.
+func discoverGroupResources(discoveryClient discovery.DiscoveryInterface, groups sets.String) ([]schema.GroupVersionResource, error) {
+	resourceLists, err := discovery.ServerPreferredResources(discoveryClient)
+	if err != nil {
+		groupDiscoveryErr, ok := err.(*discovery.ErrGroupDiscoveryFailed)
+		if !ok {
+			return nil, fmt.Errorf("failed to discover resources: %w", err)
+		}
+		for groupVersion, groupErr := range groupDiscoveryErr.Groups {
+			if groups.Has(groupVersion.Group) {
+				return nil, fmt.Errorf("failed to discover resources for %v: %w", groupVersion, groupErr)
+			}
+		}
+		klog.Warningf("Ignoring discovery failures for unrequested groups: %v", err)
+	}
+
+	ret := []schema.GroupVersionResource{}
+	foundGroups := sets.NewString()
+	for _, resourceList := range resourceLists {
+		groupVersion, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			return nil, err
+		}
+		if !groups.Has(groupVersion.Group) {
+			continue
+		}
+		foundGroups.Insert(groupVersion.Group)
+		for _, apiResource := range resourceList.APIResources {
+			// subresources cannot be watched on their own
+			if strings.Contains(apiResource.Name, "/") {
+				continue
+			}
+			if !sets.NewString(apiResource.Verbs...).HasAll("list", "watch") {
+				continue
+			}
+			ret = append(ret, groupVersion.WithResource(apiResource.Name))
+		}
+	}
+	if missing := groups.Difference(foundGroups); len(missing) > 0 {
+		klog.Warningf("No resources discovered for groups: %v", missing.List())
+	}
+	return ret, nil
+}
+
+// ParseGroupVersionResource parses group/version/resource, or version/resource for the core group.
+func ParseGroupVersionResource(value string) (schema.GroupVersionResource, error) {
+	parts := strings.Split(value, "/")
+	switch {
+	case len(parts) == 2 && len(parts[0]) > 0 && len(parts[1]) > 0:
+		return schema.GroupVersionResource{Version: parts[0], Resource: parts[1]}, nil
+	case len(parts) == 3 && len(parts[1]) > 0 && len(parts[2]) > 0:
+		return schema.GroupVersionResource{Group: parts[0], Version: parts[1], Resource: parts[2]}, nil
+	default:
+		return schema.GroupVersionResource{}, fmt.Errorf("%q must be in group/version/resource or version/resource form", value)
+	}
+}
@@ -0,0 +1,104 @@
+package operator
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func fakeDiscovery() *fakediscovery.FakeDiscovery {
+	return &fakediscovery.FakeDiscovery{
+		Fake: &clienttesting.Fake{
+			Resources: []*metav1.APIResourceList{
+				{
+					GroupVersion: "example.openshift.io/v1",
+					APIResources: []metav1.APIResource{
+						{Name: "widgets", Namespaced: true, Verbs: []string{"get", "list", "watch"}},
+						{Name: "widgets/status", Namespaced: true, Verbs: []string{"get", "update"}},
+						{Name: "reviews", Verbs: []string{"create"}},
+					},
+				},
+				{
+					GroupVersion: "other.openshift.io/v1",
+					APIResources: []metav1.APIResource{
+						{Name: "gadgets", Verbs: []string{"list", "watch"}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestResourcesToWatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  ResourceWatchConfig
+		want    []schema.GroupVersionResource
+		wantErr bool
+	}{
+		{
+			name: "includes and discovery without defaults",
+			config: ResourceWatchConfig{
+				DisableDefaultResources: true,
+				IncludeResources:        []string{"v1/configmaps", "apps/v1/deployments", "v1/configmaps"},
+				DiscoverGroups:          []string{"example.openshift.io"},
+			},
+			want: []schema.GroupVersionResource{
+				{Version: "v1", Resource: "configmaps"},
+				{Group: "apps", Version: "v1", Resource: "deployments"},
+				{Group: "example.openshift.io", Version: "v1", Resource: "widgets"},
+			},
+		},
+		{
+			name: "excludes",
+			config: ResourceWatchConfig{
+				DisableDefaultResources: true,
+				IncludeResources:        []string{"v1/configmaps", "v1/secrets"},
+				ExcludeResources:        []string{"v1/secrets"},
+			},
+			want: []schema.GroupVersionResource{
+				{Version: "v1", Resource: "configmaps"},
+			},
+		},
+		{
+			name: "invalid include",
+			config: ResourceWatchConfig{
+				IncludeResources: []string{"configmaps"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.config.resourcesToWatch(fakeDiscovery())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resourcesToWatch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resourcesToWatch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResourcesToWatchDefaults(t *testing.T) {
+	podsGVR := schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+	config := ResourceWatchConfig{ExcludeResources: []string{"v1/pods"}}
+
+	got, err := config.resourcesToWatch(fakeDiscovery())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(DefaultResourcesToWatch())-1 {
+		t.Errorf("expected every default except pods, got %d of %d", len(got), len(DefaultResourcesToWatch()))
+	}
+	for _, gvr := range got {
+		if gvr == podsGVR {
+			t.Errorf("expected pods to be excluded")
+		}
+	}
+}
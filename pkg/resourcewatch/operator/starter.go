@@ -2,6 +2,7 @@ package operator
 
 import (
 	"context"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
@@ -9,7 +10,10 @@ import (
 	"github.com/openshift/origin/pkg/clioptions/clusterinfo"
 	"github.com/openshift/origin/pkg/resourcewatch/controller/configmonitor"
 	"github.com/openshift/origin/pkg/resourcewatch/storage"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/klog/v2"
@@ -17,7 +21,11 @@ import (
 
 // this doesn't appear to handle restarts cleanly.  To do so it would need to compare the resource version that it is applying
 // to the resource version present and it would need to handle unobserved deletions properly.  both are possible, neither is easy.
-func RunResourceWatch() error {
+func RunResourceWatch(config *ResourceWatchConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
 	ctx, cancelFn := context.WithCancel(context.Background())
 	defer cancelFn()
 	abortCh := make(chan os.Signal, 2)
@@ -48,20 +56,71 @@ func RunResourceWatch() error {
 		return err
 	}
 
-	repositoryPath := "/repository"
-	if repositoryPathEnv := os.Getenv("REPOSITORY_PATH"); len(repositoryPathEnv) > 0 {
-		repositoryPath = repositoryPathEnv
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(kubeConfig)
+	if err != nil {
+		klog.Errorf("Failed to create discovery client with error %v", err)
+		return err
+	}
+
+	resourcesToWatch, err := config.resourcesToWatch(discoveryClient)
+	if err != nil {
+		klog.Errorf("Failed to determine resources to watch with error %v", err)
+		return err
+	}
+	resourceFilter, err := config.resourceFilter()
+	if err != nil {
+		return err
 	}
 
-	gitStorage, err := storage.NewGitStorage(repositoryPath)
+	gitStorage, err := storage.NewGitStorage(config.RepositoryPath, config.batchOptions())
 	if err != nil {
 		klog.Errorf("Failed to create git storage with error %v", err)
 		return err
 	}
 
-	dynamicInformer := dynamicinformer.NewDynamicSharedInformerFactory(dynamicClient, 0)
+	dynamicInformer := dynamicinformer.NewFilteredDynamicSharedInformerFactory(dynamicClient, 0, metav1.NamespaceAll, func(options *metav1.ListOptions) {
+		options.LabelSelector = config.LabelSelector
+	})
+
+	configmonitor.WireResourceInformersToGitRepo(
+		dynamicInformer,
+		gitStorage,
+		resourcesToWatch,
+		resourceFilter,
+	)
+
+	if len(config.MetricsBindAddress) > 0 {
+		go serveMetrics(ctx, config.MetricsBindAddress)
+	}
+
+	dynamicInformer.Start(ctx.Done())
+
+	klog.Infof("Started all informers")
 
-	resourcesToWatch := []schema.GroupVersionResource{
+	// commits whatever is still queued once the context is done.
+	gitStorage.Run(ctx)
+
+	return nil
+}
+
+func serveMetrics(ctx context.Context, bindAddress string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	server := &http.Server{Addr: bindAddress, Handler: mux}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+
+	klog.Infof("Serving metrics on %s", bindAddress)
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		klog.Errorf("Failed to serve metrics with error %v", err)
+	}
+}
+
+// DefaultResourcesToWatch is the set of resources watched unless DisableDefaultResources is set.
+func DefaultResourcesToWatch() []schema.GroupVersionResource {
+	return []schema.GroupVersionResource{
 		// provide high level details of configuration that feeds operator behavior
 		configResource("apiservers"),
 		configResource("authentications"),
@@ -142,20 +201,6 @@ func RunResourceWatch() error {
 		coreResource("services"),
 		coreResource("serviceaccounts"),
 	}
-
-	configmonitor.WireResourceInformersToGitRepo(
-		dynamicInformer,
-		gitStorage,
-		resourcesToWatch,
-	)
-
-	dynamicInformer.Start(ctx.Done())
-
-	klog.Infof("Started all informers")
-
-	<-ctx.Done()
-
-	return nil
 }
 
 func configResource(resource string) schema.GroupVersionResource {
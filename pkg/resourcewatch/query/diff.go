@@ -0,0 +1,72 @@
+package query
+
+import (
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/openshift/origin/pkg/resourcewatch/storage"
+)
+
+// ignoredFieldPrefixes change on every write and would otherwise show up in every diff.
+var ignoredFieldPrefixes = []string{
+	".metadata.managedFields",
+	".metadata.resourceVersion",
+}
+
+// ObjectDiff describes how an object changed between two versions.  A nil version means the object did not exist.
+type ObjectDiff struct {
+	Fields storage.FieldChanges
+	// Content is a human readable diff of the two versions.
+	Content string
+}
+
+// Diff compares two versions of an object.
+func Diff(oldObj, newObj *Object) (ObjectDiff, error) {
+	oldUnstructured, err := toUnstructured(oldObj)
+	if err != nil {
+		return ObjectDiff{}, err
+	}
+	newUnstructured, err := toUnstructured(newObj)
+	if err != nil {
+		return ObjectDiff{}, err
+	}
+
+	fields, err := storage.ModifiedFieldPaths(oldUnstructured, newUnstructured)
+	if err != nil {
+		return ObjectDiff{}, err
+	}
+	return ObjectDiff{
+		Fields: storage.FieldChanges{
+			Added:    withoutIgnoredFields(fields.Added),
+			Modified: withoutIgnoredFields(fields.Modified),
+			Removed:  withoutIgnoredFields(fields.Removed),
+		},
+		Content: cmp.Diff(oldUnstructured.Object, newUnstructured.Object),
+	}, nil
+}
+
+func toUnstructured(obj *Object) (*unstructured.Unstructured, error) {
+	if obj == nil {
+		return &unstructured.Unstructured{Object: map[string]interface{}{}}, nil
+	}
+	return obj.Unstructured()
+}
+
+func withoutIgnoredFields(paths []string) []string {
+	ret := []string{}
+	for _, path := range paths {
+		ignored := false
+		for _, prefix := range ignoredFieldPrefixes {
+			if strings.HasPrefix(path, prefix) {
+				ignored = true
+				break
+			}
+		}
+		if !ignored {
+			ret = append(ret, path)
+		}
+	}
+	return ret
+}
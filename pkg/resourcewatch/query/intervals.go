@@ -0,0 +1,33 @@
+package query
+
+import (
+	"fmt"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+// ChangesToIntervals converts changes to instant intervals so they can be overlaid on an e2e timeline.
+func ChangesToIntervals(changes []Change) monitorapi.Intervals {
+	ret := monitorapi.Intervals{}
+	for _, change := range changes {
+		reason := monitorapi.ResourceModifiedReason
+		switch change.Operation {
+		case "added":
+			reason = monitorapi.ResourceAddedReason
+		case "removed":
+			reason = monitorapi.ResourceRemovedReason
+		}
+
+		ret = append(ret,
+			monitorapi.NewInterval(monitorapi.SourceResourceWatch, monitorapi.Info).
+				Locator(monitorapi.NewLocator().Resource(change.Object.Group, change.Object.Resource, change.Object.Namespace, change.Object.Name)).
+				Message(monitorapi.NewMessage().
+					Reason(reason).
+					WithAnnotation(monitorapi.AnnotationAuthor, change.Author).
+					WithAnnotation(monitorapi.AnnotationCommit, change.Commit.String()).
+					HumanMessage(fmt.Sprintf("%s %s by %s", change.Operation, change.Object, change.Author))).
+				Build(change.Observed, change.Observed),
+		)
+	}
+	return ret
+}
@@ -0,0 +1,332 @@
+package query
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/utils/merkletrie"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/origin/pkg/resourcewatch/storage"
+)
+
+// ObjectReference identifies an object by the path resourcewatch stores it under.
+type ObjectReference struct {
+	Group     string
+	Resource  string
+	Namespace string
+	Name      string
+}
+
+// ObjectReferenceFromPath parses cluster-scoped-resources/<group>/<resource>/<name>.yaml and
+// namespaces/<namespace>/<group>/<resource>/<name>.yaml.  The core group is stored as "core".
+func ObjectReferenceFromPath(path string) (ObjectReference, error) {
+	parts := strings.Split(strings.TrimSuffix(path, ".yaml"), "/")
+	var ret ObjectReference
+	switch {
+	case len(parts) == 4 && parts[0] == "cluster-scoped-resources":
+		ret = ObjectReference{Group: parts[1], Resource: parts[2], Name: parts[3]}
+	case len(parts) == 5 && parts[0] == "namespaces":
+		ret = ObjectReference{Namespace: parts[1], Group: parts[2], Resource: parts[3], Name: parts[4]}
+	default:
+		return ObjectReference{}, fmt.Errorf("%q is not a resourcewatch object path", path)
+	}
+	if ret.Group == "core" {
+		ret.Group = ""
+	}
+	return ret, nil
+}
+
+func (r ObjectReference) String() string {
+	resource := r.Resource
+	if len(r.Group) > 0 {
+		resource = r.Resource + "." + r.Group
+	}
+	if len(r.Namespace) == 0 {
+		return fmt.Sprintf("%s/%s", resource, r.Name)
+	}
+	return fmt.Sprintf("%s/%s -n %s", resource, r.Name, r.Namespace)
+}
+
+// Selector chooses objects.  Empty fields match everything.
+type Selector struct {
+	// Resource is resource or resource.group, for instance clusteroperators or clusteroperators.config.openshift.io.
+	// Without a group every group matches.
+	Resource  string
+	Namespace string
+	Name      string
+}
+
+func (s Selector) Matches(ref ObjectReference) bool {
+	if len(s.Namespace) > 0 && s.Namespace != ref.Namespace {
+		return false
+	}
+	if len(s.Name) > 0 && s.Name != ref.Name {
+		return false
+	}
+	if len(s.Resource) > 0 {
+		resource, group, hasGroup := strings.Cut(s.Resource, ".")
+		if resource != ref.Resource {
+			return false
+		}
+		if hasGroup && group != ref.Group {
+			return false
+		}
+	}
+	return true
+}
+
+// Change is a single observed change and the commit that recorded it.
+type Change struct {
+	storage.ResourceChange
+	Object ObjectReference
+	Commit plumbing.Hash
+}
+
+// Object is the content of an object as of a change.
+type Object struct {
+	Change  Change
+	Content []byte
+}
+
+func (o Object) Unstructured() (*unstructured.Unstructured, error) {
+	ret := &unstructured.Unstructured{}
+	if err := yaml.Unmarshal(o.Content, &ret.Object); err != nil {
+		return nil, fmt.Errorf("failed to decode %v: %w", o.Change.Object, err)
+	}
+	return ret, nil
+}
+
+// Repository is the history of a resourcewatch repository, ordered by the time each change was observed.
+type Repository struct {
+	repo    *git.Repository
+	changes []Change
+}
+
+// OpenRepository reads the full history of the repository at path.
+func OpenRepository(path string) (*Repository, error) {
+	repo, err := git.PlainOpen(path)
+	if err != nil {
+		return nil, err
+	}
+	ret := &Repository{repo: repo}
+
+	if _, err := repo.Head(); err == plumbing.ErrReferenceNotFound {
+		// nothing has been committed yet.
+		return ret, nil
+	} else if err != nil {
+		return nil, err
+	}
+	commitIter, err := repo.Log(&git.LogOptions{})
+	if err != nil {
+		return nil, err
+	}
+	commits := []*object.Commit{}
+	if err := commitIter.ForEach(func(commit *object.Commit) error {
+		commits = append(commits, commit)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	// oldest first, so that changes observed at the same instant keep the order they were committed in.
+	for i := len(commits) - 1; i >= 0; i-- {
+		changes, err := commitChanges(commits[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to read changes from commit %v: %w", commits[i].Hash, err)
+		}
+		ret.changes = append(ret.changes, changes...)
+	}
+	sort.SliceStable(ret.changes, func(i, j int) bool {
+		return ret.changes[i].Observed.Before(ret.changes[j].Observed)
+	})
+	return ret, nil
+}
+
+// commitChanges reads the changes from the commit trailers.  Repositories written before trailers existed have a
+// single change per commit, which is recovered by comparing the commit with its parent.
+func commitChanges(commit *object.Commit) ([]Change, error) {
+	resourceChanges, err := storage.ParseResourceChangeTrailers(commit.Message)
+	if err != nil {
+		return nil, err
+	}
+	if len(resourceChanges) == 0 {
+		resourceChanges, err = treeChanges(commit)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ret := []Change{}
+	for _, resourceChange := range resourceChanges {
+		ref, err := ObjectReferenceFromPath(resourceChange.Path)
+		if err != nil {
+			// someone committed something else to the repository, it is not an object.
+			continue
+		}
+		ret = append(ret, Change{
+			ResourceChange: resourceChange,
+			Object:         ref,
+			Commit:         commit.Hash,
+		})
+	}
+	return ret, nil
+}
+
+func treeChanges(commit *object.Commit) ([]storage.ResourceChange, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	var parentTree *object.Tree
+	if commit.NumParents() > 0 {
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return nil, err
+		}
+		parentTree, err = parent.Tree()
+		if err != nil {
+			return nil, err
+		}
+	}
+	changes, err := object.DiffTree(parentTree, tree)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := []storage.ResourceChange{}
+	for _, change := range changes {
+		action, err := change.Action()
+		if err != nil {
+			return nil, err
+		}
+		resourceChange := storage.ResourceChange{
+			Observed: commit.Author.When,
+			Author:   commit.Author.Name,
+			Path:     change.To.Name,
+		}
+		switch action {
+		case merkletrie.Insert:
+			resourceChange.Operation = "added"
+		case merkletrie.Modify:
+			resourceChange.Operation = "modified"
+		case merkletrie.Delete:
+			resourceChange.Operation = "removed"
+			resourceChange.Path = change.From.Name
+		}
+		ret = append(ret, resourceChange)
+	}
+	return ret, nil
+}
+
+// Changes returns the changes to matching objects observed in [from, to].  Zero times are unbounded.
+func (r *Repository) Changes(selector Selector, from, to time.Time) []Change {
+	ret := []Change{}
+	for _, change := range r.changes {
+		if !from.IsZero() && change.Observed.Before(from) {
+			continue
+		}
+		if !to.IsZero() && change.Observed.After(to) {
+			continue
+		}
+		if !selector.Matches(change.Object) {
+			continue
+		}
+		ret = append(ret, change)
+	}
+	return ret
+}
+
+// StateAt reconstructs every matching object that existed at the instant, sorted by path.
+func (r *Repository) StateAt(selector Selector, at time.Time) ([]Object, error) {
+	latest := map[string]Change{}
+	for _, change := range r.Changes(selector, time.Time{}, at) {
+		latest[change.Path] = change
+	}
+
+	ret := []Object{}
+	for _, change := range latest {
+		if change.Operation == "removed" {
+			continue
+		}
+		content, err := r.Content(change)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, Object{Change: change, Content: content})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Change.Path < ret[j].Change.Path
+	})
+	return ret, nil
+}
+
+// ObjectAt returns the object as it was at the instant, or nil if it did not exist.
+func (r *Repository) ObjectAt(ref ObjectReference, at time.Time) (*Object, error) {
+	objects, err := r.StateAt(Selector{Resource: resourceWithGroup(ref), Namespace: ref.Namespace, Name: ref.Name}, at)
+	if err != nil {
+		return nil, err
+	}
+	for i := range objects {
+		// the selector cannot tell a cluster scoped object from a namespaced one with the same name.
+		if objects[i].Change.Object == ref {
+			return &objects[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// Content returns the object as written by the change.  Every change is the only change to its path in its commit,
+// so the content is whatever the commit holds.
+func (r *Repository) Content(change Change) ([]byte, error) {
+	if change.Operation == "removed" {
+		return nil, fmt.Errorf("%v was removed in %v", change.Object, change.Commit)
+	}
+	commit, err := r.repo.CommitObject(change.Commit)
+	if err != nil {
+		return nil, err
+	}
+	file, err := commit.File(change.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q from %v: %w", change.Path, change.Commit, err)
+	}
+	content, err := file.Contents()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(content), nil
+}
+
+// Previous returns the content of the object before the change, or nil if the change added it.
+func (r *Repository) Previous(change Change) (*Object, error) {
+	var previous *Change
+	for i := range r.changes {
+		if r.changes[i].Commit == change.Commit && r.changes[i].Path == change.Path {
+			break
+		}
+		if r.changes[i].Path == change.Path {
+			previous = &r.changes[i]
+		}
+	}
+	if previous == nil || previous.Operation == "removed" {
+		return nil, nil
+	}
+	content, err := r.Content(*previous)
+	if err != nil {
+		return nil, err
+	}
+	return &Object{Change: *previous, Content: content}, nil
+}
+
+func resourceWithGroup(ref ObjectReference) string {
+	if len(ref.Group) == 0 {
+		return ref.Resource
+	}
+	return ref.Resource + "." + ref.Group
+}
@@ -0,0 +1,304 @@
+package query
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/resourcewatch/storage"
+)
+
+var (
+	t1 = time.Date(2024, 5, 1, 14, 0, 0, 0, time.UTC)
+	t2 = t1.Add(10 * time.Minute)
+	t3 = t1.Add(20 * time.Minute)
+	t4 = t1.Add(30 * time.Minute)
+)
+
+const (
+	configMapA      = "namespaces/ns/core/configmaps/a.yaml"
+	configMapB      = "namespaces/ns/core/configmaps/b.yaml"
+	clusterOperator = "cluster-scoped-resources/config.openshift.io/clusteroperators/x.yaml"
+)
+
+func configMapYAML(name, value, resourceVersion string) string {
+	return "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: " + name + "\n  namespace: ns\n  resourceVersion: \"" + resourceVersion + "\"\ndata:\n  key: " + value + "\n"
+}
+
+type testRepository struct {
+	t        *testing.T
+	path     string
+	worktree *git.Worktree
+}
+
+// commit writes and removes files and commits them with the given trailers.  No trailers creates a commit the way
+// resourcewatch did before trailers existed.
+func (r *testRepository) commit(when time.Time, files map[string]string, removed []string, trailers ...storage.ResourceChange) {
+	r.t.Helper()
+	for path, content := range files {
+		fullPath := filepath.Join(r.path, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			r.t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			r.t.Fatal(err)
+		}
+		if _, err := r.worktree.Add(path); err != nil {
+			r.t.Fatal(err)
+		}
+	}
+	for _, path := range removed {
+		if _, err := r.worktree.Remove(path); err != nil {
+			r.t.Fatal(err)
+		}
+	}
+
+	message := "test commit\n\n"
+	for _, trailer := range trailers {
+		message += trailer.String() + "\n"
+	}
+	if _, err := r.worktree.Commit(message, &git.CommitOptions{
+		Author: &object.Signature{Name: "creator", Email: "ci-monitor@openshift.io", When: when},
+	}); err != nil {
+		r.t.Fatal(err)
+	}
+}
+
+func newTestRepository(t *testing.T) *Repository {
+	path := t.TempDir()
+	repo, err := git.PlainInit(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &testRepository{t: t, path: path, worktree: worktree}
+
+	r.commit(t1, map[string]string{configMapA: configMapYAML("a", "one", "1"), clusterOperator: "kind: ClusterOperator\n"}, nil)
+	// committed after t3 to show that changes are placed at the time they were observed.
+	r.commit(t4, map[string]string{configMapA: configMapYAML("a", "two", "2"), configMapB: configMapYAML("b", "one", "3")}, nil,
+		storage.ResourceChange{Operation: "modified", Observed: t2, Path: configMapA, Author: "editor"},
+		storage.ResourceChange{Operation: "added", Observed: t2.Add(time.Second), Path: configMapB, Author: "creator"},
+	)
+	r.commit(t4, nil, []string{configMapB},
+		storage.ResourceChange{Operation: "removed", Observed: t3, Path: configMapB, Author: "unknown"},
+	)
+
+	ret, err := OpenRepository(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ret
+}
+
+func paths(objects []Object) []string {
+	ret := []string{}
+	for _, obj := range objects {
+		ret = append(ret, obj.Change.Path)
+	}
+	return ret
+}
+
+func TestStateAt(t *testing.T) {
+	repo := newTestRepository(t)
+
+	tests := []struct {
+		name     string
+		at       time.Time
+		selector Selector
+		want     []string
+	}{
+		{name: "before anything", at: t1.Add(-time.Second), want: []string{}},
+		{name: "from the commit without trailers", at: t1, want: []string{clusterOperator, configMapA}},
+		{name: "batched changes", at: t2.Add(time.Minute), want: []string{clusterOperator, configMapA, configMapB}},
+		{name: "after removal", at: t3, want: []string{clusterOperator, configMapA}},
+		{name: "by resource and group", at: t3, selector: Selector{Resource: "clusteroperators.config.openshift.io"}, want: []string{clusterOperator}},
+		{name: "by other group", at: t3, selector: Selector{Resource: "clusteroperators.operator.openshift.io"}, want: []string{}},
+		{name: "by namespace and name", at: t2.Add(time.Minute), selector: Selector{Namespace: "ns", Name: "b"}, want: []string{configMapB}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects, err := repo.StateAt(tt.selector, tt.at)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := paths(objects); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	objects, err := repo.StateAt(Selector{Name: "a"}, t2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 1 || !strings.Contains(string(objects[0].Content), "key: two") {
+		t.Errorf("expected the modified content, got %v", objects)
+	}
+}
+
+func TestDiffAndHistory(t *testing.T) {
+	repo := newTestRepository(t)
+	ref := ObjectReference{Resource: "configmaps", Namespace: "ns", Name: "a"}
+
+	oldObj, err := repo.ObjectAt(ref, t1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newObj, err := repo.ObjectAt(ref, t4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff, err := Diff(oldObj, newObj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{".data.key"}; !reflect.DeepEqual(diff.Fields.Modified, want) {
+		t.Errorf("expected %v modified without resourceVersion, got %v", want, diff.Fields.Modified)
+	}
+	if !strings.Contains(diff.Content, "two") {
+		t.Errorf("expected the content diff to show the new value, got %q", diff.Content)
+	}
+
+	changes := repo.Changes(Selector{Resource: "configmaps"}, time.Time{}, time.Time{})
+	got := []string{}
+	for _, change := range changes {
+		got = append(got, change.Operation+" "+change.Object.String()+" "+change.Author)
+	}
+	want := []string{
+		"added configmaps/a -n ns creator",
+		"modified configmaps/a -n ns editor",
+		"added configmaps/b -n ns creator",
+		"removed configmaps/b -n ns unknown",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	previous, err := repo.Previous(changes[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if previous == nil || !strings.Contains(string(previous.Content), "key: one") {
+		t.Errorf("expected the previous version, got %v", previous)
+	}
+
+	intervals := ChangesToIntervals(changes[3:])
+	if len(intervals) != 1 {
+		t.Fatalf("expected one interval, got %d", len(intervals))
+	}
+	interval := intervals[0]
+	if interval.Source != monitorapi.SourceResourceWatch || interval.Message.Reason != monitorapi.ResourceRemovedReason || !interval.From.Equal(t3) {
+		t.Errorf("unexpected interval %#v", interval)
+	}
+	if interval.Locator.Keys[monitorapi.LocatorResourceKey] != "configmaps" || interval.Locator.Keys[monitorapi.LocatorNameKey] != "b" {
+		t.Errorf("unexpected locator %v", interval.Locator)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	repo := newTestRepository(t)
+	ref := ObjectReference{Resource: "configmaps", Namespace: "ns", Name: "b"}
+
+	tests := []struct {
+		name         string
+		from, to     time.Time
+		wantAdded    []string
+		wantModified []string
+		wantRemoved  []string
+		wantContent  []string
+	}{
+		{
+			name:        "absent before it was added",
+			from:        t1,
+			to:          t2.Add(time.Minute),
+			wantAdded:   []string{".apiVersion", ".data", ".data.key", ".kind", ".metadata", ".metadata.name", ".metadata.namespace"},
+			wantContent: []string{"+", "ConfigMap"},
+		},
+		{
+			name:        "absent after it was removed",
+			from:        t2.Add(time.Minute),
+			to:          t3,
+			wantRemoved: []string{".apiVersion", ".data", ".data.key", ".kind", ".metadata", ".metadata.name", ".metadata.namespace"},
+			wantContent: []string{"-", "ConfigMap"},
+		},
+		{
+			name: "absent at both",
+			from: t1,
+			to:   t4,
+		},
+		{
+			name: "same version",
+			from: t2.Add(time.Minute),
+			to:   t2.Add(2 * time.Minute),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldObj, err := repo.ObjectAt(ref, tt.from)
+			if err != nil {
+				t.Fatal(err)
+			}
+			newObj, err := repo.ObjectAt(ref, tt.to)
+			if err != nil {
+				t.Fatal(err)
+			}
+			diff, err := Diff(oldObj, newObj)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, check := range []struct {
+				name      string
+				got, want []string
+			}{
+				{"added", diff.Fields.Added, tt.wantAdded},
+				{"modified", diff.Fields.Modified, tt.wantModified},
+				{"removed", diff.Fields.Removed, tt.wantRemoved},
+			} {
+				if want := append([]string{}, check.want...); !reflect.DeepEqual(check.got, want) {
+					t.Errorf("%s: got %v, want %v", check.name, check.got, want)
+				}
+			}
+			if len(tt.wantContent) == 0 && len(diff.Content) > 0 {
+				t.Errorf("expected no content diff, got %q", diff.Content)
+			}
+			for _, want := range tt.wantContent {
+				if !strings.Contains(diff.Content, want) {
+					t.Errorf("expected the content diff to contain %q, got %q", want, diff.Content)
+				}
+			}
+		})
+	}
+}
+
+func TestObjectReferenceFromPath(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    ObjectReference
+		wantErr bool
+	}{
+		{path: configMapA, want: ObjectReference{Resource: "configmaps", Namespace: "ns", Name: "a"}},
+		{path: clusterOperator, want: ObjectReference{Group: "config.openshift.io", Resource: "clusteroperators", Name: "x"}},
+		{path: "README.md", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := ObjectReferenceFromPath(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package storage
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -10,6 +11,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/managedfields"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
 	"sigs.k8s.io/structured-merge-diff/v4/typed"
 )
 
@@ -33,6 +35,35 @@ func modifiedFields(oldRuntimeObject, newRuntimeObject *unstructured.Unstructure
 	return compare, nil
 }
 
+// FieldChanges lists the paths of the fields that differ between two versions of an object.
+type FieldChanges struct {
+	Added    []string
+	Modified []string
+	Removed  []string
+}
+
+// ModifiedFieldPaths compares two versions of an object field by field.
+func ModifiedFieldPaths(oldObj, newObj *unstructured.Unstructured) (FieldChanges, error) {
+	comparison, err := modifiedFields(oldObj, newObj)
+	if err != nil {
+		return FieldChanges{}, err
+	}
+	return FieldChanges{
+		Added:    fieldPaths(comparison.Added),
+		Modified: fieldPaths(comparison.Modified),
+		Removed:  fieldPaths(comparison.Removed),
+	}, nil
+}
+
+func fieldPaths(set *fieldpath.Set) []string {
+	ret := []string{}
+	set.Iterate(func(path fieldpath.Path) {
+		ret = append(ret, path.String())
+	})
+	sort.Strings(ret)
+	return ret
+}
+
 func whichUsersOwnModifiedFields(obj *unstructured.Unstructured, comparison typed.Comparison) ([]string, error) {
 	users := sets.NewString()
 
@@ -1,69 +1,70 @@
 package storage
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
-	"sync"
 	"time"
 
-	"k8s.io/apimachinery/pkg/util/wait"
-
-	"k8s.io/kube-openapi/pkg/util/sets"
-
 	"gopkg.in/src-d/go-git.v4"
-
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/filemode"
+	"gopkg.in/src-d/go-git.v4/plumbing/format/index"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
+	"k8s.io/kube-openapi/pkg/util/sets"
 	"sigs.k8s.io/yaml"
 )
 
-type workingSet struct {
-	currentlyWorking sets.String
-	lock             sync.RWMutex
-}
-
-func (s *workingSet) isWorkingOn(key string) bool {
-	s.lock.RLock()
-	defer s.lock.RUnlock()
-	return s.currentlyWorking.Has(key)
-}
-
-func (s *workingSet) reserve(key string) {
-	s.lock.Lock()
-	defer s.lock.Unlock()
-	s.currentlyWorking.Insert(key)
+// BatchOptions controls how observed changes are grouped into commits.
+type BatchOptions struct {
+	// MaxBatchSize is the number of changes after which a commit is made.  1 commits every change on its own.
+	MaxBatchSize int
+	// MaxBatchDelay is the longest a staged change waits before it is committed.
+	MaxBatchDelay time.Duration
+	// QueueSize bounds the number of observed changes waiting to be staged.  Once full, informer handlers block
+	// until there is room, which is reported by the queue_blocked_seconds_total metric.
+	QueueSize int
 }
 
-func (s *workingSet) release(key string) {
-	s.lock.Lock()
-	defer s.lock.Unlock()
-	s.currentlyWorking.Delete(key)
+func DefaultBatchOptions() BatchOptions {
+	return BatchOptions{
+		MaxBatchSize:  100,
+		MaxBatchDelay: 1 * time.Second,
+		QueueSize:     5000,
+	}
 }
 
-func (s *workingSet) waitUntilAvailable(key string) error {
-	return wait.PollImmediate(1*time.Second, 30*time.Second, func() (bool, error) {
-		if s.isWorkingOn(key) {
-			return false, nil
-		}
-		return true, nil
-	})
-}
+// maxCommitAttempts is how many times a batch is committed before its changes are dropped.  Without a limit a
+// repository that cannot be written to would keep every later change pending in memory.
+const maxCommitAttempts = 5
 
 type GitStorage struct {
-	repo *git.Repository
-	path string
-
-	currentlyRecording workingSet
-
-	// Writing to Git repository must be synced otherwise Git will freak out
-	sync.Mutex
+	repo     *git.Repository
+	worktree *git.Worktree
+	path     string
+	options  BatchOptions
+
+	// queue is drained by Run, the only goroutine that touches the repository.
+	queue chan *observedChange
+	// stopped is closed when Run returns so that late events do not block forever.
+	stopped chan struct{}
+
+	// pending holds the changes staged in index but not yet committed.  index is loaded once per batch and
+	// only written back when committing, because go-git reads and writes the whole index on every call.
+	pending      []*observedChange
+	pendingPaths sets.String
+	index        *index.Index
+	// commitFailures counts the failed attempts to commit pending.
+	commitFailures int
 }
 
 type gitOperation int
@@ -72,13 +73,53 @@ const (
 	gitOpAdded gitOperation = iota
 	gitOpModified
 	gitOpDeleted
+	gitOpUnchanged
 	gitOpError
 )
 
+func (o gitOperation) String() string {
+	switch o {
+	case gitOpAdded:
+		return "added"
+	case gitOpModified:
+		return "modified"
+	case gitOpDeleted:
+		return "removed"
+	case gitOpUnchanged:
+		return "unchanged"
+	default:
+		return "error"
+	}
+}
+
+// observedChange is everything needed to record one event.  It is built on the informer goroutine so that
+// the expensive decoding and author guessing happen in parallel across resources.
+type observedChange struct {
+	path      string
+	content   []byte
+	delete    bool
+	author    string
+	ocCommand string
+	observed  time.Time
+
+	// operation is decided when the change is staged, because only then is the state of the file known.
+	operation gitOperation
+}
+
 // NewGitStorage returns the resource event handler capable of storing changes observed on resource
-// into a Git repository. Each change is stored as separate commit which means a full history of the
-// resource lifecycle is preserved.
-func NewGitStorage(path string) (*GitStorage, error) {
+// into a Git repository.  Changes are committed in batches by Run, and every change is listed in the
+// commit trailers, so a full history of the resource lifecycle is preserved.
+func NewGitStorage(path string, options BatchOptions) (*GitStorage, error) {
+	if options.MaxBatchSize < 1 {
+		return nil, fmt.Errorf("max batch size must be at least 1, got %d", options.MaxBatchSize)
+	}
+	if options.MaxBatchDelay <= 0 {
+		return nil, fmt.Errorf("max batch delay must be positive, got %v", options.MaxBatchDelay)
+	}
+	if options.QueueSize < 1 {
+		return nil, fmt.Errorf("queue size must be at least 1, got %d", options.QueueSize)
+	}
+
 	// If the repo does not exists, do git init
 	if _, err := os.Stat(filepath.Join(path, ".git")); os.IsNotExist(err) {
 		_, err := git.PlainInit(path, false)
@@ -90,39 +131,119 @@ func NewGitStorage(path string) (*GitStorage, error) {
 	if err != nil {
 		return nil, err
 	}
-	storage := &GitStorage{path: path, repo: repo}
-	storage.currentlyRecording.currentlyWorking = sets.String{}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+	// a previous run killed mid-commit may leave this behind.  This process should be the only one working in
+	// this git repo so simply remove the lock file.
+	if err := os.Remove(filepath.Join(path, ".git", "index.lock")); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
 
-	return storage, nil
+	return &GitStorage{
+		repo:         repo,
+		worktree:     worktree,
+		path:         path,
+		options:      options,
+		queue:        make(chan *observedChange, options.QueueSize),
+		stopped:      make(chan struct{}),
+		pendingPaths: sets.String{},
+	}, nil
 }
 
-// handle handles different operations on git
-func (s *GitStorage) handle(gvr schema.GroupVersionResource, oldObj, obj *unstructured.Unstructured, delete bool) {
-	// notifications for resources come in a single threaded stream per-resource.
-	// this means there will never be contention on a single file.
-	// we will lock just before the commit itself.
-
-	// Allowing files to be written while a commit is in progress leads to commit failures due to unstaged changes
-	// moving the lock to the top of the handler to make the action atomic.
-	// E0706 02:06:26.489945    2444 git_store.go:338] Ran git add "namespaces/openshift-cloud-credential-operator/core/services/cco-metrics.yaml" && git commit --author="modified-unknown <ci-monitor@openshift.io>" -m "modifed services/cco-metrics -n openshift-cloud-credential-operator"
-	// On branch master
-	// Changes not staged for commit:
-	//  (use "git add <file>..." to update what will be committed)
-	//  (use "git restore <file>..." to discard changes in working directory)
-	//	modified:   namespaces/openshift-apiserver/core/pods/apiserver-856c47d994-47cf7.yaml
-	//
-	// Untracked files:
-	//  (use "git add <file>..." to include in what will be committed)
-	//	namespaces/openshift-etcd/core/pods/etcd-ci-op-g2xjpfr4-ed5cd-gqjcq-master-0.yaml
-
-	s.Lock()
-	defer s.Unlock()
+// Run stages and commits observed changes until ctx is done.  Changes already queued when ctx is done are
+// committed before Run returns.
+func (s *GitStorage) Run(ctx context.Context) {
+	defer close(s.stopped)
+
+	ticker := time.NewTicker(s.options.MaxBatchDelay)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case change := <-s.queue:
+			s.stage(change)
+		case <-ticker.C:
+			s.commit()
+		case <-ctx.Done():
+			s.drain()
+			klog.Infof("Stopped git storage")
+			return
+		}
+	}
+}
 
-	filePath, content, err := decodeUnstructuredObject(gvr, obj)
+// drain commits whatever is already queued without waiting for more.
+func (s *GitStorage) drain() {
+	for {
+		select {
+		case change := <-s.queue:
+			s.stage(change)
+		default:
+			s.commit()
+			return
+		}
+	}
+}
+
+func (s *GitStorage) OnAdd(gvr schema.GroupVersionResource, obj interface{}) {
+	s.enqueue(gvr, nil, obj.(*unstructured.Unstructured), false)
+}
+
+func (s *GitStorage) OnUpdate(gvr schema.GroupVersionResource, oldObj, obj interface{}) {
+	s.enqueue(gvr, oldObj.(*unstructured.Unstructured), obj.(*unstructured.Unstructured), false)
+}
+
+func (s *GitStorage) OnDelete(gvr schema.GroupVersionResource, obj interface{}) {
+	objUnstructured, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			utilruntime.HandleError(fmt.Errorf("couldn't get object from tombstone %#v", obj))
+			return
+		}
+		objUnstructured, ok = tombstone.Obj.(*unstructured.Unstructured)
+		if !ok {
+			utilruntime.HandleError(fmt.Errorf("tombstone contained object that is not a Namespace %#v", obj))
+			return
+		}
+	}
+	s.enqueue(gvr, nil, objUnstructured, true)
+}
+
+// enqueue hands the change to Run.  Notifications for a resource come in a single stream per informer, so
+// the queue keeps changes to a single object in order.
+func (s *GitStorage) enqueue(gvr schema.GroupVersionResource, oldObj, obj *unstructured.Unstructured, delete bool) {
+	change, err := newObservedChange(gvr, oldObj, obj, delete)
 	if err != nil {
-		klog.Warningf("Decoding %q failed: %v", filePath, err)
+		klog.Warningf("Decoding %q failed: %v", change.path, err)
+		stagedChanges.WithLabelValues("failed").Inc()
 		return
 	}
+
+	select {
+	case s.queue <- change:
+	default:
+		// the queue is full, block the informer until there is room instead of dropping history.
+		start := time.Now()
+		select {
+		case s.queue <- change:
+		case <-s.stopped:
+			klog.Warningf("Dropping change to %q, storage is stopped", change.path)
+			droppedChanges.Inc()
+		}
+		queueBlockedSeconds.Add(time.Since(start).Seconds())
+	}
+	queueDepth.Set(float64(len(s.queue)))
+}
+
+func newObservedChange(gvr schema.GroupVersionResource, oldObj, obj *unstructured.Unstructured, delete bool) (*observedChange, error) {
+	observed := time.Now()
+	filePath, content, err := decodeUnstructuredObject(gvr, obj)
+	if err != nil {
+		return &observedChange{path: filePath}, err
+	}
 	resourceName := ""
 	if len(gvr.Group) == 0 {
 		resourceName = gvr.Resource
@@ -136,130 +257,169 @@ func (s *GitStorage) handle(gvr schema.GroupVersionResource, oldObj, obj *unstru
 		ocCommand = fmt.Sprintf("%s/%s -n %s", resourceName, obj.GetName(), obj.GetNamespace())
 	}
 
-	if delete {
-		klog.Infof("Calling commitRemove for %s", filePath)
-		// ignore error, we've already reported and we're not doing anything else.
-		pollErr := wait.PollImmediate(1*time.Second, 15*time.Second, func() (bool, error) {
-			if err := s.commitRemove(filePath, "unknown", ocCommand); err != nil {
-				klog.Error(err)
-				return false, nil
-			}
-			return true, nil
-		})
+	modifyingUser := "unknown"
+	if !delete {
+		modifyingUser, err = guessAtModifyingUsers(oldObj, obj)
+		if err != nil {
+			klog.Warningf("Guessing users failed %q: %v", filePath, err)
+			modifyingUser = err.Error()
+		}
+	}
 
-		if pollErr != nil {
-			klog.Errorf("PollWait Error: %v", pollErr)
+	return &observedChange{
+		path:      filepath.ToSlash(filePath),
+		content:   content,
+		delete:    delete,
+		author:    modifyingUser,
+		ocCommand: ocCommand,
+		observed:  observed,
+	}, nil
+}
+
+// stage writes the change to the worktree and the in-memory index.  A change to a path that is already part
+// of the batch commits the batch first, so every observed state of an object is kept in history.
+func (s *GitStorage) stage(change *observedChange) {
+	queueDepth.Set(float64(len(s.queue)))
+
+	if s.pendingPaths.Has(change.path) {
+		s.commit()
+	}
+	if s.index == nil {
+		idx, err := s.repo.Storer.Index()
+		if err != nil {
+			stagedChanges.WithLabelValues("failed").Inc()
+			change.operation = gitOpError
+			dropChange(change, fmt.Errorf("reading the git index failed: %w", err))
+			return
 		}
+		s.index = idx
+	}
 
+	var err error
+	if change.delete {
+		change.operation, err = s.remove(change.path)
+	} else {
+		change.operation, err = s.write(change.path, change.content)
+	}
+	switch {
+	case err != nil:
+		klog.Warningf("Staging %q failed: %v", change.path, err)
+		stagedChanges.WithLabelValues("failed").Inc()
+		return
+	case change.operation == gitOpUnchanged:
+		// nothing changed, there is nothing to commit.
+		stagedChanges.WithLabelValues(change.operation.String()).Inc()
 		return
 	}
+	stagedChanges.WithLabelValues(change.operation.String()).Inc()
 
-	klog.Infof("Calling write for %s", filePath)
-	operation, err := s.write(filePath, content)
-	if err != nil {
-		klog.Warningf("Writing file content failed %q: %v", filePath, err)
+	s.pending = append(s.pending, change)
+	s.pendingPaths.Insert(change.path)
+	if len(s.pending) >= s.options.MaxBatchSize {
+		s.commit()
+	}
+}
+
+// commit records every pending change in a single commit.  On failure the changes stay pending and are
+// retried with the next batch, up to maxCommitAttempts times before they are dropped.
+func (s *GitStorage) commit() {
+	if len(s.pending) == 0 {
 		return
 	}
 
-	modifyingUser, err := guessAtModifyingUsers(oldObj, obj)
+	start := time.Now()
+	message, author := commitMessage(s.pending)
+	err := s.repo.Storer.SetIndex(s.index)
+	if err == nil {
+		_, err = s.worktree.Commit(message, &git.CommitOptions{
+			Author: &object.Signature{
+				Name:  author,
+				Email: "ci-monitor@openshift.io",
+				When:  s.pending[len(s.pending)-1].observed,
+			},
+		})
+	}
+	commitDurationSeconds.Observe(time.Since(start).Seconds())
 	if err != nil {
-		klog.Warningf("Guessing users failed %q: %v", filePath, err)
-		modifyingUser = err.Error()
-	}
-
-	// ignore error, we've already reported and we're not doing anything else.
-	pollErr := wait.PollImmediate(1*time.Second, 15*time.Second, func() (bool, error) {
-		switch {
-		case operation == gitOpAdded:
-			klog.Infof("Calling commitAdd for %s", filePath)
-			if err := s.commitAdd(filePath, modifyingUser, ocCommand); err != nil {
-				klog.Error(err)
-				return false, nil
+		klog.Errorf("Committing %d changes failed: %v", len(s.pending), err)
+		commits.WithLabelValues("failure").Inc()
+		s.commitFailures++
+		if s.commitFailures >= maxCommitAttempts {
+			for _, change := range s.pending {
+				dropChange(change, fmt.Errorf("%d commits failed, last error: %w", s.commitFailures, err))
 			}
-		case operation == gitOpModified:
-			klog.Infof("Calling commitModify for %s", filePath)
-			if err := s.commitModify(filePath, modifyingUser, ocCommand); err != nil {
-				klog.Error(err)
-				return false, nil
+			// the worktree and index hold the dropped changes.  Unless they go back to the last commit, a later
+			// change back to the same content would look unchanged and never be recorded either.
+			if err := s.restoreFromHEAD(s.pendingPaths.List()); err != nil {
+				klog.Errorf("Restoring dropped changes from HEAD failed, reloading the git index: %v", err)
+				s.index = nil
 			}
-		default:
-			klog.Errorf("unhandled case for %s", filePath)
-
-			return true, nil
+			s.resetPending()
 		}
-		return true, nil
-	})
-
-	if pollErr != nil {
-		klog.Errorf("PollWait Error: %v", pollErr)
+		return
 	}
-}
-
-func (s *GitStorage) OnAdd(gvr schema.GroupVersionResource, obj interface{}) {
-	objUnstructured := obj.(*unstructured.Unstructured)
 
-	// serialize updates to individual files
-	key := fmt.Sprintf("%s/%s/%s/%s/%s", gvr.Group, gvr.Version, gvr.Resource, objUnstructured.GetNamespace(), objUnstructured.GetName())
-	if err := s.currentlyRecording.waitUntilAvailable(key); err != nil {
-		klog.Error(err)
-		return
+	committed := time.Now()
+	commits.WithLabelValues("success").Inc()
+	commitBatchSize.Observe(float64(len(s.pending)))
+	for _, change := range s.pending {
+		changeLatencySeconds.Observe(committed.Sub(change.observed).Seconds())
+		klog.V(2).Infof("Committed: %v -- %v %v %v", change.path, change.author, change.operation, change.ocCommand)
 	}
-	s.currentlyRecording.reserve(key)
+	s.resetPending()
+}
 
-	// start new go func to allow parallel processing where possible and to avoid blocking all progress on retries.
-	go func() {
-		defer s.currentlyRecording.release(key)
-		s.handle(gvr, nil, objUnstructured, false)
-	}()
+func (s *GitStorage) resetPending() {
+	s.pending = nil
+	s.pendingPaths = sets.String{}
+	s.commitFailures = 0
 }
 
-func (s *GitStorage) OnUpdate(gvr schema.GroupVersionResource, oldObj, obj interface{}) {
-	objUnstructured := obj.(*unstructured.Unstructured)
-	oldObjUnstructured := oldObj.(*unstructured.Unstructured)
+// dropChange logs a change that will never be committed with enough detail to know what is missing from history.
+func dropChange(change *observedChange, err error) {
+	klog.Errorf("Dropping change observed at %v: %v %v by %v (%v): %v",
+		change.observed.Format(time.RFC3339), change.operation, change.ocCommand, change.author, change.path, err)
+	droppedChanges.Inc()
+}
 
-	// serialize updates to individual files
-	key := fmt.Sprintf("%s/%s/%s/%s/%s", gvr.Group, gvr.Version, gvr.Resource, objUnstructured.GetNamespace(), objUnstructured.GetName())
-	if err := s.currentlyRecording.waitUntilAvailable(key); err != nil {
-		klog.Error(err)
-		return
+// commitMessage describes a single change the way it always has been, with the change as the subject.  Batches get
+// a summary subject and list each change in the body.  Either way every change has a trailer, since the commit
+// author can only name one of them.
+func commitMessage(changes []*observedChange) (string, string) {
+	message := &strings.Builder{}
+	authors := sets.NewString()
+	for _, change := range changes {
+		authors.Insert(change.author)
+	}
+	author := changes[0].author
+	if len(authors) > 1 {
+		author = "multiple-authors"
 	}
-	s.currentlyRecording.reserve(key)
-
-	// start new go func to allow parallel processing where possible and to avoid blocking all progress on retries.
-	go func() {
-		defer s.currentlyRecording.release(key)
-		s.handle(gvr, oldObjUnstructured, objUnstructured, false)
-	}()
-}
 
-func (s *GitStorage) OnDelete(gvr schema.GroupVersionResource, obj interface{}) {
-	objUnstructured, ok := obj.(*unstructured.Unstructured)
-	if !ok {
-		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
-		if !ok {
-			utilruntime.HandleError(fmt.Errorf("couldn't get object from tombstone %#v", obj))
-			return
+	if len(changes) == 1 {
+		fmt.Fprintf(message, "%s %s\n", changes[0].operation, changes[0].ocCommand)
+	} else {
+		counts := map[gitOperation]int{}
+		for _, change := range changes {
+			counts[change.operation]++
 		}
-		objUnstructured, ok = tombstone.Obj.(*unstructured.Unstructured)
-		if !ok {
-			utilruntime.HandleError(fmt.Errorf("tombstone contained object that is not a Namespace %#v", obj))
-			return
+		fmt.Fprintf(message, "recorded %d changes: %d added, %d modified, %d removed\n\n",
+			len(changes), counts[gitOpAdded], counts[gitOpModified], counts[gitOpDeleted])
+		for _, change := range changes {
+			fmt.Fprintf(message, "%s %s\n", change.operation, change.ocCommand)
 		}
 	}
 
-	// serialize updates to individual files
-	key := fmt.Sprintf("%s/%s/%s/%s/%s", gvr.Group, gvr.Version, gvr.Resource, objUnstructured.GetNamespace(), objUnstructured.GetName())
-	if err := s.currentlyRecording.waitUntilAvailable(key); err != nil {
-		klog.Error(err)
-		return
+	message.WriteString("\n")
+	for _, change := range changes {
+		fmt.Fprintf(message, "%s\n", ResourceChange{
+			Operation: change.operation.String(),
+			Observed:  change.observed,
+			Path:      change.path,
+			Author:    change.author,
+		})
 	}
-	s.currentlyRecording.reserve(key)
-
-	// start new go func to allow parallel processing where possible and to avoid blocking all progress on retries.
-	go func() {
-		defer s.currentlyRecording.release(key)
-		s.handle(gvr, nil, objUnstructured, true)
-	}()
+	return message.String(), author
 }
 
 // guessAtModifyingUsers tries to figure out who modified the resource
@@ -322,113 +482,147 @@ func resourceFilename(gvr schema.GroupVersionResource, namespace, name string) s
 	return filepath.Join("namespaces", namespace, groupStr, gvr.Resource, name+".yaml")
 }
 
-func (s *GitStorage) commitAdd(path, author, ocCommand string) error {
-	authorString := fmt.Sprintf("%s <ci-monitor@openshift.io>", author)
-	commitMessage := fmt.Sprintf("added %s", ocCommand)
-	command := fmt.Sprintf(`git add %q && git commit --author=%q -m %q`, path, authorString, commitMessage)
+// write updates the file and stages it.
+func (s *GitStorage) write(name string, content []byte) (gitOperation, error) {
+	fullPath := filepath.Join(s.path, name)
 
-	osCommand := exec.Command("bash", "-e", "-c", command)
-	osCommand.Dir = s.path
-	output, err := osCommand.CombinedOutput()
-	if err != nil {
-		klog.Errorf("Ran %v\n%v\n\n", command, string(output))
-		// sometimes git can leave behind an index.lock.  This process should be the only one working in this git repo
-		// so simply remove the lock file.
-		if _, statErr := os.Stat(filepath.Join(s.path, ".git/index.lock")); statErr == nil {
-			if deleteErr := os.Remove(filepath.Join(s.path, ".git/index.lock")); deleteErr != nil {
-				klog.Errorf("Error removing .git/index.lock: %v", deleteErr)
-			}
+	operation := gitOpModified
+	existing, err := os.ReadFile(fullPath)
+	switch {
+	case os.IsNotExist(err):
+		operation = gitOpAdded
+		if err := os.MkdirAll(filepath.Dir(fullPath), os.ModePerm); err != nil {
+			return gitOpError, err
 		}
-		return err
+	case err != nil:
+		return gitOpError, err
+	case bytes.Equal(existing, content):
+		return gitOpUnchanged, nil
 	}
 
-	klog.Infof("Add: %v -- %v added %v", path, author, ocCommand)
-	return nil
-}
-
-func (s *GitStorage) commitModify(path, author, ocCommand string) error {
-	authorString := fmt.Sprintf("%s <ci-monitor@openshift.io>", author)
-	commitMessage := fmt.Sprintf("modifed %s", ocCommand)
-	command := fmt.Sprintf(`git add %q && git commit --author=%q -m %q`, path, authorString, commitMessage)
-
-	osCommand := exec.Command("bash", "-e", "-c", command)
-	osCommand.Dir = s.path
-	output, err := osCommand.CombinedOutput()
+	if err := os.WriteFile(fullPath, content, os.FileMode(0644)); err != nil {
+		return gitOpError, err
+	}
+	info, err := os.Lstat(fullPath)
 	if err != nil {
-		klog.Errorf("Ran %v\n%v\n\n", command, string(output))
-		// sometimes git can leave behind an index.lock.  This process should be the only one working in this git repo
-		// so simply remove the lock file.
-		if _, statErr := os.Stat(filepath.Join(s.path, ".git/index.lock")); statErr == nil {
-			if deleteErr := os.Remove(filepath.Join(s.path, ".git/index.lock")); deleteErr != nil {
-				klog.Errorf("Error removing .git/index.lock: %v", deleteErr)
-			}
-		}
-		// if nothing changed in the modify don't keep trying over and over
-		if strings.Contains(string(output), "nothing to commit") {
-			klog.Info("Exiting commitModify as nothing to commit")
-			return nil
-		}
-
-		return err
+		return gitOpError, err
 	}
 
-	if output != nil {
-		klog.Infof("Ran %v\n%v\n\n", command, string(output))
+	hash, err := s.storeBlob(content)
+	if err != nil {
+		return gitOpError, err
 	}
-
-	klog.Infof("Modified: %v -- %v updated %v", path, author, ocCommand)
-	return nil
+	entry, err := s.index.Entry(name)
+	if err == index.ErrEntryNotFound {
+		entry = s.index.Add(name)
+	} else if err != nil {
+		return gitOpError, err
+	}
+	entry.Hash = hash
+	entry.Mode = filemode.Regular
+	entry.ModifiedAt = info.ModTime()
+	entry.Size = uint32(info.Size())
+	return operation, nil
 }
 
-func (s *GitStorage) commitRemove(path, author, ocCommand string) error {
-	authorString := fmt.Sprintf("%s <ci-monitor@openshift.io>", author)
-	commitMessage := fmt.Sprintf("removed %s", ocCommand)
-	command := fmt.Sprintf(`rm %q && git rm %q && git commit --author=%q -m %q`, path, path, authorString, commitMessage)
-
-	osCommand := exec.Command("bash", "-e", "-c", command)
-	osCommand.Dir = s.path
-	output, err := osCommand.CombinedOutput()
-	if err != nil {
-		klog.Errorf("Ran %v\n%v\n\n", command, string(output))
-		// sometimes git can leave behind an index.lock.  This process should be the only one working in this git repo
-		// so simply remove the lock file.
-		if _, statErr := os.Stat(filepath.Join(s.path, ".git/index.lock")); statErr == nil {
-			if deleteErr := os.Remove(filepath.Join(s.path, ".git/index.lock")); deleteErr != nil {
-				klog.Errorf("Error removing .git/index.lock: %v", deleteErr)
-			}
-		}
-		return err
+// remove deletes the file and unstages it.  Removing a file that was never recorded is reported as unchanged.
+func (s *GitStorage) remove(name string) (gitOperation, error) {
+	err := os.Remove(filepath.Join(s.path, name))
+	switch {
+	case os.IsNotExist(err):
+		return gitOpUnchanged, nil
+	case err != nil:
+		return gitOpError, err
 	}
-
-	klog.Infof("Removed: %v -- %v deleted %v", path, author, ocCommand)
-	return nil
+	if _, err := s.index.Remove(name); err != nil && err != index.ErrEntryNotFound {
+		return gitOpError, err
+	}
+	return gitOpDeleted, nil
 }
 
-// write handle writing the content into git repository
-func (s *GitStorage) write(name string, content []byte) (gitOperation, error) {
-	fullPath := filepath.Join(s.path, name)
-
-	fileMode := os.FileMode(0644)
+// restoreFromHEAD puts the files and their index entries back to the last commit.  Files that are not part of the
+// last commit are removed.
+func (s *GitStorage) restoreFromHEAD(paths []string) error {
+	var tree *object.Tree
+	head, err := s.repo.Head()
+	switch {
+	case err == plumbing.ErrReferenceNotFound:
+		// nothing was ever committed.
+	case err != nil:
+		return err
+	default:
+		commit, err := s.repo.CommitObject(head.Hash())
+		if err != nil {
+			return err
+		}
+		if tree, err = commit.Tree(); err != nil {
+			return err
+		}
+	}
 
-	// If the file does not exist, create it and report it as new file
-	// This will get reflected in the commit message
-	if _, err := os.Lstat(fullPath); err != nil {
-		if !os.IsNotExist(err) {
-			return gitOpError, err
+	for _, path := range paths {
+		fullPath := filepath.Join(s.path, path)
+		var file *object.File
+		if tree != nil {
+			file, err = tree.File(path)
+			if err != nil && err != object.ErrFileNotFound {
+				return err
+			}
+		}
+		if file == nil {
+			if err := os.Remove(fullPath); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			if _, err := s.index.Remove(path); err != nil && err != index.ErrEntryNotFound {
+				return err
+			}
+			continue
 		}
 
+		content, err := file.Contents()
+		if err != nil {
+			return err
+		}
 		if err := os.MkdirAll(filepath.Dir(fullPath), os.ModePerm); err != nil {
-			return gitOpError, err
+			return err
 		}
-		if err := os.WriteFile(fullPath, content, fileMode); err != nil {
-			return gitOpError, err
+		if err := os.WriteFile(fullPath, []byte(content), os.FileMode(0644)); err != nil {
+			return err
+		}
+		info, err := os.Lstat(fullPath)
+		if err != nil {
+			return err
+		}
+		entry, err := s.index.Entry(path)
+		if err == index.ErrEntryNotFound {
+			entry = s.index.Add(path)
+		} else if err != nil {
+			return err
 		}
-		return gitOpAdded, nil
+		entry.Hash = file.Hash
+		entry.Mode = file.Mode
+		entry.ModifiedAt = info.ModTime()
+		entry.Size = uint32(info.Size())
 	}
+	return nil
+}
 
-	// If the file exists, updated its content and report modified
-	if err := os.WriteFile(fullPath, content, fileMode); err != nil {
-		return gitOpError, err
+// storeBlob writes the object directly instead of using Worktree.Add, which computes the status of the entire
+// worktree on every call.
+func (s *GitStorage) storeBlob(content []byte) (plumbing.Hash, error) {
+	obj := s.repo.Storer.NewEncodedObject()
+	obj.SetType(plumbing.BlobObject)
+	obj.SetSize(int64(len(content)))
+	writer, err := obj.Writer()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if _, err := writer.Write(content); err != nil {
+		writer.Close()
+		return plumbing.ZeroHash, err
+	}
+	if err := writer.Close(); err != nil {
+		return plumbing.ZeroHash, err
 	}
-	return gitOpModified, nil
+	return s.repo.Storer.SetEncodedObject(obj)
 }
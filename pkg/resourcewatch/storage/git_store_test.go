@@ -0,0 +1,255 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var configMapsGVR = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+func configMap(name, value, manager string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"namespace": "ns",
+			"name":      name,
+			"managedFields": []interface{}{
+				map[string]interface{}{
+					"manager":    manager,
+					"operation":  "Update",
+					"apiVersion": "v1",
+					"fieldsType": "FieldsV1",
+					"fieldsV1":   map[string]interface{}{"f:data": map[string]interface{}{"f:key": map[string]interface{}{}}},
+				},
+			},
+		},
+		"data": map[string]interface{}{"key": value},
+	}}
+}
+
+// runUntilDrained stops storage before running it, so everything queued is staged by the final drain.
+func runUntilDrained(s *GitStorage) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.Run(ctx)
+}
+
+// commitMessages returns the messages from oldest to newest.
+func commitMessages(t *testing.T, repo *git.Repository) []string {
+	t.Helper()
+	commitIter, err := repo.Log(&git.LogOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ret := []string{}
+	if err := commitIter.ForEach(func(commit *object.Commit) error {
+		ret = append([]string{commit.Message}, ret...)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return ret
+}
+
+func TestGitStorageBatches(t *testing.T) {
+	path := t.TempDir()
+	s, err := NewGitStorage(path, BatchOptions{MaxBatchSize: 3, MaxBatchDelay: time.Hour, QueueSize: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.OnAdd(configMapsGVR, configMap("a", "1", "creator"))
+	s.OnAdd(configMapsGVR, configMap("b", "1", "creator"))
+	// a is already part of the batch, so this commits the adds first.
+	s.OnUpdate(configMapsGVR, configMap("a", "1", "creator"), configMap("a", "2", "editor"))
+	// no content change, nothing to commit.
+	s.OnUpdate(configMapsGVR, configMap("b", "1", "creator"), configMap("b", "1", "creator"))
+	s.OnDelete(configMapsGVR, configMap("b", "1", "creator"))
+	runUntilDrained(s)
+
+	messages := commitMessages(t, s.repo)
+	if len(messages) != 2 {
+		t.Fatalf("expected 2 commits, got %d: %v", len(messages), messages)
+	}
+	if !strings.HasPrefix(messages[0], "recorded 2 changes: 2 added, 0 modified, 0 removed\n") {
+		t.Errorf("unexpected first commit: %q", messages[0])
+	}
+
+	var got [][]string
+	for _, message := range messages {
+		changes, err := ParseResourceChangeTrailers(message)
+		if err != nil {
+			t.Fatal(err)
+		}
+		commitChanges := []string{}
+		for _, change := range changes {
+			commitChanges = append(commitChanges, strings.Join([]string{change.Operation, change.Path, change.Author}, " "))
+		}
+		got = append(got, commitChanges)
+	}
+	want := [][]string{
+		{"added namespaces/ns/core/configmaps/a.yaml creator", "added namespaces/ns/core/configmaps/b.yaml creator"},
+		{"modified namespaces/ns/core/configmaps/a.yaml editor", "removed namespaces/ns/core/configmaps/b.yaml unknown"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected trailers:\n got %v\nwant %v", got, want)
+	}
+
+	if _, err := os.Stat(filepath.Join(path, "namespaces/ns/core/configmaps/b.yaml")); !os.IsNotExist(err) {
+		t.Errorf("expected b to be removed, got %v", err)
+	}
+	worktree, err := s.repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.IsClean() {
+		t.Errorf("expected everything to be committed, got:\n%v", status)
+	}
+}
+
+func TestGitStorageSingleChangeCommits(t *testing.T) {
+	s, err := NewGitStorage(t.TempDir(), BatchOptions{MaxBatchSize: 1, MaxBatchDelay: time.Hour, QueueSize: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.OnAdd(configMapsGVR, configMap("a", "1", "creator"))
+	runUntilDrained(s)
+
+	commitIter, err := s.repo.Log(&git.LogOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit, err := commitIter.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subject := strings.SplitN(commit.Message, "\n", 2)[0]; subject != "added configmaps/a -n ns" {
+		t.Errorf("unexpected subject %q", subject)
+	}
+	if commit.Author.Name != "creator" || commit.Author.Email != "ci-monitor@openshift.io" {
+		t.Errorf("unexpected author %v", commit.Author)
+	}
+}
+
+func TestGitStorageDropsChangesAfterFailedCommits(t *testing.T) {
+	path := t.TempDir()
+	s, err := NewGitStorage(path, BatchOptions{MaxBatchSize: 10, MaxBatchDelay: time.Hour, QueueSize: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stage := func(obj *unstructured.Unstructured) {
+		t.Helper()
+		change, err := newObservedChange(configMapsGVR, nil, obj, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.stage(change)
+	}
+
+	stage(configMap("a", "1", "creator"))
+	s.commit()
+
+	// a directory in place of the index makes every commit fail.
+	indexPath := filepath.Join(path, ".git", "index")
+	indexBytes, err := os.ReadFile(indexPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(indexPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(indexPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	stage(configMap("b", "1", "creator"))
+	stage(configMap("a", "2", "editor"))
+	for i := 1; i < maxCommitAttempts; i++ {
+		s.commit()
+		if len(s.pending) != 2 {
+			t.Fatalf("expected the changes to stay pending after %d failed commits, got %d", i, len(s.pending))
+		}
+	}
+	s.commit()
+	if len(s.pending) != 0 || s.pendingPaths.Len() != 0 {
+		t.Fatalf("expected the changes to be dropped after %d failed commits, got %d pending", maxCommitAttempts, len(s.pending))
+	}
+	if _, err := os.Stat(filepath.Join(path, "namespaces/ns/core/configmaps/b.yaml")); !os.IsNotExist(err) {
+		t.Errorf("expected the dropped add of b to be removed from the worktree, got %v", err)
+	}
+	if content, err := os.ReadFile(filepath.Join(path, "namespaces/ns/core/configmaps/a.yaml")); err != nil || !strings.Contains(string(content), "key: \"1\"") {
+		t.Errorf("expected the dropped update of a to be reverted in the worktree, got %q: %v", content, err)
+	}
+
+	if err := os.Remove(indexPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(indexPath, indexBytes, 0644); err != nil {
+		t.Fatal(err)
+	}
+	// observing the dropped states again records them.
+	stage(configMap("b", "1", "creator"))
+	stage(configMap("a", "2", "editor"))
+	s.commit()
+
+	messages := commitMessages(t, s.repo)
+	if len(messages) != 2 {
+		t.Fatalf("expected 2 commits, got %d: %v", len(messages), messages)
+	}
+	changes, err := ParseResourceChangeTrailers(messages[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := []string{}
+	for _, change := range changes {
+		got = append(got, change.Operation+" "+change.Path)
+	}
+	want := []string{"added namespaces/ns/core/configmaps/b.yaml", "modified namespaces/ns/core/configmaps/a.yaml"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected changes in the last commit\n got %v\nwant %v", got, want)
+	}
+	worktree, err := s.repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.IsClean() {
+		t.Errorf("expected everything to be committed, got:\n%v", status)
+	}
+}
+
+func TestParseResourceChangeTrailers(t *testing.T) {
+	observed := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	change := ResourceChange{Operation: "modified", Observed: observed, Path: "cluster-scoped-resources/core/nodes/n.yaml", Author: "kubelet AND machine-config"}
+	message := "modified nodes/n\n\n" + change.String() + "\n"
+
+	got, err := ParseResourceChangeTrailers(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, []ResourceChange{change}) {
+		t.Errorf("got %#v, want %#v", got, change)
+	}
+
+	if _, err := ParseResourceChangeTrailers(ResourceChangeTrailer + ": added not-a-time path author"); err == nil {
+		t.Errorf("expected an error for a malformed time")
+	}
+}
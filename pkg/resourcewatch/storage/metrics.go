@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const metricsSubsystem = "resourcewatch_git_storage"
+
+var (
+	// queueDepth is how many observed changes are waiting to be staged.  A queue that stays full means commits are not
+	// keeping up and informers are being slowed down.
+	queueDepth = prometheus.NewGauge(prometheus.GaugeOpts{
+		Subsystem: metricsSubsystem,
+		Name:      "queue_depth",
+		Help:      "Number of observed changes waiting to be written to the repository.",
+	})
+	queueBlockedSeconds = prometheus.NewCounter(prometheus.CounterOpts{
+		Subsystem: metricsSubsystem,
+		Name:      "queue_blocked_seconds_total",
+		Help:      "Time informer handlers spent waiting for room in a full queue.",
+	})
+	droppedChanges = prometheus.NewCounter(prometheus.CounterOpts{
+		Subsystem: metricsSubsystem,
+		Name:      "dropped_changes_total",
+		Help:      "Observed changes that were dropped because storage had already stopped or they could not be committed.",
+	})
+	stagedChanges = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: metricsSubsystem,
+		Name:      "changes_total",
+		Help:      "Observed changes by the operation they resulted in: added, modified, removed, unchanged, or failed.",
+	}, []string{"operation"})
+	commits = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: metricsSubsystem,
+		Name:      "commits_total",
+		Help:      "Commits attempted by result.",
+	}, []string{"result"})
+	commitDurationSeconds = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Subsystem: metricsSubsystem,
+		Name:      "commit_duration_seconds",
+		Help:      "Time taken to write the index and create a commit.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 4, 8),
+	})
+	commitBatchSize = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Subsystem: metricsSubsystem,
+		Name:      "commit_batch_size",
+		Help:      "Number of changes included in each commit.",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 12),
+	})
+	changeLatencySeconds = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Subsystem: metricsSubsystem,
+		Name:      "change_latency_seconds",
+		Help:      "Time from a change being observed to it being committed.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 4, 9),
+	})
+)
+
+func init() {
+	prometheus.MustRegister(
+		queueDepth,
+		queueBlockedSeconds,
+		droppedChanges,
+		stagedChanges,
+		commits,
+		commitDurationSeconds,
+		commitBatchSize,
+		changeLatencySeconds,
+	)
+}
@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ResourceChangeTrailer is the git trailer key used to record each change in a commit.
+const ResourceChangeTrailer = "Resource-Change"
+
+// ResourceChange is a single observed change as recorded in a commit trailer:
+//
+//	Resource-Change: <added|modified|removed> <observed time in RFC3339Nano> <path> <author>
+//
+// The author is last because it may contain spaces.
+type ResourceChange struct {
+	Operation string
+	Observed  time.Time
+	Path      string
+	Author    string
+}
+
+func (c ResourceChange) String() string {
+	return fmt.Sprintf("%s: %s %s %s %s", ResourceChangeTrailer, c.Operation, c.Observed.UTC().Format(time.RFC3339Nano), c.Path, c.Author)
+}
+
+// ParseResourceChangeTrailers returns every change recorded in a commit message.  Lines that are not
+// Resource-Change trailers are ignored.
+func ParseResourceChangeTrailers(message string) ([]ResourceChange, error) {
+	ret := []ResourceChange{}
+	scanner := bufio.NewScanner(strings.NewReader(message))
+	for scanner.Scan() {
+		value, ok := strings.CutPrefix(scanner.Text(), ResourceChangeTrailer+": ")
+		if !ok {
+			continue
+		}
+		fields := strings.SplitN(value, " ", 4)
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("malformed %s trailer: %q", ResourceChangeTrailer, scanner.Text())
+		}
+		observed, err := time.Parse(time.RFC3339Nano, fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("malformed %s trailer %q: %w", ResourceChangeTrailer, scanner.Text(), err)
+		}
+		ret = append(ret, ResourceChange{
+			Operation: fields[0],
+			Observed:  observed,
+			Path:      fields[2],
+			Author:    fields[3],
+		})
+	}
+	return ret, scanner.Err()
+}
@@ -40,10 +40,12 @@ const (
 type Options struct {
 	JUnitDir string
 	SippyURL string
+	// HistoricalDataFile, when set, scores the failures against the pass rates in this file instead of asking sippy.
+	HistoricalDataFile string
 }
 
-// Run performs the test risk analysis by reading the output files from the test run, submitting them to sippy,
-// and writing out the analysis result as a new artifact.
+// Run performs the test risk analysis by reading the output files from the test run, submitting them to sippy
+// (or scoring them against local historical data), and writing out the analysis result as a new artifact.
 func (opt *Options) Run() error {
 	logrus.Infof("Scanning for %s files in: %s", testFailureSummaryFilePrefix, opt.JUnitDir)
 
@@ -144,8 +146,15 @@ type raRequestLog struct {
 
 // readWriteRiskAnalysis requests Risk Analysis from sippy, writes the results to disk, and returns the RA html to include in prow job output.
 // If the request fails, it will try up to maxTries times before returning an error; an error means no RA data returned.
+// When a historical data file is configured, the analysis is computed locally instead.
 func (opt *Options) readWriteRiskAnalysis(inputBytes []byte) ([]byte, error) {
-	riskAnalysisBytes, err := opt.requestRiskAnalysis(inputBytes, &http.Client{}, &realSleeper{})
+	var riskAnalysisBytes []byte
+	var err error
+	if len(opt.HistoricalDataFile) > 0 {
+		riskAnalysisBytes, err = opt.offlineRiskAnalysis(inputBytes)
+	} else {
+		riskAnalysisBytes, err = opt.requestRiskAnalysis(inputBytes, &http.Client{}, &realSleeper{})
+	}
 	if err != nil {
 		return nil, err
 	}
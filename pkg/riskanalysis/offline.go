@@ -0,0 +1,258 @@
+package riskanalysis
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// minimumHistoricalRuns is how many runs a test needs before its pass rate says anything about a failure.
+	minimumHistoricalRuns = 7
+	// highRiskPassPercentage and mediumRiskPassPercentage decide how unusual a failure is.  A test that almost
+	// never fails is a high risk when it does.
+	highRiskPassPercentage   = 98
+	mediumRiskPassPercentage = 80
+)
+
+// HistoricalPassRates holds the pass rate for each test on each job variant.
+type HistoricalPassRates struct {
+	// passRates are keyed by test name.  Rows leave out the parts of the variant they apply to every value of.
+	passRates map[string][]HistoricalTestPassRate
+}
+
+// LoadHistoricalPassRates reads the pass rates from a JSON list or, for files ending in .csv, a CSV file with a header
+// row naming the HistoricalTestPassRate fields.
+func LoadHistoricalPassRates(path string) (*HistoricalPassRates, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var rows []HistoricalTestPassRate
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		rows, err = readHistoricalPassRatesCSV(f)
+	} else {
+		err = json.NewDecoder(f).Decode(&rows)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read historical pass rates from %s: %w", path, err)
+	}
+	return NewHistoricalPassRates(rows), nil
+}
+
+func NewHistoricalPassRates(rows []HistoricalTestPassRate) *HistoricalPassRates {
+	ret := &HistoricalPassRates{passRates: map[string][]HistoricalTestPassRate{}}
+	for _, row := range rows {
+		ret.passRates[row.TestName] = append(ret.passRates[row.TestName], row)
+	}
+	return ret
+}
+
+func readHistoricalPassRatesCSV(in io.Reader) ([]HistoricalTestPassRate, error) {
+	records, err := csv.NewReader(in).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	columns := map[string]int{}
+	for i, column := range records[0] {
+		columns[strings.TrimSpace(column)] = i
+	}
+	for _, required := range []string{"TestName", "Runs", "Passes"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("missing %q column", required)
+		}
+	}
+	value := func(record []string, column string) string {
+		i, ok := columns[column]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	ret := []HistoricalTestPassRate{}
+	for line, record := range records[1:] {
+		runs, err := strconv.Atoi(value(record, "Runs"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid Runs: %w", line+2, err)
+		}
+		passes, err := strconv.Atoi(value(record, "Passes"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid Passes: %w", line+2, err)
+		}
+		ret = append(ret, HistoricalTestPassRate{
+			TestName: value(record, "TestName"),
+			JobType: platformidentification.JobType{
+				Release:      value(record, "Release"),
+				FromRelease:  value(record, "FromRelease"),
+				Platform:     value(record, "Platform"),
+				Architecture: value(record, "Architecture"),
+				Network:      value(record, "Network"),
+				Topology:     value(record, "Topology"),
+			},
+			Runs:   runs,
+			Passes: passes,
+		})
+	}
+	return ret, nil
+}
+
+// passRateFor prefers data for the exact job variant and falls back through less specific variants, down to data that
+// applies to every variant.  A row applies when every part of its variant that is set matches the job.  Of those, the
+// most specific wins, where release outranks the upgrade source, which outranks platform, then architecture, network,
+// and topology.  Rows for the same variant are resolved in favor of the last one.
+func (h *HistoricalPassRates) passRateFor(testName string, jobType platformidentification.JobType) (HistoricalTestPassRate, bool) {
+	ret := HistoricalTestPassRate{}
+	bestSpecificity := -1
+	for _, passRate := range h.passRates[testName] {
+		specificity, ok := variantSpecificity(passRate.JobType, jobType)
+		if ok && specificity >= bestSpecificity {
+			ret = passRate
+			bestSpecificity = specificity
+		}
+	}
+	return ret, bestSpecificity >= 0
+}
+
+// variantSpecificity reports whether the data variant applies to the job and, if it does, how specific it is.  Each
+// part is weighted above all the less important parts combined so that a release match is never traded for others.
+func variantSpecificity(variant, jobType platformidentification.JobType) (int, bool) {
+	specificity := 0
+	for _, part := range []struct{ variant, job string }{
+		{variant.Release, jobType.Release},
+		{variant.FromRelease, jobType.FromRelease},
+		{variant.Platform, jobType.Platform},
+		{variant.Architecture, jobType.Architecture},
+		{variant.Network, jobType.Network},
+		{variant.Topology, jobType.Topology},
+	} {
+		specificity <<= 1
+		switch {
+		case len(part.variant) == 0:
+		case part.variant == part.job:
+			specificity |= 1
+		default:
+			return 0, false
+		}
+	}
+	return specificity, true
+}
+
+// testCountFor is how many distinct tests we have history for that applies to the job variant.
+func (h *HistoricalPassRates) testCountFor(jobType platformidentification.JobType) int {
+	count := 0
+	for testName := range h.passRates {
+		if _, ok := h.passRateFor(testName, jobType); ok {
+			count++
+		}
+	}
+	return count
+}
+
+// Analyze scores every failed test in the job run by how often it passed historically, the same way sippy does
+// for the online analysis.
+func (h *HistoricalPassRates) Analyze(jobRun *ProwJobRun) *ProwJobRunRiskAnalysis {
+	jobType := jobRun.ClusterData.JobType
+	ret := &ProwJobRunRiskAnalysis{
+		ProwJobName:    jobRun.ProwJob.Name,
+		ProwJobRunID:   jobRun.ID,
+		Release:        jobType.Release,
+		CompareRelease: jobType.Release,
+		Tests:          []ProwJobRunTestRiskAnalysis{},
+		OverallRisk: JobFailureRisk{
+			Level:                  RiskLevelNone,
+			Reasons:                []string{},
+			JobRunTestCount:        jobRun.TestCount,
+			JobRunTestFailures:     len(jobRun.Tests),
+			HistoricalRunTestCount: h.testCountFor(jobType),
+		},
+		OpenBugs: []interface{}{},
+	}
+
+	for _, test := range jobRun.Tests {
+		risk := TestFailureRisk{Level: RiskLevelUnknown}
+		passRate, ok := h.passRateFor(test.Test.Name, jobType)
+		switch {
+		case !ok:
+			risk.Reasons = []string{"No historical data found for this test."}
+		case passRate.Runs < minimumHistoricalRuns:
+			risk.CurrentRuns = passRate.Runs
+			risk.CurrentPasses = passRate.Passes
+			risk.Reasons = []string{fmt.Sprintf("This test has only %d historical runs, at least %d are needed to assess risk.", passRate.Runs, minimumHistoricalRuns)}
+		default:
+			risk.CurrentRuns = passRate.Runs
+			risk.CurrentPasses = passRate.Passes
+			risk.CurrentPassPercentage = float64(passRate.Passes) / float64(passRate.Runs) * 100
+			switch {
+			case risk.CurrentPassPercentage >= highRiskPassPercentage:
+				risk.Level = RiskLevelHigh
+			case risk.CurrentPassPercentage >= mediumRiskPassPercentage:
+				risk.Level = RiskLevelMedium
+			default:
+				risk.Level = RiskLevelLow
+			}
+			risk.Reasons = []string{fmt.Sprintf("This test has passed %.2f%% of %d runs on jobs like %s in the historical data.", risk.CurrentPassPercentage, passRate.Runs, describeJobType(passRate.JobType))}
+		}
+		ret.Tests = append(ret.Tests, ProwJobRunTestRiskAnalysis{
+			Name:     test.Test.Name,
+			Risk:     risk,
+			OpenBugs: []interface{}{},
+		})
+		if risk.Level.Level > ret.OverallRisk.Level.Level {
+			ret.OverallRisk.Level = risk.Level
+		}
+	}
+	sort.SliceStable(ret.Tests, func(i, j int) bool {
+		return ret.Tests[i].Risk.Level.Level > ret.Tests[j].Risk.Level.Level
+	})
+	if len(ret.Tests) > 0 {
+		ret.OverallRisk.Reasons = append(ret.OverallRisk.Reasons, fmt.Sprintf("Maximum failed test risk: %s", ret.OverallRisk.Level.Name))
+	}
+	return ret
+}
+
+func describeJobType(jobType platformidentification.JobType) string {
+	if jobType == (platformidentification.JobType{}) {
+		return "any variant"
+	}
+	parts := []string{}
+	for _, part := range []string{jobType.Release, jobType.Platform, jobType.Architecture, jobType.Network, jobType.Topology} {
+		if len(part) > 0 {
+			parts = append(parts, part)
+		}
+	}
+	if len(jobType.FromRelease) > 0 {
+		parts = append(parts, "upgrade from "+jobType.FromRelease)
+	}
+	return strings.Join(parts, " ")
+}
+
+// offlineRiskAnalysis produces the same output as sippy would, but from the historical data file.
+func (opt *Options) offlineRiskAnalysis(inputBytes []byte) ([]byte, error) {
+	logrus.Infof("Analyzing risk offline using historical data from: %s", opt.HistoricalDataFile)
+	historicalPassRates, err := LoadHistoricalPassRates(opt.HistoricalDataFile)
+	if err != nil {
+		logrus.WithError(err).Error("Error loading historical data for offline risk analysis")
+		return nil, err
+	}
+	jobRun := &ProwJobRun{}
+	if err := json.Unmarshal(inputBytes, jobRun); err != nil {
+		logrus.WithError(err).Error("Error unmarshalling job run for offline risk analysis")
+		return nil, err
+	}
+	return json.MarshalIndent(historicalPassRates.Analyze(jobRun), "", "    ")
+}
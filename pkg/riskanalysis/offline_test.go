@@ -0,0 +1,233 @@
+package riskanalysis
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var awsJobType = platformidentification.JobType{
+	Release:      "4.17",
+	Platform:     "aws",
+	Architecture: "amd64",
+	Network:      "ovn",
+	Topology:     "ha",
+}
+
+func newTestJobRun(failedTests ...string) *ProwJobRun {
+	jobRun := &ProwJobRun{
+		ID:          1234,
+		ProwJob:     ProwJob{Name: "periodic-ci-openshift-release-master-ci-4.17-e2e-aws-ovn"},
+		ClusterData: platformidentification.ClusterData{JobType: awsJobType},
+		TestCount:   100,
+	}
+	for _, name := range failedTests {
+		jobRun.Tests = append(jobRun.Tests, ProwJobRunTest{Test: Test{Name: name}, Suite: Suite{Name: "openshift-tests"}, Status: 12})
+	}
+	return jobRun
+}
+
+func newTestHistoricalPassRates() *HistoricalPassRates {
+	return NewHistoricalPassRates([]HistoricalTestPassRate{
+		{TestName: "stable", JobType: awsJobType, Runs: 200, Passes: 199},
+		{TestName: "stable", JobType: platformidentification.JobType{Release: "4.17", Platform: "gcp"}, Runs: 200, Passes: 100},
+		{TestName: "sometimes", JobType: awsJobType, Runs: 100, Passes: 90},
+		{TestName: "flaky", Runs: 100, Passes: 50},
+		{TestName: "new", JobType: awsJobType, Runs: 3, Passes: 3},
+	})
+}
+
+func TestHistoricalPassRatesAnalyze(t *testing.T) {
+	analysis := newTestHistoricalPassRates().Analyze(newTestJobRun("flaky", "unknown", "sometimes", "new", "stable"))
+
+	levels := map[string]RiskLevel{}
+	for _, test := range analysis.Tests {
+		levels[test.Name] = test.Risk.Level
+	}
+	assert.Equal(t, map[string]RiskLevel{
+		"stable":    RiskLevelHigh,
+		"sometimes": RiskLevelMedium,
+		"flaky":     RiskLevelLow,
+		"unknown":   RiskLevelUnknown,
+		"new":       RiskLevelUnknown,
+	}, levels)
+	assert.Equal(t, "stable", analysis.Tests[0].Name, "highest risk should be first")
+	assert.Contains(t, analysis.Tests[0].Risk.Reasons[0], "99.50% of 200 runs")
+
+	assert.Equal(t, RiskLevelHigh, analysis.OverallRisk.Level)
+	assert.Equal(t, []string{"Maximum failed test risk: High"}, analysis.OverallRisk.Reasons)
+	assert.Equal(t, 5, analysis.OverallRisk.JobRunTestFailures)
+	assert.Equal(t, 100, analysis.OverallRisk.JobRunTestCount)
+	assert.Equal(t, 4, analysis.OverallRisk.HistoricalRunTestCount, "data for every variant applies to this one")
+	assert.Equal(t, "4.17", analysis.CompareRelease)
+
+	noFailures := newTestHistoricalPassRates().Analyze(newTestJobRun())
+	assert.Equal(t, RiskLevelNone, noFailures.OverallRisk.Level)
+}
+
+func TestHistoricalPassRatesFallback(t *testing.T) {
+	passRates := NewHistoricalPassRates([]HistoricalTestPassRate{
+		{TestName: "test", Runs: 1},
+		{TestName: "test", JobType: platformidentification.JobType{Release: "4.17"}, Runs: 2},
+		{TestName: "test", JobType: platformidentification.JobType{Release: "4.17", Platform: "aws"}, Runs: 3},
+		{TestName: "test", JobType: platformidentification.JobType{Release: "4.17", Platform: "aws", Topology: "ha"}, Runs: 4},
+		{TestName: "test", JobType: platformidentification.JobType{Release: "4.17", FromRelease: "4.16"}, Runs: 5},
+		{TestName: "test", JobType: platformidentification.JobType{Platform: "gcp", Architecture: "arm64", Network: "ovn", Topology: "ha"}, Runs: 6},
+		{TestName: "test", JobType: platformidentification.JobType{Release: "4.16", Platform: "metal"}, Runs: 7},
+		{TestName: "test", JobType: awsJobType, Runs: 8},
+		{TestName: "no fallback", JobType: platformidentification.JobType{Release: "4.17", Platform: "gcp"}, Runs: 9},
+	})
+
+	tests := []struct {
+		name     string
+		testName string
+		jobType  platformidentification.JobType
+		wantRuns int
+		wantOK   bool
+	}{
+		{name: "exact", testName: "test", jobType: awsJobType, wantRuns: 8, wantOK: true},
+		{
+			name:     "matching release, platform, and topology",
+			testName: "test",
+			jobType:  platformidentification.JobType{Release: "4.17", Platform: "aws", Architecture: "arm64", Network: "sdn", Topology: "ha"},
+			wantRuns: 4, wantOK: true,
+		},
+		{
+			name:     "matching release and platform",
+			testName: "test",
+			jobType:  platformidentification.JobType{Release: "4.17", Platform: "aws", Architecture: "amd64", Network: "ovn", Topology: "single"},
+			wantRuns: 3, wantOK: true,
+		},
+		{
+			name:     "matching release and upgrade",
+			testName: "test",
+			jobType:  platformidentification.JobType{Release: "4.17", FromRelease: "4.16", Platform: "aws", Topology: "ha"},
+			wantRuns: 5, wantOK: true,
+		},
+		{
+			name:     "release outranks every other part",
+			testName: "test",
+			jobType:  platformidentification.JobType{Release: "4.17", Platform: "gcp", Architecture: "arm64", Network: "ovn", Topology: "ha"},
+			wantRuns: 2, wantOK: true,
+		},
+		{
+			name:     "without the release",
+			testName: "test",
+			jobType:  platformidentification.JobType{Release: "4.18", Platform: "gcp", Architecture: "arm64", Network: "ovn", Topology: "ha"},
+			wantRuns: 6, wantOK: true,
+		},
+		{
+			name:     "every variant",
+			testName: "test",
+			jobType:  platformidentification.JobType{Release: "4.18", Platform: "metal"},
+			wantRuns: 1, wantOK: true,
+		},
+		{name: "no variant applies", testName: "no fallback", jobType: awsJobType},
+		{name: "unknown test", testName: "unknown", jobType: awsJobType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			passRate, ok := passRates.passRateFor(tt.testName, tt.jobType)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantRuns, passRate.Runs)
+		})
+	}
+}
+
+func TestLoadHistoricalPassRatesCSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pass-rates.csv")
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join([]string{
+		"TestName,Release,Platform,Architecture,Network,Topology,Runs,Passes",
+		`"[sig-node] a, test",4.17,aws,amd64,ovn,ha,200,199`,
+		"other,,,,,,10,5",
+	}, "\n")), 0644))
+
+	passRates, err := LoadHistoricalPassRates(path)
+	require.NoError(t, err)
+	passRate, ok := passRates.passRateFor("[sig-node] a, test", awsJobType)
+	assert.True(t, ok)
+	assert.Equal(t, 199, passRate.Passes)
+	passRate, ok = passRates.passRateFor("other", awsJobType)
+	assert.True(t, ok, "rows without a variant should apply to every variant")
+	assert.Equal(t, 10, passRate.Runs)
+
+	require.NoError(t, os.WriteFile(path, []byte("TestName,Runs\nother,10\n"), 0644))
+	_, err = LoadHistoricalPassRates(path)
+	assert.ErrorContains(t, err, `missing "Passes" column`)
+}
+
+// newFakeSippy answers risk analysis requests the way sippy would if it had the historical pass rates.
+func newFakeSippy(t *testing.T, passRates *HistoricalPassRates) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		jobRun := &ProwJobRun{}
+		if err := json.NewDecoder(req.Body).Decode(jobRun); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(passRates.Analyze(jobRun)); err != nil {
+			t.Error(err)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func writeTestFailureSummary(t *testing.T, dir string, jobRun *ProwJobRun) {
+	jobRunBytes, err := json.Marshal(jobRun)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, testFailureSummaryFilePrefix+"_20240501-120000.json"), jobRunBytes, 0644))
+}
+
+func readRiskAnalysis(t *testing.T, dir string) *ProwJobRunRiskAnalysis {
+	analysisBytes, err := os.ReadFile(filepath.Join(dir, raDataFile))
+	require.NoError(t, err)
+	analysis := &ProwJobRunRiskAnalysis{}
+	require.NoError(t, json.Unmarshal(analysisBytes, analysis))
+
+	html, err := os.ReadFile(filepath.Join(dir, "test-risk-analysis.html"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(html), "TEST_RISK_ANALYSIS_JSON_GOES_HERE")
+	return analysis
+}
+
+func TestRunOfflineMatchesOnline(t *testing.T) {
+	jobRun := newTestJobRun("stable", "flaky")
+	passRates := newTestHistoricalPassRates()
+
+	onlineDir := t.TempDir()
+	writeTestFailureSummary(t, onlineDir, jobRun)
+	online := &Options{JUnitDir: onlineDir, SippyURL: newFakeSippy(t, passRates).URL}
+	require.NoError(t, online.Run())
+
+	offlineDir := t.TempDir()
+	writeTestFailureSummary(t, offlineDir, jobRun)
+	historicalData := filepath.Join(t.TempDir(), "pass-rates.json")
+	historicalBytes, err := json.Marshal([]HistoricalTestPassRate{
+		{TestName: "stable", JobType: awsJobType, Runs: 200, Passes: 199},
+		{TestName: "sometimes", JobType: awsJobType, Runs: 100, Passes: 90},
+		{TestName: "flaky", Runs: 100, Passes: 50},
+		{TestName: "new", JobType: awsJobType, Runs: 3, Passes: 3},
+	})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(historicalData, historicalBytes, 0644))
+	offline := &Options{JUnitDir: offlineDir, SippyURL: "http://sippy.invalid", HistoricalDataFile: historicalData}
+	require.NoError(t, offline.Run())
+
+	onlineAnalysis := readRiskAnalysis(t, onlineDir)
+	offlineAnalysis := readRiskAnalysis(t, offlineDir)
+	assert.Equal(t, RiskLevelHigh, offlineAnalysis.OverallRisk.Level)
+	assert.Equal(t, onlineAnalysis.Tests, offlineAnalysis.Tests)
+	assert.Equal(t, onlineAnalysis.OverallRisk.Level, offlineAnalysis.OverallRisk.Level)
+
+	_, err = os.Stat(filepath.Join(offlineDir, raTestResultsFileName))
+	assert.NoError(t, err, "offline analysis should write the same autodl files")
+}
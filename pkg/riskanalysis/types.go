@@ -34,3 +34,63 @@ type ProwJobRunTest struct {
 	Suite  Suite
 	Status int // would like to use smallint here, but gorm auto-migrate breaks trying to change the type every start
 }
+
+// ProwJobRunRiskAnalysis is the subset of the sippy risk analysis response that we read and, when running offline,
+// produce.  It is what gets written to risk-analysis.json and rendered in the html summary.
+type ProwJobRunRiskAnalysis struct {
+	ProwJobName    string
+	ProwJobRunID   int
+	Release        string
+	CompareRelease string
+	Tests          []ProwJobRunTestRiskAnalysis
+	OverallRisk    JobFailureRisk
+	OpenBugs       []interface{}
+}
+
+type ProwJobRunTestRiskAnalysis struct {
+	Name     string
+	TestID   int
+	Risk     TestFailureRisk
+	OpenBugs []interface{}
+}
+
+type TestFailureRisk struct {
+	Level                 RiskLevel
+	Reasons               []string
+	CurrentRuns           int
+	CurrentPasses         int
+	CurrentPassPercentage float64
+}
+
+type JobFailureRisk struct {
+	Level                  RiskLevel
+	Reasons                []string
+	JobRunTestCount        int
+	JobRunTestFailures     int
+	NeverStableJob         bool
+	HistoricalRunTestCount int
+}
+
+type RiskLevel struct {
+	Name  string
+	Level int
+}
+
+var (
+	RiskLevelNone    = RiskLevel{Name: "None", Level: 0}
+	RiskLevelLow     = RiskLevel{Name: "Low", Level: 1}
+	RiskLevelUnknown = RiskLevel{Name: "Unknown", Level: 25}
+	RiskLevelMedium  = RiskLevel{Name: "Medium", Level: 50}
+	RiskLevelHigh    = RiskLevel{Name: "High", Level: 100}
+)
+
+// HistoricalTestPassRate is how often a test passed on a job variant, as supplied for offline risk analysis.
+// A row with an empty job type applies to every variant that has no row of its own.
+type HistoricalTestPassRate struct {
+	TestName string
+
+	platformidentification.JobType `json:",inline"`
+
+	Runs   int
+	Passes int
+}
@@ -76,6 +76,12 @@ func (b *TestBinary) Info(ctx context.Context) (*ExtensionInfo, error) {
 	return b.info, nil
 }
 
+// CachedInfo returns the info gathered by a previous call to Info, or nil if Info has not been called.
+// Unlike Info, it never executes the binary.
+func (b *TestBinary) CachedInfo() *ExtensionInfo {
+	return b.info
+}
+
 // ListTests returns which tests this binary advertises.  Eventually, it should take an environment struct
 // to provide to the binary so it can determine for itself which tests are relevant.
 func (b *TestBinary) ListTests(ctx context.Context) (ExtensionTestSpecs, error) {
@@ -65,6 +65,12 @@ type GinkgoRunSuiteOptions struct {
 
 	DryRun        bool
 	PrintCommands bool
+	// Explain, if set, is a regular expression of test names to explain the selection of instead of running the suite.
+	Explain       string
+	ExplainFormat string
+	// ExplainNote, if set, qualifies every decision in the explanation, for instance when the cluster was not
+	// discovered.
+	ExplainNote string
 	genericclioptions.IOStreams
 
 	StartTime time.Time
@@ -85,6 +91,9 @@ func (o *GinkgoRunSuiteOptions) BindFlags(flags *pflag.FlagSet) {
 
 	flags.BoolVar(&o.DryRun, "dry-run", o.DryRun, "Print the tests to run without executing them.")
 	flags.BoolVar(&o.PrintCommands, "print-commands", o.PrintCommands, "Print the sub-commands that would be executed instead.")
+	flags.StringVar(&o.Explain, "explain", o.Explain, "Print why each test matching this regular expression was selected or excluded instead of running the suite. Nothing is run.")
+	flags.Lookup("explain").NoOptDefVal = "."
+	flags.StringVar(&o.ExplainFormat, "explain-format", "table", "The format of --explain output: table or json.")
 	flags.StringVar(&o.ClusterStabilityDuringTest, "cluster-stability", o.ClusterStabilityDuringTest, "cluster stability during test, usually dependent on the job: Stable or Disruptive. Empty default will be treated as Stable.")
 	flags.StringVar(&o.JUnitDir, "junit-dir", o.JUnitDir, "The directory to write test reports to.")
 	flags.IntVar(&o.Count, "count", o.Count, "Run each test a specified number of times. Defaults to 1 or the suite's preferred value. -1 will run forever.")
@@ -197,6 +206,14 @@ func (o *GinkgoRunSuiteOptions) Run(suite *TestSuite, junitSuiteName string, mon
 	r := rand.New(rand.NewSource(suiteConfig.RandomSeed))
 	r.Shuffle(len(tests), func(i, j int) { tests[i], tests[j] = tests[j], tests[i] })
 
+	if err := suite.Validate(testNames(tests)); err != nil {
+		return fmt.Errorf("invalid tests in suite %q: %w", suite.Name, err)
+	}
+
+	if len(o.Explain) > 0 {
+		return explainSelection(o.Out, o.ErrOut, suite, tests, o.Explain, o.ExplainFormat, o.ExplainNote)
+	}
+
 	tests = suite.Filter(tests)
 	if len(tests) == 0 {
 		return fmt.Errorf("suite %q does not contain any tests", suite.Name)
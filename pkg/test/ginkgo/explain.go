@@ -0,0 +1,53 @@
+package ginkgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"text/tabwriter"
+)
+
+// explainSelection writes, for every test whose name matches the --explain expression, whether the suite selects it
+// and which filter rejected it first.  Nothing is run.  A note qualifying the decisions heads the table, and goes to
+// errOut for json so that the output stays parseable.
+func explainSelection(out, errOut io.Writer, suite *TestSuite, tests []*testCase, expression, format, note string) error {
+	re, err := regexp.Compile(expression)
+	if err != nil {
+		return fmt.Errorf("invalid --explain expression: %w", err)
+	}
+
+	decisions := []TestSelectionDecision{}
+	for _, test := range sortedTests(tests) {
+		if !re.MatchString(test.name) {
+			continue
+		}
+		decisions = append(decisions, suite.ExplainMatch(test.name))
+	}
+
+	switch format {
+	case "json":
+		if len(note) > 0 {
+			fmt.Fprintf(errOut, "NOTE: %s\n", note)
+		}
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(decisions)
+	case "", "table":
+		if len(note) > 0 {
+			fmt.Fprintf(out, "NOTE: %s\n\n", note)
+		}
+		w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "DECISION\tFILTER\tREASON\tTEST")
+		for _, decision := range decisions {
+			result := "selected"
+			if !decision.Selected {
+				result = "excluded"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%q\n", result, decision.RejectedBy, decision.Reason, decision.Name)
+		}
+		return w.Flush()
+	default:
+		return fmt.Errorf("unknown --explain-format %q, expected table or json", format)
+	}
+}
@@ -0,0 +1,136 @@
+package ginkgo
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/openshift/origin/pkg/clioptions/clusterdiscovery"
+)
+
+func TestExplainSelection(t *testing.T) {
+	suite := &TestSuite{
+		Name: "openshift/conformance",
+		Matches: func(name string) bool {
+			return strings.Contains(name, "[Suite:openshift/conformance")
+		},
+	}
+	suite.AddRequiredNamedMatchFunc("--run", func(name string) bool {
+		return strings.Contains(name, "sig-network")
+	})
+	suite.AddRequiredFilter("cluster configuration", func(name string) string {
+		if strings.Contains(name, "[Skipped:gce]") {
+			return `the cluster excludes tests labeled "[Skipped:gce]"`
+		}
+		return ""
+	})
+	suite.AddRequiredMatchFunc(nil)
+
+	tests := []*testCase{
+		{name: "[sig-network] selected [Suite:openshift/conformance/parallel]"},
+		{name: "[sig-network] skipped on gce [Skipped:gce] [Suite:openshift/conformance/parallel]"},
+		{name: "[sig-node] not run [Suite:openshift/conformance/parallel]"},
+		{name: "[sig-network] not in suite"},
+	}
+
+	out := &bytes.Buffer{}
+	if err := explainSelection(out, &bytes.Buffer{}, suite, tests, "sig-", "json", ""); err != nil {
+		t.Fatal(err)
+	}
+	decisions := []TestSelectionDecision{}
+	if err := json.Unmarshal(out.Bytes(), &decisions); err != nil {
+		t.Fatal(err)
+	}
+	expected := []TestSelectionDecision{
+		{Name: "[sig-network] not in suite", RejectedBy: "suite qualifier", Reason: `not part of suite "openshift/conformance"`},
+		{Name: "[sig-network] selected [Suite:openshift/conformance/parallel]", Selected: true},
+		{Name: "[sig-network] skipped on gce [Skipped:gce] [Suite:openshift/conformance/parallel]", RejectedBy: "cluster configuration", Reason: `the cluster excludes tests labeled "[Skipped:gce]"`},
+		{Name: "[sig-node] not run [Suite:openshift/conformance/parallel]", RejectedBy: "--run", Reason: "did not match"},
+	}
+	if !reflect.DeepEqual(expected, decisions) {
+		t.Errorf("unexpected decisions\n got: %#v\nwant: %#v", decisions, expected)
+	}
+
+	// explaining must agree with the filtering that is actually used
+	for _, test := range tests {
+		if suite.Matches(test.name) != suite.ExplainMatch(test.name).Selected {
+			t.Errorf("Matches and ExplainMatch disagree on %q", test.name)
+		}
+	}
+
+	out.Reset()
+	if err := explainSelection(out, &bytes.Buffer{}, suite, tests, "gce", "table", ""); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[1], "excluded  cluster configuration") {
+		t.Errorf("unexpected table:\n%s", out.String())
+	}
+
+	if err := explainSelection(out, &bytes.Buffer{}, suite, tests, ".", "yaml", ""); err == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+}
+
+// TestExplainSelectionForDiscoveredCluster explains against the filter a discovered cluster produces rather than the
+// skeleton cluster of a dry-run.
+func TestExplainSelectionForDiscoveredCluster(t *testing.T) {
+	config := &clusterdiscovery.ClusterConfiguration{
+		ProviderName:  "aws",
+		NetworkPlugin: "OVNKubernetes",
+		HasIPv4:       true,
+	}
+	suite := &TestSuite{
+		Name: "openshift/conformance",
+		Matches: func(name string) bool {
+			return strings.Contains(name, "[Suite:openshift/conformance")
+		},
+	}
+	suite.AddRequiredFilter("cluster configuration", config.RejectionReasonFn(nil))
+	suite.AddRequiredMatchFunc(nil)
+
+	tests := []*testCase{
+		{name: "[sig-network] skipped on aws [Skipped:aws] [Suite:openshift/conformance/parallel]"},
+		{name: "[sig-network] skipped on gce [Skipped:gce] [Suite:openshift/conformance/parallel]"},
+	}
+
+	out := &bytes.Buffer{}
+	if err := explainSelection(out, &bytes.Buffer{}, suite, tests, "sig-", "json", ""); err != nil {
+		t.Fatal(err)
+	}
+	decisions := []TestSelectionDecision{}
+	if err := json.Unmarshal(out.Bytes(), &decisions); err != nil {
+		t.Fatal(err)
+	}
+	if len(decisions) != 2 {
+		t.Fatalf("unexpected decisions: %#v", decisions)
+	}
+	if aws := decisions[0]; aws.Selected || aws.RejectedBy != "cluster configuration" || !strings.Contains(aws.Reason, "[Skipped:aws]") {
+		t.Errorf("expected the aws cluster to exclude [Skipped:aws], got %#v", aws)
+	}
+	if gce := decisions[1]; !gce.Selected {
+		t.Errorf("expected the aws cluster to select [Skipped:gce], got %#v", gce)
+	}
+
+	out.Reset()
+	errOut := &bytes.Buffer{}
+	if err := explainSelection(out, errOut, suite, tests, "aws", "table", "the cluster could not be discovered"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out.String(), "NOTE: the cluster could not be discovered\n") {
+		t.Errorf("expected the table to start with the note, got:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := explainSelection(out, errOut, suite, tests, "aws", "json", "the cluster could not be discovered"); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(out.Bytes(), &decisions); err != nil {
+		t.Errorf("expected the json to stay parseable with a note: %v", err)
+	}
+	if !strings.Contains(errOut.String(), "NOTE: the cluster could not be discovered") {
+		t.Errorf("expected the note on errOut, got %q", errOut.String())
+	}
+}
@@ -32,14 +32,16 @@ func generateJUnitTestSuiteResults(
 		},
 	}
 	for _, test := range tests {
+		properties := testCaseProperties(test)
 		switch {
 		case test.skipped:
 			s.NumTests++
 			s.NumSkipped++
 			s.TestCases = append(s.TestCases, &junitapi.JUnitTestCase{
-				Name:      test.name,
-				SystemOut: string(test.testOutputBytes),
-				Duration:  test.duration.Seconds(),
+				Name:       test.name,
+				SystemOut:  string(test.testOutputBytes),
+				Duration:   test.duration.Seconds(),
+				Properties: properties,
 				SkipMessage: &junitapi.SkipMessage{
 					Message: lastLinesUntil(string(test.testOutputBytes), 100, "skip ["),
 				},
@@ -48,9 +50,10 @@ func generateJUnitTestSuiteResults(
 			s.NumTests++
 			s.NumFailed++
 			s.TestCases = append(s.TestCases, &junitapi.JUnitTestCase{
-				Name:      test.name,
-				SystemOut: string(test.testOutputBytes),
-				Duration:  test.duration.Seconds(),
+				Name:       test.name,
+				SystemOut:  string(test.testOutputBytes),
+				Duration:   test.duration.Seconds(),
+				Properties: properties,
 				FailureOutput: &junitapi.FailureOutput{
 					Output: lastLinesUntil(string(test.testOutputBytes), 100, "fail ["),
 				},
@@ -59,9 +62,10 @@ func generateJUnitTestSuiteResults(
 			s.NumTests++
 			s.NumFailed++
 			s.TestCases = append(s.TestCases, &junitapi.JUnitTestCase{
-				Name:      test.name,
-				SystemOut: string(test.testOutputBytes),
-				Duration:  test.duration.Seconds(),
+				Name:       test.name,
+				SystemOut:  string(test.testOutputBytes),
+				Duration:   test.duration.Seconds(),
+				Properties: properties,
 				FailureOutput: &junitapi.FailureOutput{
 					Output: lastLinesUntil(string(test.testOutputBytes), 100, "flake:"),
 				},
@@ -70,14 +74,16 @@ func generateJUnitTestSuiteResults(
 			// also add the successful junit result:
 			s.NumTests++
 			s.TestCases = append(s.TestCases, &junitapi.JUnitTestCase{
-				Name:     test.name,
-				Duration: test.duration.Seconds(),
+				Name:       test.name,
+				Duration:   test.duration.Seconds(),
+				Properties: properties,
 			})
 		case test.success:
 			s.NumTests++
 			s.TestCases = append(s.TestCases, &junitapi.JUnitTestCase{
-				Name:     test.name,
-				Duration: test.duration.Seconds(),
+				Name:       test.name,
+				Duration:   test.duration.Seconds(),
+				Properties: properties,
 			})
 		}
 	}
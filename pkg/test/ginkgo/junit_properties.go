@@ -0,0 +1,121 @@
+package ginkgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/origin/pkg/test/extensions"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+)
+
+// testNameLabelRegex finds the bracketed labels, like [sig-node] or [Suite:openshift/conformance/parallel], that
+// annotations add to test names.
+var testNameLabelRegex = regexp.MustCompile(`\[([^\[\]]+)\]`)
+
+// testCaseProperties describes everything we know about a test beyond its result so that consumers of the junit
+// can filter on owners, lifecycle, and origin without parsing test names.
+func testCaseProperties(test *testCase) []*junitapi.TestCaseProperty {
+	ret := []*junitapi.TestCaseProperty{}
+	add := func(name, value string) {
+		if len(value) == 0 {
+			return
+		}
+		ret = append(ret, &junitapi.TestCaseProperty{Name: name, Value: value})
+	}
+
+	labels := sets.New[string]()
+	for _, match := range testNameLabelRegex.FindAllStringSubmatch(test.name, -1) {
+		labels.Insert(match[1])
+	}
+	if spec := test.extensionTestSpec; spec != nil {
+		labels = labels.Union(spec.Labels)
+	}
+	for _, label := range sets.List(labels) {
+		add("label", label)
+		key, value, hasValue := strings.Cut(label, ":")
+		switch {
+		case strings.HasPrefix(label, "sig-"):
+			add("owner", label)
+		case hasValue && key == "Jira":
+			add("jira-component", strings.Trim(value, `"`))
+		case hasValue:
+			add("label:"+key, value)
+		}
+	}
+
+	// the lifecycle reported with the result replaces the one from the spec
+	lifecycle := extensions.Lifecycle("")
+	if spec := test.extensionTestSpec; spec != nil {
+		lifecycle = spec.Lifecycle
+	}
+	if result := test.extensionTestResult; result != nil && len(result.Lifecycle) > 0 {
+		lifecycle = result.Lifecycle
+	}
+	add("lifecycle", string(lifecycle))
+
+	if spec := test.extensionTestSpec; spec != nil {
+		add("original-name", spec.OriginalName)
+		add("source", spec.Source)
+		tagKeys := make([]string, 0, len(spec.Tags))
+		for key := range spec.Tags {
+			tagKeys = append(tagKeys, key)
+		}
+		sort.Strings(tagKeys)
+		for _, key := range tagKeys {
+			add("tag:"+key, spec.Tags[key])
+		}
+	}
+	if test.binary != nil {
+		if info := test.binary.CachedInfo(); info != nil {
+			add("source-binary", info.Source.SourceBinary)
+			add("source-image", info.Source.SourceImage)
+			add("source-commit", info.Source.Commit)
+			add("component", strings.Trim(strings.Join([]string{info.Component.Product, info.Component.Kind, info.Component.Name}, ":"), ":"))
+		}
+	} else {
+		add("source-binary", test.binaryName)
+	}
+
+	add("isolation", test.testExclusion)
+	if test.testTimeout != 0 {
+		add("timeout", test.testTimeout.String())
+	}
+	if len(test.locations) > 0 {
+		add("code-location", test.locations[len(test.locations)-1].String())
+	}
+
+	if !test.start.IsZero() {
+		add("start-time", test.start.UTC().Format(time.RFC3339))
+	}
+	if !test.end.IsZero() {
+		add("end-time", test.end.UTC().Format(time.RFC3339))
+	}
+
+	if result := test.extensionTestResult; result != nil {
+		for _, detail := range result.Details {
+			add("detail:"+detail.Name, detailValue(detail.Value))
+		}
+	}
+	return ret
+}
+
+// detailValue keeps strings readable and encodes anything more structured as JSON.
+func detailValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(encoded)
+}
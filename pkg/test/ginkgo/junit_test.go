@@ -1,6 +1,17 @@
 package ginkgo
 
-import "testing"
+import (
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/origin/pkg/test/extensions"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+)
 
 func Test_lastLines(t *testing.T) {
 	tests := []struct {
@@ -33,3 +44,66 @@ func Test_lastLines(t *testing.T) {
 		})
 	}
 }
+
+func Test_testCaseProperties(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	test := &testCase{
+		name: `[sig-api-machinery][Jira:"kube-apiserver"] API should work [Suite:openshift/conformance/parallel]`,
+		extensionTestSpec: &extensions.ExtensionTestSpec{
+			Labels:    sets.New[string]("Slow"),
+			Tags:      map[string]string{"team": "apiserver"},
+			Lifecycle: extensions.LifecycleInforming,
+		},
+		testExclusion: "apiserver",
+		start:         start,
+		end:           start.Add(time.Minute),
+		extensionTestResult: &extensions.ExtensionTestResult{
+			Lifecycle: extensions.LifecycleInforming,
+			Details: []extensions.Details{
+				{Name: "reason", Value: "timed out"},
+				{Name: "pods", Value: []string{"a", "b"}},
+			},
+		},
+	}
+
+	got := map[string][]string{}
+	for _, property := range testCaseProperties(test) {
+		got[property.Name] = append(got[property.Name], property.Value)
+	}
+	want := map[string][]string{
+		"label":          {`Jira:"kube-apiserver"`, "Slow", "Suite:openshift/conformance/parallel", "sig-api-machinery"},
+		"owner":          {"sig-api-machinery"},
+		"jira-component": {"kube-apiserver"},
+		"label:Suite":    {"openshift/conformance/parallel"},
+		"lifecycle":      {"informing"},
+		"tag:team":       {"apiserver"},
+		"isolation":      {"apiserver"},
+		"start-time":     {"2024-05-01T12:00:00Z"},
+		"end-time":       {"2024-05-01T12:01:00Z"},
+		"detail:reason":  {"timed out"},
+		"detail:pods":    {`["a","b"]`},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected properties\n got: %v\nwant: %v", got, want)
+	}
+
+	// a lifecycle reported with the result replaces the one from the spec
+	test.extensionTestSpec.Lifecycle = extensions.LifecycleBlocking
+	lifecycles := []string{}
+	for _, property := range testCaseProperties(test) {
+		if property.Name == "lifecycle" {
+			lifecycles = append(lifecycles, property.Value)
+		}
+	}
+	if !reflect.DeepEqual(lifecycles, []string{"informing"}) {
+		t.Errorf("expected the lifecycle of the result only, got %v", lifecycles)
+	}
+
+	out, err := xml.Marshal(&junitapi.JUnitTestCase{Name: "test", Properties: testCaseProperties(test)[:1]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), `<properties><property name="label" value="Jira:&#34;kube-apiserver&#34;"></property></properties>`) {
+		t.Errorf("unexpected xml: %s", out)
+	}
+}
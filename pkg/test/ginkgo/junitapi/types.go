@@ -66,6 +66,9 @@ type JUnitTestCase struct {
 	// Duration is the time taken in seconds to run the test
 	Duration float64 `xml:"time,attr"`
 
+	// Properties holds details about the test case, like its owners and lifecycle, that are not part of its name
+	Properties []*TestCaseProperty `xml:"properties>property,omitempty"`
+
 	// SkipMessage holds the reason why the test was skipped
 	SkipMessage *SkipMessage `xml:"skipped"`
 
@@ -79,6 +82,15 @@ type JUnitTestCase struct {
 	SystemErr string `xml:"system-err,omitempty"`
 }
 
+// TestCaseProperty contains a mapping of a property name to a value.  Names may repeat, for instance
+// one "label" property is written for every label on the test.
+type TestCaseProperty struct {
+	XMLName xml.Name `xml:"property"`
+
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
 // SkipMessage holds a message explaining why a test was skipped
 type SkipMessage struct {
 	XMLName xml.Name `xml:"skipped"`
@@ -1,6 +1,7 @@
 package ginkgo
 
 import (
+	"fmt"
 	"regexp"
 	"time"
 
@@ -53,9 +54,10 @@ func externalBinaryTestsToOriginTestCases(specs extensions.ExtensionTestSpecs) [
 	var tests []*testCase
 	for _, spec := range specs {
 		tests = append(tests, &testCase{
-			name:    spec.Name,
-			rawName: spec.Name,
-			binary:  spec.Binary,
+			name:              spec.Name,
+			rawName:           spec.Name,
+			binary:            spec.Binary,
+			extensionTestSpec: spec,
 		})
 	}
 	return tests
@@ -92,6 +94,8 @@ type testCase struct {
 	binaryName string
 	// binary is the reference when using an external binary
 	binary *extensions.TestBinary
+	// extensionTestSpec is what the external binary advertised about the test
+	extensionTestSpec *extensions.ExtensionTestSpec
 
 	spec      types.TestSpec
 	locations []types.CodeLocation
@@ -126,6 +130,8 @@ func (t *testCase) Retry() *testCase {
 		locations:     t.locations,
 		testExclusion: t.testExclusion,
 
+		extensionTestSpec: t.extensionTestSpec,
+
 		previous: t,
 	}
 	return copied
@@ -159,10 +165,36 @@ type TestSuite struct {
 	ClusterStabilityDuringTest ClusterStabilityDuringTest
 
 	TestTimeout time.Duration
+
+	// qualifier is what Matches was before any filters were added, and filters are what has been added since.
+	qualifier TestMatchFunc
+	filters   []namedTestFilter
+	// validations must pass for the names of all tests before any are selected.
+	validations []TestNamesValidationFunc
 }
 
 type TestMatchFunc func(name string) bool
 
+// TestRejectionReasonFunc returns why a test is excluded, or an empty string if it is included.
+type TestRejectionReasonFunc func(name string) string
+
+// TestNamesValidationFunc returns an error when the names of the tests are not valid, like a malformed label.
+type TestNamesValidationFunc func(names []string) error
+
+// namedTestFilter is a requirement added to a suite, kept so that we can explain which requirement excluded a test.
+type namedTestFilter struct {
+	name   string
+	reason TestRejectionReasonFunc
+}
+
+// TestSelectionDecision explains whether a test is part of a suite and, if not, which filter excluded it first.
+type TestSelectionDecision struct {
+	Name       string `json:"name"`
+	Selected   bool   `json:"selected"`
+	RejectedBy string `json:"rejectedBy,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+}
+
 func (s *TestSuite) Filter(tests []*testCase) []*testCase {
 	matches := make([]*testCase, 0, len(tests))
 	for _, test := range tests {
@@ -175,9 +207,36 @@ func (s *TestSuite) Filter(tests []*testCase) []*testCase {
 }
 
 func (s *TestSuite) AddRequiredMatchFunc(matchFn TestMatchFunc) {
+	s.AddRequiredNamedMatchFunc("additional match function", matchFn)
+}
+
+// AddRequiredNamedMatchFunc is AddRequiredMatchFunc with a name to report when the function excludes a test.
+func (s *TestSuite) AddRequiredNamedMatchFunc(filterName string, matchFn TestMatchFunc) {
 	if matchFn == nil {
 		return
 	}
+	s.AddRequiredFilter(filterName, func(name string) string {
+		if matchFn(name) {
+			return ""
+		}
+		return "did not match"
+	})
+}
+
+// AddRequiredFilter requires tests to have no rejection reason from reasonFn to be part of the suite.
+func (s *TestSuite) AddRequiredFilter(filterName string, reasonFn TestRejectionReasonFunc) {
+	if reasonFn == nil {
+		return
+	}
+	if s.filters == nil {
+		// whatever the suite matched before any filters were added is the suite's own qualifier
+		s.qualifier = s.Matches
+	}
+	s.filters = append(s.filters, namedTestFilter{name: filterName, reason: reasonFn})
+
+	matchFn := func(name string) bool {
+		return len(reasonFn(name)) == 0
+	}
 	if s.Matches == nil {
 		s.Matches = matchFn
 		return
@@ -189,6 +248,44 @@ func (s *TestSuite) AddRequiredMatchFunc(matchFn TestMatchFunc) {
 	}
 }
 
+// AddValidation requires the names of every test to pass validateFn before the suite selects any, so that a filter
+// given a malformed name fails the run instead of quietly excluding the test.
+func (s *TestSuite) AddValidation(validateFn TestNamesValidationFunc) {
+	if validateFn == nil {
+		return
+	}
+	s.validations = append(s.validations, validateFn)
+}
+
+// Validate runs every validation added to the suite against the test names.
+func (s *TestSuite) Validate(names []string) error {
+	var errs []error
+	for _, validateFn := range s.validations {
+		if err := validateFn(names); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.NewAggregate(errs)
+}
+
+// ExplainMatch evaluates the suite qualifier and then every filter in the order they were added, which is the same
+// order Matches evaluates them in, and reports the first one that excludes the test.
+func (s *TestSuite) ExplainMatch(name string) TestSelectionDecision {
+	qualifier := s.qualifier
+	if s.filters == nil {
+		qualifier = s.Matches
+	}
+	if qualifier != nil && !qualifier(name) {
+		return TestSelectionDecision{Name: name, RejectedBy: "suite qualifier", Reason: fmt.Sprintf("not part of suite %q", s.Name)}
+	}
+	for _, filter := range s.filters {
+		if reason := filter.reason(name); len(reason) > 0 {
+			return TestSelectionDecision{Name: name, RejectedBy: filter.name, Reason: reason}
+		}
+	}
+	return TestSelectionDecision{Name: name, Selected: true}
+}
+
 func testNames(tests []*testCase) []string {
 	var names []string
 	for _, t := range tests {
@@ -26,6 +26,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/version"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/dynamic"
@@ -70,8 +71,18 @@ func AllTests() []upgrades.Test {
 	}
 }
 
+// Hop is one upgrade of an ordered upgrade path.
+type Hop struct {
+	// ToImage is the release image or version to upgrade to.
+	ToImage string
+	// PausedPools are the machine config pools that stay paused while this hop rolls out, like the worker pool during
+	// the intermediate hop of an EUS-to-EUS upgrade. Pools paused by an earlier hop that are not listed are unpaused
+	// before this hop starts, and any pool still paused after the last hop is unpaused once it completes.
+	PausedPools []string `json:",omitempty"`
+}
+
 var (
-	upgradeToImage             string
+	upgradePath                = []Hop{}
 	upgradeTests               = []upgrades.Test{}
 	upgradeAbortAt             int
 	upgradeDisruptRebootPolicy string
@@ -88,10 +99,9 @@ func SetTests(tests []upgrades.Test) {
 	upgradeTests = tests
 }
 
-// SetToImage sets the image that will be upgraded to. This may be a comma delimited list
-// of sequential upgrade attempts.
-func SetToImage(image string) {
-	upgradeToImage = image
+// SetUpgradePath sets the ordered upgrades to perform, all of which run within a single upgrade test.
+func SetUpgradePath(path []Hop) {
+	upgradePath = path
 }
 
 func SetUpgradeDisruptReboot(policy string) error {
@@ -173,6 +183,11 @@ var _ = g.Describe("[sig-arch][Feature:ClusterUpgrade]", func() {
 		client := configv1client.NewForConfigOrDie(config)
 		dynamicClient := dynamic.NewForConfigOrDie(config)
 
+		toImages := []string{}
+		for _, hop := range upgradePath {
+			toImages = append(toImages, hop.ToImage)
+		}
+		upgradeToImage := strings.Join(toImages, ",")
 		upgCtx, err := getUpgradeContext(client, upgradeToImage)
 		framework.ExpectNoError(err, "determining what to upgrade to version=%s image=%s", "", upgradeToImage)
 
@@ -183,11 +198,24 @@ var _ = g.Describe("[sig-arch][Feature:ClusterUpgrade]", func() {
 			},
 			upgradeTests,
 			func() {
+				pausedPools := sets.NewString()
 				for i := 1; i < len(upgCtx.Versions); i++ {
+					hop := upgradeHop{number: i, count: len(upgCtx.Versions) - 1}
+					if i <= len(upgradePath) {
+						hop.pausedPools = sets.NewString(upgradePath[i-1].PausedPools...)
+					}
+					framework.ExpectNoError(
+						pausePools(f, dynamicClient, hop, pausedPools),
+						fmt.Sprintf("before upgrade to %s", upgCtx.Versions[i].NodeImage))
 					framework.ExpectNoError(
-						clusterUpgrade(f, client, dynamicClient, config, upgCtx.Versions[i]),
+						clusterUpgrade(f, client, dynamicClient, config, upgCtx.Versions[i], hop),
 						fmt.Sprintf("during upgrade to %s", upgCtx.Versions[i].NodeImage))
 				}
+				if pausedPools.Len() > 0 {
+					framework.ExpectNoError(
+						unpausePoolsAfterUpgrade(f, dynamicClient, pausedPools),
+						"after upgrade while updating paused machine config pools")
+				}
 			},
 		)
 	})
@@ -314,14 +342,116 @@ func getUpgradeContext(c configv1client.Interface, upgradeImage string) (*upgrad
 
 var errControlledAbort = fmt.Errorf("beginning abort")
 
-func clusterUpgrade(f *framework.Framework, c configv1client.Interface, dc dynamic.Interface, config *rest.Config, version upgrades.VersionContext) error {
+var machineConfigPoolsGVR = schema.GroupVersionResource{
+	Group:    "machineconfiguration.openshift.io",
+	Version:  "v1",
+	Resource: "machineconfigpools",
+}
+
+// upgradeHop identifies one upgrade of the upgrade path while it runs.
+type upgradeHop struct {
+	// number is the position of the hop in the upgrade path, starting at one
+	number int
+	count  int
+	// pausedPools are the machine config pools that stay paused during the hop
+	pausedPools sets.String
+}
+
+// testName qualifies the name of a test recorded during the hop when the upgrade path has more than one hop. Results
+// recorded under the same name are treated as a flake, so each hop needs its own name to report its own result.
+func (h upgradeHop) testName(name string) string {
+	if h.count <= 1 {
+		return name
+	}
+	return fmt.Sprintf("%s (hop %d of %d)", name, h.number, h.count)
+}
+
+// eventNote appends the hop to the note of an upgrade event when the upgrade path has more than one hop, which allows
+// the upgrade-hops monitor test to mark the hop boundaries on the timeline.
+func (h upgradeHop) eventNote(note string) string {
+	if h.count <= 1 {
+		return note
+	}
+	return fmt.Sprintf("%s hop/%d hops/%d", note, h.number, h.count)
+}
+
+// pausePools pauses the machine config pools the hop keeps paused and unpauses the pools an earlier hop paused that
+// the hop does not list. pausedPools tracks the pools paused so far and is updated in place.
+func pausePools(f *framework.Framework, dc dynamic.Interface, hop upgradeHop, pausedPools sets.String) error {
+	toPause := hop.pausedPools.Difference(pausedPools)
+	toUnpause := pausedPools.Difference(hop.pausedPools)
+	if toPause.Len() == 0 && toUnpause.Len() == 0 {
+		return nil
+	}
+	return disruption.RecordJUnit(
+		f,
+		hop.testName("[sig-mco] Machine config pools are paused for the upgrade"),
+		func() (error, bool) {
+			mcps := dc.Resource(machineConfigPoolsGVR)
+			for _, name := range toUnpause.List() {
+				if err := setPoolPaused(mcps, name, false); err != nil {
+					return err, false
+				}
+				pausedPools.Delete(name)
+			}
+			for _, name := range toPause.List() {
+				if err := setPoolPaused(mcps, name, true); err != nil {
+					return err, false
+				}
+				pausedPools.Insert(name)
+			}
+			return nil, false
+		},
+	)
+}
+
+// unpausePoolsAfterUpgrade unpauses the machine config pools still paused after the last hop and waits for them to
+// roll out the configuration of the final release.
+func unpausePoolsAfterUpgrade(f *framework.Framework, dc dynamic.Interface, pausedPools sets.String) error {
+	return disruption.RecordJUnit(
+		f,
+		"[sig-mco] Paused machine config pools complete upgrade after they are unpaused",
+		func() (error, bool) {
+			mcps := dc.Resource(machineConfigPoolsGVR)
+			for _, name := range pausedPools.List() {
+				if err := setPoolPaused(mcps, name, false); err != nil {
+					return err, false
+				}
+			}
+			// the pools reboot every node they hold, so allow as long as a full upgrade takes
+			if err := wait.PollImmediate(10*time.Second, 150*time.Minute, func() (bool, error) {
+				for _, name := range pausedPools.List() {
+					if updated, _ := IsPoolUpdated(mcps, name); !updated {
+						return false, nil
+					}
+				}
+				return true, nil
+			}); err != nil {
+				return fmt.Errorf("Pools %s did not complete upgrade after they were unpaused: %v", strings.Join(pausedPools.List(), ", "), err), false
+			}
+			framework.Logf("Pools %s completed upgrade after they were unpaused", strings.Join(pausedPools.List(), ", "))
+			return nil, false
+		},
+	)
+}
+
+func setPoolPaused(mcps dynamic.NamespaceableResourceInterface, name string, paused bool) error {
+	framework.Logf("Setting machine config pool %s paused=%v", name, paused)
+	patch := []byte(fmt.Sprintf(`{"spec":{"paused":%v}}`, paused))
+	if _, err := mcps.Patch(context.Background(), name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("unable to set machine config pool %s paused=%v: %v", name, paused, err)
+	}
+	return nil
+}
+
+func clusterUpgrade(f *framework.Framework, c configv1client.Interface, dc dynamic.Interface, config *rest.Config, version upgrades.VersionContext, hop upgradeHop) error {
 	fmt.Fprintf(os.Stderr, "\n\n\n")
 	defer func() { fmt.Fprintf(os.Stderr, "\n\n\n") }()
 
 	// ignore the failure here, we don't want this to fail the upgrade, we want it to fail this particular test.
 	_ = disruption.RecordJUnit(
 		f,
-		"[bz-Routing] console is not available via ingress",
+		hop.testName("[bz-Routing] console is not available via ingress"),
 		func() (error, bool) {
 			pollErr := wait.PollImmediateWithContext(context.TODO(), 1*time.Second, 10*time.Minute, func(ctx context.Context) (bool, error) {
 				consoleSampler := disruptioningress.CreateConsoleRouteAvailableWithNewConnections(config)
@@ -431,7 +561,7 @@ func clusterUpgrade(f *framework.Framework, c configv1client.Interface, dc dynam
 	framework.Logf("Upgrade time limit set as %0.2f", upgradeDurationLimit.Minutes())
 
 	framework.Logf("Starting upgrade to version=%s image=%s attempt=%s", version.Version.String(), version.NodeImage, uid)
-	recordClusterEvent(kubeClient, uid, "Upgrade", monitorapi.UpgradeStartedReason, fmt.Sprintf("version/%s image/%s", version.Version.String(), version.NodeImage), false)
+	recordClusterEvent(kubeClient, uid, "Upgrade", monitorapi.UpgradeStartedReason, hop.eventNote(fmt.Sprintf("version/%s image/%s", version.Version.String(), version.NodeImage)), false)
 
 	// decide whether to abort at a percent
 	abortAt := upgradeAbortAt
@@ -461,12 +591,12 @@ func clusterUpgrade(f *framework.Framework, c configv1client.Interface, dc dynam
 	defer monitor.Describe(f)
 
 	//used below in separate paths
-	clusterCompletesUpgradeTestName := "[sig-cluster-lifecycle] Cluster completes upgrade"
+	clusterCompletesUpgradeTestName := hop.testName("[sig-cluster-lifecycle] Cluster completes upgrade")
 
 	// trigger the update and record verification as an independent step
 	if err := disruption.RecordJUnit(
 		f,
-		"[sig-cluster-lifecycle] Cluster version operator acknowledges upgrade",
+		hop.testName("[sig-cluster-lifecycle] Cluster version operator acknowledges upgrade"),
 		func() (error, bool) {
 			cv, err := c.ConfigV1().ClusterVersions().Get(context.Background(), "version", metav1.GetOptions{})
 			if err != nil {
@@ -532,7 +662,7 @@ func clusterUpgrade(f *framework.Framework, c configv1client.Interface, dc dynam
 			framework.Logf("Cluster version operator failed to acknowledge upgrade request")
 			return fmt.Errorf("Cluster did not complete upgrade: operator failed to acknowledge upgrade request"), false
 		})
-		recordClusterEvent(kubeClient, uid, "Upgrade", monitorapi.UpgradeFailedReason, fmt.Sprintf("failed to acknowledge version: %v", err), true)
+		recordClusterEvent(kubeClient, uid, "Upgrade", monitorapi.UpgradeFailedReason, hop.eventNote(fmt.Sprintf("failed to acknowledge version: %v", err)), true)
 		return err
 	}
 
@@ -580,7 +710,7 @@ func clusterUpgrade(f *framework.Framework, c configv1client.Interface, dc dynam
 					}); err != nil {
 						return false, err
 					}
-					recordClusterEvent(kubeClient, uid, "Upgrade", monitorapi.UpgradeRollbackReason, fmt.Sprintf("version/%s image/%s", original.Status.Desired.Version, original.Status.Desired.Version), false)
+					recordClusterEvent(kubeClient, uid, "Upgrade", monitorapi.UpgradeRollbackReason, hop.eventNote(fmt.Sprintf("version/%s image/%s", original.Status.Desired.Version, original.Status.Desired.Version)), false)
 					aborted = true
 					action = "aborted upgrade"
 					return false, nil
@@ -596,12 +726,12 @@ func clusterUpgrade(f *framework.Framework, c configv1client.Interface, dc dynam
 			}
 
 			framework.Logf("Completed %s to %s", action, versionString(desired))
-			recordClusterEvent(kubeClient, uid, "Upgrade", monitorapi.UpgradeVersionReason, fmt.Sprintf("version/%s image/%s", updated.Status.Desired.Version, updated.Status.Desired.Version), false)
+			recordClusterEvent(kubeClient, uid, "Upgrade", monitorapi.UpgradeVersionReason, hop.eventNote(fmt.Sprintf("version/%s image/%s", updated.Status.Desired.Version, updated.Status.Desired.Version)), false)
 
 			// record whether the cluster was fast or slow upgrading.  Don't fail the test, we still want signal on the actual tests themselves.
 			upgradeEnded := time.Now()
 			upgradeDuration := upgradeEnded.Sub(upgradeStarted)
-			testCaseName := fmt.Sprintf("[sig-cluster-lifecycle] cluster upgrade should complete in a reasonable time")
+			testCaseName := hop.testName("[sig-cluster-lifecycle] cluster upgrade should complete in a reasonable time")
 			failure := ""
 			if upgradeDuration > upgradeDurationLimit {
 				failure = fmt.Sprintf("%s to %s took too long: %0.2f minutes (for this platform/network, it should be less than %0.2f minutes)", action, versionString(desired), upgradeDuration.Minutes(), upgradeDurationLimit.Minutes())
@@ -611,22 +741,18 @@ func clusterUpgrade(f *framework.Framework, c configv1client.Interface, dc dynam
 			return nil, false
 		},
 	); err != nil {
-		recordClusterEvent(kubeClient, uid, "Upgrade", monitorapi.UpgradeFailedReason, fmt.Sprintf("failed to reach cluster version: %v", err), true)
+		recordClusterEvent(kubeClient, uid, "Upgrade", monitorapi.UpgradeFailedReason, hop.eventNote(fmt.Sprintf("failed to reach cluster version: %v", err)), true)
 		return err
 	}
 
 	var errMasterUpdating error
 	if err := disruption.RecordJUnit(
 		f,
-		"[sig-mco] Machine config pools complete upgrade",
+		hop.testName("[sig-mco] Machine config pools complete upgrade"),
 		func() (error, bool) {
 			framework.Logf("Waiting on pools to be upgraded")
 			if err := wait.PollImmediate(10*time.Second, 30*time.Minute, func() (bool, error) {
-				mcps := dc.Resource(schema.GroupVersionResource{
-					Group:    "machineconfiguration.openshift.io",
-					Version:  "v1",
-					Resource: "machineconfigpools",
-				})
+				mcps := dc.Resource(machineConfigPoolsGVR)
 				pools, err := mcps.List(context.Background(), metav1.ListOptions{})
 				if err != nil {
 					framework.Logf("error getting pools %v", err)
@@ -651,18 +777,18 @@ func clusterUpgrade(f *framework.Framework, c configv1client.Interface, dc dynam
 			return nil, false
 		},
 	); err != nil {
-		recordClusterEvent(kubeClient, uid, "Upgrade", monitorapi.UpgradeFailedReason, fmt.Sprintf("failed to upgrade nodes: %v", err), true)
+		recordClusterEvent(kubeClient, uid, "Upgrade", monitorapi.UpgradeFailedReason, hop.eventNote(fmt.Sprintf("failed to upgrade nodes: %v", err)), true)
 		return err
 	}
 
 	if errMasterUpdating != nil {
-		recordClusterEvent(kubeClient, uid, "Upgrade", monitorapi.UpgradeFailedReason, fmt.Sprintf("master was updating after cluster version reached level: %v", errMasterUpdating), true)
+		recordClusterEvent(kubeClient, uid, "Upgrade", monitorapi.UpgradeFailedReason, hop.eventNote(fmt.Sprintf("master was updating after cluster version reached level: %v", errMasterUpdating)), true)
 		return errMasterUpdating
 	}
 
 	if err := disruption.RecordJUnit(
 		f,
-		"[sig-cluster-lifecycle] ClusterOperators are available and not degraded after upgrade",
+		hop.testName("[sig-cluster-lifecycle] ClusterOperators are available and not degraded after upgrade"),
 		func() (error, bool) {
 			if err := operator.WaitForOperatorsToSettle(context.TODO(), c); err != nil {
 				return err, false
@@ -670,11 +796,11 @@ func clusterUpgrade(f *framework.Framework, c configv1client.Interface, dc dynam
 			return nil, false
 		},
 	); err != nil {
-		recordClusterEvent(kubeClient, uid, "Upgrade", monitorapi.UpgradeFailedReason, fmt.Sprintf("failed to settle operators: %v", err), true)
+		recordClusterEvent(kubeClient, uid, "Upgrade", monitorapi.UpgradeFailedReason, hop.eventNote(fmt.Sprintf("failed to settle operators: %v", err)), true)
 		return err
 	}
 
-	recordClusterEvent(kubeClient, uid, "Upgrade", monitorapi.UpgradeCompleteReason, fmt.Sprintf("version/%s image/%s", updated.Status.Desired.Version, updated.Status.Desired.Image), false)
+	recordClusterEvent(kubeClient, uid, "Upgrade", monitorapi.UpgradeCompleteReason, hop.eventNote(fmt.Sprintf("version/%s image/%s", updated.Status.Desired.Version, updated.Status.Desired.Image)), false)
 	return nil
 }
 
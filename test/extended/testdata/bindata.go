@@ -53622,6 +53622,18 @@ var _e2echartE2eChartTemplateHtml = []byte(`<html lang="en">
         return eventInterval.source === "NodeState"
     }
 
+    function isMachineConfig(eventInterval) {
+        if (eventInterval.source !== "MachineConfigMonitor") {
+            return false
+        }
+        const reason = eventInterval.message.reason
+        return reason === "MachineConfigPoolCondition" || reason === "MachineConfigNodeState" || reason === "MachineConfigDrain"
+    }
+
+    function isUpgradeHop(eventInterval) {
+        return eventInterval.source === "UpgradeHopMonitor" && eventInterval.message.reason === "UpgradeHop"
+    }
+
     function isCloudMetrics(eventInterval) {
         return eventInterval.source === "CloudMetrics";
     }
@@ -53717,6 +53729,28 @@ var _e2echartE2eChartTemplateHtml = []byte(`<html lang="en">
         return [buildLocatorDisplayString(item.locator), "", "AlertCritical"]
     }
 
+    function machineConfigValue(item) {
+        if (item.message.reason === "MachineConfigDrain") {
+            return [buildLocatorDisplayString(item.locator), "", "MachineConfigDrain"]
+        }
+        if (item.message.reason === "MachineConfigNodeState") {
+            if (item.message.annotations["state"] === "Working") {
+                return [buildLocatorDisplayString(item.locator), "", "MachineConfigNodeWorking"]
+            }
+            return [buildLocatorDisplayString(item.locator), "", "MachineConfigNodeDegraded"]
+        }
+        if (item.message.annotations["condition"] === "Updating") {
+            return [buildLocatorDisplayString(item.locator), "", "MachineConfigPoolUpdating"]
+        }
+        return [buildLocatorDisplayString(item.locator), "", "MachineConfigPoolDegraded"]
+    }
+
+    function upgradeHopValue(item) {
+        const state = item.message.annotations["state"]
+        const label = "hop " + item.message.annotations["hop"] + " of " + item.message.annotations["hops"]
+        return [label, "", "UpgradeHop" + state]
+    }
+
     function apiserverDisruptionValue(item) {
         // TODO: isolate DNS error into CIClusterDisruption
         return [buildLocatorDisplayString(item.locator), "", "Disruption"]
@@ -53925,6 +53959,9 @@ var _e2echartE2eChartTemplateHtml = []byte(`<html lang="en">
         var loc = window.location.href;
 
         var timelineGroups = []
+        timelineGroups.push({group: "upgrade-hops", data: []})
+        createTimelineData(upgradeHopValue, timelineGroups[timelineGroups.length - 1].data, eventIntervals, isUpgradeHop, regex)
+
         timelineGroups.push({group: "operator-unavailable", data: []})
         createTimelineData("OperatorUnavailable", timelineGroups[timelineGroups.length - 1].data, eventIntervals, isOperatorAvailable, regex)
 
@@ -53941,6 +53978,9 @@ var _e2echartE2eChartTemplateHtml = []byte(`<html lang="en">
             return e1.label < e2.label ? -1 : e1.label > e2.label;
         })
 
+        timelineGroups.push({group: "machine-config", data: []})
+        createTimelineData(machineConfigValue, timelineGroups[timelineGroups.length - 1].data, eventIntervals, isMachineConfig, regex)
+
         timelineGroups.push({group: "disruption", data: []})
         createTimelineData(disruptionValue, timelineGroups[timelineGroups.length - 1].data, eventIntervals, isEndpointConnectivity, regex)
 
@@ -54024,7 +54064,9 @@ var _e2echartE2eChartTemplateHtml = []byte(`<html lang="en">
                 'CIClusterDisruption', 'Disruption', // disruption
                 'Degraded', 'Upgradeable', 'False', 'Unknown',
                 'PodLogInfo', 'PodLogWarning', 'PodLogError',
-                'EtcdOther', 'EtcdLeaderFound', 'EtcdLeaderLost', 'EtcdLeaderElected', 'EtcdLeaderMissing'])
+                'EtcdOther', 'EtcdLeaderFound', 'EtcdLeaderLost', 'EtcdLeaderElected', 'EtcdLeaderMissing',
+                'MachineConfigPoolUpdating', 'MachineConfigPoolDegraded', 'MachineConfigNodeWorking', 'MachineConfigNodeDegraded', 'MachineConfigDrain',
+                'UpgradeHopCompleted', 'UpgradeHopFailed', 'UpgradeHopIncomplete'])
             .range([
                 '#6E6E6E', '#0000ff', '#d0312d', '#ffa500', // pathological and interesting events
                 '#fada5e','#fada5e','#ffa500', '#d0312d',  // alerts
@@ -54035,7 +54077,9 @@ var _e2echartE2eChartTemplateHtml = []byte(`<html lang="en">
                 '#96cbff', '#d0312d', // disruption
                 '#b65049', '#32b8b6', '#ffffff', '#bbbbbb',
                 '#96cbff', '#fada5e', '#d0312d',
-                '#d3d3de', '#03fc62', '#fc0303', '#fada5e', '#8c5efa']); // EtcdLeadership
+                '#d3d3de', '#03fc62', '#fc0303', '#fada5e', '#8c5efa', // EtcdLeadership
+                '#1e7bd9', '#d0312d', '#6aaef2', '#ffa500', '#4294e6', // machine config
+                '#3cb043', '#d0312d', '#ffa500']); // upgrade hops
         myChart.
         data(timelineGroups).
         useUtc(true).
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	jsonserializer "k8s.io/apimachinery/pkg/runtime/serializer/json"
+	"k8s.io/kubectl/pkg/scheme"
+	"sigs.k8s.io/yaml"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/client/v3"
+)
+
+// pageSize limits how many keys are read from etcd at once.
+const pageSize = 500
+
+var (
+	yamlEncoder = jsonserializer.NewSerializerWithOptions(jsonserializer.DefaultMetaFactory, scheme.Scheme, scheme.Scheme, jsonserializer.SerializerOptions{Yaml: true})
+	jsonEncoder = jsonserializer.NewSerializerWithOptions(jsonserializer.DefaultMetaFactory, scheme.Scheme, scheme.Scheme, jsonserializer.SerializerOptions{})
+)
+
+// decodeToYAML decodes a value as stored by the apiserver, protobuf for built-in types and JSON for custom resources,
+// to YAML.
+func decodeToYAML(value []byte) ([]byte, error) {
+	obj, _, err := scheme.Codecs.UniversalDeserializer().Decode(value, nil, nil)
+	if err != nil {
+		// custom resources are not in the scheme but are stored as plain JSON
+		if json.Valid(value) {
+			return yaml.JSONToYAML(value)
+		}
+		return nil, err
+	}
+
+	out := &bytes.Buffer{}
+	if err := yamlEncoder.Encode(obj, out); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// decodeToJSON is decodeToYAML for dump, which writes JSON.
+func decodeToJSON(value []byte) ([]byte, error) {
+	obj, _, err := scheme.Codecs.UniversalDeserializer().Decode(value, nil, nil)
+	if err != nil {
+		// custom resources are not in the scheme but are stored as plain JSON
+		if json.Valid(value) {
+			return value, nil
+		}
+		return nil, err
+	}
+
+	out := &bytes.Buffer{}
+	if err := jsonEncoder.Encode(obj, out); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// decodeToYAMLOrDigest is decodeToYAML for comparisons.  Values which cannot be decoded, like encrypted ones, are
+// represented by their size and digest.
+func decodeToYAMLOrDigest(value []byte) string {
+	decoded, err := decodeToYAML(value)
+	if err != nil {
+		return fmt.Sprintf("# unable to decode %d bytes with sha256 %x: %v\n", len(value), sha256.Sum256(value), err)
+	}
+	return string(decoded)
+}
+
+// resourcePrefix returns the part of a key identifying the resource, like /kubernetes.io/configmaps or
+// /kubernetes.io/operator.openshift.io/etcds.
+func resourcePrefix(key string) string {
+	parts := strings.SplitN(strings.TrimPrefix(key, "/"), "/", 4)
+	switch {
+	case len(parts) >= 3 && strings.Contains(parts[1], "."):
+		return "/" + strings.Join(parts[:3], "/")
+	case len(parts) >= 2:
+		return "/" + strings.Join(parts[:2], "/")
+	default:
+		return key
+	}
+}
+
+// rangePrefix calls fn for every key under prefix at revision, or the current revision if it is zero, in key order.
+// It returns the revision read.
+func rangePrefix(ctx context.Context, client *clientv3.Client, prefix string, revision int64, fn func(kv *mvccpb.KeyValue) error) (int64, error) {
+	end := clientv3.GetPrefixRangeEnd(prefix)
+	key := prefix
+	for {
+		resp, err := clientv3.NewKV(client).Get(ctx, key, clientv3.WithRange(end), clientv3.WithRev(revision), clientv3.WithLimit(pageSize))
+		if err != nil {
+			return 0, err
+		}
+		// read every page at the revision of the first
+		revision = resp.Header.Revision
+		for _, kv := range resp.Kvs {
+			if err := fn(kv); err != nil {
+				return 0, err
+			}
+		}
+		if !resp.More || len(resp.Kvs) == 0 {
+			return revision, nil
+		}
+		key = string(resp.Kvs[len(resp.Kvs)-1].Key) + "\x00"
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"sigs.k8s.io/yaml"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/client/v3"
+)
+
+// diffSnapshots writes the keys added, removed and modified between from and to, each of which is either a revision
+// or a file written by dump, with a unified diff of the YAML of every modified key.
+func diffSnapshots(ctx context.Context, client *clientv3.Client, from, to string, out io.Writer) error {
+	fromValues, err := readSnapshot(ctx, client, from)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", from, err)
+	}
+	toValues, err := readSnapshot(ctx, client, to)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", to, err)
+	}
+
+	keys := []string{}
+	for key := range fromValues {
+		keys = append(keys, key)
+	}
+	for key := range toValues {
+		if _, ok := fromValues[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	added, removed, modified := 0, 0, 0
+	for _, key := range keys {
+		fromValue, inFrom := fromValues[key]
+		toValue, inTo := toValues[key]
+		switch {
+		case !inFrom:
+			added++
+			fmt.Fprintf(out, "+ %s\n", key)
+		case !inTo:
+			removed++
+			fmt.Fprintf(out, "- %s\n", key)
+		case fromValue != toValue:
+			modified++
+			fmt.Fprintf(out, "~ %s\n", key)
+			err := difflib.WriteUnifiedDiff(out, difflib.UnifiedDiff{
+				A:        difflib.SplitLines(fromValue),
+				B:        difflib.SplitLines(toValue),
+				FromFile: from,
+				ToFile:   to,
+				Context:  3,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	fmt.Fprintf(out, "%d added, %d removed, %d modified\n", added, removed, modified)
+	return nil
+}
+
+// readSnapshot returns the YAML of every key at a revision or in a dump file.
+func readSnapshot(ctx context.Context, client *clientv3.Client, source string) (map[string]string, error) {
+	values := map[string]string{}
+
+	if revision, err := strconv.ParseInt(source, 10, 64); err == nil {
+		_, err := rangePrefix(ctx, client, "/", revision, func(kv *mvccpb.KeyValue) error {
+			values[string(kv.Key)] = decodeToYAMLOrDigest(kv.Value)
+			return nil
+		})
+		return values, err
+	}
+
+	content, err := os.ReadFile(source)
+	if err != nil {
+		return nil, err
+	}
+	kvData := []etcd3kv{}
+	if err := json.Unmarshal(content, &kvData); err != nil {
+		return nil, err
+	}
+	for _, kv := range kvData {
+		value, err := yaml.JSONToYAML([]byte(kv.Value))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", kv.Key, err)
+		}
+		values[kv.Key] = string(value)
+	}
+	return values, nil
+}
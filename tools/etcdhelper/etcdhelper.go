@@ -1,13 +1,15 @@
 package main
 
 import (
-	"bytes"
 	"context"
 	"crypto/tls"
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	jsonserializer "k8s.io/apimachinery/pkg/runtime/serializer/json"
@@ -26,14 +28,18 @@ func init() {
 
 func main() {
 	var endpoint, keyFile, certFile, caFile string
+	var revision int64
+	var top int
 	flag.StringVar(&endpoint, "endpoint", "https://127.0.0.1:2379", "etcd endpoint.")
 	flag.StringVar(&keyFile, "key", "", "TLS client key.")
 	flag.StringVar(&certFile, "cert", "", "TLS client certificate.")
 	flag.StringVar(&caFile, "cacert", "", "Server TLS CA certificate.")
+	flag.Int64Var(&revision, "revision", 0, "Revision to start watching from, defaults to the current one.")
+	flag.IntVar(&top, "top", 10, "Number of largest keys listed by stats.")
 	flag.Parse()
 
 	if flag.NArg() == 0 {
-		fmt.Fprint(os.Stderr, "ERROR: you need to specify action: dump or ls [<key>] or get <key>\n")
+		fmt.Fprint(os.Stderr, "ERROR: you need to specify action: dump or ls [<key>] or get <key> or watch [<prefix>] or stats [<prefix>] or diff <from> <to>\n")
 		os.Exit(1)
 	}
 	if flag.Arg(0) == "get" && flag.NArg() == 1 {
@@ -44,6 +50,10 @@ func main() {
 		fmt.Fprint(os.Stderr, "ERROR: you cannot specify positional arguments with dump\n")
 		os.Exit(1)
 	}
+	if flag.Arg(0) == "diff" && flag.NArg() != 3 {
+		fmt.Fprint(os.Stderr, "ERROR: you need to specify <from> and <to> revisions or dump files for diff operation\n")
+		os.Exit(1)
+	}
 	action := flag.Arg(0)
 	key := ""
 	if flag.NArg() > 1 {
@@ -77,13 +87,29 @@ func main() {
 	}
 	defer client.Close()
 
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
 	switch action {
 	case "ls":
 		err = listKeys(client, key)
 	case "get":
 		err = getKey(client, key)
 	case "dump":
-		err = dump(client)
+		err = dump(client, os.Stdout)
+	case "watch":
+		if len(key) == 0 {
+			key = "/"
+		}
+		err = watchPrefix(ctx, client, key, revision, os.Stdout)
+	case "stats":
+		if len(key) == 0 {
+			key = "/"
+		}
+		err = stats(ctx, client, key, top, os.Stdout)
+	case "diff":
+		key = flag.Arg(1) + ".." + flag.Arg(2)
+		err = diffSnapshots(ctx, client, flag.Arg(1), flag.Arg(2), os.Stdout)
 	default:
 		fmt.Fprintf(os.Stderr, "ERROR: invalid action: %s\n", action)
 		os.Exit(1)
@@ -139,33 +165,25 @@ func getKey(client *clientv3.Client, key string) error {
 	return nil
 }
 
-func dump(client *clientv3.Client) error {
+func dump(client *clientv3.Client, out io.Writer) error {
 	response, err := clientv3.NewKV(client).Get(context.Background(), "/", clientv3.WithPrefix(), clientv3.WithSort(clientv3.SortByKey, clientv3.SortDescend))
 	if err != nil {
 		return err
 	}
 
 	kvData := []etcd3kv{}
-	decoder := scheme.Codecs.UniversalDeserializer()
-	encoder := jsonserializer.NewSerializer(jsonserializer.DefaultMetaFactory, scheme.Scheme, scheme.Scheme, false)
-	objJSON := &bytes.Buffer{}
 
 	for _, kv := range response.Kvs {
-		obj, _, err := decoder.Decode(kv.Value, nil, nil)
+		objJSON, err := decodeToJSON(kv.Value)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "WARN: error decoding value %q: %v\n", string(kv.Value), err)
 			continue
 		}
-		objJSON.Reset()
-		if err := encoder.Encode(obj, objJSON); err != nil {
-			fmt.Fprintf(os.Stderr, "WARN: error encoding object %#v as JSON: %v", obj, err)
-			continue
-		}
 		kvData = append(
 			kvData,
 			etcd3kv{
 				Key:            string(kv.Key),
-				Value:          string(objJSON.Bytes()),
+				Value:          string(objJSON),
 				CreateRevision: kv.CreateRevision,
 				ModRevision:    kv.ModRevision,
 				Version:        kv.Version,
@@ -179,7 +197,7 @@ func dump(client *clientv3.Client) error {
 		return err
 	}
 
-	fmt.Println(string(jsonData))
+	fmt.Fprintln(out, string(jsonData))
 
 	return nil
 }
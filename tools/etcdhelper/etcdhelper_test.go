@@ -0,0 +1,326 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer/protobuf"
+	"k8s.io/kubectl/pkg/scheme"
+
+	"go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/embed"
+)
+
+func startEtcd(t *testing.T) *clientv3.Client {
+	t.Helper()
+	localhost := url.URL{Scheme: "http", Host: "127.0.0.1:0"}
+	cfg := embed.NewConfig()
+	cfg.Dir = t.TempDir()
+	cfg.LogLevel = "error"
+	cfg.ListenClientUrls = []url.URL{localhost}
+	cfg.AdvertiseClientUrls = []url.URL{localhost}
+	cfg.ListenPeerUrls = []url.URL{localhost}
+	cfg.AdvertisePeerUrls = []url.URL{localhost}
+	cfg.InitialCluster = cfg.InitialClusterFromName(cfg.Name)
+
+	server, err := embed.StartEtcd(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Close)
+	select {
+	case <-server.Server.ReadyNotify():
+	case <-time.After(30 * time.Second):
+		t.Fatal("etcd did not become ready")
+	}
+
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   []string{server.Clients[0].Addr().String()},
+		DialTimeout: 5 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func put(t *testing.T, client *clientv3.Client, key string, obj runtime.Object) int64 {
+	t.Helper()
+	value := &bytes.Buffer{}
+	if err := protobuf.NewSerializer(scheme.Scheme, scheme.Scheme).Encode(obj, value); err != nil {
+		t.Fatal(err)
+	}
+	return putRaw(t, client, key, value.String())
+}
+
+func putRaw(t *testing.T, client *clientv3.Client, key, value string) int64 {
+	t.Helper()
+	resp, err := client.Put(context.Background(), key, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.Header.Revision
+}
+
+func configMap(name string, data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+		Data:       data,
+	}
+}
+
+func assertContains(t *testing.T, actual string, expected ...string) {
+	t.Helper()
+	for _, curr := range expected {
+		if !strings.Contains(actual, curr) {
+			t.Errorf("missing %q in:\n%s", curr, actual)
+		}
+	}
+}
+
+const customResource = `{"apiVersion":"example.com/v1","kind":"Widget","metadata":{"name":"cluster"},"spec":{"size":3}}`
+
+func TestDecodeToYAML(t *testing.T) {
+	value := &bytes.Buffer{}
+	if err := protobuf.NewSerializer(scheme.Scheme, scheme.Scheme).Encode(configMap("foo", map[string]string{"key": "value"}), value); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := decodeToYAML(value.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertContains(t, string(decoded), "apiVersion: v1\n", "kind: ConfigMap\n", "  key: value\n", "  name: foo\n")
+
+	decoded, err = decodeToYAML([]byte(customResource))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertContains(t, string(decoded), "kind: Widget\n", "  size: 3\n")
+
+	if _, err := decodeToYAML([]byte("k8s:enc:aescbc:v1:key:garbage")); err == nil {
+		t.Error("expected an error decoding an encrypted value")
+	}
+}
+
+func TestResourcePrefix(t *testing.T) {
+	for key, expected := range map[string]string{
+		"/kubernetes.io/configmaps/default/foo":                         "/kubernetes.io/configmaps",
+		"/kubernetes.io/namespaces/default":                             "/kubernetes.io/namespaces",
+		"/kubernetes.io/operator.openshift.io/etcds/cluster":            "/kubernetes.io/operator.openshift.io/etcds",
+		"/kubernetes.io/apiextensions.k8s.io/customresourcedefinitions": "/kubernetes.io/apiextensions.k8s.io/customresourcedefinitions",
+		"/openshift.io/routes/default/foo":                              "/openshift.io/routes",
+		"/registry":                                                     "/registry",
+	} {
+		if actual := resourcePrefix(key); actual != expected {
+			t.Errorf("%s: expected %s, got %s", key, expected, actual)
+		}
+	}
+}
+
+func TestWatch(t *testing.T) {
+	client := startEtcd(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	resp, err := client.Get(ctx, "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := &syncBuffer{}
+	done := make(chan error)
+	go func() {
+		// the watch is established asynchronously, so start it at the next revision to not miss the first writes
+		done <- watchPrefix(ctx, client, "/kubernetes.io/configmaps/", resp.Header.Revision+1, out)
+	}()
+
+	putRaw(t, client, "/kubernetes.io/secrets/default/ignored", "ignored")
+	first := put(t, client, "/kubernetes.io/configmaps/default/foo", configMap("foo", map[string]string{"key": "first"}))
+	put(t, client, "/kubernetes.io/configmaps/default/foo", configMap("foo", map[string]string{"key": "second"}))
+	putRaw(t, client, "/kubernetes.io/configmaps/default/undecodable", "k8s:enc:aescbc:v1:key:garbage")
+	if _, err := client.Delete(context.Background(), "/kubernetes.io/configmaps/default/foo"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"---\n# revision 3: PUT /kubernetes.io/configmaps/default/foo\napiVersion: v1\ndata:\n  key: first\n",
+		"---\n# revision 4: PUT /kubernetes.io/configmaps/default/foo\napiVersion: v1\ndata:\n  key: second\n",
+		"---\n# revision 5: PUT /kubernetes.io/configmaps/default/undecodable\n# unable to decode: ",
+		"---\n# revision 6: DELETE /kubernetes.io/configmaps/default/foo\n",
+	}
+	if first != 3 {
+		t.Fatalf("expected the first configmap at revision 3, got %d", first)
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	for !strings.Contains(out.String(), "DELETE") && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	assertContains(t, out.String(), expected...)
+	if strings.Contains(out.String(), "ignored") {
+		t.Errorf("unexpected change outside of the prefix:\n%s", out.String())
+	}
+
+	// replaying from a revision does not need a running watch
+	replay := &syncBuffer{}
+	replayCtx, replayCancel := context.WithTimeout(context.Background(), time.Second)
+	defer replayCancel()
+	if err := watchPrefix(replayCtx, client, "/kubernetes.io/configmaps/", 4, replay); err != nil {
+		t.Fatal(err)
+	}
+	assertContains(t, replay.String(), expected[1:]...)
+	if strings.Contains(replay.String(), "revision 3") {
+		t.Errorf("unexpected change before the start revision:\n%s", replay.String())
+	}
+}
+
+func TestStats(t *testing.T) {
+	client := startEtcd(t)
+	put(t, client, "/kubernetes.io/configmaps/default/small", configMap("small", nil))
+	put(t, client, "/kubernetes.io/configmaps/default/large", configMap("large", map[string]string{"key": strings.Repeat("x", 1000)}))
+	putRaw(t, client, "/kubernetes.io/example.com/widgets/cluster", customResource)
+	putRaw(t, client, "/other/key", "value")
+
+	out := &bytes.Buffer{}
+	if err := stats(context.Background(), client, "/kubernetes.io/", 1, out); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	fields := [][]string{}
+	for _, line := range lines {
+		fields = append(fields, strings.Fields(line))
+	}
+	if len(fields) != 9 {
+		t.Fatalf("unexpected stats:\n%s", out.String())
+	}
+	if fields[0][0] != "Revision" || fields[2][0] != "RESOURCE" || fields[3][0] != "/kubernetes.io/configmaps" || fields[3][1] != "2" ||
+		fields[4][0] != "/kubernetes.io/example.com/widgets" || fields[4][1] != "1" || fields[4][2] != strconv.Itoa(len(customResource)) ||
+		fields[5][0] != "TOTAL" || fields[5][1] != "3" ||
+		fields[7][0] != "KEY" || fields[8][0] != "/kubernetes.io/configmaps/default/large" {
+		t.Errorf("unexpected stats:\n%s", out.String())
+	}
+}
+
+func TestDiff(t *testing.T) {
+	client := startEtcd(t)
+	put(t, client, "/kubernetes.io/configmaps/default/modified", configMap("modified", map[string]string{"key": "before"}))
+	put(t, client, "/kubernetes.io/configmaps/default/removed", configMap("removed", nil))
+	put(t, client, "/kubernetes.io/configmaps/default/unchanged", configMap("unchanged", nil))
+	from := put(t, client, "/kubernetes.io/configmaps/default/rewritten", configMap("rewritten", nil))
+
+	dir := t.TempDir()
+	fromDump := filepath.Join(dir, "from.json")
+	writeDump(t, client, fromDump)
+
+	put(t, client, "/kubernetes.io/configmaps/default/modified", configMap("modified", map[string]string{"key": "after"}))
+	put(t, client, "/kubernetes.io/configmaps/default/added", configMap("added", nil))
+	put(t, client, "/kubernetes.io/configmaps/default/rewritten", configMap("rewritten", nil))
+	if _, err := client.Delete(context.Background(), "/kubernetes.io/configmaps/default/removed"); err != nil {
+		t.Fatal(err)
+	}
+	to := putRaw(t, client, "/kubernetes.io/example.com/widgets/cluster", customResource)
+
+	toDump := filepath.Join(dir, "to.json")
+	writeDump(t, client, toDump)
+
+	fromRevision, toRevision := strconv.FormatInt(from, 10), strconv.FormatInt(to, 10)
+	for _, test := range []struct {
+		name     string
+		from, to string
+		expected []string
+	}{
+		{
+			name: "revisions",
+			from: fromRevision,
+			to:   toRevision,
+			expected: []string{
+				"+ /kubernetes.io/configmaps/default/added\n",
+				"+ /kubernetes.io/example.com/widgets/cluster\n",
+				"- /kubernetes.io/configmaps/default/removed\n",
+				"~ /kubernetes.io/configmaps/default/modified\n--- " + fromRevision + "\n+++ " + toRevision + "\n",
+				"-  key: before\n+  key: after\n",
+				"2 added, 1 removed, 1 modified\n",
+			},
+		},
+		{
+			name: "dumps",
+			from: fromDump,
+			to:   toDump,
+			expected: []string{
+				"+ /kubernetes.io/configmaps/default/added\n",
+				"+ /kubernetes.io/example.com/widgets/cluster\n",
+				"- /kubernetes.io/configmaps/default/removed\n",
+				"-  key: before\n+  key: after\n",
+				"2 added, 1 removed, 1 modified\n",
+			},
+		},
+		{
+			name:     "dump and revision",
+			from:     fromDump,
+			to:       fromRevision,
+			expected: []string{"0 added, 0 removed, 0 modified\n"},
+		},
+		{
+			// custom resources are kept as raw JSON by both
+			name:     "dump and revision with a custom resource",
+			from:     toDump,
+			to:       toRevision,
+			expected: []string{"0 added, 0 removed, 0 modified\n"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			if err := diffSnapshots(context.Background(), client, test.from, test.to, out); err != nil {
+				t.Fatal(err)
+			}
+			assertContains(t, out.String(), test.expected...)
+			if strings.Contains(out.String(), "unchanged") || strings.Contains(out.String(), "rewritten") {
+				t.Errorf("unexpected unchanged key in:\n%s", out.String())
+			}
+		})
+	}
+}
+
+func writeDump(t *testing.T, client *clientv3.Client, filename string) {
+	t.Helper()
+	out := &bytes.Buffer{}
+	if err := dump(client, out); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filename, out.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe to read while it is written.
+type syncBuffer struct {
+	lock sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.String()
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/client/v3"
+)
+
+type resourceStats struct {
+	Prefix string
+	Count  int
+	Bytes  int
+}
+
+type keyStats struct {
+	Key   string
+	Bytes int
+}
+
+// stats writes the number of keys and the size of their values per resource under prefix, largest first, followed by
+// the top largest keys.
+func stats(ctx context.Context, client *clientv3.Client, prefix string, top int, out io.Writer) error {
+	byPrefix := map[string]*resourceStats{}
+	keys := []keyStats{}
+	total := resourceStats{Prefix: "TOTAL"}
+	revision, err := rangePrefix(ctx, client, prefix, 0, func(kv *mvccpb.KeyValue) error {
+		resource := resourcePrefix(string(kv.Key))
+		if _, ok := byPrefix[resource]; !ok {
+			byPrefix[resource] = &resourceStats{Prefix: resource}
+		}
+		byPrefix[resource].Count++
+		byPrefix[resource].Bytes += len(kv.Value)
+		total.Count++
+		total.Bytes += len(kv.Value)
+		keys = append(keys, keyStats{Key: string(kv.Key), Bytes: len(kv.Value)})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	resources := []*resourceStats{}
+	for _, resource := range byPrefix {
+		resources = append(resources, resource)
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		if resources[i].Bytes != resources[j].Bytes {
+			return resources[i].Bytes > resources[j].Bytes
+		}
+		return resources[i].Prefix < resources[j].Prefix
+	})
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i].Bytes > keys[j].Bytes
+	})
+	if len(keys) > top {
+		keys = keys[:top]
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "Revision %d\n\n", revision)
+	fmt.Fprintf(w, "RESOURCE\tCOUNT\tBYTES\n")
+	for _, resource := range append(resources, &total) {
+		fmt.Fprintf(w, "%s\t%d\t%d\n", resource.Prefix, resource.Count, resource.Bytes)
+	}
+	if len(keys) > 0 {
+		fmt.Fprintf(w, "\nKEY\tBYTES\n")
+		for _, key := range keys {
+			fmt.Fprintf(w, "%s\t%d\n", key.Key, key.Bytes)
+		}
+	}
+	return w.Flush()
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"go.etcd.io/etcd/client/v3"
+)
+
+// watchPrefix writes every change under prefix as a YAML document, starting at revision if it is not zero, until ctx
+// is done.
+func watchPrefix(ctx context.Context, client *clientv3.Client, prefix string, revision int64, out io.Writer) error {
+	opts := []clientv3.OpOption{clientv3.WithPrefix()}
+	if revision > 0 {
+		opts = append(opts, clientv3.WithRev(revision))
+	}
+
+	for resp := range client.Watch(clientv3.WithRequireLeader(ctx), prefix, opts...) {
+		if err := resp.Err(); err != nil {
+			return err
+		}
+		for _, event := range resp.Events {
+			fmt.Fprintf(out, "---\n# revision %d: %s %s\n", event.Kv.ModRevision, event.Type, event.Kv.Key)
+			if event.Type == clientv3.EventTypeDelete {
+				continue
+			}
+			decoded, err := decodeToYAML(event.Kv.Value)
+			if err != nil {
+				fmt.Fprintf(out, "# unable to decode: %v\n", err)
+				continue
+			}
+			if _, err := out.Write(decoded); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -50,8 +50,9 @@ func init() {
 const (
 	junitReportUsageLong = `Consume test output to create jUnit XML files and summarize jUnit XML files.
 
-%[1]s consumes test output through Stdin and creates jUnit XML files. Currently, only the output of 'go test'
-and the output of 'oscmd' functions with $JUNIT_REPORT_OUTPUT set are supported. jUnit XML can be build with
+%[1]s consumes test output through Stdin and creates jUnit XML files. Currently, only the output of 'go test',
+either verbose text or 'go test -json' events, and the output of 'oscmd' functions with $JUNIT_REPORT_OUTPUT set
+are supported. jUnit XML can be build with
 nested or flat test suites. Sub-trees of test suites can be selected when using the nested test-suites represen-
 tation to only build XML for some subset of the test output. This parser is greedy, so all output not directly
 related to a test suite is considered test case output.
@@ -69,6 +70,9 @@ related to a test suite is considered test case output.
   # Consume 'go test' output to create a jUnit XML file, while also printing package output as it is generated
   go test -v -cover ./... | %[1]s --stream > report.xml
 
+  # Consume 'go test -json' output to create a jUnit XML file with exact timing for parallel tests
+  go test -json ./... | %[1]s --type=gotest-json > report.xml
+
   # Consume 'go test' output from a file to create a jUnit XML file
   %[1]s -f testoutput.txt > report.xml
 
@@ -18,6 +18,31 @@ func ExtractRun(line string) (string, bool) {
 	return "", false
 }
 
+// testPausePattern matches the line in verbose `go test` output that marks a parallel test pausing until its parent
+// test completes. The first submatch of this regex is the name of the test
+var testPausePattern = regexp.MustCompile(`^=== PAUSE\s+([^\s]+)$`)
+
+// ExtractPause identifies a parallel test pausing.
+func ExtractPause(line string) (string, bool) {
+	if matches := testPausePattern.FindStringSubmatch(line); len(matches) > 1 && len(matches[1]) > 0 {
+		return matches[1], true
+	}
+	return "", false
+}
+
+// testContinuePattern matches the line in verbose `go test` output that marks the output following it as belonging to
+// a test, either because the paused test continued (CONT) or because it is interleaved with other tests (NAME).
+// The first submatch of this regex is the name of the test
+var testContinuePattern = regexp.MustCompile(`^=== (CONT|NAME)\s+([^\s]+)$`)
+
+// ExtractContinue identifies the continuation of a test output section.
+func ExtractContinue(line string) (string, bool) {
+	if matches := testContinuePattern.FindStringSubmatch(line); len(matches) > 2 && len(matches[2]) > 0 {
+		return matches[2], true
+	}
+	return "", false
+}
+
 // testResultPattern matches the line in verbose `go test` output that marks the result of a test.
 // The first submatch of this regex is the result of the test (PASS, FAIL, or SKIP)
 // The second submatch of this regex is the name of the test
@@ -79,6 +104,19 @@ func ExtractPackage(line string) (name string, duration string, coverage string,
 	return "", "", "", false
 }
 
+// buildFailurePattern matches the `go test` output for a package that failed to build or set up.
+// The first submatch of this regex matches the name of the package
+// The second submatch of this regex matches the reason, like "build failed"
+var buildFailurePattern = regexp.MustCompile(`^FAIL\s+([^\s]+)\s+\[(build failed|setup failed)\]$`)
+
+// ExtractBuildFailure extracts the name of the package and the reason from a package build failure line.
+func ExtractBuildFailure(line string) (name string, reason string, ok bool) {
+	if matches := buildFailurePattern.FindStringSubmatch(line); len(matches) > 2 {
+		return matches[1], matches[2], true
+	}
+	return "", "", false
+}
+
 // ExtractDuration extracts the package duration from a test output line
 func ExtractDuration(line string) (string, bool) {
 	if resultMatches := packageResultPattern.FindStringSubmatch(line); len(resultMatches) > 3 && len(resultMatches[3]) > 0 {
@@ -240,3 +240,111 @@ func TestSuiteProperties(t *testing.T) {
 		}
 	}
 }
+
+func TestExtractPause(t *testing.T) {
+	var testCases = []struct {
+		name         string
+		testLine     string
+		expectedName string
+		fail         bool
+	}{
+		{
+			name:         "basic",
+			testLine:     "=== PAUSE TestOne",
+			expectedName: "TestOne",
+		},
+		{
+			name:         "subtest",
+			testLine:     "=== PAUSE TestNested/one/fast",
+			expectedName: "TestNested/one/fast",
+		},
+		{
+			name:     "continue",
+			testLine: "=== CONT  TestOne",
+			fail:     true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		actual, contained := ExtractPause(testCase.testLine)
+		if testCase.fail == contained {
+			t.Errorf("%s: unexpected result extracting pause from line %q: expected ok to be %v", testCase.name, testCase.testLine, !testCase.fail)
+		}
+		if testCase.expectedName != actual {
+			t.Errorf("%s: did not correctly extract paused test name from line %q: expected %q, got %q", testCase.name, testCase.testLine, testCase.expectedName, actual)
+		}
+	}
+}
+
+func TestExtractContinue(t *testing.T) {
+	var testCases = []struct {
+		name         string
+		testLine     string
+		expectedName string
+		fail         bool
+	}{
+		{
+			name:         "basic",
+			testLine:     "=== CONT  TestOne",
+			expectedName: "TestOne",
+		},
+		{
+			name:         "go 1.14 name",
+			testLine:     "=== NAME  TestNested/two/slow",
+			expectedName: "TestNested/two/slow",
+		},
+		{
+			name:     "pause",
+			testLine: "=== PAUSE TestOne",
+			fail:     true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		actual, contained := ExtractContinue(testCase.testLine)
+		if testCase.fail == contained {
+			t.Errorf("%s: unexpected result extracting continue from line %q: expected ok to be %v", testCase.name, testCase.testLine, !testCase.fail)
+		}
+		if testCase.expectedName != actual {
+			t.Errorf("%s: did not correctly extract continued test name from line %q: expected %q, got %q", testCase.name, testCase.testLine, testCase.expectedName, actual)
+		}
+	}
+}
+
+func TestExtractBuildFailure(t *testing.T) {
+	var testCases = []struct {
+		name           string
+		testLine       string
+		expectedName   string
+		expectedReason string
+		fail           bool
+	}{
+		{
+			name:           "build failed",
+			testLine:       "FAIL	package/name [build failed]",
+			expectedName:   "package/name",
+			expectedReason: "build failed",
+		},
+		{
+			name:           "setup failed",
+			testLine:       "FAIL	package/name [setup failed]",
+			expectedName:   "package/name",
+			expectedReason: "setup failed",
+		},
+		{
+			name:     "test failure",
+			testLine: "FAIL	package/name	0.005s",
+			fail:     true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		name, reason, contained := ExtractBuildFailure(testCase.testLine)
+		if testCase.fail == contained {
+			t.Errorf("%s: unexpected result extracting build failure from line %q: expected ok to be %v", testCase.name, testCase.testLine, !testCase.fail)
+		}
+		if testCase.expectedName != name || testCase.expectedReason != reason {
+			t.Errorf("%s: did not correctly extract build failure from line %q: expected %q %q, got %q %q", testCase.name, testCase.testLine, testCase.expectedName, testCase.expectedReason, name, reason)
+		}
+	}
+}
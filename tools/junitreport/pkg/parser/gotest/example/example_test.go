@@ -5,6 +5,7 @@ package example
 import (
 	"fmt"
 	"testing"
+	"time"
 )
 
 func TestSubTestWithFailures(t *testing.T) {
@@ -37,4 +38,62 @@ func TestSubTestWithMiddleFailures(t *testing.T) {
 	t.Run("subtest-fail-1", func(t *testing.T) { fmt.Printf("text line\n"); t.Logf("log line"); t.Errorf("failed") })
 	t.Run("subtest-pass-2", func(t *testing.T) {})
 }
+
+// Output for the parallel, panic and build failure fixtures is generated from separate packages
+
+func TestParallelA(t *testing.T) {
+	t.Parallel()
+	time.Sleep(20 * time.Millisecond)
+	t.Log("log from A")
+}
+
+func TestParallelB(t *testing.T) {
+	t.Parallel()
+	t.Log("log from B")
+	t.Error("B failed")
+}
+
+func TestNested(t *testing.T) {
+	for _, name := range []string{"one", "two"} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			for _, inner := range []string{"fast", "slow"} {
+				t.Run(inner, func(t *testing.T) {
+					t.Parallel()
+					if inner == "slow" {
+						time.Sleep(10 * time.Millisecond)
+					}
+					fmt.Printf("stdout from %s\n", t.Name())
+					t.Logf("log from %s", t.Name())
+					if name == "two" && inner == "slow" {
+						t.Errorf("failed %s", t.Name())
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestSkipped(t *testing.T) {
+	t.Parallel()
+	t.Skip("not today")
+}
+
+func TestBefore(t *testing.T) {
+	t.Log("fine")
+}
+
+func TestPanics(t *testing.T) {
+	t.Run("sub", func(t *testing.T) {
+		var m map[string]int
+		m["boom"] = 1
+	})
+}
+
+func TestNeverRuns(t *testing.T) {
+}
+
+func TestBroken(t *testing.T) {
+	undefinedFunction()
+}
 */
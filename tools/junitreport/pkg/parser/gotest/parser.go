@@ -37,17 +37,97 @@ func log(format string, args ...interface{}) {
 // Parse parses `go test -v` output into test suites. Test output from `go test -v` is not bookmarked for packages, so
 // the parsing strategy is to advance line-by-line, building up a slice of test cases until a package declaration is found,
 // at which point all tests cases are added to that package and the process can start again.
+//
+// Parallel tests interleave their output: `=== PAUSE` lines are dropped and `=== CONT` or `=== NAME` lines switch the
+// test that following output belongs to.  Results of top-level tests may appear in any order.
 func (p *testOutputParser) Parse(input *bufio.Scanner) (*api.TestSuites, error) {
 	suites := &api.TestSuites{}
 
 	var testNameStack []string
 	var tests map[string]*api.TestCase
+	var finished map[string]bool
 	var output map[string][]string
 	var messages map[string][]string
 	var currentSuite *api.TestSuite
 	var state int
 	var count int
 	var orderedTests []string
+	// preamble holds the output outside of any suite, which is where build failures are reported
+	var preamble []string
+
+	// recordResult sets the result and duration of a test that has been declared
+	recordResult := func(result api.TestResult, name, duration string) error {
+		test := tests[name]
+		switch result {
+		case api.TestResultPass:
+		case api.TestResultFail:
+			test.FailureOutput = &api.FailureOutput{}
+		case api.TestResultSkip:
+			test.SkipMessage = &api.SkipMessage{}
+		}
+		finished[name] = true
+		if err := test.SetDuration(duration); err != nil {
+			return fmt.Errorf("unexpected duration on line %d: %s", count, duration)
+		}
+		return nil
+	}
+
+	// completeSuite adds the current suite if the line ends it
+	completeSuite := func(line string) (bool, error) {
+		name, duration, coverage, ok := ExtractPackage(line)
+		if !ok {
+			return false, nil
+		}
+		currentSuite.Name = name
+		if props, ok := ExtractProperties(coverage); ok {
+			for k, v := range props {
+				currentSuite.AddProperty(k, v)
+			}
+		}
+		for _, name := range orderedTests {
+			test := tests[name]
+			messageLines := messages[name]
+			var extraOutput []string
+			for i, s := range messageLines {
+				if s == "=== OUTPUT" {
+					log("test %s has OUTPUT section, %d %d\n", name, i, len(messageLines))
+					if i < len(messageLines) {
+						log("  test %s add lines: %d\n", name, len(messageLines[i+1:]))
+						extraOutput = messageLines[i+1:]
+					}
+					messageLines = messageLines[:i]
+					break
+				}
+			}
+
+			// a test without a result in a failed package was interrupted, by a panic or a timeout
+			if !finished[name] && strings.HasPrefix(line, "FAIL") {
+				test.FailureOutput = &api.FailureOutput{}
+			}
+
+			switch {
+			case test.FailureOutput != nil:
+				test.FailureOutput.Output = strings.Join(messageLines, "\n")
+
+				lines := append(output[name], extraOutput...)
+				test.SystemOut = strings.Join(lines, "\n")
+
+			case test.SkipMessage != nil:
+				test.SkipMessage.Message = strings.Join(messageLines, "\n")
+
+			default:
+				lines := append(output[name], extraOutput...)
+				test.SystemOut = strings.Join(lines, "\n")
+			}
+
+			currentSuite.AddTestCase(test)
+		}
+		if err := currentSuite.SetDuration(duration); err != nil {
+			return false, fmt.Errorf("unexpected duration on line %d: %s", count, duration)
+		}
+		suites.Suites = append(suites.Suites, currentSuite)
+		return true, nil
+	}
 
 	for input.Scan() {
 		line := input.Text()
@@ -59,19 +139,29 @@ func (p *testOutputParser) Parse(input *bufio.Scanner) (*api.TestSuites, error)
 
 		case stateBegin:
 			// this is the first state
+			if name, reason, ok := ExtractBuildFailure(line); ok {
+				log("  found build failure %s\n", name)
+				suites.Suites = append(suites.Suites, buildFailureSuite(name, reason, preamble))
+				preamble = nil
+				continue
+			}
+
 			name, ok := ExtractRun(line)
 			if !ok {
 				// A test that defines a test.M handler can write output prior to test execution. We will drop this because
 				// we have no place to put it, although the first test case *could* use it in the future.
 				log("  ignored output outside of suite\n")
+				preamble = append(preamble, line)
 				continue
 			}
 			log("  found run command %s\n", name)
 
 			currentSuite = &api.TestSuite{}
 			tests = make(map[string]*api.TestCase)
+			finished = make(map[string]bool)
 			output = make(map[string][]string)
 			messages = make(map[string][]string)
+			preamble = nil
 
 			orderedTests = []string{name}
 			testNameStack = []string{name}
@@ -97,25 +187,38 @@ func (p *testOutputParser) Parse(input *bufio.Scanner) (*api.TestSuites, error)
 				continue
 			}
 
+			// a parallel test is paused until its parent completes, nothing to attribute
+			if _, ok := ExtractPause(line); ok {
+				continue
+			}
+
+			// switch the test gathering output
+			if name, ok := ExtractContinue(line); ok && tests[name] != nil {
+				log("  found continue %s\n", name)
+				testNameStack = []string{name}
+				continue
+			}
+
 			// transition to result mode ONLY if it matches a result at the top level
 			if result, name, depth, duration, ok := ExtractResult(line); ok && tests[name] != nil && depth == 0 {
-				test := tests[name]
 				log("  found result %s %s %s\n", result, name, duration)
-				switch result {
-				case api.TestResultPass:
-				case api.TestResultFail:
-					test.FailureOutput = &api.FailureOutput{}
-				case api.TestResultSkip:
-					test.SkipMessage = &api.SkipMessage{}
-				}
-				if err := test.SetDuration(duration); err != nil {
-					return nil, fmt.Errorf("unexpected duration on line %d: %s", count, duration)
+				if err := recordResult(result, name, duration); err != nil {
+					return nil, err
 				}
 				testNameStack = []string{name}
 				state = stateResults
 				continue
 			}
 
+			// a test panicked or timed out before any result was written
+			if ok, err := completeSuite(line); err != nil {
+				return nil, err
+			} else if ok {
+				log("  found end of suite without results\n")
+				state = stateBegin
+				continue
+			}
+
 			// in output mode, turn output lines into output on the particular test
 			if _, _, ok := ExtractOutput(line); ok {
 				log("  found output\n")
@@ -144,6 +247,33 @@ func (p *testOutputParser) Parse(input *bufio.Scanner) (*api.TestSuites, error)
 					state = stateOutput
 					continue
 				}
+				if _, ok := ExtractPause(line); ok {
+					continue
+				}
+				// a paused parallel test continues after the results of the tests before it
+				if name, ok := ExtractContinue(line); ok && tests[name] != nil {
+					log("  found continue %s\n", name)
+					testNameStack = []string{name}
+					state = stateOutput
+					continue
+				}
+				// parallel top-level tests finish in any order
+				if result, name, _, duration, ok := ExtractResult(line); ok && tests[name] != nil {
+					log("  found result %s %s %s\n", result, name, duration)
+					if err := recordResult(result, name, duration); err != nil {
+						return nil, err
+					}
+					testNameStack = []string{name}
+					continue
+				}
+				// a panic ends the suite without the PASS or FAIL line
+				if ok, err := completeSuite(line); err != nil {
+					return nil, err
+				} else if ok {
+					log("  found end of suite after panic\n")
+					state = stateBegin
+					continue
+				}
 				switch {
 				case line == "PASS", line == "FAIL":
 					log("  found end of suite\n")
@@ -165,16 +295,8 @@ func (p *testOutputParser) Parse(input *bufio.Scanner) (*api.TestSuites, error)
 			// if this is a result AND we have already declared this as a test, parse it
 			if result, name, _, duration, ok := ExtractResult(output); ok && tests[name] != nil {
 				log("  found result %s %s (%d)\n", result, name, depth)
-				test := tests[name]
-				switch result {
-				case api.TestResultPass:
-				case api.TestResultFail:
-					test.FailureOutput = &api.FailureOutput{}
-				case api.TestResultSkip:
-					test.SkipMessage = &api.SkipMessage{}
-				}
-				if err := test.SetDuration(duration); err != nil {
-					return nil, fmt.Errorf("unexpected duration on line %d: %s", count, duration)
+				if err := recordResult(result, name, duration); err != nil {
+					return nil, err
 				}
 				switch {
 				case depth >= len(testNameStack):
@@ -208,51 +330,9 @@ func (p *testOutputParser) Parse(input *bufio.Scanner) (*api.TestSuites, error)
 
 		case stateComplete:
 			// suite exit line
-			if name, duration, coverage, ok := ExtractPackage(line); ok {
-				currentSuite.Name = name
-				if props, ok := ExtractProperties(coverage); ok {
-					for k, v := range props {
-						currentSuite.AddProperty(k, v)
-					}
-				}
-				for _, name := range orderedTests {
-					test := tests[name]
-					messageLines := messages[name]
-					var extraOutput []string
-					for i, s := range messageLines {
-						if s == "=== OUTPUT" {
-							log("test %s has OUTPUT section, %d %d\n", name, i, len(messageLines))
-							if i < len(messageLines) {
-								log("  test %s add lines: %d\n", name, len(messageLines[i+1:]))
-								extraOutput = messageLines[i+1:]
-							}
-							messageLines = messageLines[:i]
-							break
-						}
-					}
-
-					switch {
-					case test.FailureOutput != nil:
-						test.FailureOutput.Output = strings.Join(messageLines, "\n")
-
-						lines := append(output[name], extraOutput...)
-						test.SystemOut = strings.Join(lines, "\n")
-
-					case test.SkipMessage != nil:
-						test.SkipMessage.Message = strings.Join(messageLines, "\n")
-
-					default:
-						lines := append(output[name], extraOutput...)
-						test.SystemOut = strings.Join(lines, "\n")
-					}
-
-					currentSuite.AddTestCase(test)
-				}
-				if err := currentSuite.SetDuration(duration); err != nil {
-					return nil, fmt.Errorf("unexpected duration on line %d: %s", count, duration)
-				}
-				suites.Suites = append(suites.Suites, currentSuite)
-
+			if ok, err := completeSuite(line); err != nil {
+				return nil, err
+			} else if ok {
 				state = stateBegin
 				continue
 			}
@@ -268,3 +348,24 @@ func (p *testOutputParser) Parse(input *bufio.Scanner) (*api.TestSuites, error)
 
 	return suites, nil
 }
+
+// buildFailureSuite returns a suite for a package that failed to build, with a single failed test case holding the
+// compiler output for the package from the preamble.
+func buildFailureSuite(name, reason string, preamble []string) *api.TestSuite {
+	var buildOutput []string
+	for i := len(preamble) - 1; i >= 0; i-- {
+		if preamble[i] == "# "+name || strings.HasPrefix(preamble[i], "# "+name+" ") {
+			buildOutput = preamble[i:]
+			break
+		}
+	}
+
+	suite := &api.TestSuite{Name: name}
+	suite.AddTestCase(&api.TestCase{
+		Name: "[" + reason + "]",
+		FailureOutput: &api.FailureOutput{
+			Output: strings.Join(buildOutput, "\n"),
+		},
+	})
+	return suite
+}
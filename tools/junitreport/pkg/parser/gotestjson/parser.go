@@ -0,0 +1,240 @@
+package gotestjson
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/openshift/origin/tools/junitreport/pkg/api"
+	"github.com/openshift/origin/tools/junitreport/pkg/builder"
+	"github.com/openshift/origin/tools/junitreport/pkg/parser"
+	"github.com/openshift/origin/tools/junitreport/pkg/parser/gotest"
+)
+
+// NewParser returns a new parser that's capable of parsing `go test -json` output
+func NewParser(builder builder.TestSuitesBuilder, stream bool) parser.TestOutputParser {
+	return &testOutputParser{
+		builder: builder,
+		stream:  stream,
+	}
+}
+
+// event is a single line of `go test -json` output, as documented by `go doc test2json`
+type event struct {
+	Time       time.Time
+	Action     string
+	Package    string
+	Test       string
+	Elapsed    float64
+	Output     string
+	OutputType string
+
+	// ImportPath identifies the package build output belongs to, which FailedBuild refers to when it fails
+	ImportPath  string
+	FailedBuild string
+}
+
+// testOutputParser parses the events of every package into a test suite. As each event names its package and test,
+// interleaved output of parallel tests and packages is attributed exactly, unlike in the verbose text output.
+type testOutputParser struct {
+	builder builder.TestSuitesBuilder
+	stream  bool
+}
+
+// packageState holds the tests of a package until the package completes
+type packageState struct {
+	tests        map[string]*api.TestCase
+	orderedTests []string
+	output       map[string]*strings.Builder
+	// started is when each test last started running, excluding the time parallel tests were paused
+	started    map[string]time.Time
+	finished   map[string]bool
+	properties map[string]string
+	// buildFailure is the reason the package did not run, like "build failed"
+	buildFailure string
+}
+
+func newPackageState() *packageState {
+	return &packageState{
+		tests:      map[string]*api.TestCase{},
+		output:     map[string]*strings.Builder{},
+		started:    map[string]time.Time{},
+		finished:   map[string]bool{},
+		properties: map[string]string{},
+	}
+}
+
+// Parse parses `go test -json` output into test suites, one per package that ran tests or failed
+func (p *testOutputParser) Parse(input *bufio.Scanner) (*api.TestSuites, error) {
+	packages := map[string]*packageState{}
+	buildOutput := map[string][]string{}
+	count := 0
+
+	for input.Scan() {
+		line := input.Bytes()
+		count++
+
+		// the go command writes some failures, like build failures before go 1.24, as plain text
+		if len(line) == 0 || line[0] != '{' {
+			continue
+		}
+		e := &event{}
+		if err := json.Unmarshal(line, e); err != nil {
+			return nil, fmt.Errorf("unexpected event on line %d: %v", count, err)
+		}
+
+		if e.Action == "build-output" {
+			buildOutput[e.ImportPath] = append(buildOutput[e.ImportPath], strings.TrimSuffix(e.Output, "\n"))
+			continue
+		}
+		if len(e.Package) == 0 {
+			continue
+		}
+		state, ok := packages[e.Package]
+		if !ok {
+			state = newPackageState()
+			packages[e.Package] = state
+		}
+
+		if len(e.Test) == 0 {
+			switch e.Action {
+			case "output":
+				if props, ok := gotest.ExtractProperties(e.Output); ok {
+					for k, v := range props {
+						state.properties[k] = v
+					}
+				}
+				if _, reason, ok := gotest.ExtractBuildFailure(strings.TrimSuffix(e.Output, "\n")); ok {
+					state.buildFailure = reason
+				}
+				if p.stream && isPackageResult(e.Output) {
+					fmt.Fprint(os.Stdout, e.Output)
+				}
+			case "pass", "fail", "skip":
+				if suite := p.completePackage(e, state, buildOutput[e.FailedBuild]); suite != nil {
+					p.builder.AddSuite(suite)
+				}
+				delete(packages, e.Package)
+			}
+			continue
+		}
+
+		test, ok := state.tests[e.Test]
+		if !ok {
+			test = &api.TestCase{Name: e.Test}
+			state.tests[e.Test] = test
+			state.orderedTests = append(state.orderedTests, e.Test)
+			state.output[e.Test] = &strings.Builder{}
+		}
+		switch e.Action {
+		case "run", "cont":
+			state.started[e.Test] = e.Time
+		case "output":
+			if !isFrame(e) {
+				state.output[e.Test].WriteString(e.Output)
+			}
+		case "pass", "fail", "skip":
+			state.finished[e.Test] = true
+			switch e.Action {
+			case "fail":
+				test.FailureOutput = &api.FailureOutput{}
+			case "skip":
+				test.SkipMessage = &api.SkipMessage{}
+			}
+			duration := time.Duration(e.Elapsed * float64(time.Second))
+			if started := state.started[e.Test]; !started.IsZero() && !e.Time.IsZero() {
+				duration = e.Time.Sub(started)
+			}
+			if err := test.SetDuration(duration.String()); err != nil {
+				return nil, fmt.Errorf("unexpected duration on line %d: %v", count, err)
+			}
+		}
+	}
+
+	return p.builder.Build(), nil
+}
+
+// completePackage returns the test suite for a completed package, or nil for a package without tests that passed
+func (p *testOutputParser) completePackage(e *event, state *packageState, buildOutput []string) *api.TestSuite {
+	suite := &api.TestSuite{Name: e.Package}
+	for k, v := range state.properties {
+		suite.AddProperty(k, v)
+	}
+
+	for _, name := range state.orderedTests {
+		test := state.tests[name]
+		output := strings.TrimSuffix(state.output[name].String(), "\n")
+
+		// a test without a result in a failed package was interrupted, by a panic or a timeout
+		if !state.finished[name] && e.Action == "fail" {
+			test.FailureOutput = &api.FailureOutput{}
+		}
+
+		switch {
+		case test.FailureOutput != nil:
+			test.FailureOutput.Output = output
+		case test.SkipMessage != nil:
+			test.SkipMessage.Message = output
+		default:
+			test.SystemOut = output
+		}
+		suite.AddTestCase(test)
+	}
+
+	if len(state.buildFailure) > 0 {
+		suite.AddTestCase(&api.TestCase{
+			Name: "[" + state.buildFailure + "]",
+			FailureOutput: &api.FailureOutput{
+				Output: strings.Join(buildOutput, "\n"),
+			},
+		})
+	}
+
+	if suite.NumTests == 0 {
+		if e.Action != "fail" {
+			return nil
+		}
+		// the package failed outside of any test, like in TestMain
+		suite.AddTestCase(&api.TestCase{Name: "[package failed]", FailureOutput: &api.FailureOutput{}})
+	}
+
+	// the package elapsed time covers tests running in parallel, so it is used instead of their sum
+	suite.Duration = float64(int(e.Elapsed*1000)) / 1000
+	return suite
+}
+
+// isFrame determines if test output is written by the testing package to delimit tests, rather than by the test.
+// Before go 1.24, events do not have an OutputType and the lines are matched instead.
+func isFrame(e *event) bool {
+	if e.OutputType == "frame" {
+		return true
+	}
+	if len(e.OutputType) > 0 {
+		return false
+	}
+	line := strings.TrimSuffix(e.Output, "\n")
+	if _, ok := gotest.ExtractRun(line); ok {
+		return true
+	}
+	if _, ok := gotest.ExtractPause(line); ok {
+		return true
+	}
+	if _, ok := gotest.ExtractContinue(line); ok {
+		return true
+	}
+	_, _, _, _, ok := gotest.ExtractResult(line)
+	return ok
+}
+
+// isPackageResult determines if package output is the line with its result
+func isPackageResult(output string) bool {
+	line := strings.TrimSuffix(output, "\n")
+	if _, _, _, ok := gotest.ExtractPackage(line); ok {
+		return true
+	}
+	_, _, ok := gotest.ExtractBuildFailure(line)
+	return ok
+}
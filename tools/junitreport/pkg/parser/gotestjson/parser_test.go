@@ -0,0 +1,127 @@
+package gotestjson
+
+import (
+	"bufio"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/openshift/origin/tools/junitreport/pkg/api"
+	"github.com/openshift/origin/tools/junitreport/pkg/builder/flat"
+)
+
+// TestParse tests that parsing the `go test -json` output in the test directory attributes results, output and timing
+// to the right tests
+func TestParse(t *testing.T) {
+	var testCases = []struct {
+		name              string
+		testFile          string
+		expectedSuite     string
+		expectedDuration  float64
+		expectedFailed    []string
+		expectedSkipped   []string
+		expectedPassed    []string
+		expectedDurations map[string]float64
+		expectedOutput    map[string]string
+	}{
+		{
+			name:             "nested parallel subtests",
+			testFile:         "1.txt",
+			expectedSuite:    "package/parallel",
+			expectedDuration: 0.044,
+			expectedFailed:   []string{"TestParallelB", "TestNested", "TestNested/two", "TestNested/two/slow"},
+			expectedSkipped:  []string{"TestSkipped"},
+			expectedPassed:   []string{"TestParallelA", "TestNested/one", "TestNested/one/fast", "TestNested/one/slow", "TestNested/two/fast"},
+			expectedDurations: map[string]float64{
+				// paused until TestNested returns, so only the time spent running is counted
+				"TestParallelA":       0.02,
+				"TestNested/two/slow": 0.01,
+			},
+			expectedOutput: map[string]string{
+				"TestParallelB":       "    parallel_test.go:17: log from B\n    parallel_test.go:18: B failed",
+				"TestNested/two/slow": "stdout from TestNested/two/slow\n    parallel_test.go:32: log from TestNested/two/slow\n    parallel_test.go:34: failed TestNested/two/slow",
+			},
+		},
+		{
+			name:             "panic",
+			testFile:         "2.txt",
+			expectedSuite:    "package/panics",
+			expectedDuration: 0.007,
+			expectedFailed:   []string{"TestPanics", "TestPanics/sub"},
+			expectedPassed:   []string{"TestBefore"},
+		},
+		{
+			name:           "build failure",
+			testFile:       "3.txt",
+			expectedSuite:  "package/buildfail",
+			expectedFailed: []string{"[build failed]"},
+			expectedOutput: map[string]string{
+				"[build failed]": "# package/buildfail [package/buildfail.test]\nbuildfail/buildfail_test.go:6:2: undefined: undefinedFunction",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			testFile := "./../../../test/gotest-json/testdata/" + testCase.testFile
+
+			reader, err := os.Open(testFile)
+			if err != nil {
+				t.Fatalf("unexpected error opening file %q: %v", testFile, err)
+			}
+			defer reader.Close()
+
+			testSuites, err := NewParser(flat.NewTestSuitesBuilder(), false).Parse(bufio.NewScanner(reader))
+			if err != nil {
+				t.Fatalf("unexpected error parsing file %q: %v", testFile, err)
+			}
+			if len(testSuites.Suites) != 1 {
+				t.Fatalf("expected one suite, got %d", len(testSuites.Suites))
+			}
+			suite := testSuites.Suites[0]
+			if suite.Name != testCase.expectedSuite {
+				t.Errorf("expected suite %q, got %q", testCase.expectedSuite, suite.Name)
+			}
+			if suite.Duration != testCase.expectedDuration {
+				t.Errorf("expected suite duration %v, got %v", testCase.expectedDuration, suite.Duration)
+			}
+
+			var failed, skipped, passed []string
+			testCases := map[string]*api.TestCase{}
+			for _, test := range suite.TestCases {
+				testCases[test.Name] = test
+				switch {
+				case test.FailureOutput != nil:
+					failed = append(failed, test.Name)
+				case test.SkipMessage != nil:
+					skipped = append(skipped, test.Name)
+				default:
+					passed = append(passed, test.Name)
+				}
+			}
+			if !reflect.DeepEqual(failed, testCase.expectedFailed) {
+				t.Errorf("expected failed tests %v, got %v", testCase.expectedFailed, failed)
+			}
+			if !reflect.DeepEqual(skipped, testCase.expectedSkipped) {
+				t.Errorf("expected skipped tests %v, got %v", testCase.expectedSkipped, skipped)
+			}
+			if !reflect.DeepEqual(passed, testCase.expectedPassed) {
+				t.Errorf("expected passed tests %v, got %v", testCase.expectedPassed, passed)
+			}
+			if suite.NumTests != uint(len(suite.TestCases)) || suite.NumFailed != uint(len(failed)) || suite.NumSkipped != uint(len(skipped)) {
+				t.Errorf("suite counts do not match its test cases: %d tests, %d failed, %d skipped", suite.NumTests, suite.NumFailed, suite.NumSkipped)
+			}
+
+			for name, expected := range testCase.expectedDurations {
+				if actual := testCases[name].Duration; actual != expected {
+					t.Errorf("expected %s to take %v, got %v", name, expected, actual)
+				}
+			}
+			for name, expected := range testCase.expectedOutput {
+				if actual := testCases[name].FailureOutput.Output; actual != expected {
+					t.Errorf("expected %s to fail with output %q, got %q", name, expected, actual)
+				}
+			}
+		})
+	}
+}